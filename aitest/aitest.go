@@ -0,0 +1,485 @@
+// Package aitest provides fake OpenAI and Claude HTTP servers for tests. Unlike
+// mock.MockClient, the fakes exercise the real clients end to end: request encoding,
+// response parsing, streaming, and error handling.
+//
+// Each server answers from replies queued with Enqueue and then from Default, and
+// records every request it receives:
+//
+//	srv := aitest.NewFakeOpenAIServer(t)
+//	srv.Enqueue(aitest.Text("hello"), aitest.Error(http.StatusBadRequest, "invalid_request_error", "bad input"))
+//
+//	aiClient, err := client.NewClientFactory().CreateClient(srv.Config())
+//	response, err := aiClient.CallWithPrompt(ctx, "hi") // a chat completion with content "hello"
+//	fmt.Println(srv.Requests()[0].Prompt)               // "hi"
+//
+// The OpenAI client retries 429 and 5xx responses, and each retry consumes a reply.
+// Set Default to an error reply to make a failure persist across retries.
+package aitest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kengibson1111/go-aiprovider/types"
+)
+
+// Fake API keys used by Config. They carry the real key prefixes so configuration
+// validation passes.
+const (
+	FakeOpenAIKey = "sk-fake-openai-key"
+	FakeClaudeKey = "sk-ant-fake-claude-key"
+)
+
+// Reply is the fake server's answer to one completion request.
+type Reply struct {
+	// Text is the generated text for a successful reply.
+	Text string
+
+	// Chunks, if set, are the text deltas sent when the request asks for a stream.
+	// Streams of a reply without Chunks send Text as a single delta.
+	Chunks []string
+
+	// StopReason is the finish reason ("stop" for OpenAI, "end_turn" for Claude by default).
+	StopReason string
+
+	// Status, ErrorType, and ErrorMessage describe an error reply in the provider's error
+	// format. Status 0 or 200 is a successful reply.
+	Status       int
+	ErrorType    string
+	ErrorMessage string
+
+	// Delay is how long the server waits before answering.
+	Delay time.Duration
+
+	// Handler, if set, answers the request instead, for responses the fields above
+	// can't describe.
+	Handler http.HandlerFunc
+}
+
+// Text returns a successful reply with the given text.
+func Text(text string) Reply {
+	return Reply{Text: text}
+}
+
+// Stream returns a reply that streams the given deltas. Non-streaming requests receive
+// the deltas joined as a single message.
+func Stream(chunks ...string) Reply {
+	return Reply{Text: strings.Join(chunks, ""), Chunks: chunks}
+}
+
+// Error returns an error reply. errType is the provider's error type or code, for example
+// "rate_limit_exceeded" for OpenAI or "overloaded_error" for Claude.
+func Error(status int, errType, message string) Reply {
+	return Reply{Status: status, ErrorType: errType, ErrorMessage: message}
+}
+
+// Request is a request received by a fake server.
+type Request struct {
+	Method string
+	Path   string
+	Header http.Header
+	Body   []byte
+
+	// Prompt is the text of the last user message, and Stream reports whether a
+	// stream was requested. Both are empty for requests other than completions.
+	Prompt string
+	Stream bool
+
+	// Model is the model named in the request body.
+	Model string
+}
+
+// FakeServer is a fake provider API backed by httptest.Server. Configure it before
+// sending requests; the methods are safe for concurrent use.
+type FakeServer struct {
+	*httptest.Server
+
+	// Default answers completion requests once the queue is empty.
+	Default Reply
+
+	// Models is returned from the models endpoint.
+	Models []string
+
+	provider string
+	mu       sync.Mutex
+	queue    []Reply
+	requests []Request
+}
+
+// NewFakeOpenAIServer starts a fake OpenAI API serving POST /chat/completions (with SSE
+// streaming) and GET /models. The server is closed when the test ends. Default answers
+// "fake response".
+func NewFakeOpenAIServer(t testing.TB) *FakeServer {
+	return newFakeServer(t, types.ProviderOpenAI, []string{"gpt-4o-mini", "gpt-4o"})
+}
+
+// NewFakeClaudeServer starts a fake Claude API serving POST /v1/messages (with SSE
+// streaming) and GET /v1/models. The server is closed when the test ends. Default answers
+// "fake response".
+func NewFakeClaudeServer(t testing.TB) *FakeServer {
+	return newFakeServer(t, types.ProviderClaude, []string{"claude-sonnet-4-6", "claude-haiku-4-5"})
+}
+
+func newFakeServer(t testing.TB, provider string, models []string) *FakeServer {
+	s := &FakeServer{
+		Default:  Text("fake response"),
+		Models:   models,
+		provider: provider,
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Config returns a client configuration for the server's provider pointing at the server.
+func (s *FakeServer) Config() *types.AIConfig {
+	config := &types.AIConfig{
+		Provider: s.provider,
+		APIKey:   FakeOpenAIKey,
+		BaseURL:  s.URL,
+	}
+	if s.provider == types.ProviderClaude {
+		config.APIKey = FakeClaudeKey
+	}
+	return config
+}
+
+// Enqueue adds replies to be returned, in order, by the next completion requests.
+func (s *FakeServer) Enqueue(replies ...Reply) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queue = append(s.queue, replies...)
+}
+
+// Requests returns a copy of the requests received so far.
+func (s *FakeServer) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// Reset clears queued replies and recorded requests. Default and Models are kept.
+func (s *FakeServer) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queue = nil
+	s.requests = nil
+}
+
+// serve routes a request to the provider's handlers.
+func (s *FakeServer) serve(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	req := Request{Method: r.Method, Path: r.URL.Path, Header: r.Header.Clone(), Body: body}
+
+	completions, models := "/chat/completions", "/models"
+	if s.provider == types.ProviderClaude {
+		completions, models = "/v1/messages", "/v1/models"
+	}
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == models:
+		s.record(req)
+		s.writeModels(w)
+	case r.Method == http.MethodPost && r.URL.Path == completions:
+		parseCompletionRequest(&req)
+		reply := s.next(req)
+		if reply.Delay > 0 {
+			select {
+			case <-time.After(reply.Delay):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		r.Body = io.NopCloser(strings.NewReader(string(body)))
+		s.writeReply(w, r, req, reply)
+	default:
+		s.record(req)
+		s.writeError(w, Error(http.StatusNotFound, "not_found_error", fmt.Sprintf("no fake route for %s %s", r.Method, r.URL.Path)))
+	}
+}
+
+// record appends a request to the log.
+func (s *FakeServer) record(req Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, req)
+}
+
+// next records the request and takes the next queued reply, or Default.
+func (s *FakeServer) next(req Request) Reply {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, req)
+	if len(s.queue) > 0 {
+		reply := s.queue[0]
+		s.queue = s.queue[1:]
+		return reply
+	}
+	return s.Default
+}
+
+// parseCompletionRequest fills in the prompt, stream flag, and model from the body.
+// Message content may be a string or a list of content parts in either provider's format.
+func parseCompletionRequest(req *Request) {
+	var body struct {
+		Model    string `json:"model"`
+		Stream   bool   `json:"stream"`
+		Messages []struct {
+			Role    string          `json:"role"`
+			Content json.RawMessage `json:"content"`
+		} `json:"messages"`
+	}
+	if err := json.Unmarshal(req.Body, &body); err != nil {
+		return
+	}
+	req.Model = body.Model
+	req.Stream = body.Stream
+
+	for i := len(body.Messages) - 1; i >= 0; i-- {
+		if body.Messages[i].Role != "user" {
+			continue
+		}
+		var text string
+		if err := json.Unmarshal(body.Messages[i].Content, &text); err == nil {
+			req.Prompt = text
+			return
+		}
+		var parts []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		}
+		if err := json.Unmarshal(body.Messages[i].Content, &parts); err == nil {
+			for _, part := range parts {
+				if part.Type == "text" {
+					req.Prompt += part.Text
+				}
+			}
+		}
+		return
+	}
+}
+
+// writeReply writes a reply in the provider's format.
+func (s *FakeServer) writeReply(w http.ResponseWriter, r *http.Request, req Request, reply Reply) {
+	switch {
+	case reply.Handler != nil:
+		reply.Handler(w, r)
+	case reply.Status != 0 && reply.Status != http.StatusOK:
+		s.writeError(w, reply)
+	case req.Stream && s.provider == types.ProviderClaude:
+		writeClaudeStream(w, req, reply)
+	case req.Stream:
+		writeOpenAIStream(w, req, reply)
+	case s.provider == types.ProviderClaude:
+		writeJSON(w, http.StatusOK, claudeMessage(req, reply))
+	default:
+		writeJSON(w, http.StatusOK, openAICompletion(req, reply))
+	}
+}
+
+// writeError writes an error reply in the provider's error format.
+func (s *FakeServer) writeError(w http.ResponseWriter, reply Reply) {
+	status := reply.Status
+	if status == 0 {
+		status = http.StatusInternalServerError
+	}
+	if status == http.StatusTooManyRequests || status >= 500 {
+		w.Header().Set("Retry-After-Ms", "1")
+	}
+
+	if s.provider == types.ProviderClaude {
+		writeJSON(w, status, map[string]any{
+			"type":  "error",
+			"error": map[string]any{"type": reply.ErrorType, "message": reply.ErrorMessage},
+		})
+		return
+	}
+	writeJSON(w, status, map[string]any{
+		"error": map[string]any{"message": reply.ErrorMessage, "type": reply.ErrorType, "code": reply.ErrorType, "param": nil},
+	})
+}
+
+// writeModels writes the model list in the provider's format.
+func (s *FakeServer) writeModels(w http.ResponseWriter) {
+	data := make([]map[string]any, 0, len(s.Models))
+	for _, id := range s.Models {
+		if s.provider == types.ProviderClaude {
+			data = append(data, map[string]any{"type": "model", "id": id, "display_name": id, "created_at": "2025-01-01T00:00:00Z"})
+		} else {
+			data = append(data, map[string]any{"id": id, "object": "model", "created": 1700000000, "owned_by": "openai"})
+		}
+	}
+
+	if s.provider == types.ProviderClaude {
+		writeJSON(w, http.StatusOK, map[string]any{"data": data, "has_more": false})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"object": "list", "data": data})
+}
+
+// model returns the requested model, or a placeholder when none was sent.
+func model(req Request) string {
+	if req.Model != "" {
+		return req.Model
+	}
+	return "fake-model"
+}
+
+// fakeUsage estimates token counts as one token per four characters.
+func fakeUsage(req Request, reply Reply) (int, int) {
+	return len(req.Prompt)/4 + 1, len(reply.Text)/4 + 1
+}
+
+// openAICompletion builds an OpenAI chat completion for a reply.
+func openAICompletion(req Request, reply Reply) map[string]any {
+	stop := reply.StopReason
+	if stop == "" {
+		stop = "stop"
+	}
+	prompt, completion := fakeUsage(req, reply)
+	return map[string]any{
+		"id":      "chatcmpl-fake",
+		"object":  "chat.completion",
+		"created": time.Now().Unix(),
+		"model":   model(req),
+		"choices": []map[string]any{{
+			"index":         0,
+			"message":       map[string]any{"role": "assistant", "content": reply.Text, "refusal": nil},
+			"finish_reason": stop,
+			"logprobs":      nil,
+		}},
+		"usage": map[string]any{"prompt_tokens": prompt, "completion_tokens": completion, "total_tokens": prompt + completion},
+	}
+}
+
+// claudeMessage builds a Claude Messages API response for a reply.
+func claudeMessage(req Request, reply Reply) map[string]any {
+	stop := reply.StopReason
+	if stop == "" {
+		stop = "end_turn"
+	}
+	input, output := fakeUsage(req, reply)
+	return map[string]any{
+		"id":            "msg_fake",
+		"type":          "message",
+		"role":          "assistant",
+		"model":         model(req),
+		"content":       []map[string]any{{"type": "text", "text": reply.Text}},
+		"stop_reason":   stop,
+		"stop_sequence": nil,
+		"usage":         map[string]any{"input_tokens": input, "output_tokens": output},
+	}
+}
+
+// streamChunks returns the deltas to stream for a reply.
+func streamChunks(reply Reply) []string {
+	if len(reply.Chunks) > 0 {
+		return reply.Chunks
+	}
+	return []string{reply.Text}
+}
+
+// writeOpenAIStream writes a reply as OpenAI chat completion chunks, ending with a
+// usage chunk and [DONE].
+func writeOpenAIStream(w http.ResponseWriter, req Request, reply Reply) {
+	stop := reply.StopReason
+	if stop == "" {
+		stop = "stop"
+	}
+	sse := newSSEWriter(w)
+	chunk := func(delta map[string]any, finish any, usage any) map[string]any {
+		choices := []map[string]any{}
+		if delta != nil {
+			choices = append(choices, map[string]any{"index": 0, "delta": delta, "finish_reason": finish})
+		}
+		return map[string]any{
+			"id":      "chatcmpl-fake",
+			"object":  "chat.completion.chunk",
+			"created": time.Now().Unix(),
+			"model":   model(req),
+			"choices": choices,
+			"usage":   usage,
+		}
+	}
+
+	sse.data(chunk(map[string]any{"role": "assistant", "content": ""}, nil, nil))
+	for _, text := range streamChunks(reply) {
+		sse.data(chunk(map[string]any{"content": text}, nil, nil))
+	}
+	sse.data(chunk(map[string]any{}, stop, nil))
+	prompt, completion := fakeUsage(req, reply)
+	sse.data(chunk(nil, nil, map[string]any{"prompt_tokens": prompt, "completion_tokens": completion, "total_tokens": prompt + completion}))
+	sse.raw("data: [DONE]\n\n")
+}
+
+// writeClaudeStream writes a reply as Claude Messages streaming events.
+func writeClaudeStream(w http.ResponseWriter, req Request, reply Reply) {
+	stop := reply.StopReason
+	if stop == "" {
+		stop = "end_turn"
+	}
+	input, output := fakeUsage(req, reply)
+	sse := newSSEWriter(w)
+
+	message := claudeMessage(req, reply)
+	message["content"] = []any{}
+	message["stop_reason"] = nil
+	message["usage"] = map[string]any{"input_tokens": input, "output_tokens": 0}
+
+	sse.event("message_start", map[string]any{"type": "message_start", "message": message})
+	sse.event("content_block_start", map[string]any{"type": "content_block_start", "index": 0, "content_block": map[string]any{"type": "text", "text": ""}})
+	for _, text := range streamChunks(reply) {
+		sse.event("content_block_delta", map[string]any{"type": "content_block_delta", "index": 0, "delta": map[string]any{"type": "text_delta", "text": text}})
+	}
+	sse.event("content_block_stop", map[string]any{"type": "content_block_stop", "index": 0})
+	sse.event("message_delta", map[string]any{"type": "message_delta", "delta": map[string]any{"stop_reason": stop, "stop_sequence": nil}, "usage": map[string]any{"output_tokens": output}})
+	sse.event("message_stop", map[string]any{"type": "message_stop"})
+}
+
+// sseWriter writes server-sent events and flushes after each one.
+type sseWriter struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+}
+
+func newSSEWriter(w http.ResponseWriter) *sseWriter {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+	return &sseWriter{w: w, flusher: flusher}
+}
+
+// data writes an event with only a data field.
+func (s *sseWriter) data(payload any) {
+	encoded, _ := json.Marshal(payload)
+	s.raw("data: " + string(encoded) + "\n\n")
+}
+
+// event writes a named event.
+func (s *sseWriter) event(name string, payload any) {
+	encoded, _ := json.Marshal(payload)
+	s.raw("event: " + name + "\ndata: " + string(encoded) + "\n\n")
+}
+
+// raw writes text and flushes it to the client.
+func (s *sseWriter) raw(text string) {
+	_, _ = io.WriteString(s.w, text)
+	if s.flusher != nil {
+		s.flusher.Flush()
+	}
+}
+
+// writeJSON writes a JSON response with the given status.
+func writeJSON(w http.ResponseWriter, status int, payload any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(payload)
+}
@@ -0,0 +1,158 @@
+package aitest
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/kengibson1111/go-aiprovider/client"
+	"github.com/kengibson1111/go-aiprovider/streaming"
+	"github.com/kengibson1111/go-aiprovider/types"
+	"github.com/openai/openai-go/v2"
+	"github.com/openai/openai-go/v2/packages/ssestream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// promptStreamer is implemented by the OpenAI client returned from the factory.
+type promptStreamer interface {
+	CallWithPromptStream(ctx context.Context, prompt string, opts ...types.CallOption) (*ssestream.Stream[openai.ChatCompletionChunk], error)
+}
+
+func TestFakeOpenAIServer_Completions(t *testing.T) {
+	srv := NewFakeOpenAIServer(t)
+	srv.Enqueue(Text("hello there"))
+
+	aiClient, err := client.NewClientFactory().CreateClient(srv.Config())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	response, err := aiClient.CallWithPrompt(ctx, "hi", types.WithModel("gpt-4o"))
+	require.NoError(t, err)
+
+	var completion openai.ChatCompletion
+	require.NoError(t, json.Unmarshal(response, &completion))
+	require.Len(t, completion.Choices, 1)
+	assert.Equal(t, "hello there", completion.Choices[0].Message.Content)
+
+	// The queue is empty, so Default answers
+	response, err = aiClient.CallWithPromptAndVariables(ctx, "Hello {{name}}", `{"name": "Ada"}`)
+	require.NoError(t, err)
+	assert.Contains(t, string(response), "fake response")
+
+	requests := srv.Requests()
+	require.Len(t, requests, 2)
+	assert.Equal(t, "/chat/completions", requests[0].Path)
+	assert.Equal(t, "hi", requests[0].Prompt)
+	assert.Equal(t, "gpt-4o", requests[0].Model)
+	assert.Equal(t, "Hello Ada", requests[1].Prompt)
+	assert.Equal(t, "Bearer "+FakeOpenAIKey, requests[0].Header.Get("Authorization"))
+
+	models, err := client.ListModels(ctx, aiClient)
+	require.NoError(t, err)
+	assert.Len(t, models, 2)
+}
+
+func TestFakeOpenAIServer_Stream(t *testing.T) {
+	srv := NewFakeOpenAIServer(t)
+	srv.Enqueue(Stream("Once ", "upon ", "a time"))
+
+	aiClient, err := client.NewClientFactory().CreateClient(srv.Config())
+	require.NoError(t, err)
+	streamer, ok := aiClient.(promptStreamer)
+	require.True(t, ok)
+
+	stream, err := streamer.CallWithPromptStream(context.Background(), "tell a story")
+	require.NoError(t, err)
+	defer stream.Close()
+
+	var b strings.Builder
+	for stream.Next() {
+		b.WriteString(streaming.ChatChunkText(stream.Current()))
+	}
+	require.NoError(t, stream.Err())
+	assert.Equal(t, "Once upon a time", b.String())
+	assert.True(t, srv.Requests()[0].Stream)
+}
+
+func TestFakeClaudeServer(t *testing.T) {
+	srv := NewFakeClaudeServer(t)
+	srv.Enqueue(Text("bonjour"), Error(http.StatusTooManyRequests, "rate_limit_error", "slow down"))
+
+	aiClient, err := client.NewClientFactory().CreateClient(srv.Config())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	response, err := aiClient.CallWithPrompt(ctx, "translate hello")
+	require.NoError(t, err)
+	citations, err := client.ParseCitations(response)
+	require.NoError(t, err)
+	assert.Empty(t, citations)
+	assert.Contains(t, string(response), `"text":"bonjour"`)
+
+	_, err = aiClient.CallWithPrompt(ctx, "again")
+	var errResp *types.ErrorResponse
+	require.True(t, errors.As(err, &errResp))
+	assert.Contains(t, errResp.Message, "429")
+
+	models, err := client.ListModels(ctx, aiClient)
+	require.NoError(t, err)
+	assert.Equal(t, "claude-sonnet-4-6", models[0].ID)
+
+	requests := srv.Requests()
+	require.Len(t, requests, 3)
+	assert.Equal(t, FakeClaudeKey, requests[0].Header.Get("x-api-key"))
+	assert.Equal(t, "translate hello", requests[0].Prompt)
+	assert.Equal(t, "/v1/models", requests[2].Path)
+}
+
+func TestFakeClaudeServer_StreamAndHandler(t *testing.T) {
+	srv := NewFakeClaudeServer(t)
+	srv.Enqueue(Stream("a", "b"), Reply{Handler: func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}})
+
+	body := `{"model":"claude-sonnet-4-6","stream":true,"messages":[{"role":"user","content":[{"type":"text","text":"go"}]}]}`
+	resp, err := http.Post(srv.URL+"/v1/messages", "application/json", strings.NewReader(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	var events, deltas []string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if name, ok := strings.CutPrefix(line, "event: "); ok {
+			events = append(events, name)
+		}
+		if data, ok := strings.CutPrefix(line, "data: "); ok && strings.Contains(data, "text_delta") {
+			var event struct {
+				Delta struct {
+					Text string `json:"text"`
+				} `json:"delta"`
+			}
+			require.NoError(t, json.Unmarshal([]byte(data), &event))
+			deltas = append(deltas, event.Delta.Text)
+		}
+	}
+	assert.Equal(t, []string{"message_start", "content_block_start", "content_block_delta", "content_block_delta", "content_block_stop", "message_delta", "message_stop"}, events)
+	assert.Equal(t, []string{"a", "b"}, deltas)
+	assert.Equal(t, "go", srv.Requests()[0].Prompt)
+
+	resp, err = http.Post(srv.URL+"/v1/messages", "application/json", strings.NewReader(`{}`))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusTeapot, resp.StatusCode)
+
+	resp, err = http.Get(srv.URL + "/unknown")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	srv.Reset()
+	assert.Empty(t, srv.Requests())
+}
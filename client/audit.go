@@ -0,0 +1,299 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kengibson1111/go-aiprovider/internal/shared/logging"
+	"github.com/kengibson1111/go-aiprovider/types"
+)
+
+// AuditContent selects how much prompt and response text an audit record keeps.
+type AuditContent string
+
+// Audit content modes for AuditOptions.Content
+const (
+	// AuditContentFull records scrubbed prompt and response text.
+	AuditContentFull AuditContent = "full"
+
+	// AuditContentHash records SHA-256 hashes of the prompt and response instead of text.
+	AuditContentHash AuditContent = "hash"
+
+	// AuditContentNone records metadata only.
+	AuditContentNone AuditContent = "none"
+)
+
+// AuditRecord describes one call made through an AuditClient.
+type AuditRecord struct {
+	Time     time.Time     `json:"time"`
+	Method   string        `json:"method"`
+	Model    string        `json:"model,omitempty"`
+	Duration time.Duration `json:"duration"`
+
+	// Prompt is the prompt, or the template for calls with variables, and Variables the
+	// variables as JSON. Both are scrubbed, and empty unless Content is AuditContentFull.
+	Prompt    string `json:"prompt,omitempty"`
+	Variables string `json:"variables,omitempty"`
+
+	// Response is the scrubbed response text, empty unless Content is AuditContentFull.
+	Response string `json:"response,omitempty"`
+
+	// PromptHash and ResponseHash are hex SHA-256 hashes of the unscrubbed text, set when
+	// Content is AuditContentHash.
+	PromptHash   string `json:"promptHash,omitempty"`
+	ResponseHash string `json:"responseHash,omitempty"`
+
+	// PromptTokens and CompletionTokens are the usage reported in the response.
+	PromptTokens     int `json:"promptTokens,omitempty"`
+	CompletionTokens int `json:"completionTokens,omitempty"`
+
+	// Error is the call's error message, if it failed.
+	Error string `json:"error,omitempty"`
+
+	// Labels are copied from AuditOptions.Labels, e.g. application or tenant names.
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Tags are the UsageTags of the call's context, set with WithUsageTags.
+	Tags UsageTags `json:"tags,omitzero"`
+}
+
+// AuditSink stores audit records. Implement it to write to a database or queue.
+type AuditSink interface {
+	WriteAudit(ctx context.Context, record AuditRecord) error
+}
+
+// AuditSinkFunc adapts a function to an AuditSink.
+type AuditSinkFunc func(ctx context.Context, record AuditRecord) error
+
+// WriteAudit calls f.
+func (f AuditSinkFunc) WriteAudit(ctx context.Context, record AuditRecord) error {
+	return f(ctx, record)
+}
+
+// AuditOptions configures an AuditClient.
+type AuditOptions struct {
+	// Sink receives a record for every call. Required.
+	Sink AuditSink
+
+	// Content selects full text, hashes, or metadata only (default: AuditContentHash).
+	Content AuditContent
+
+	// ScrubPatterns are additional patterns replaced with [REDACTED] in recorded text,
+	// on top of the built-in email and credential scrubbing.
+	ScrubPatterns []*regexp.Regexp
+
+	// Labels are attached to every record.
+	Labels map[string]string
+
+	// OnError, if set, receives sink errors. Sink errors never fail the call.
+	OnError func(error)
+}
+
+// AuditClient wraps an AIClient and writes an AuditRecord for every prompt call to a
+// sink. ValidateCredentials is passed through unchanged.
+type AuditClient struct {
+	AIClient
+	opts AuditOptions
+}
+
+// emailPattern matches email addresses for scrubbing
+var emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+
+// privateKeyPattern matches PEM private key blocks
+var privateKeyPattern = regexp.MustCompile(`(?s)-----BEGIN [A-Z ]*PRIVATE KEY-----.*?-----END [A-Z ]*PRIVATE KEY-----`)
+
+// NewAuditClient wraps aiClient so every call is recorded to opts.Sink.
+//
+// Example:
+//
+//	file, _ := os.OpenFile("ai-audit.jsonl", os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+//	ac, err := client.NewAuditClient(aiClient, client.AuditOptions{
+//		Sink:    client.NewWriterAuditSink(file),
+//		Content: client.AuditContentFull,
+//		Labels:  map[string]string{"app": "support-bot"},
+//	})
+func NewAuditClient(aiClient AIClient, opts AuditOptions) (*AuditClient, error) {
+	if aiClient == nil {
+		return nil, fmt.Errorf("AI client is required")
+	}
+	if opts.Sink == nil {
+		return nil, fmt.Errorf("audit sink is required")
+	}
+	switch opts.Content {
+	case "":
+		opts.Content = AuditContentHash
+	case AuditContentFull, AuditContentHash, AuditContentNone:
+	default:
+		return nil, fmt.Errorf("unsupported audit content mode: %q", opts.Content)
+	}
+
+	return &AuditClient{
+		AIClient: aiClient,
+		opts:     opts,
+	}, nil
+}
+
+// CallWithPrompt sends the prompt and records the call.
+func (c *AuditClient) CallWithPrompt(ctx context.Context, prompt string, opts ...types.CallOption) ([]byte, error) {
+	start := time.Now()
+	response, err := c.AIClient.CallWithPrompt(ctx, prompt, opts...)
+	c.audit(ctx, "CallWithPrompt", prompt, "", opts, start, response, err)
+	return response, err
+}
+
+// CallWithPromptAndVariables renders and sends the prompt and records the call.
+func (c *AuditClient) CallWithPromptAndVariables(ctx context.Context, prompt string, variablesJSON string, opts ...types.CallOption) ([]byte, error) {
+	start := time.Now()
+	response, err := c.AIClient.CallWithPromptAndVariables(ctx, prompt, variablesJSON, opts...)
+	c.audit(ctx, "CallWithPromptAndVariables", prompt, variablesJSON, opts, start, response, err)
+	return response, err
+}
+
+// Scrub replaces email addresses, API keys, bearer tokens, private keys, and matches of
+// the configured ScrubPatterns in text.
+func (c *AuditClient) Scrub(text string) string {
+	text = privateKeyPattern.ReplaceAllString(text, "[PRIVATE KEY]")
+	text = emailPattern.ReplaceAllString(text, "[EMAIL]")
+	text = logging.RedactSecrets(text)
+	for _, pattern := range c.opts.ScrubPatterns {
+		text = pattern.ReplaceAllString(text, "[REDACTED]")
+	}
+	return text
+}
+
+// audit builds the record for a call and writes it to the sink.
+func (c *AuditClient) audit(ctx context.Context, method, prompt, variables string, opts []types.CallOption, start time.Time, response []byte, callErr error) {
+	record := AuditRecord{
+		Time:     start,
+		Method:   method,
+		Model:    types.ResolveCallOptions(types.CallOptions{}, opts).Model,
+		Duration: time.Since(start),
+		Labels:   c.opts.Labels,
+	}
+	if tags, ok := ctx.Value(usageTagsKey{}).(UsageTags); ok {
+		record.Tags = tags
+	}
+	if callErr != nil {
+		record.Error = c.Scrub(callErr.Error())
+	}
+
+	responseText := ""
+	if callErr == nil {
+		responseText = extractResponseText(response)
+		model, promptTokens, completionTokens := responseUsage(response)
+		if model != "" {
+			record.Model = model
+		}
+		record.PromptTokens = promptTokens
+		record.CompletionTokens = completionTokens
+	}
+
+	switch c.opts.Content {
+	case AuditContentFull:
+		record.Prompt = c.Scrub(prompt)
+		record.Variables = c.Scrub(variables)
+		record.Response = c.Scrub(responseText)
+	case AuditContentHash:
+		record.PromptHash = sha256Hex(prompt + variables)
+		if callErr == nil {
+			record.ResponseHash = sha256Hex(responseText)
+		}
+	}
+
+	if err := c.opts.Sink.WriteAudit(context.WithoutCancel(ctx), record); err != nil && c.opts.OnError != nil {
+		c.opts.OnError(fmt.Errorf("audit sink failed: %w", err))
+	}
+}
+
+// extractResponseText returns the generated text of a raw response, or the raw response
+// when it is not a recognized format.
+func extractResponseText(response []byte) string {
+	var texts []string
+	if _, err := rewriteResponseText(response, func(text string) string {
+		texts = append(texts, text)
+		return text
+	}); err != nil {
+		return string(response)
+	}
+	return strings.Join(texts, "\n")
+}
+
+// sha256Hex returns the hex SHA-256 hash of s
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// writerAuditSink writes records as JSON lines.
+type writerAuditSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewWriterAuditSink returns a sink that writes each record to w as one line of JSON,
+// for example to an append-only file. Writes are serialized.
+func NewWriterAuditSink(w io.Writer) AuditSink {
+	return &writerAuditSink{w: w}
+}
+
+// WriteAudit writes the record as a JSON line.
+func (s *writerAuditSink) WriteAudit(ctx context.Context, record AuditRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.w.Write(append(line, '\n'))
+	return err
+}
+
+// webhookAuditSink posts records to a URL.
+type webhookAuditSink struct {
+	url        string
+	httpClient *http.Client
+}
+
+// NewWebhookAuditSink returns a sink that POSTs each record as JSON to url. A nil
+// httpClient uses a client with a 10 second timeout. Non-2xx responses are errors.
+func NewWebhookAuditSink(url string, httpClient *http.Client) AuditSink {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &webhookAuditSink{url: url, httpClient: httpClient}
+}
+
+// WriteAudit posts the record.
+func (s *webhookAuditSink) WriteAudit(ctx context.Context, record AuditRecord) error {
+	body, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("audit webhook returned HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/kengibson1111/go-aiprovider/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingSink collects audit records in memory.
+type recordingSink struct {
+	records []AuditRecord
+	err     error
+}
+
+func (s *recordingSink) WriteAudit(ctx context.Context, record AuditRecord) error {
+	s.records = append(s.records, record)
+	return s.err
+}
+
+func TestAuditClient_FullContentIsScrubbed(t *testing.T) {
+	stub := &stubClient{responses: []string{`{"choices":[{"index":0,"message":{"role":"assistant","content":"Mail jane.doe@example.com with key sk-abcdefghijklmnopqrstuv"}}]}`}}
+	sink := &recordingSink{}
+	ac, err := NewAuditClient(stub, AuditOptions{
+		Sink:          sink,
+		Content:       AuditContentFull,
+		ScrubPatterns: []*regexp.Regexp{regexp.MustCompile(`\d{3}-\d{2}-\d{4}`)},
+		Labels:        map[string]string{"app": "test"},
+	})
+	require.NoError(t, err)
+
+	_, err = ac.CallWithPrompt(context.Background(), "Contact bob@example.org, SSN 123-45-6789", types.WithModel("gpt-4o"))
+	require.NoError(t, err)
+
+	require.Len(t, sink.records, 1)
+	record := sink.records[0]
+	assert.Equal(t, "CallWithPrompt", record.Method)
+	assert.Equal(t, "gpt-4o", record.Model)
+	assert.Equal(t, "Contact [EMAIL], SSN [REDACTED]", record.Prompt)
+	assert.NotContains(t, record.Response, "jane.doe@example.com")
+	assert.NotContains(t, record.Response, "sk-abcdefghijklmnopqrstuv")
+	assert.Empty(t, record.PromptHash)
+	assert.Equal(t, "test", record.Labels["app"])
+}
+
+func TestAuditClient_HashContentByDefault(t *testing.T) {
+	stub := &stubClient{responses: []string{answeredResponse}}
+	sink := &recordingSink{}
+	ac, err := NewAuditClient(stub, AuditOptions{Sink: sink})
+	require.NoError(t, err)
+
+	ctx := WithUsageTags(context.Background(), UsageTags{Template: "hello@2", Experiment: "greeting", Variant: "a"})
+	_, err = ac.CallWithPromptAndVariables(ctx, "Hello {{.name}}", `{"name":"Ann"}`)
+	require.NoError(t, err)
+
+	require.Len(t, sink.records, 1)
+	record := sink.records[0]
+	assert.Equal(t, UsageTags{Template: "hello@2", Experiment: "greeting", Variant: "a"}, record.Tags)
+	assert.Empty(t, record.Prompt)
+	assert.Empty(t, record.Response)
+	assert.Equal(t, sha256Hex(`Hello {{.name}}{"name":"Ann"}`), record.PromptHash)
+	assert.Equal(t, sha256Hex("Here is the answer."), record.ResponseHash)
+}
+
+func TestAuditClient_RecordsErrorsAndSinkFailures(t *testing.T) {
+	sink := &recordingSink{err: errors.New("disk full")}
+	var sinkErrs []error
+	ac, err := NewAuditClient(&stubClient{}, AuditOptions{
+		Sink:    sink,
+		Content: AuditContentNone,
+		OnError: func(err error) { sinkErrs = append(sinkErrs, err) },
+	})
+	require.NoError(t, err)
+
+	_, err = ac.CallWithPrompt(context.Background(), "hi")
+	assert.EqualError(t, err, "no response")
+
+	require.Len(t, sink.records, 1)
+	assert.Equal(t, "no response", sink.records[0].Error)
+	assert.Empty(t, sink.records[0].PromptHash)
+	require.Len(t, sinkErrs, 1)
+	assert.ErrorContains(t, sinkErrs[0], "disk full")
+}
+
+func TestNewAuditClient_Validation(t *testing.T) {
+	_, err := NewAuditClient(nil, AuditOptions{Sink: &recordingSink{}})
+	assert.Error(t, err)
+
+	_, err = NewAuditClient(&stubClient{}, AuditOptions{})
+	assert.Error(t, err)
+
+	_, err = NewAuditClient(&stubClient{}, AuditOptions{Sink: &recordingSink{}, Content: "everything"})
+	assert.Error(t, err)
+}
+
+func TestAuditSinks(t *testing.T) {
+	record := AuditRecord{Method: "CallWithPrompt", PromptHash: "abc"}
+
+	var buf bytes.Buffer
+	require.NoError(t, NewWriterAuditSink(&buf).WriteAudit(context.Background(), record))
+	var decoded AuditRecord
+	require.NoError(t, json.Unmarshal(bytes.TrimSuffix(buf.Bytes(), []byte("\n")), &decoded))
+	assert.Equal(t, "abc", decoded.PromptHash)
+
+	var received AuditRecord
+	status := http.StatusNoContent
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	webhook := NewWebhookAuditSink(srv.URL, nil)
+	require.NoError(t, webhook.WriteAudit(context.Background(), record))
+	assert.Equal(t, "CallWithPrompt", received.Method)
+
+	status = http.StatusInternalServerError
+	assert.ErrorContains(t, webhook.WriteAudit(context.Background(), record), "HTTP 500")
+}
@@ -0,0 +1,170 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/kengibson1111/go-aiprovider/types"
+)
+
+// Best-of-N defaults
+const (
+	defaultBestOfNSamples     = 5
+	defaultBestOfNConcurrency = 5
+)
+
+// Selection methods reported in BestOfNResult.Method
+const (
+	SelectionMajorityVote = "majority_vote"
+	SelectionScore        = "score"
+)
+
+// BestOfNOptions configures BestOfN. The zero value uses the defaults.
+type BestOfNOptions struct {
+	// N is the number of samples (default: 5).
+	N int
+
+	// Concurrency is the number of samples requested at once (default: 5).
+	Concurrency int
+
+	// Scorer, if set, scores each sample and the highest score wins. Otherwise the most
+	// common answer wins.
+	Scorer func(text string) float64
+
+	// Normalize maps a sample to the answer it votes for (default: trimmed, lowercased,
+	// with whitespace collapsed). Use it to vote on an extracted field rather than the
+	// whole text.
+	Normalize func(text string) string
+
+	// CallOptions are passed to every call. Samples differ only through sampling, so
+	// leave the temperature well above zero.
+	CallOptions []types.CallOption
+}
+
+// BestOfNCandidate is one sample.
+type BestOfNCandidate struct {
+	Text  string
+	Usage types.TokenUsage
+	Err   error   // Why the sample failed; Text is empty
+	Votes int     // Samples with the same normalized answer, including this one
+	Score float64 // Scorer result, zero without a Scorer
+}
+
+// BestOfNResult is the outcome of BestOfN.
+type BestOfNResult struct {
+	Best       string             // Text of the selected candidate
+	Index      int                // Index of the selected candidate
+	Candidates []BestOfNCandidate // All samples, in request order
+	Method     string             // SelectionMajorityVote or SelectionScore
+	Rationale  string             // Why the candidate was selected, for logs
+	Usage      types.TokenUsage   // Tokens used by all samples
+}
+
+// BestOfN samples the prompt N times in parallel and selects the best answer by majority
+// vote (self-consistency) or by the highest Scorer score. Ties go to the earliest sample.
+// Failed samples are recorded in Candidates and left out of the selection; BestOfN fails
+// only when every sample fails.
+//
+// Example:
+//
+//	result, err := client.BestOfN(ctx, aiClient, "What year was the contract signed? Reply with the year only.",
+//		client.BestOfNOptions{N: 5, CallOptions: []types.CallOption{types.WithTemperature(0.8)}})
+//	log.Printf("%s (%s)", result.Best, result.Rationale)
+func BestOfN(ctx context.Context, aiClient AIClient, prompt string, opts BestOfNOptions) (BestOfNResult, error) {
+	if aiClient == nil {
+		return BestOfNResult{}, fmt.Errorf("AI client is required")
+	}
+	if opts.N < 0 {
+		return BestOfNResult{}, fmt.Errorf("sample count must not be negative")
+	}
+	if opts.N == 0 {
+		opts.N = defaultBestOfNSamples
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = defaultBestOfNConcurrency
+	}
+	if opts.Normalize == nil {
+		opts.Normalize = normalizeAnswer
+	}
+
+	candidates := make([]BestOfNCandidate, opts.N)
+	sem := make(chan struct{}, opts.Concurrency)
+	var wg sync.WaitGroup
+	for i := range candidates {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				candidates[i].Err = ctx.Err()
+				return
+			}
+			text, usage, err := CompleteText(ctx, aiClient, prompt, opts.CallOptions...)
+			candidates[i] = BestOfNCandidate{Text: text, Usage: usage, Err: err}
+		}()
+	}
+	wg.Wait()
+
+	result := BestOfNResult{Index: -1, Candidates: candidates}
+	votes := map[string]int{}
+	var errs []error
+	succeeded := 0
+	for i, c := range candidates {
+		result.Usage.PromptTokens += c.Usage.PromptTokens
+		result.Usage.CompletionTokens += c.Usage.CompletionTokens
+		result.Usage.TotalTokens += c.Usage.TotalTokens
+		if c.Err != nil {
+			errs = append(errs, fmt.Errorf("sample %d: %w", i+1, c.Err))
+			continue
+		}
+		succeeded++
+		votes[opts.Normalize(c.Text)]++
+	}
+	if succeeded == 0 {
+		return result, fmt.Errorf("all %d samples failed: %w", opts.N, errors.Join(errs...))
+	}
+
+	for i := range candidates {
+		c := &candidates[i]
+		if c.Err != nil {
+			continue
+		}
+		c.Votes = votes[opts.Normalize(c.Text)]
+		if opts.Scorer != nil {
+			c.Score = opts.Scorer(c.Text)
+		}
+		if result.Index < 0 || better(*c, candidates[result.Index], opts.Scorer != nil) {
+			result.Index = i
+		}
+	}
+
+	best := candidates[result.Index]
+	result.Best = best.Text
+	if opts.Scorer != nil {
+		result.Method = SelectionScore
+		result.Rationale = fmt.Sprintf("sample %d had the highest score %.3g of %d successful samples", result.Index+1, best.Score, succeeded)
+	} else {
+		result.Method = SelectionMajorityVote
+		result.Rationale = fmt.Sprintf("%d of %d successful samples agreed with sample %d", best.Votes, succeeded, result.Index+1)
+	}
+	return result, nil
+}
+
+// better reports whether candidate beats the current best, by score or by votes.
+func better(candidate, best BestOfNCandidate, byScore bool) bool {
+	if byScore {
+		return candidate.Score > best.Score
+	}
+	return candidate.Votes > best.Votes
+}
+
+// normalizeAnswer lowercases text and collapses its whitespace, so answers that differ
+// only in case or spacing vote together.
+func normalizeAnswer(text string) string {
+	return strings.ToLower(strings.Join(strings.Fields(text), " "))
+}
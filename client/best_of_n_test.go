@@ -0,0 +1,88 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/kengibson1111/go-aiprovider/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sampleClient returns its answers in order, one per call, from any goroutine. An empty
+// answer fails the call.
+type sampleClient struct {
+	stubClient
+	mu      sync.Mutex
+	answers []string
+}
+
+func (s *sampleClient) CallWithPrompt(ctx context.Context, prompt string, opts ...types.CallOption) ([]byte, error) {
+	s.mu.Lock()
+	answer := s.answers[0]
+	s.answers = s.answers[1:]
+	s.mu.Unlock()
+	if answer == "" {
+		return nil, errors.New("sample failed")
+	}
+	return []byte(`{"choices":[{"index":0,"message":{"role":"assistant","content":` + jsonText(answer) + `}}],"usage":{"prompt_tokens":10,"completion_tokens":2,"total_tokens":12}}`), nil
+}
+
+func TestBestOfN_MajorityVote(t *testing.T) {
+	aiClient := &sampleClient{answers: []string{"2001", "1998", " 2001\n", "", "2001 "}}
+
+	result, err := BestOfN(context.Background(), aiClient, "When?", BestOfNOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, "2001", strings.TrimSpace(result.Best))
+	assert.Equal(t, result.Candidates[result.Index].Text, result.Best)
+	assert.Equal(t, SelectionMajorityVote, result.Method)
+	assert.Equal(t, fmt.Sprintf("3 of 4 successful samples agreed with sample %d", result.Index+1), result.Rationale)
+	require.Len(t, result.Candidates, 5)
+
+	failed := 0
+	for _, c := range result.Candidates {
+		switch {
+		case c.Err != nil:
+			failed++
+			assert.EqualError(t, c.Err, "sample failed")
+		case c.Text == "1998":
+			assert.Equal(t, 1, c.Votes)
+		default:
+			assert.Equal(t, 3, c.Votes)
+		}
+	}
+	assert.Equal(t, 1, failed)
+	assert.Equal(t, types.TokenUsage{PromptTokens: 40, CompletionTokens: 8, TotalTokens: 48}, result.Usage)
+}
+
+func TestBestOfN_Scorer(t *testing.T) {
+	aiClient := &sampleClient{answers: []string{"short", "a much longer answer", "medium one"}}
+
+	result, err := BestOfN(context.Background(), aiClient, "Explain", BestOfNOptions{
+		N:      3,
+		Scorer: func(text string) float64 { return float64(len(text)) },
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "a much longer answer", result.Best)
+	assert.Equal(t, SelectionScore, result.Method)
+	assert.Equal(t, float64(20), result.Candidates[result.Index].Score)
+	assert.True(t, strings.HasSuffix(result.Rationale, "had the highest score 20 of 3 successful samples"))
+}
+
+func TestBestOfN_Errors(t *testing.T) {
+	_, err := BestOfN(context.Background(), nil, "p", BestOfNOptions{})
+	assert.EqualError(t, err, "AI client is required")
+
+	_, err = BestOfN(context.Background(), &sampleClient{}, "p", BestOfNOptions{N: -1})
+	assert.EqualError(t, err, "sample count must not be negative")
+
+	result, err := BestOfN(context.Background(), &sampleClient{answers: []string{"", ""}}, "p", BestOfNOptions{N: 2})
+	assert.ErrorContains(t, err, "all 2 samples failed")
+	assert.Len(t, result.Candidates, 2)
+}
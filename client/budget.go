@@ -0,0 +1,82 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DeadlineBudget splits the time left before a context's deadline across a planned
+// number of attempts, such as retries, fallbacks, or the steps of a multi-call workflow.
+// Each attempt gets an equal share of the time remaining when it starts, so time an
+// attempt does not use carries over to the attempts after it, and the last attempt gets
+// everything that is left. The whole sequence never runs past the parent deadline.
+//
+// A DeadlineBudget is safe for concurrent use.
+type DeadlineBudget struct {
+	mu       sync.Mutex
+	deadline time.Time
+	bounded  bool
+	left     int
+}
+
+// NewDeadlineBudget creates a budget for up to attempts calls within ctx's deadline. If
+// ctx has no deadline, attempts are not time-limited by the budget.
+//
+// Example:
+//
+//	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+//	defer cancel()
+//	budget := client.NewDeadlineBudget(ctx, len(clients))
+//	for _, c := range clients {
+//		attemptCtx, cancel := budget.Next(ctx) // 20s, then 20s plus whatever the first left unused, ...
+//		response, err := c.CallWithPrompt(attemptCtx, prompt)
+//		cancel()
+//		if err == nil {
+//			return response, nil
+//		}
+//	}
+func NewDeadlineBudget(ctx context.Context, attempts int) *DeadlineBudget {
+	if attempts < 1 {
+		attempts = 1
+	}
+	deadline, bounded := ctx.Deadline()
+	return &DeadlineBudget{
+		deadline: deadline,
+		bounded:  bounded,
+		left:     attempts,
+	}
+}
+
+// Next returns a context for the next attempt, derived from ctx, with a deadline at its
+// share of the remaining time. Attempts beyond the planned number share nothing and get
+// whatever time remains. The returned cancel function must be called when the attempt
+// is done.
+func (b *DeadlineBudget) Next(ctx context.Context) (context.Context, context.CancelFunc) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.bounded {
+		if b.left > 1 {
+			b.left--
+		}
+		return context.WithCancel(ctx)
+	}
+
+	remaining := time.Until(b.deadline)
+	slice := remaining
+	if b.left > 1 && remaining > 0 {
+		slice = remaining / time.Duration(b.left)
+		b.left--
+	}
+	return context.WithDeadline(ctx, time.Now().Add(slice))
+}
+
+// Remaining returns the time left before the parent deadline, and false when the parent
+// context has no deadline.
+func (b *DeadlineBudget) Remaining() (time.Duration, bool) {
+	if !b.bounded {
+		return 0, false
+	}
+	return time.Until(b.deadline), true
+}
@@ -0,0 +1,86 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kengibson1111/go-aiprovider/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// deadlineClient records the time left before the deadline of each call.
+type deadlineClient struct {
+	stubClient
+	budgets []time.Duration
+}
+
+func (d *deadlineClient) CallWithPrompt(ctx context.Context, prompt string, opts ...types.CallOption) ([]byte, error) {
+	deadline, ok := ctx.Deadline()
+	if ok {
+		d.budgets = append(d.budgets, time.Until(deadline))
+	}
+	return d.stubClient.CallWithPrompt(ctx, prompt, opts...)
+}
+
+func TestDeadlineBudget_SplitsRemainingTime(t *testing.T) {
+	parent, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	budget := NewDeadlineBudget(parent, 3)
+
+	first, cancelFirst := budget.Next(parent)
+	deadline, ok := first.Deadline()
+	require.True(t, ok)
+	assert.InDelta(t, time.Second, time.Until(deadline), float64(100*time.Millisecond))
+	cancelFirst()
+
+	// The first attempt used no time, so the second gets half of the remaining three seconds
+	second, cancelSecond := budget.Next(parent)
+	deadline, _ = second.Deadline()
+	assert.InDelta(t, 1500*time.Millisecond, time.Until(deadline), float64(100*time.Millisecond))
+	cancelSecond()
+
+	// The last and any extra attempts get everything that is left
+	for i := 0; i < 2; i++ {
+		last, cancelLast := budget.Next(parent)
+		deadline, _ = last.Deadline()
+		parentDeadline, _ := parent.Deadline()
+		assert.WithinDuration(t, parentDeadline, deadline, 10*time.Millisecond)
+		cancelLast()
+	}
+
+	remaining, bounded := budget.Remaining()
+	assert.True(t, bounded)
+	assert.Greater(t, remaining, 2*time.Second)
+}
+
+func TestDeadlineBudget_NoDeadline(t *testing.T) {
+	budget := NewDeadlineBudget(context.Background(), 2)
+
+	ctx, cancel := budget.Next(context.Background())
+	defer cancel()
+	_, ok := ctx.Deadline()
+	assert.False(t, ok)
+
+	_, bounded := budget.Remaining()
+	assert.False(t, bounded)
+}
+
+func TestEscalationClient_SplitDeadline(t *testing.T) {
+	primary := &deadlineClient{stubClient: stubClient{responses: []string{refusedResponse, answeredResponse}}}
+	ec, err := NewEscalationClient(primary, EscalationOptions{
+		Steps:         []EscalationStep{{Name: "clarify", SystemContext: "context"}},
+		SplitDeadline: true,
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	_, err = ec.CallWithPrompt(ctx, "prompt")
+	require.NoError(t, err)
+
+	require.Len(t, primary.budgets, 2)
+	assert.InDelta(t, time.Second, primary.budgets[0], float64(100*time.Millisecond))
+	assert.InDelta(t, 2*time.Second, primary.budgets[1], float64(100*time.Millisecond))
+}
@@ -0,0 +1,183 @@
+package client
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Characters per token used by EstimateTokens for English text and code. Claude's
+// tokenizer produces slightly more tokens than OpenAI's for the same text.
+const (
+	charsPerToken       = 4.0
+	claudeCharsPerToken = 3.5
+)
+
+// wordPattern matches a word with the whitespace that follows it, so chunks keep the
+// original spacing and line breaks.
+var wordPattern = regexp.MustCompile(`\S+\s*`)
+
+// sentencePattern matches a sentence with its trailing whitespace: text up to sentence
+// punctuation (with closing quotes or brackets) followed by whitespace, up to a blank
+// line, or up to the end of the text.
+var sentencePattern = regexp.MustCompile(`(?s).+?(?:[.!?]+["')\]]*\s+|\n\s*\n|$)`)
+
+// EstimateTokens estimates how many tokens model's tokenizer produces for text, without
+// a tokenizer: about four characters per token for OpenAI models, three and a half for
+// Claude, and one token per non-ASCII character such as CJK text. Estimates are usually
+// within 10-20% for English prose; leave headroom when a limit is strict.
+func EstimateTokens(text, model string) int {
+	return int(math.Ceil(estimateTokens(text, tokenRatio(model))))
+}
+
+// ChunkByTokens splits text into chunks of at most maxTokens estimated tokens (see
+// EstimateTokens), breaking between words. Each chunk after the first starts with about
+// overlap tokens from the end of the previous one, so context that spans a boundary
+// appears in both. Words longer than maxTokens are split. Whitespace inside chunks is
+// kept; chunks are trimmed at the ends.
+//
+// Example:
+//
+//	chunks, err := client.ChunkByTokens(document, "gpt-4o", 2000, 200)
+//	for _, chunk := range chunks {
+//		response, err := aiClient.CallWithPrompt(ctx, "Extract the action items:\n\n"+chunk)
+//		...
+//	}
+func ChunkByTokens(text, model string, maxTokens, overlap int) ([]string, error) {
+	if err := validateChunking(maxTokens, overlap); err != nil {
+		return nil, err
+	}
+	ratio := tokenRatio(model)
+	return chunkSegments(splitOversized(wordPattern.FindAllString(text, -1), ratio, maxTokens), ratio, maxTokens, overlap), nil
+}
+
+// ChunkBySentences is ChunkByTokens that keeps sentences and paragraphs whole, breaking
+// only between them. Sentences longer than maxTokens are split between words. Overlap
+// repeats whole sentences.
+func ChunkBySentences(text, model string, maxTokens, overlap int) ([]string, error) {
+	if err := validateChunking(maxTokens, overlap); err != nil {
+		return nil, err
+	}
+	ratio := tokenRatio(model)
+
+	var segments []string
+	for _, sentence := range sentencePattern.FindAllString(text, -1) {
+		if estimateTokens(sentence, ratio) <= float64(maxTokens) {
+			segments = append(segments, sentence)
+			continue
+		}
+		segments = append(segments, splitOversized(wordPattern.FindAllString(sentence, -1), ratio, maxTokens)...)
+	}
+	return chunkSegments(segments, ratio, maxTokens, overlap), nil
+}
+
+// validateChunking checks the chunk size and overlap.
+func validateChunking(maxTokens, overlap int) error {
+	if maxTokens <= 0 {
+		return fmt.Errorf("maxTokens must be positive, got %d", maxTokens)
+	}
+	if overlap < 0 || overlap >= maxTokens {
+		return fmt.Errorf("overlap must be between 0 and maxTokens-1, got %d", overlap)
+	}
+	return nil
+}
+
+// chunkSegments packs segments into chunks of at most maxTokens, starting each chunk
+// after the first with trailing segments of the previous chunk worth at most overlap.
+// Every segment must fit in maxTokens on its own.
+func chunkSegments(segments []string, ratio float64, maxTokens, overlap int) []string {
+	var chunks []string
+	var current []string
+	var currentTokens float64
+	added := 0 // segments in current that are not overlap from the previous chunk
+
+	emit := func() {
+		if chunk := strings.TrimSpace(strings.Join(current, "")); chunk != "" {
+			chunks = append(chunks, chunk)
+		}
+	}
+
+	for _, segment := range segments {
+		tokens := estimateTokens(segment, ratio)
+		if added > 0 && currentTokens+tokens > float64(maxTokens) {
+			emit()
+
+			// Carry the tail of the chunk into the next one
+			start, tail := len(current), 0.0
+			for start > 0 {
+				t := estimateTokens(current[start-1], ratio)
+				if tail+t > float64(overlap) {
+					break
+				}
+				tail += t
+				start--
+			}
+			current, currentTokens, added = append([]string(nil), current[start:]...), tail, 0
+		}
+		// Drop overlap that would leave no room for the new segment
+		for len(current) > added && currentTokens+tokens > float64(maxTokens) {
+			currentTokens -= estimateTokens(current[0], ratio)
+			current = current[1:]
+		}
+		current = append(current, segment)
+		currentTokens += tokens
+		added++
+	}
+	if added > 0 {
+		emit()
+	}
+	return chunks
+}
+
+// splitOversized splits segments longer than maxTokens into pieces that fit.
+func splitOversized(segments []string, ratio float64, maxTokens int) []string {
+	var out []string
+	for _, segment := range segments {
+		for estimateTokens(segment, ratio) > float64(maxTokens) {
+			// Take the longest prefix that fits, at least one character
+			cut, tokens := 0, 0.0
+			for i, r := range segment {
+				t := runeTokens(r, ratio)
+				if i > 0 && tokens+t > float64(maxTokens) {
+					break
+				}
+				tokens += t
+				cut = i + utf8.RuneLen(r)
+			}
+			out = append(out, segment[:cut])
+			segment = segment[cut:]
+		}
+		if segment != "" {
+			out = append(out, segment)
+		}
+	}
+	return out
+}
+
+// estimateTokens returns the unrounded token estimate for text.
+func estimateTokens(text string, ratio float64) float64 {
+	var tokens float64
+	for _, r := range text {
+		tokens += runeTokens(r, ratio)
+	}
+	return tokens
+}
+
+// runeTokens is the estimated token cost of one character.
+func runeTokens(r rune, ratio float64) float64 {
+	if r < utf8.RuneSelf {
+		return 1 / ratio
+	}
+	return 1
+}
+
+// tokenRatio returns the characters per token for model.
+func tokenRatio(model string) float64 {
+	id := strings.ToLower(model)
+	if strings.Contains(id, "claude") || strings.Contains(id, "anthropic") {
+		return claudeCharsPerToken
+	}
+	return charsPerToken
+}
@@ -0,0 +1,81 @@
+package client
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	assert.Equal(t, 0, EstimateTokens("", "gpt-4o"))
+	assert.Equal(t, 3, EstimateTokens("hello world!", "gpt-4o"))
+	assert.Equal(t, 4, EstimateTokens("hello world!", "claude-sonnet-4-6"))
+	assert.Equal(t, 4, EstimateTokens("日本語テ", "gpt-4o"))
+}
+
+func TestChunkByTokens(t *testing.T) {
+	words := make([]string, 100)
+	for i := range words {
+		words[i] = "word"
+	}
+	text := strings.Join(words, " ") // 100 words of 5 characters, about 1.25 tokens each
+
+	chunks, err := ChunkByTokens(text, "gpt-4o", 25, 5)
+	require.NoError(t, err)
+	require.Greater(t, len(chunks), 4)
+	for _, chunk := range chunks {
+		assert.LessOrEqual(t, EstimateTokens(chunk, "gpt-4o"), 25)
+	}
+	// Each chunk starts with the last words of the one before
+	first := strings.Fields(chunks[0])
+	second := strings.Fields(chunks[1])
+	assert.Equal(t, first[len(first)-4:], second[:4])
+
+	// Without overlap every word appears exactly once
+	chunks, err = ChunkByTokens(text, "gpt-4o", 25, 0)
+	require.NoError(t, err)
+	assert.Len(t, strings.Fields(strings.Join(chunks, " ")), 100)
+
+	// Words longer than a chunk are split
+	chunks, err = ChunkByTokens(strings.Repeat("x", 100), "gpt-4o", 10, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{strings.Repeat("x", 40), strings.Repeat("x", 40), strings.Repeat("x", 20)}, chunks)
+
+	chunks, err = ChunkByTokens("  \n ", "gpt-4o", 10, 0)
+	require.NoError(t, err)
+	assert.Empty(t, chunks)
+}
+
+func TestChunkBySentences(t *testing.T) {
+	text := "The first sentence is here. The second one follows! Is this the third? Yes.\n\nA new paragraph starts"
+
+	chunks, err := ChunkBySentences(text, "gpt-4o", 14, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"The first sentence is here. The second one follows!",
+		"Is this the third? Yes.\n\nA new paragraph starts",
+	}, chunks)
+
+	chunks, err = ChunkBySentences(text, "gpt-4o", 8, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"The first sentence is here.",
+		"The second one follows!",
+		"Is this the third? Yes.",
+		"A new paragraph starts",
+	}, chunks)
+
+	chunks, err = ChunkBySentences(text, "gpt-4o", 15, 6)
+	require.NoError(t, err)
+	require.Len(t, chunks, 3)
+	assert.True(t, strings.HasPrefix(chunks[1], "The second one follows!"), chunks[1])
+}
+
+func TestChunk_InvalidArguments(t *testing.T) {
+	_, err := ChunkByTokens("text", "gpt-4o", 0, 0)
+	assert.ErrorContains(t, err, "maxTokens must be positive")
+	_, err = ChunkBySentences("text", "gpt-4o", 10, 10)
+	assert.ErrorContains(t, err, "overlap must be between 0 and maxTokens-1")
+}
@@ -0,0 +1,170 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/kengibson1111/go-aiprovider/types"
+)
+
+// claudeCitationResponse is the subset of a Claude Messages response that carries citations.
+type claudeCitationResponse struct {
+	Content []struct {
+		Type      string `json:"type"`
+		Text      string `json:"text"`
+		Citations []struct {
+			Type              string `json:"type"`
+			CitedText         string `json:"cited_text"`
+			DocumentIndex     *int   `json:"document_index"`
+			DocumentTitle     string `json:"document_title"`
+			URL               string `json:"url"`
+			Title             string `json:"title"`
+			Source            string `json:"source"`
+			StartCharIndex    *int   `json:"start_char_index"`
+			EndCharIndex      *int   `json:"end_char_index"`
+			StartPageNumber   *int   `json:"start_page_number"`
+			EndPageNumber     *int   `json:"end_page_number"`
+			StartBlockIndex   *int   `json:"start_block_index"`
+			EndBlockIndex     *int   `json:"end_block_index"`
+			SearchResultIndex *int   `json:"search_result_index"`
+		} `json:"citations"`
+	} `json:"content"`
+}
+
+// openAICitationResponse is the subset of an OpenAI chat completion that carries annotations.
+type openAICitationResponse struct {
+	Choices []struct {
+		Index   int `json:"index"`
+		Message struct {
+			Annotations []struct {
+				Type        string `json:"type"`
+				URLCitation struct {
+					URL        string `json:"url"`
+					Title      string `json:"title"`
+					StartIndex int    `json:"start_index"`
+					EndIndex   int    `json:"end_index"`
+				} `json:"url_citation"`
+			} `json:"annotations"`
+		} `json:"message"`
+	} `json:"choices"`
+}
+
+// ParseCitations extracts source citations from a raw response returned by CallWithPrompt
+// or CallWithPromptAndVariables, for any provider. Claude text-block citations and OpenAI
+// url_citation annotations are both normalized to types.Citation, so a UI can render
+// source links without provider-specific code. A response without citations returns an
+// empty slice.
+//
+// Example:
+//
+//	response, err := aiClient.CallWithPrompt(ctx, prompt)
+//	if err != nil {
+//		return err
+//	}
+//	citations, err := client.ParseCitations(response)
+//	for _, c := range citations {
+//		fmt.Printf("[%s](%s)\n", c.Title, c.URL)
+//	}
+func ParseCitations(response []byte) ([]types.Citation, error) {
+	var shape struct {
+		Content json.RawMessage `json:"content"`
+		Choices json.RawMessage `json:"choices"`
+	}
+	if err := json.Unmarshal(response, &shape); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	switch {
+	case len(shape.Choices) > 0:
+		return parseOpenAICitations(response)
+	case len(shape.Content) > 0:
+		return parseClaudeCitations(response)
+	default:
+		return []types.Citation{}, nil
+	}
+}
+
+// parseClaudeCitations converts citations on Claude text blocks. Text offsets refer to the
+// concatenation of all text blocks, which is the response text.
+func parseClaudeCitations(response []byte) ([]types.Citation, error) {
+	var resp claudeCitationResponse
+	if err := json.Unmarshal(response, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse Claude response: %w", err)
+	}
+
+	citations := []types.Citation{}
+	offset := 0
+	for _, block := range resp.Content {
+		if block.Type != "text" {
+			continue
+		}
+		length := utf8.RuneCountInString(block.Text)
+
+		for _, c := range block.Citations {
+			citation := types.Citation{
+				Type:          c.Type,
+				URL:           c.URL,
+				Title:         c.Title,
+				CitedText:     c.CitedText,
+				DocumentIndex: -1,
+				TextStart:     offset,
+				TextEnd:       offset + length,
+			}
+			if citation.Title == "" {
+				citation.Title = c.DocumentTitle
+			}
+			if citation.URL == "" && c.Source != "" {
+				citation.URL = c.Source
+			}
+			switch {
+			case c.DocumentIndex != nil:
+				citation.DocumentIndex = *c.DocumentIndex
+			case c.SearchResultIndex != nil:
+				citation.DocumentIndex = *c.SearchResultIndex
+			}
+
+			switch {
+			case c.StartCharIndex != nil && c.EndCharIndex != nil:
+				citation.Location = &types.CitationLocation{Unit: "char", Start: *c.StartCharIndex, End: *c.EndCharIndex}
+			case c.StartPageNumber != nil && c.EndPageNumber != nil:
+				citation.Location = &types.CitationLocation{Unit: "page", Start: *c.StartPageNumber, End: *c.EndPageNumber}
+			case c.StartBlockIndex != nil && c.EndBlockIndex != nil:
+				citation.Location = &types.CitationLocation{Unit: "block", Start: *c.StartBlockIndex, End: *c.EndBlockIndex}
+			}
+
+			citations = append(citations, citation)
+		}
+		offset += length
+	}
+
+	return citations, nil
+}
+
+// parseOpenAICitations converts url_citation annotations on every choice.
+func parseOpenAICitations(response []byte) ([]types.Citation, error) {
+	var resp openAICitationResponse
+	if err := json.Unmarshal(response, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAI response: %w", err)
+	}
+
+	citations := []types.Citation{}
+	for _, choice := range resp.Choices {
+		for _, a := range choice.Message.Annotations {
+			if a.Type != "url_citation" {
+				continue
+			}
+			citations = append(citations, types.Citation{
+				Type:          a.Type,
+				URL:           a.URLCitation.URL,
+				Title:         a.URLCitation.Title,
+				DocumentIndex: -1,
+				TextStart:     a.URLCitation.StartIndex,
+				TextEnd:       a.URLCitation.EndIndex,
+				Choice:        choice.Index,
+			})
+		}
+	}
+
+	return citations, nil
+}
@@ -0,0 +1,77 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/kengibson1111/go-aiprovider/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCitations(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		expected []types.Citation
+	}{
+		{
+			name: "Claude document and web search citations",
+			response: `{
+				"type": "message",
+				"content": [
+					{"type": "text", "text": "According to the doc, "},
+					{"type": "text", "text": "the sky is blue.", "citations": [
+						{"type": "char_location", "cited_text": "The sky is blue.", "document_index": 0, "document_title": "Facts", "start_char_index": 10, "end_char_index": 26}
+					]},
+					{"type": "text", "text": " Grass is green.", "citations": [
+						{"type": "page_location", "cited_text": "Grass", "document_index": 1, "start_page_number": 2, "end_page_number": 3},
+						{"type": "web_search_result_location", "cited_text": "green grass", "url": "https://example.com/grass", "title": "Grass"}
+					]}
+				]
+			}`,
+			expected: []types.Citation{
+				{Type: "char_location", Title: "Facts", CitedText: "The sky is blue.", DocumentIndex: 0, Location: &types.CitationLocation{Unit: "char", Start: 10, End: 26}, TextStart: 22, TextEnd: 38},
+				{Type: "page_location", CitedText: "Grass", DocumentIndex: 1, Location: &types.CitationLocation{Unit: "page", Start: 2, End: 3}, TextStart: 38, TextEnd: 54},
+				{Type: "web_search_result_location", URL: "https://example.com/grass", Title: "Grass", CitedText: "green grass", DocumentIndex: -1, TextStart: 38, TextEnd: 54},
+			},
+		},
+		{
+			name: "OpenAI url citations across choices",
+			response: `{
+				"object": "chat.completion",
+				"choices": [
+					{"index": 0, "message": {"role": "assistant", "content": "See Go docs.", "annotations": [
+						{"type": "url_citation", "url_citation": {"url": "https://go.dev", "title": "Go", "start_index": 4, "end_index": 11}}
+					]}},
+					{"index": 1, "message": {"role": "assistant", "content": "No sources."}}
+				]
+			}`,
+			expected: []types.Citation{
+				{Type: "url_citation", URL: "https://go.dev", Title: "Go", DocumentIndex: -1, TextStart: 4, TextEnd: 11},
+			},
+		},
+		{
+			name:     "Claude response without citations",
+			response: `{"content": [{"type": "text", "text": "Hello"}]}`,
+			expected: []types.Citation{},
+		},
+		{
+			name:     "Unrecognized response shape",
+			response: `{"id": "x"}`,
+			expected: []types.Citation{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			citations, err := ParseCitations([]byte(tt.response))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, citations)
+		})
+	}
+}
+
+func TestParseCitations_InvalidJSON(t *testing.T) {
+	_, err := ParseCitations([]byte(`{not json`))
+	assert.Error(t, err)
+}
@@ -2,13 +2,17 @@ package client
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"strings"
+	"log/slog"
+	"sync"
 
 	"github.com/kengibson1111/go-aiprovider/internal/claudeclient"
 	"github.com/kengibson1111/go-aiprovider/internal/openaiclient"
 	"github.com/kengibson1111/go-aiprovider/internal/shared/logging"
 	"github.com/kengibson1111/go-aiprovider/internal/shared/testutil"
+	"github.com/kengibson1111/go-aiprovider/internal/shared/utils"
 	"github.com/kengibson1111/go-aiprovider/types"
 )
 
@@ -39,7 +43,13 @@ type AIClient interface {
 	// CallWithPrompt sends a raw prompt directly to the AI provider and returns the raw response.
 	// This is the foundational method that other methods build upon, providing direct access
 	// to the AI provider's API without any preprocessing or response parsing.
-	CallWithPrompt(ctx context.Context, prompt string) ([]byte, error)
+	//
+	// Call options override the client configuration for a single request:
+	//   response, err := client.CallWithPrompt(ctx, prompt, types.WithSystemPrompt("You are a Go expert."))
+	//
+	// The response is the provider's own JSON. Use the Complete and CompleteText functions
+	// for the text, finish reason, and token usage without provider-specific parsing.
+	CallWithPrompt(ctx context.Context, prompt string, opts ...types.CallOption) ([]byte, error)
 
 	// CallWithPromptAndVariables sends a prompt template with variable substitution to the AI provider.
 	// Variables in the prompt template should use {{variable_name}} format, and variablesJSON
@@ -50,15 +60,52 @@ type AIClient interface {
 	//   prompt := "Hello {{name}}, please review this {{language}} code."
 	//   variables := `{"name": "Alice", "language": "Go"}`
 	//   response, err := client.CallWithPromptAndVariables(ctx, prompt, variables)
-	CallWithPromptAndVariables(ctx context.Context, prompt string, variablesJSON string) ([]byte, error)
+	CallWithPromptAndVariables(ctx context.Context, prompt string, variablesJSON string, opts ...types.CallOption) ([]byte, error)
 
 	// ValidateCredentials validates API credentials for the configured provider.
 	ValidateCredentials(ctx context.Context) error
 }
 
+// CallWithValues is CallWithPromptAndVariables with the variables supplied as a Go value
+// instead of a JSON string. values may be a map with string keys or a struct (fields are
+// named by their json tags), so callers don't need to marshal to JSON first. It works
+// with any AIClient, including wrapped clients.
+//
+// Example:
+//
+//	response, err := client.CallWithValues(ctx, aiClient, prompt, map[string]any{
+//		"name":     "Alice",
+//		"language": "Go",
+//	})
+func CallWithValues(ctx context.Context, aiClient AIClient, prompt string, values any, opts ...types.CallOption) ([]byte, error) {
+	variables, err := utils.VariablesFromValue(values)
+	if err != nil {
+		return nil, fmt.Errorf("variable substitution failed: %w", err)
+	}
+	variablesJSON, err := json.Marshal(variables)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode variables: %w", err)
+	}
+	return aiClient.CallWithPromptAndVariables(ctx, prompt, string(variablesJSON), opts...)
+}
+
+// ErrFactoryClosed is returned by CreateClient after the factory is closed.
+var ErrFactoryClosed = errors.New("client factory is closed")
+
 // ClientFactory creates AI clients based on provider configuration
 type ClientFactory struct {
-	logger *logging.DefaultLogger
+	logger     *logging.DefaultLogger
+	slogLogger *slog.Logger
+	profiles   *types.Profiles
+
+	mu      sync.Mutex
+	clients []AIClient // Clients created so far, released by Close
+	closed  bool
+}
+
+// idleConnectionCloser is implemented by clients that keep pooled HTTP connections.
+type idleConnectionCloser interface {
+	CloseIdleConnections()
 }
 
 // NewClientFactory creates a new client factory
@@ -68,26 +115,142 @@ func NewClientFactory() *ClientFactory {
 	}
 }
 
-// CreateClient creates an AI client based on the provider configuration
+// SetLogger sends the factory's log records, and those of every client it creates
+// without its own AIConfig.Logger, to logger. API keys are redacted from all messages.
+//
+// Example:
+//
+//	factory := client.NewClientFactory()
+//	factory.SetLogger(slog.New(slog.NewJSONHandler(os.Stderr, nil)))
+func (f *ClientFactory) SetLogger(logger *slog.Logger) {
+	f.slogLogger = logger
+	f.logger = logging.NewLogger(logging.Options{Slog: logger})
+}
+
+// LoadProfiles reads a providers.yaml or providers.json profile file (see types.Profiles)
+// for use with CreateClientFromProfile, replacing any profiles loaded before.
+func (f *ClientFactory) LoadProfiles(path string) error {
+	profiles, err := types.LoadProfiles(path)
+	if err != nil {
+		return err
+	}
+	f.profiles = profiles
+	return nil
+}
+
+// CreateClientFromProfile creates a client from a named profile loaded with LoadProfiles.
+// An empty name selects the file's default profile.
+//
+// Example:
+//
+//	factory := client.NewClientFactory()
+//	if err := factory.LoadProfiles("providers.yaml"); err != nil {
+//		log.Fatal(err)
+//	}
+//	aiClient, err := factory.CreateClientFromProfile("prod-gpt4o")
+func (f *ClientFactory) CreateClientFromProfile(name string) (AIClient, error) {
+	if f.profiles == nil {
+		return nil, fmt.Errorf("no profiles loaded; call LoadProfiles first")
+	}
+
+	config, err := f.profiles.Config(name)
+	if err != nil {
+		return nil, err
+	}
+
+	f.logger.Info("Using profile: %s", name)
+	return f.CreateClient(config)
+}
+
+// CreateClient creates an AI client based on the provider configuration.
+// The configuration is normalized and validated with AIConfig.Validate before the
+// client is created; the caller's config is not modified.
 func (f *ClientFactory) CreateClient(config *types.AIConfig) (AIClient, error) {
 	if config == nil {
 		return nil, fmt.Errorf("configuration is required")
 	}
 
+	// Validate a normalized copy so problems are reported up front, together,
+	// instead of surfacing one at a time from the provider constructors or the first request.
+	normalized := *config
+	normalized.Normalize()
+	if normalized.Logger == nil {
+		normalized.Logger = f.slogLogger
+	}
+	if err := normalized.Validate(); err != nil {
+		return nil, err
+	}
+	config = &normalized
+
+	if f.isClosed() {
+		return nil, ErrFactoryClosed
+	}
+
 	f.logger.Info("Creating AI client for provider: %s", config.Provider)
 
-	switch strings.ToLower(config.Provider) {
+	var aiClient AIClient
+	var err error
+	switch config.Provider {
 	case types.ProviderClaude:
-		return claudeclient.NewClaudeClient(config)
+		aiClient, err = claudeclient.NewClaudeClient(config)
 	case types.ProviderClaudeBedrock:
-		return claudeclient.NewClaudeBedrockClient(config)
+		aiClient, err = claudeclient.NewClaudeBedrockClient(config)
 	case types.ProviderOpenAI:
-		return openaiclient.NewOpenAIClient(config)
+		aiClient, err = openaiclient.NewOpenAIClient(config)
 	case types.ProviderOpenAIAzure:
-		return openaiclient.NewOpenAIAzureClient(config)
+		aiClient, err = openaiclient.NewOpenAIAzureClient(config)
 	case types.ProviderOpenAIAzureUP:
-		return openaiclient.NewOpenAIAzureUPClient(config)
+		aiClient, err = openaiclient.NewOpenAIAzureUPClient(config)
 	default:
 		return nil, fmt.Errorf("unsupported provider: %s", config.Provider)
 	}
+	if err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		// Closed while the client was being created
+		if closer, ok := aiClient.(idleConnectionCloser); ok {
+			closer.CloseIdleConnections()
+		}
+		return nil, ErrFactoryClosed
+	}
+	f.clients = append(f.clients, aiClient)
+	return aiClient, nil
+}
+
+// isClosed reports whether Close has been called.
+func (f *ClientFactory) isClosed() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.closed
+}
+
+// Close releases the resources held by every client the factory created, closing their
+// idle HTTP connections, and makes later CreateClient calls fail with ErrFactoryClosed.
+// Requests already in flight finish normally; cancel their contexts to stop them early.
+// Close is safe to call more than once.
+//
+// Example:
+//
+//	factory := client.NewClientFactory()
+//	defer factory.Close()
+func (f *ClientFactory) Close() error {
+	f.mu.Lock()
+	clients := f.clients
+	f.clients = nil
+	f.closed = true
+	f.mu.Unlock()
+
+	for _, aiClient := range clients {
+		if closer, ok := aiClient.(idleConnectionCloser); ok {
+			closer.CloseIdleConnections()
+		}
+	}
+	if len(clients) > 0 {
+		f.logger.Info("Closed client factory and %d clients", len(clients))
+	}
+	return nil
 }
@@ -0,0 +1,309 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kengibson1111/go-aiprovider/aitest"
+	"github.com/kengibson1111/go-aiprovider/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientFactory_SetLogger(t *testing.T) {
+	srv := aitest.NewFakeClaudeServer(t)
+
+	var buf bytes.Buffer
+	factory := NewClientFactory()
+	factory.SetLogger(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	config := srv.Config()
+	config.LogLevel = "debug"
+	aiClient, err := factory.CreateClient(config)
+	require.NoError(t, err)
+	_, err = aiClient.CallWithPrompt(context.Background(), "hello from the test")
+	require.NoError(t, err)
+
+	output := buf.String()
+	assert.Contains(t, output, "Creating AI client for provider: claude")
+	assert.Contains(t, output, "Prompt: hello from the test")
+	assert.NotContains(t, output, aitest.FakeClaudeKey)
+
+	// RedactPrompts keeps prompt text out of the logs
+	buf.Reset()
+	config.RedactPrompts = true
+	aiClient, err = factory.CreateClient(config)
+	require.NoError(t, err)
+	_, err = aiClient.CallWithPrompt(context.Background(), "hello from the test")
+	require.NoError(t, err)
+	assert.NotContains(t, buf.String(), "hello from the test")
+	assert.Contains(t, buf.String(), "[19 characters redacted]")
+
+	// A logger on the config takes precedence over the factory logger
+	var own bytes.Buffer
+	buf.Reset()
+	config.Logger = slog.New(slog.NewTextHandler(&own, nil))
+	config.LogLevel = "info"
+	aiClient, err = factory.CreateClient(config)
+	require.NoError(t, err)
+	_, err = aiClient.CallWithPrompt(context.Background(), "hi")
+	require.NoError(t, err)
+	assert.Contains(t, own.String(), "HTTP request completed")
+	assert.NotContains(t, buf.String(), "HTTP request completed")
+}
+
+func TestClientFactory_KeyProviderRotation(t *testing.T) {
+	tests := []struct {
+		name   string
+		srv    *aitest.FakeServer
+		header func(aitest.Request) string
+	}{
+		{"claude", aitest.NewFakeClaudeServer(t), func(r aitest.Request) string { return r.Header.Get("x-api-key") }},
+		{"openai", aitest.NewFakeOpenAIServer(t), func(r aitest.Request) string { return r.Header.Get("Authorization") }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var current atomic.Value
+			current.Store("key-1")
+
+			config := tt.srv.Config()
+			config.APIKey = ""
+			config.KeyProvider = types.KeyProviderFunc(func(ctx context.Context) (string, error) {
+				return current.Load().(string), nil
+			})
+			aiClient, err := NewClientFactory().CreateClient(config)
+			require.NoError(t, err)
+
+			_, err = aiClient.CallWithPrompt(context.Background(), "hello")
+			require.NoError(t, err)
+			current.Store("key-2")
+			_, err = aiClient.CallWithPrompt(context.Background(), "hello")
+			require.NoError(t, err)
+
+			requests := tt.srv.Requests()
+			require.Len(t, requests, 2)
+			assert.Contains(t, tt.header(requests[0]), "key-1")
+			assert.Contains(t, tt.header(requests[1]), "key-2")
+		})
+	}
+}
+
+func TestClientFactory_KeyProviderError(t *testing.T) {
+	for _, srv := range []*aitest.FakeServer{aitest.NewFakeClaudeServer(t), aitest.NewFakeOpenAIServer(t)} {
+		config := srv.Config()
+		config.APIKey = ""
+		config.KeyProvider = types.KeyProviderFunc(func(ctx context.Context) (string, error) {
+			return "", fmt.Errorf("vault lookup: %w", context.DeadlineExceeded)
+		})
+		aiClient, err := NewClientFactory().CreateClient(config)
+		require.NoError(t, err)
+
+		_, err = aiClient.CallWithPrompt(context.Background(), "hello")
+		var errResp *types.ErrorResponse
+		require.ErrorAs(t, err, &errResp, config.Provider)
+		assert.Equal(t, "key_provider_error", errResp.Code, config.Provider)
+		assert.ErrorIs(t, err, context.DeadlineExceeded, config.Provider)
+		assert.NotErrorIs(t, err, types.ErrInvalidAPIKey, config.Provider)
+		assert.Empty(t, srv.Requests(), config.Provider)
+	}
+}
+
+func TestCallWithValues(t *testing.T) {
+	srv := aitest.NewFakeClaudeServer(t)
+	aiClient, err := NewClientFactory().CreateClient(srv.Config())
+	require.NoError(t, err)
+
+	values := struct {
+		Name  string `json:"name"`
+		Count int64  `json:"count"`
+	}{Name: "Alice", Count: 1234567}
+	_, err = CallWithValues(context.Background(), aiClient, "{{name}} has {{count}} points", values)
+	require.NoError(t, err)
+	assert.Contains(t, string(srv.Requests()[0].Body), "Alice has 1234567 points")
+
+	_, err = CallWithValues(context.Background(), aiClient, "{{x}}", []string{"not", "a", "map"})
+	assert.Error(t, err)
+	assert.Len(t, srv.Requests(), 1)
+}
+
+func TestClientFactory_Close(t *testing.T) {
+	srv := aitest.NewFakeOpenAIServer(t)
+	factory := NewClientFactory()
+
+	aiClient, err := factory.CreateClient(srv.Config())
+	require.NoError(t, err)
+	_, err = aiClient.CallWithPrompt(context.Background(), "hello")
+	require.NoError(t, err)
+
+	require.NoError(t, factory.Close())
+	require.NoError(t, factory.Close())
+
+	_, err = factory.CreateClient(srv.Config())
+	assert.ErrorIs(t, err, ErrFactoryClosed)
+
+	// Clients already created keep working after Close, on new connections
+	_, err = aiClient.CallWithPrompt(context.Background(), "hello again")
+	assert.NoError(t, err)
+}
+
+func TestClientFactory_ResponseMeta(t *testing.T) {
+	tests := []struct {
+		name    string
+		srv     *aitest.FakeServer
+		headers map[string]string
+		body    string
+	}{
+		{
+			name:    "openai",
+			srv:     aitest.NewFakeOpenAIServer(t),
+			headers: map[string]string{"x-request-id": "req_123", "x-ratelimit-remaining-requests": "59", "x-ratelimit-remaining-tokens": "149000", "x-ratelimit-reset-requests": "1s"},
+			body:    `{"id":"c1","object":"chat.completion","created":1,"model":"gpt-4o","choices":[{"index":0,"message":{"role":"assistant","content":"hi"},"finish_reason":"stop"}],"usage":{"prompt_tokens":1,"completion_tokens":1,"total_tokens":2}}`,
+		},
+		{
+			name:    "claude",
+			srv:     aitest.NewFakeClaudeServer(t),
+			headers: map[string]string{"request-id": "req_123", "anthropic-ratelimit-requests-remaining": "59", "anthropic-ratelimit-tokens-remaining": "149000", "anthropic-ratelimit-requests-reset": time.Now().Add(time.Second).UTC().Format(time.RFC3339)},
+			body:    `{"id":"m1","type":"message","role":"assistant","model":"claude","content":[{"type":"text","text":"hi"}],"stop_reason":"end_turn","usage":{"input_tokens":1,"output_tokens":1}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.srv.Enqueue(aitest.Reply{Handler: func(w http.ResponseWriter, r *http.Request) {
+				for name, value := range tt.headers {
+					w.Header().Set(name, value)
+				}
+				w.Header().Set("Content-Type", "application/json")
+				io.WriteString(w, tt.body)
+			}}, aitest.Reply{Handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("x-request-id", "req_456")
+				w.Header().Set("request-id", "req_456")
+				w.Header().Set("retry-after", "7")
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusBadRequest)
+				io.WriteString(w, `{"type":"error","error":{"type":"invalid_request_error","message":"bad input"}}`)
+			}})
+			aiClient, err := NewClientFactory().CreateClient(tt.srv.Config())
+			require.NoError(t, err)
+
+			ctx, meta := types.WithResponseMeta(context.Background())
+			_, err = aiClient.CallWithPrompt(ctx, "hello")
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusOK, meta.StatusCode)
+			assert.Equal(t, "req_123", meta.RequestID)
+			assert.Equal(t, 59, meta.RemainingRequests)
+			assert.Equal(t, 149000, meta.RemainingTokens)
+			assert.Positive(t, meta.ResetRequests)
+			assert.LessOrEqual(t, meta.ResetRequests, time.Second)
+			assert.Zero(t, meta.ResetTokens)
+
+			// Error responses are recorded too
+			ctx, meta = types.WithResponseMeta(context.Background())
+			_, err = aiClient.CallWithPrompt(ctx, "hello")
+			require.Error(t, err)
+			assert.Equal(t, http.StatusBadRequest, meta.StatusCode)
+			assert.Equal(t, "req_456", meta.RequestID)
+			assert.Equal(t, -1, meta.RemainingRequests)
+			assert.Equal(t, 7*time.Second, meta.RetryAfter)
+		})
+	}
+}
+
+func TestClientFactory_SentinelErrors(t *testing.T) {
+	// The OpenAI client retries 429 and 5xx responses; retry-after-ms keeps the retries fast
+	retried := func(status int, errType, message string) aitest.Reply {
+		return aitest.Reply{Handler: func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("retry-after-ms", "1")
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(status)
+			io.WriteString(w, `{"error":{"type":"`+errType+`","code":"`+errType+`","message":"`+message+`"}}`)
+		}}
+	}
+
+	tests := []struct {
+		name  string
+		srv   *aitest.FakeServer
+		reply aitest.Reply
+		want  error
+	}{
+		{"openai rate limit", aitest.NewFakeOpenAIServer(t), retried(http.StatusTooManyRequests, "rate_limit_exceeded", "slow down"), types.ErrRateLimited},
+		{"openai invalid key", aitest.NewFakeOpenAIServer(t), aitest.Error(http.StatusUnauthorized, "invalid_api_key", "bad key"), types.ErrInvalidAPIKey},
+		{"openai context length", aitest.NewFakeOpenAIServer(t), aitest.Error(http.StatusBadRequest, "context_length_exceeded", "too long"), types.ErrContextLengthExceeded},
+		{"openai model not found", aitest.NewFakeOpenAIServer(t), aitest.Error(http.StatusNotFound, "model_not_found", "no such model"), types.ErrModelNotFound},
+		{"openai server error", aitest.NewFakeOpenAIServer(t), retried(http.StatusInternalServerError, "server_error", "oops"), types.ErrServerError},
+		{"claude rate limit", aitest.NewFakeClaudeServer(t), aitest.Error(http.StatusTooManyRequests, "rate_limit_error", "slow down"), types.ErrRateLimited},
+		{"claude invalid key", aitest.NewFakeClaudeServer(t), aitest.Error(http.StatusUnauthorized, "authentication_error", "invalid x-api-key"), types.ErrInvalidAPIKey},
+		{"claude context length", aitest.NewFakeClaudeServer(t), aitest.Error(http.StatusBadRequest, "invalid_request_error", "prompt is too long: 210000 tokens > 200000 maximum"), types.ErrContextLengthExceeded},
+		{"claude model not found", aitest.NewFakeClaudeServer(t), aitest.Error(http.StatusNotFound, "not_found_error", "model: claude-nope"), types.ErrModelNotFound},
+		{"claude server error", aitest.NewFakeClaudeServer(t), aitest.Error(http.StatusInternalServerError, "api_error", "Internal server error"), types.ErrServerError},
+		{"claude overloaded", aitest.NewFakeClaudeServer(t), aitest.Error(529, "overloaded_error", "Overloaded"), types.ErrServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.srv.Default = tt.reply
+			aiClient, err := NewClientFactory().CreateClient(tt.srv.Config())
+			require.NoError(t, err)
+
+			_, err = aiClient.CallWithPrompt(context.Background(), "hello")
+			require.Error(t, err)
+			assert.ErrorIs(t, err, tt.want)
+			for _, other := range []error{types.ErrRateLimited, types.ErrInvalidAPIKey, types.ErrContextLengthExceeded, types.ErrModelNotFound, types.ErrServerError} {
+				if other != tt.want {
+					assert.NotErrorIs(t, err, other)
+				}
+			}
+		})
+	}
+}
+
+func TestClientFactory_ErrorRetryAfter(t *testing.T) {
+	rateLimited := func(headers map[string]string) aitest.Reply {
+		return aitest.Reply{Handler: func(w http.ResponseWriter, r *http.Request) {
+			for name, value := range headers {
+				w.Header().Set(name, value)
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusTooManyRequests)
+			io.WriteString(w, `{"type":"error","error":{"type":"rate_limit_error","code":"rate_limit_exceeded","message":"slow down"}}`)
+		}}
+	}
+
+	tests := []struct {
+		name    string
+		srv     *aitest.FakeServer
+		headers map[string]string
+		min     time.Duration
+		max     time.Duration
+	}{
+		{"openai retry-after-ms", aitest.NewFakeOpenAIServer(t), map[string]string{"retry-after-ms": "5"}, 5 * time.Millisecond, 5 * time.Millisecond},
+		{"claude retry-after", aitest.NewFakeClaudeServer(t), map[string]string{"retry-after": "12"}, 12 * time.Second, 12 * time.Second},
+		{"claude exhausted limit", aitest.NewFakeClaudeServer(t), map[string]string{
+			"anthropic-ratelimit-requests-remaining": "0",
+			"anthropic-ratelimit-requests-reset":     time.Now().Add(30 * time.Second).UTC().Format(time.RFC3339),
+		}, 20 * time.Second, 30 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.srv.Default = rateLimited(tt.headers)
+			aiClient, err := NewClientFactory().CreateClient(tt.srv.Config())
+			require.NoError(t, err)
+
+			_, err = aiClient.CallWithPrompt(context.Background(), "hello")
+			var errResp *types.ErrorResponse
+			require.ErrorAs(t, err, &errResp)
+			assert.True(t, errResp.Retry)
+			assert.GreaterOrEqual(t, errResp.RetryAfter, tt.min)
+			assert.LessOrEqual(t, errResp.RetryAfter, tt.max)
+		})
+	}
+}
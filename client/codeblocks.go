@@ -0,0 +1,291 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"regexp"
+	"strings"
+
+	"github.com/kengibson1111/go-aiprovider/types"
+)
+
+// SyntaxValidator checks that code in one language is syntactically valid.
+type SyntaxValidator func(code string) error
+
+// CodeFormatter formats code in one language, such as gofmt for Go.
+type CodeFormatter func(code string) (string, error)
+
+// CodeNormalizeOptions configures NormalizeCode.
+type CodeNormalizeOptions struct {
+	// Language selects the fenced block to return and the formatter to run. Empty returns
+	// the first block and formats it by its own language tag.
+	Language string
+
+	// Formatters maps languages to formatters, e.g. DefaultCodeFormatters. Languages
+	// without a formatter are returned as extracted.
+	Formatters map[string]CodeFormatter
+}
+
+// chatterPattern matches the conversational lines models put around unfenced code.
+var chatterPattern = regexp.MustCompile(`(?i)^(?:sure|certainly|of course|absolutely|okay)[,.!].*$|^(?:here(?:'s| is| are)|below is|the following)\b.*:$`)
+
+// languageAliases maps common fence info strings to a canonical language name.
+var languageAliases = map[string]string{
+	"golang":     "go",
+	"js":         "javascript",
+	"jsx":        "javascript",
+	"ts":         "typescript",
+	"tsx":        "typescript",
+	"py":         "python",
+	"python3":    "python",
+	"sh":         "bash",
+	"shell":      "bash",
+	"zsh":        "bash",
+	"yml":        "yaml",
+	"rs":         "rust",
+	"rb":         "ruby",
+	"c++":        "cpp",
+	"cs":         "csharp",
+	"c#":         "csharp",
+	"kt":         "kotlin",
+	"ps1":        "powershell",
+	"jsonc":      "json",
+	"postgresql": "sql",
+}
+
+// DefaultSyntaxValidators returns validators for the languages the standard library can
+// parse: Go and JSON.
+func DefaultSyntaxValidators() map[string]SyntaxValidator {
+	return map[string]SyntaxValidator{
+		"go":   ValidateGoSyntax,
+		"json": validateJSONSyntax,
+	}
+}
+
+// ExtractCodeBlocks returns the fenced code blocks in markdown text, in order. Fences may
+// use backticks or tildes, be indented up to three spaces, and carry any language tag;
+// text between blocks, such as explanations, is skipped. A block left open at the end of
+// the text, as in a truncated response, runs to the end.
+//
+// Example:
+//
+//	text, _, err := client.CompleteText(ctx, aiClient, "Write a Go function that reverses a string.")
+//	if err != nil {
+//		return err
+//	}
+//	for _, block := range client.ExtractCodeBlocks(text) {
+//		fmt.Printf("%s:\n%s\n", block.Language, block.Code)
+//	}
+func ExtractCodeBlocks(text string) []types.CodeBlock {
+	var blocks []types.CodeBlock
+	var current *types.CodeBlock
+	var fence string
+	var body []string
+
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		marker, info, ok := parseFence(line)
+		switch {
+		case current == nil && ok:
+			current = &types.CodeBlock{Language: normalizeLanguage(info)}
+			fence = marker
+			body = nil
+		case current != nil && ok && info == "" && marker[0] == fence[0] && len(marker) >= len(fence):
+			current.Code = strings.Join(body, "\n")
+			blocks = append(blocks, *current)
+			current = nil
+		case current != nil:
+			body = append(body, line)
+		}
+	}
+	if current != nil {
+		current.Code = strings.TrimRight(strings.Join(body, "\n"), "\n")
+		blocks = append(blocks, *current)
+	}
+	return blocks
+}
+
+// ExtractCode returns the code from markdown text: the first fenced block in language,
+// or the first block of any language when language is empty. Text without fences is
+// returned trimmed, on the assumption that the model answered with bare code; text with
+// fences but none in language returns "".
+func ExtractCode(text, language string) string {
+	blocks := ExtractCodeBlocks(text)
+	if len(blocks) == 0 {
+		return strings.TrimSpace(text)
+	}
+	language = normalizeLanguage(language)
+	for _, block := range blocks {
+		if language == "" || block.Language == language {
+			return block.Code
+		}
+	}
+	return ""
+}
+
+// NormalizeCode turns a code generation response into clean code: it removes a byte order
+// mark, normalizes line endings to "\n", takes the code out of its fence (or drops
+// chatter such as "Here's the code:" around unfenced code), trims trailing whitespace
+// from each line, and runs the formatter for the language.
+//
+// Example:
+//
+//	text, _, err := client.CompleteText(ctx, aiClient, "Write a Go function that reverses a string.")
+//	if err != nil {
+//		return err
+//	}
+//	code, err := client.NormalizeCode(text, client.CodeNormalizeOptions{
+//		Language:   "go",
+//		Formatters: client.DefaultCodeFormatters(),
+//	})
+func NormalizeCode(text string, opts CodeNormalizeOptions) (string, error) {
+	text = strings.TrimPrefix(text, "\ufeff")
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+
+	language := normalizeLanguage(opts.Language)
+	var code string
+	if blocks := ExtractCodeBlocks(text); len(blocks) > 0 {
+		found := false
+		for _, block := range blocks {
+			if language == "" || block.Language == language {
+				code, language, found = block.Code, block.Language, true
+				break
+			}
+		}
+		if !found {
+			return "", fmt.Errorf("response has no %s code block", language)
+		}
+	} else {
+		code = stripChatter(text)
+	}
+
+	lines := strings.Split(strings.TrimPrefix(code, "\ufeff"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	code = strings.Trim(strings.Join(lines, "\n"), "\n")
+
+	if format, ok := opts.Formatters[language]; ok && code != "" {
+		formatted, err := format(code)
+		if err != nil {
+			return "", fmt.Errorf("failed to format %s code: %w", language, err)
+		}
+		code = strings.TrimRight(formatted, "\n")
+	}
+	return code, nil
+}
+
+// DefaultCodeFormatters returns formatters for the languages the standard library can
+// format: Go.
+func DefaultCodeFormatters() map[string]CodeFormatter {
+	return map[string]CodeFormatter{
+		"go": FormatGo,
+	}
+}
+
+// FormatGo formats Go code like gofmt. Code may be a complete file or a fragment of
+// declarations or statements.
+func FormatGo(code string) (string, error) {
+	formatted, err := format.Source([]byte(code))
+	if err != nil {
+		return "", err
+	}
+	return string(formatted), nil
+}
+
+// stripChatter removes conversational lines before and after unfenced code.
+func stripChatter(text string) string {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	for len(lines) > 0 && (chatterPattern.MatchString(strings.TrimSpace(lines[0])) || strings.TrimSpace(lines[0]) == "") {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && (chatterPattern.MatchString(strings.TrimSpace(lines[len(lines)-1])) || strings.TrimSpace(lines[len(lines)-1]) == "") {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ValidateCodeBlocks checks each block with the validator for its language, skipping
+// blocks whose language has no validator, and returns the errors joined. Pass
+// DefaultSyntaxValidators, optionally with validators for other languages added.
+func ValidateCodeBlocks(blocks []types.CodeBlock, validators map[string]SyntaxValidator) error {
+	var errs []error
+	for i, block := range blocks {
+		validate, ok := validators[block.Language]
+		if !ok {
+			continue
+		}
+		if err := validate(block.Code); err != nil {
+			errs = append(errs, fmt.Errorf("code block %d (%s): %w", i+1, block.Language, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ValidateGoSyntax reports whether code parses as Go. Code may be a complete file, a set
+// of declarations without a package clause, or a list of statements.
+func ValidateGoSyntax(code string) error {
+	fset := token.NewFileSet()
+	_, err := parser.ParseFile(fset, "", code, parser.AllErrors)
+	if err == nil {
+		return nil
+	}
+	if _, declErr := parser.ParseFile(fset, "", "package p\n"+code, parser.AllErrors); declErr == nil {
+		return nil
+	}
+	if _, stmtErr := parser.ParseFile(fset, "", "package p\nfunc _() {\n"+code+"\n}", parser.AllErrors); stmtErr == nil {
+		return nil
+	}
+	return err
+}
+
+// validateJSONSyntax reports whether code is a valid JSON document.
+func validateJSONSyntax(code string) error {
+	var v any
+	if err := json.Unmarshal([]byte(code), &v); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+	return nil
+}
+
+// parseFence reports whether line is a code fence, returning the fence marker and the
+// first word of the info string.
+func parseFence(line string) (string, string, bool) {
+	trimmed := strings.TrimLeft(line, " ")
+	if len(line)-len(trimmed) > 3 || len(trimmed) < 3 {
+		return "", "", false
+	}
+	char := trimmed[0]
+	if char != '`' && char != '~' {
+		return "", "", false
+	}
+	n := 0
+	for n < len(trimmed) && trimmed[n] == char {
+		n++
+	}
+	if n < 3 {
+		return "", "", false
+	}
+	info := strings.TrimSpace(trimmed[n:])
+	if char == '`' && strings.Contains(info, "`") {
+		return "", "", false
+	}
+	if fields := strings.Fields(info); len(fields) > 0 {
+		info = fields[0]
+	}
+	return trimmed[:n], info, true
+}
+
+// normalizeLanguage lowercases a language tag and resolves common aliases.
+func normalizeLanguage(language string) string {
+	language = strings.ToLower(strings.TrimSpace(language))
+	language = strings.TrimPrefix(language, "language-")
+	if canonical, ok := languageAliases[language]; ok {
+		return canonical
+	}
+	return language
+}
@@ -0,0 +1,81 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/kengibson1111/go-aiprovider/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const codeAnswer = "Here is the function:\n\n" +
+	"```golang\nfunc Reverse(s string) string {\n\treturn s\n}\n```\n\n" +
+	"And a test, using a tilde fence:\n\n" +
+	"~~~~ ts {highlight: 1}\nconst x = \"```\";\n~~~~\n\n" +
+	"  ```\nplain\n  ```\n" +
+	"Done."
+
+func TestExtractCodeBlocks(t *testing.T) {
+	blocks := ExtractCodeBlocks(codeAnswer)
+	assert.Equal(t, []types.CodeBlock{
+		{Language: "go", Code: "func Reverse(s string) string {\n\treturn s\n}"},
+		{Language: "typescript", Code: "const x = \"```\";"},
+		{Language: "", Code: "plain"},
+	}, blocks)
+}
+
+func TestExtractCodeBlocks_Unclosed(t *testing.T) {
+	blocks := ExtractCodeBlocks("Sure:\n```python\nprint('hi')\nprint('truncated\n\n")
+	require.Len(t, blocks, 1)
+	assert.Equal(t, types.CodeBlock{Language: "python", Code: "print('hi')\nprint('truncated"}, blocks[0])
+
+	assert.Empty(t, ExtractCodeBlocks("no code here"))
+}
+
+func TestExtractCode(t *testing.T) {
+	assert.Equal(t, "func Reverse(s string) string {\n\treturn s\n}", ExtractCode(codeAnswer, ""))
+	assert.Equal(t, "const x = \"```\";", ExtractCode(codeAnswer, "TS"))
+	assert.Equal(t, "", ExtractCode(codeAnswer, "rust"))
+	assert.Equal(t, "x := 1", ExtractCode("  x := 1\n", "go"))
+}
+
+func TestValidateCodeBlocks(t *testing.T) {
+	blocks := []types.CodeBlock{
+		{Language: "go", Code: "package main\n\nfunc main() {}"},
+		{Language: "go", Code: "func add(a, b int) int { return a + b }"},
+		{Language: "go", Code: "x := 1\nfmt.Println(x)"},
+		{Language: "json", Code: `{"ok": true}`},
+		{Language: "cobol", Code: "anything goes"},
+	}
+	require.NoError(t, ValidateCodeBlocks(blocks, DefaultSyntaxValidators()))
+
+	err := ValidateCodeBlocks([]types.CodeBlock{
+		{Language: "go", Code: "func broken( {"},
+		{Language: "json", Code: `{"ok": }`},
+	}, DefaultSyntaxValidators())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "code block 1 (go)")
+	assert.Contains(t, err.Error(), "code block 2 (json)")
+}
+
+func TestNormalizeCode(t *testing.T) {
+	opts := CodeNormalizeOptions{Language: "go", Formatters: DefaultCodeFormatters()}
+
+	code, err := NormalizeCode("\ufeffSure! Here's the code:\r\n\r\n```go\r\nfunc add(a,b int) int {   \r\nreturn a+b\r\n}\r\n```\r\nThis adds two numbers.", opts)
+	require.NoError(t, err)
+	assert.Equal(t, "func add(a, b int) int {\n\treturn a + b\n}", code)
+
+	code, err = NormalizeCode("Here is the function:\nok := check()\nif !ok {\n\treturn\n}\n", opts)
+	require.NoError(t, err)
+	assert.Equal(t, "ok := check()\nif !ok {\n\treturn\n}", code)
+
+	_, err = NormalizeCode("```python\nprint(1)\n```", opts)
+	assert.Error(t, err)
+
+	_, err = NormalizeCode("```go\nfunc broken( {\n```", opts)
+	assert.Error(t, err)
+
+	code, err = NormalizeCode("```ts\nconst x = 1;  \n```", CodeNormalizeOptions{Formatters: DefaultCodeFormatters()})
+	require.NoError(t, err)
+	assert.Equal(t, "const x = 1;", code)
+}
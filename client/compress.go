@@ -0,0 +1,124 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/kengibson1111/go-aiprovider/types"
+)
+
+// defaultCompressInstruction asks a model to shorten a prompt without losing information.
+const defaultCompressInstruction = "Rewrite the following text to use as few tokens as possible while keeping every fact, name, number, identifier, and instruction. Keep code that is needed to understand it, and drop comments, boilerplate, and repetition. Reply with the rewritten text only."
+
+// importLinePattern matches import and include lines in common languages.
+var importLinePattern = regexp.MustCompile(`^\s*(?:import\s|from\s+\S+\s+import\s|#include\s|using\s+[\w.]+;|require\s*\(|const\s+\w+\s*=\s*require\s*\(|use\s+[\w:]+)`)
+
+// CompressOptions configures CompressPrompt. The zero value only removes redundant
+// whitespace.
+type CompressOptions struct {
+	// CollapseSpaces turns runs of spaces and tabs inside lines into one space. Leading
+	// indentation is kept. Leave it off for code where alignment matters.
+	CollapseSpaces bool
+
+	// DedupLines removes repeats of lines that match LinePattern, keeping the first,
+	// such as the same imports repeated across several pasted files.
+	DedupLines bool
+
+	// LinePattern selects the lines DedupLines removes (default: import and include lines).
+	LinePattern *regexp.Regexp
+
+	// AIClient, if set, rewrites the text more compactly after the other passes. The
+	// rewrite is used only if it is estimated to be shorter. It costs a call, and the
+	// model may drop details, so use it for context rather than instructions.
+	AIClient AIClient
+
+	// Instruction replaces the default rewriting instruction for AIClient.
+	Instruction string
+
+	// Model names the model for the token estimates (see EstimateTokens).
+	Model string
+
+	// CallOptions are passed to the AIClient call.
+	CallOptions []types.CallOption
+}
+
+// CompressResult is the outcome of CompressPrompt.
+type CompressResult struct {
+	Text             string
+	OriginalTokens   int              // Estimated tokens before compression
+	CompressedTokens int              // Estimated tokens after compression
+	Usage            types.TokenUsage // Tokens used by the AIClient rewrite, if any
+}
+
+// CompressPrompt reduces the tokens a prompt or context will use. It removes trailing
+// whitespace and repeated blank lines, and with options collapses spaces, removes repeated
+// import lines, and has a model rewrite the text. Run it on large code or document context
+// before sending it.
+//
+// Example:
+//
+//	result, err := client.CompressPrompt(ctx, sourceFiles, client.CompressOptions{DedupLines: true})
+//	log.Printf("context reduced from %d to %d tokens", result.OriginalTokens, result.CompressedTokens)
+//	response, err := aiClient.CallWithPrompt(ctx, "Review this code:\n\n"+result.Text)
+func CompressPrompt(ctx context.Context, text string, opts CompressOptions) (CompressResult, error) {
+	result := CompressResult{OriginalTokens: EstimateTokens(text, opts.Model)}
+
+	pattern := opts.LinePattern
+	if pattern == nil {
+		pattern = importLinePattern
+	}
+	seen := map[string]bool{}
+	var lines []string
+	blank := false
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		line = strings.TrimRight(line, " \t")
+		if line == "" {
+			if !blank && len(lines) > 0 {
+				lines = append(lines, line)
+			}
+			blank = true
+			continue
+		}
+		blank = false
+		if opts.CollapseSpaces {
+			line = collapseSpaces(line)
+		}
+		if opts.DedupLines && pattern.MatchString(line) {
+			key := strings.TrimSpace(line)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+		}
+		lines = append(lines, line)
+	}
+	result.Text = strings.TrimRight(strings.Join(lines, "\n"), "\n")
+
+	if opts.AIClient != nil && result.Text != "" {
+		instruction := opts.Instruction
+		if instruction == "" {
+			instruction = defaultCompressInstruction
+		}
+		rewritten, usage, err := CompleteText(ctx, opts.AIClient, instruction+"\n\n"+result.Text, opts.CallOptions...)
+		result.Usage = usage
+		if err != nil {
+			return result, fmt.Errorf("failed to compress prompt: %w", err)
+		}
+		rewritten = strings.TrimSpace(rewritten)
+		if rewritten != "" && EstimateTokens(rewritten, opts.Model) < EstimateTokens(result.Text, opts.Model) {
+			result.Text = rewritten
+		}
+	}
+
+	result.CompressedTokens = EstimateTokens(result.Text, opts.Model)
+	return result, nil
+}
+
+// collapseSpaces replaces runs of spaces and tabs after the indentation with one space.
+func collapseSpaces(line string) string {
+	body := strings.TrimLeft(line, " \t")
+	indent := line[:len(line)-len(body)]
+	return indent + strings.Join(strings.Fields(body), " ")
+}
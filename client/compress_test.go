@@ -0,0 +1,67 @@
+package client
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const compressInput = "package main   \r\n\r\n\r\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"a    b\")   \n}\n\n\n\nimport \"fmt\"\nimport \"os\"\n"
+
+func TestCompressPrompt(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     CompressOptions
+		expected string
+	}{
+		{
+			name:     "whitespace only",
+			expected: "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"a    b\")\n}\n\nimport \"fmt\"\nimport \"os\"",
+		},
+		{
+			name:     "dedup imports and collapse spaces",
+			opts:     CompressOptions{DedupLines: true, CollapseSpaces: true},
+			expected: "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"a b\")\n}\n\nimport \"os\"",
+		},
+		{
+			name:     "custom line pattern",
+			opts:     CompressOptions{DedupLines: true, LinePattern: regexp.MustCompile(`^package `)},
+			expected: "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"a    b\")\n}\n\nimport \"fmt\"\nimport \"os\"",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := CompressPrompt(context.Background(), compressInput, tt.opts)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result.Text)
+			assert.Equal(t, EstimateTokens(compressInput, ""), result.OriginalTokens)
+			assert.Equal(t, EstimateTokens(tt.expected, ""), result.CompressedTokens)
+			assert.Less(t, result.CompressedTokens, result.OriginalTokens)
+		})
+	}
+}
+
+func TestCompressPrompt_AIClient(t *testing.T) {
+	stub := &stubClient{responses: []string{
+		`{"choices":[{"index":0,"message":{"role":"assistant","content":"main prints a b"}}],"usage":{"prompt_tokens":50,"completion_tokens":5,"total_tokens":55}}`,
+		`{"choices":[{"index":0,"message":{"role":"assistant","content":"` + "a rewrite that is much longer than the text it was asked to compress, so it is not used" + `"}}]}`,
+	}}
+
+	result, err := CompressPrompt(context.Background(), compressInput, CompressOptions{AIClient: stub, Instruction: "Shorten:"})
+	require.NoError(t, err)
+	assert.Equal(t, "main prints a b", result.Text)
+	assert.Equal(t, 55, result.Usage.TotalTokens)
+	assert.Contains(t, stub.prompts[0], "Shorten:\n\npackage main\n")
+
+	// A longer rewrite is discarded
+	result, err = CompressPrompt(context.Background(), "short text", CompressOptions{AIClient: stub})
+	require.NoError(t, err)
+	assert.Equal(t, "short text", result.Text)
+
+	_, err = CompressPrompt(context.Background(), "text", CompressOptions{AIClient: stub})
+	assert.ErrorContains(t, err, "failed to compress prompt")
+}
@@ -0,0 +1,97 @@
+package client
+
+import (
+	"math"
+
+	"github.com/kengibson1111/go-aiprovider/types"
+)
+
+// ConfidenceInput is the information about a response that a ConfidenceScorer scores.
+type ConfidenceInput struct {
+	// FinishReason is the normalized finish reason, one of the types.Finish constants.
+	FinishReason string
+
+	// TokenLogprobs are the log probabilities of the generated tokens, or nil when the call
+	// did not request them with types.WithLogprobs or the provider does not return them.
+	TokenLogprobs []float64
+
+	// Length is the length of the generated text in characters.
+	Length int
+}
+
+// ConfidenceScorer scores how much a response can be trusted, from 0 (not at all) to 1.
+type ConfidenceScorer interface {
+	Score(input ConfidenceInput) float64
+}
+
+// ConfidenceScorerFunc adapts a function to a ConfidenceScorer.
+type ConfidenceScorerFunc func(input ConfidenceInput) float64
+
+// Score calls f.
+func (f ConfidenceScorerFunc) Score(input ConfidenceInput) float64 {
+	return f(input)
+}
+
+// DefaultConfidenceScorer scores empty, refused, and filtered responses 0. With token log
+// probabilities it scores the geometric mean token probability, halved for a response
+// cut off at the token limit. Without them it falls back to a heuristic: 0.75 for a
+// response that finished normally and 0.4 for a truncated one.
+var DefaultConfidenceScorer ConfidenceScorer = ConfidenceScorerFunc(defaultConfidence)
+
+// defaultConfidence implements DefaultConfidenceScorer.
+func defaultConfidence(input ConfidenceInput) float64 {
+	if input.Length == 0 || input.FinishReason == types.FinishRefusal || input.FinishReason == types.FinishContentFilter {
+		return 0
+	}
+	if len(input.TokenLogprobs) == 0 {
+		if input.FinishReason == types.FinishLength {
+			return 0.4
+		}
+		return 0.75
+	}
+
+	sum := 0.0
+	for _, logprob := range input.TokenLogprobs {
+		sum += logprob
+	}
+	score := math.Exp(sum / float64(len(input.TokenLogprobs)))
+	if input.FinishReason == types.FinishLength {
+		score /= 2
+	}
+	return score
+}
+
+// Confidence scores a raw response with scorer, or DefaultConfidenceScorer when scorer is
+// nil. The result is clamped to the range 0 to 1. Request token log probabilities with
+// types.WithLogprobs for a probability-based score.
+//
+// Example:
+//
+//	response, err := aiClient.CallWithPrompt(ctx, prompt, types.WithLogprobs())
+//	if err != nil {
+//		return err
+//	}
+//	confidence, err := client.Confidence(response, nil)
+//	if confidence < 0.5 {
+//		// escalate to a stronger model or a human
+//	}
+func Confidence(response []byte, scorer ConfidenceScorer) (float64, error) {
+	parsed, err := ParseResponse(response)
+	if err != nil {
+		return 0, err
+	}
+	var logprobs []float64
+	for _, token := range parsed.Logprobs {
+		logprobs = append(logprobs, token.Logprob)
+	}
+	if scorer == nil {
+		scorer = DefaultConfidenceScorer
+	}
+
+	score := scorer.Score(ConfidenceInput{
+		FinishReason:  parsed.FinishReason,
+		TokenLogprobs: logprobs,
+		Length:        len([]rune(parsed.Content)),
+	})
+	return min(max(score, 0), 1), nil
+}
@@ -0,0 +1,96 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/kengibson1111/go-aiprovider/aitest"
+	"github.com/kengibson1111/go-aiprovider/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfidence(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		expected float64
+	}{
+		{
+			name:     "logprobs",
+			response: `{"choices":[{"message":{"content":"Paris"},"finish_reason":"stop","logprobs":{"content":[{"token":"Par","logprob":-0.1},{"token":"is","logprob":-0.3}]}}]}`,
+			expected: math.Exp(-0.2),
+		},
+		{
+			name:     "logprobs truncated",
+			response: `{"choices":[{"message":{"content":"Par"},"finish_reason":"length","logprobs":{"content":[{"token":"Par","logprob":0}]}}]}`,
+			expected: 0.5,
+		},
+		{
+			name:     "heuristic without logprobs",
+			response: `{"content":[{"type":"text","text":"Paris"}],"stop_reason":"end_turn"}`,
+			expected: 0.75,
+		},
+		{
+			name:     "heuristic truncated",
+			response: `{"content":[{"type":"text","text":"Par"}],"stop_reason":"max_tokens"}`,
+			expected: 0.4,
+		},
+		{
+			name:     "refusal",
+			response: `{"choices":[{"message":{"content":null,"refusal":"No."},"finish_reason":"stop"}]}`,
+			expected: 0,
+		},
+		{
+			name:     "empty",
+			response: `{"choices":[{"message":{"content":""},"finish_reason":"stop"}]}`,
+			expected: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			confidence, err := Confidence([]byte(tt.response), nil)
+			require.NoError(t, err)
+			assert.InDelta(t, tt.expected, confidence, 1e-9)
+		})
+	}
+
+	_, err := Confidence([]byte("not json"), nil)
+	assert.Error(t, err)
+}
+
+func TestConfidence_CustomScorer(t *testing.T) {
+	var got ConfidenceInput
+	scorer := ConfidenceScorerFunc(func(input ConfidenceInput) float64 {
+		got = input
+		return 1.5
+	})
+
+	confidence, err := Confidence([]byte(`{"choices":[{"message":{"content":"héllo"},"finish_reason":"stop","logprobs":{"content":[{"token":"h","logprob":-1}]}}]}`), scorer)
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, confidence)
+	assert.Equal(t, ConfidenceInput{FinishReason: types.FinishStop, TokenLogprobs: []float64{-1}, Length: 5}, got)
+}
+
+func TestWithLogprobs_RequestsLogprobs(t *testing.T) {
+	srv := aitest.NewFakeOpenAIServer(t)
+	aiClient, err := NewClientFactory().CreateClient(srv.Config())
+	require.NoError(t, err)
+
+	_, err = aiClient.CallWithPrompt(context.Background(), "a")
+	require.NoError(t, err)
+	_, err = aiClient.CallWithPrompt(context.Background(), "b", types.WithLogprobs())
+	require.NoError(t, err)
+
+	var plain, withLogprobs struct {
+		Logprobs bool `json:"logprobs"`
+	}
+	requests := srv.Requests()
+	require.NoError(t, json.Unmarshal(requests[0].Body, &plain))
+	require.NoError(t, json.Unmarshal(requests[1].Body, &withLogprobs))
+	assert.False(t, plain.Logprobs)
+	assert.True(t, withLogprobs.Logprobs)
+}
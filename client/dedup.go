@@ -0,0 +1,136 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/kengibson1111/go-aiprovider/types"
+)
+
+// DedupClient wraps an AIClient so that identical concurrent requests share one API call.
+// Requests are identical when they use the same method, prompt, variables, and resolved
+// call options; the wrapped client fixes the provider and model defaults. Each caller
+// receives its own copy of the response. ValidateCredentials is passed through unchanged.
+//
+// The shared call keeps running while any caller is still waiting, so a caller that
+// gives up (for example an editor request superseded by the next keystroke) does not
+// cancel it for the others. It is cancelled once every caller has gone. Only in-flight
+// requests are shared; nothing is cached after the call returns. Callers that attach a
+// ResponseMeta with types.WithResponseMeta each receive a copy of the shared call's metadata.
+type DedupClient struct {
+	AIClient
+
+	mu     sync.Mutex
+	calls  map[string]*dedupCall
+	shared atomic.Int64
+}
+
+// dedupCall is an in-flight request and the callers waiting for it.
+type dedupCall struct {
+	done    chan struct{}
+	result  []byte
+	err     error
+	meta    *types.ResponseMeta
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// NewDedupClient wraps aiClient with request deduplication.
+//
+// Example:
+//
+//	dc, err := client.NewDedupClient(aiClient)
+//	// Concurrent identical completions from rapid keystrokes make one API call
+//	response, err := dc.CallWithPrompt(ctx, prefix)
+func NewDedupClient(aiClient AIClient) (*DedupClient, error) {
+	if aiClient == nil {
+		return nil, fmt.Errorf("AI client is required")
+	}
+	return &DedupClient{AIClient: aiClient, calls: map[string]*dedupCall{}}, nil
+}
+
+// CallWithPrompt sends the prompt, joining an identical request already in flight.
+func (d *DedupClient) CallWithPrompt(ctx context.Context, prompt string, opts ...types.CallOption) ([]byte, error) {
+	return d.do(ctx, dedupKey("prompt", prompt, "", opts), func(ctx context.Context) ([]byte, error) {
+		return d.AIClient.CallWithPrompt(ctx, prompt, opts...)
+	})
+}
+
+// CallWithPromptAndVariables sends the prompt template, joining an identical request
+// already in flight.
+func (d *DedupClient) CallWithPromptAndVariables(ctx context.Context, prompt string, variablesJSON string, opts ...types.CallOption) ([]byte, error) {
+	return d.do(ctx, dedupKey("variables", prompt, variablesJSON, opts), func(ctx context.Context) ([]byte, error) {
+		return d.AIClient.CallWithPromptAndVariables(ctx, prompt, variablesJSON, opts...)
+	})
+}
+
+// Shared returns the number of requests answered by joining another caller's request.
+func (d *DedupClient) Shared() int64 {
+	return d.shared.Load()
+}
+
+// do runs call once per key among concurrent callers and waits for its result or for
+// ctx to end.
+func (d *DedupClient) do(ctx context.Context, key string, call func(context.Context) ([]byte, error)) ([]byte, error) {
+	d.mu.Lock()
+	c, ok := d.calls[key]
+	if ok {
+		c.waiters++
+		d.shared.Add(1)
+	} else {
+		// Detach from this caller's cancellation; the call is cancelled when all callers leave.
+		// The call records its metadata into its own ResponseMeta rather than this caller's,
+		// which may be read as soon as this caller leaves.
+		callCtx, meta := types.WithResponseMeta(context.WithoutCancel(ctx))
+		callCtx, cancel := context.WithCancel(callCtx)
+		c = &dedupCall{done: make(chan struct{}), meta: meta, waiters: 1, cancel: cancel}
+		d.calls[key] = c
+		go func() {
+			c.result, c.err = call(callCtx)
+			cancel()
+			d.forget(key, c)
+			close(c.done)
+		}()
+	}
+	d.mu.Unlock()
+
+	select {
+	case <-c.done:
+		if meta := types.ResponseMetaFromContext(ctx); meta != nil {
+			*meta = *c.meta
+			meta.Header = c.meta.Header.Clone()
+		}
+		return bytes.Clone(c.result), c.err
+	case <-ctx.Done():
+		d.mu.Lock()
+		c.waiters--
+		abandoned := c.waiters == 0
+		if abandoned && d.calls[key] == c {
+			delete(d.calls, key)
+		}
+		d.mu.Unlock()
+		if abandoned {
+			c.cancel()
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// forget removes c from the in-flight calls, so later requests start a new call.
+func (d *DedupClient) forget(key string, c *dedupCall) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.calls[key] == c {
+		delete(d.calls, key)
+	}
+}
+
+// dedupKey identifies a request by method, prompt, variables, and resolved call options.
+func dedupKey(method, prompt, variables string, opts []types.CallOption) string {
+	resolved, _ := json.Marshal(types.ResolveCallOptions(types.CallOptions{}, opts))
+	return fmt.Sprintf("%s\x00%s\x00%s\x00%s", method, prompt, variables, resolved)
+}
@@ -0,0 +1,170 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kengibson1111/go-aiprovider/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingClient answers every prompt call with the prompt once release is closed, and
+// counts the calls and the calls whose context was cancelled.
+type blockingClient struct {
+	stubClient
+	release   chan struct{}
+	calls     atomic.Int32
+	cancelled atomic.Int32
+}
+
+func (b *blockingClient) CallWithPrompt(ctx context.Context, prompt string, opts ...types.CallOption) ([]byte, error) {
+	b.calls.Add(1)
+	select {
+	case <-b.release:
+		return []byte(prompt), nil
+	case <-ctx.Done():
+		b.cancelled.Add(1)
+		return nil, ctx.Err()
+	}
+}
+
+func TestDedupClient_SharesIdenticalRequests(t *testing.T) {
+	inner := &blockingClient{release: make(chan struct{})}
+	dc, err := NewDedupClient(inner)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	results := make([]string, 3)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			response, err := dc.CallWithPrompt(context.Background(), "func main() {", types.WithMaxTokens(64))
+			assert.NoError(t, err)
+			results[i] = string(response)
+		}()
+	}
+	// A request with different options is not shared
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_, err := dc.CallWithPrompt(context.Background(), "func main() {", types.WithMaxTokens(128))
+		assert.NoError(t, err)
+	}()
+
+	require.Eventually(t, func() bool { return dc.Shared() == 2 && inner.calls.Load() == 2 }, time.Second, time.Millisecond)
+	close(inner.release)
+	wg.Wait()
+
+	assert.Equal(t, []string{"func main() {", "func main() {", "func main() {"}, results)
+	assert.Equal(t, int32(2), inner.calls.Load())
+
+	// Finished requests are not cached
+	_, err = dc.CallWithPrompt(context.Background(), "func main() {", types.WithMaxTokens(64))
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), inner.calls.Load())
+}
+
+func TestDedupClient_Cancellation(t *testing.T) {
+	inner := &blockingClient{release: make(chan struct{})}
+	dc, err := NewDedupClient(inner)
+	require.NoError(t, err)
+
+	first, cancelFirst := context.WithCancel(context.Background())
+	firstDone := make(chan error)
+	go func() {
+		_, err := dc.CallWithPrompt(first, "prefix")
+		firstDone <- err
+	}()
+	require.Eventually(t, func() bool { return inner.calls.Load() == 1 }, time.Second, time.Millisecond)
+
+	secondDone := make(chan string)
+	go func() {
+		response, _ := dc.CallWithPrompt(context.Background(), "prefix")
+		secondDone <- string(response)
+	}()
+	require.Eventually(t, func() bool { return dc.Shared() == 1 }, time.Second, time.Millisecond)
+
+	// The first caller leaving does not cancel the call for the second
+	cancelFirst()
+	assert.ErrorIs(t, <-firstDone, context.Canceled)
+	close(inner.release)
+	assert.Equal(t, "prefix", <-secondDone)
+	assert.Zero(t, inner.cancelled.Load())
+
+	// When every caller leaves, the call is cancelled
+	inner.release = make(chan struct{})
+	only, cancelOnly := context.WithCancel(context.Background())
+	go func() {
+		assert.Eventually(t, func() bool { return inner.calls.Load() == 2 }, time.Second, time.Millisecond)
+		cancelOnly()
+	}()
+	_, err = dc.CallWithPrompt(only, "prefix")
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Eventually(t, func() bool { return inner.cancelled.Load() == 1 }, time.Second, time.Millisecond)
+}
+
+// metaClient is a blockingClient that records response metadata like a provider client.
+type metaClient struct {
+	blockingClient
+}
+
+func (m *metaClient) CallWithPrompt(ctx context.Context, prompt string, opts ...types.CallOption) ([]byte, error) {
+	response, err := m.blockingClient.CallWithPrompt(ctx, prompt, opts...)
+	if meta := types.ResponseMetaFromContext(ctx); meta != nil {
+		meta.StatusCode = 200
+		meta.RequestID = "req_" + prompt
+		meta.Header = http.Header{"Request-Id": {"req_" + prompt}}
+	}
+	return response, err
+}
+
+func TestDedupClient_ResponseMeta(t *testing.T) {
+	inner := &metaClient{blockingClient{release: make(chan struct{})}}
+	dc, err := NewDedupClient(inner)
+	require.NoError(t, err)
+
+	// A caller that leaves keeps its metadata untouched while the call finishes
+	first, cancelFirst := context.WithCancel(context.Background())
+	first, firstMeta := types.WithResponseMeta(first)
+	firstDone := make(chan error)
+	go func() {
+		_, err := dc.CallWithPrompt(first, "prefix")
+		firstDone <- err
+	}()
+	require.Eventually(t, func() bool { return inner.calls.Load() == 1 }, time.Second, time.Millisecond)
+
+	var wg sync.WaitGroup
+	metas := make([]*types.ResponseMeta, 2)
+	for i := range metas {
+		ctx, meta := types.WithResponseMeta(context.Background())
+		metas[i] = meta
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := dc.CallWithPrompt(ctx, "prefix")
+			assert.NoError(t, err)
+		}()
+	}
+	require.Eventually(t, func() bool { return dc.Shared() == 2 }, time.Second, time.Millisecond)
+
+	cancelFirst()
+	assert.ErrorIs(t, <-firstDone, context.Canceled)
+	close(inner.release)
+	wg.Wait()
+
+	// Every waiting caller gets its own copy of the metadata
+	for _, meta := range metas {
+		assert.Equal(t, 200, meta.StatusCode)
+		assert.Equal(t, "req_prefix", meta.RequestID)
+		assert.Equal(t, -1, meta.RemainingRequests)
+	}
+	metas[0].Header.Set("Request-Id", "changed")
+	assert.Equal(t, "req_prefix", metas[1].Header.Get("Request-Id"))
+	assert.Zero(t, firstMeta.StatusCode)
+}
@@ -0,0 +1,131 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/kengibson1111/go-aiprovider/types"
+)
+
+// ErrFingerprintDrift is returned by a strict DeterministicClient when the provider's
+// system fingerprint for a model changes between calls.
+var ErrFingerprintDrift = errors.New("system fingerprint changed")
+
+// FingerprintDrift reports a change of a model's system fingerprint: the provider changed
+// the backend configuration serving the model, so identical requests may no longer give
+// identical responses.
+type FingerprintDrift struct {
+	Model    string `json:"model"`
+	Previous string `json:"previous"`
+	Current  string `json:"current"`
+}
+
+// DeterministicOptions configures a DeterministicClient.
+type DeterministicOptions struct {
+	// Seed is sent with every call.
+	Seed int64
+
+	// Strict fails calls whose fingerprint changed with ErrFingerprintDrift. The response
+	// is returned with the error.
+	Strict bool
+
+	// OnDrift, if set, receives every fingerprint change.
+	OnDrift func(FingerprintDrift)
+}
+
+// DeterministicClient wraps an AIClient for reproducible output: every call is sent with
+// temperature 0 and a fixed seed, overriding the call's own options, and the
+// system_fingerprint of each response is recorded per model so that backend changes that
+// break reproducibility are detected. OpenAI returns fingerprints and honors the seed;
+// Claude supports neither, so only the temperature applies to it. ValidateCredentials is
+// passed through unchanged.
+type DeterministicClient struct {
+	AIClient
+	opts DeterministicOptions
+
+	mu           sync.Mutex
+	fingerprints map[string]string
+}
+
+// NewDeterministicClient wraps aiClient in deterministic mode.
+//
+// Example:
+//
+//	dc, err := client.NewDeterministicClient(aiClient, client.DeterministicOptions{
+//		Seed:    42,
+//		OnDrift: func(d client.FingerprintDrift) { log.Printf("reproducibility at risk: %+v", d) },
+//	})
+//	response, err := dc.CallWithPrompt(ctx, "Extract the fields from this form: "+form)
+func NewDeterministicClient(aiClient AIClient, opts DeterministicOptions) (*DeterministicClient, error) {
+	if aiClient == nil {
+		return nil, fmt.Errorf("AI client is required")
+	}
+
+	return &DeterministicClient{
+		AIClient:     aiClient,
+		opts:         opts,
+		fingerprints: map[string]string{},
+	}, nil
+}
+
+// Fingerprints returns the latest system fingerprint seen for each model, for recording
+// alongside results that must be reproducible.
+func (c *DeterministicClient) Fingerprints() map[string]string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return maps.Clone(c.fingerprints)
+}
+
+// CallWithPrompt sends the prompt deterministically and checks the fingerprint.
+func (c *DeterministicClient) CallWithPrompt(ctx context.Context, prompt string, opts ...types.CallOption) ([]byte, error) {
+	return c.check(c.AIClient.CallWithPrompt(ctx, prompt, c.options(opts)...))
+}
+
+// CallWithPromptAndVariables sends the prompt deterministically and checks the
+// fingerprint.
+func (c *DeterministicClient) CallWithPromptAndVariables(ctx context.Context, prompt string, variablesJSON string, opts ...types.CallOption) ([]byte, error) {
+	return c.check(c.AIClient.CallWithPromptAndVariables(ctx, prompt, variablesJSON, c.options(opts)...))
+}
+
+// options appends the deterministic settings after the caller's, so they take effect.
+func (c *DeterministicClient) options(opts []types.CallOption) []types.CallOption {
+	return append(slices.Clip(opts), types.WithTemperature(0), types.WithSeed(c.opts.Seed), func(o *types.CallOptions) {
+		o.TopP = nil
+	})
+}
+
+// check records the response's fingerprint and reports a change.
+func (c *DeterministicClient) check(response []byte, err error) ([]byte, error) {
+	if err != nil {
+		return response, err
+	}
+	var parsed struct {
+		Model             string `json:"model"`
+		SystemFingerprint string `json:"system_fingerprint"`
+	}
+	if json.Unmarshal(response, &parsed) != nil || parsed.SystemFingerprint == "" {
+		return response, nil
+	}
+
+	c.mu.Lock()
+	previous, seen := c.fingerprints[parsed.Model]
+	c.fingerprints[parsed.Model] = parsed.SystemFingerprint
+	c.mu.Unlock()
+	if !seen || previous == parsed.SystemFingerprint {
+		return response, nil
+	}
+
+	drift := FingerprintDrift{Model: parsed.Model, Previous: previous, Current: parsed.SystemFingerprint}
+	if c.opts.OnDrift != nil {
+		c.opts.OnDrift(drift)
+	}
+	if c.opts.Strict {
+		return response, fmt.Errorf("%w for model %s: %s to %s", ErrFingerprintDrift, drift.Model, drift.Previous, drift.Current)
+	}
+	return response, nil
+}
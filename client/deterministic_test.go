@@ -0,0 +1,72 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kengibson1111/go-aiprovider/aitest"
+	"github.com/kengibson1111/go-aiprovider/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fingerprintResponse(fingerprint string) string {
+	return `{"model":"gpt-4o","system_fingerprint":"` + fingerprint + `","choices":[{"index":0,"message":{"role":"assistant","content":"ok"}}]}`
+}
+
+func TestDeterministicClient_SendsSeedAndTemperature(t *testing.T) {
+	srv := aitest.NewFakeOpenAIServer(t)
+	aiClient, err := NewClientFactory().CreateClient(srv.Config())
+	require.NoError(t, err)
+
+	dc, err := NewDeterministicClient(aiClient, DeterministicOptions{Seed: 42})
+	require.NoError(t, err)
+	_, err = dc.CallWithPrompt(context.Background(), "hello", types.WithTemperature(0.9), types.WithTopP(0.5))
+	require.NoError(t, err)
+
+	body := string(srv.Requests()[0].Body)
+	assert.Contains(t, body, `"seed":42`)
+	assert.Contains(t, body, `"temperature":0`)
+	assert.NotContains(t, body, `"temperature":0.9`)
+	assert.NotContains(t, body, `"top_p"`)
+}
+
+func TestDeterministicClient_FingerprintDrift(t *testing.T) {
+	stub := &stubClient{responses: []string{
+		fingerprintResponse("fp_1"),
+		fingerprintResponse("fp_1"),
+		fingerprintResponse("fp_2"),
+		`{"content":[{"type":"text","text":"no fingerprint"}]}`,
+	}}
+	var drifts []FingerprintDrift
+	dc, err := NewDeterministicClient(stub, DeterministicOptions{OnDrift: func(d FingerprintDrift) { drifts = append(drifts, d) }})
+	require.NoError(t, err)
+
+	for range 4 {
+		_, err := dc.CallWithPrompt(context.Background(), "hello")
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []FingerprintDrift{{Model: "gpt-4o", Previous: "fp_1", Current: "fp_2"}}, drifts)
+	assert.Equal(t, map[string]string{"gpt-4o": "fp_2"}, dc.Fingerprints())
+}
+
+func TestDeterministicClient_Strict(t *testing.T) {
+	stub := &stubClient{responses: []string{fingerprintResponse("fp_1"), fingerprintResponse("fp_2"), fingerprintResponse("fp_2")}}
+	dc, err := NewDeterministicClient(stub, DeterministicOptions{Strict: true})
+	require.NoError(t, err)
+
+	_, err = dc.CallWithPromptAndVariables(context.Background(), "hi {{x}}", `{"x":1}`)
+	require.NoError(t, err)
+
+	response, err := dc.CallWithPrompt(context.Background(), "hello")
+	assert.ErrorIs(t, err, ErrFingerprintDrift)
+	assert.EqualError(t, err, "system fingerprint changed for model gpt-4o: fp_1 to fp_2")
+	assert.Equal(t, fingerprintResponse("fp_2"), string(response))
+
+	// The new fingerprint becomes the baseline
+	_, err = dc.CallWithPrompt(context.Background(), "hello")
+	assert.NoError(t, err)
+
+	_, err = NewDeterministicClient(nil, DeterministicOptions{})
+	assert.EqualError(t, err, "AI client is required")
+}
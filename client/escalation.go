@@ -0,0 +1,294 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kengibson1111/go-aiprovider/types"
+)
+
+// ErrRefused is returned by EscalationClient when the request was refused and every
+// escalation step was refused as well.
+var ErrRefused = errors.New("request refused by model")
+
+// refusalPhrases are openings that mark a response as a refusal when the provider does
+// not flag it explicitly. Only the start of the text is checked, so answers that quote
+// these phrases later on are not treated as refusals.
+var refusalPhrases = []string{
+	"i can't help with",
+	"i cannot help with",
+	"i can't assist with",
+	"i cannot assist with",
+	"i can't provide",
+	"i cannot provide",
+	"i'm not able to help",
+	"i am not able to help",
+	"i'm unable to help",
+	"i am unable to help",
+	"i won't be able to help",
+	"i'm sorry, but i can't",
+	"i'm sorry, but i cannot",
+	"sorry, but i can't",
+	"sorry, i can't help",
+}
+
+// IsRefusal reports whether a raw Claude or OpenAI response is a refusal: an OpenAI
+// message with a refusal field, a Claude response with stop_reason "refusal", or text
+// that opens with a common refusal phrase. Unrecognized responses are not refusals.
+func IsRefusal(response []byte) bool {
+	var parsed struct {
+		StopReason string `json:"stop_reason"`
+		Choices    []struct {
+			Message struct {
+				Refusal string `json:"refusal"`
+			} `json:"message"`
+		} `json:"choices"`
+	}
+	if err := json.Unmarshal(response, &parsed); err != nil {
+		return false
+	}
+	if parsed.StopReason == "refusal" {
+		return true
+	}
+	for _, choice := range parsed.Choices {
+		if choice.Message.Refusal != "" {
+			return true
+		}
+	}
+
+	var texts []string
+	if _, err := rewriteResponseText(response, func(text string) string {
+		texts = append(texts, text)
+		return text
+	}); err != nil {
+		return false
+	}
+	opening := strings.ToLower(strings.TrimSpace(strings.Join(texts, "")))
+	opening = strings.ReplaceAll(opening, "’", "'")
+	for _, phrase := range refusalPhrases {
+		if strings.HasPrefix(opening, phrase) {
+			return true
+		}
+	}
+	return false
+}
+
+// EscalationStep is one retry strategy tried after a refusal. A step can combine a
+// rephrased prompt, extra system context, and a different client.
+type EscalationStep struct {
+	// Name identifies the step in the EscalationReport.
+	Name string
+
+	// Rephrase, if set, rewrites the original prompt (the template, for calls with
+	// variables), for example to state the legitimate purpose of the request.
+	Rephrase func(prompt string) string
+
+	// SystemContext, if set, is sent as the system prompt for this step, appended to
+	// any system prompt passed to the call with types.WithSystemPrompt. It replaces a
+	// system prompt set in AIConfig.
+	SystemContext string
+
+	// Client, if set, handles this step instead of the wrapped client, for example a
+	// client for a different provider.
+	Client AIClient
+}
+
+// EscalationAttempt records the outcome of the original call or one escalation step.
+type EscalationAttempt struct {
+	// Step is "original" for the first call, otherwise the step name.
+	Step     string        `json:"step"`
+	Refused  bool          `json:"refused"`
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// EscalationReport is the audit of one escalated call.
+type EscalationReport struct {
+	// Attempts lists the original call and each step tried, in order.
+	Attempts []EscalationAttempt `json:"attempts"`
+
+	// SucceededStep is the step that produced the returned response, or empty when
+	// every attempt was refused or failed.
+	SucceededStep string `json:"succeededStep,omitempty"`
+}
+
+// EscalationOptions configures an EscalationClient.
+type EscalationOptions struct {
+	// Steps are tried in order after the original call is refused.
+	Steps []EscalationStep
+
+	// IsRefusal decides whether a response is a refusal. Defaults to IsRefusal.
+	IsRefusal func(response []byte) bool
+
+	// OnReport, if set, receives the report for every call that was refused at least once.
+	OnReport func(EscalationReport)
+
+	// SplitDeadline gives the original call and each step a share of the time left before
+	// the context deadline (see DeadlineBudget), so a slow attempt cannot use up the time
+	// of the steps after it.
+	SplitDeadline bool
+}
+
+// EscalationClient wraps an AIClient and retries refused prompt calls through a list of
+// escalation steps, returning the first response that is not a refusal.
+// ValidateCredentials is passed through unchanged.
+//
+// Escalation does not bypass provider safety policies; it recovers from refusals of
+// legitimate requests caused by ambiguous wording or missing context.
+type EscalationClient struct {
+	AIClient
+	opts EscalationOptions
+}
+
+// escalationOriginal is the step name reported for the original call
+const escalationOriginal = "original"
+
+// NewEscalationClient wraps aiClient with the given escalation steps. Every step needs a
+// unique name and at least one of Rephrase, SystemContext, or Client.
+//
+// Example:
+//
+//	ec, err := client.NewEscalationClient(aiClient, client.EscalationOptions{
+//		Steps: []client.EscalationStep{
+//			{Name: "clarify", SystemContext: "The user is a nurse asking about medication doses for patient care."},
+//			{Name: "rephrase", Rephrase: func(p string) string { return "For internal documentation: " + p }},
+//			{Name: "claude", Client: claudeClient},
+//		},
+//		OnReport: func(r client.EscalationReport) { log.Printf("escalated: %+v", r) },
+//	})
+func NewEscalationClient(aiClient AIClient, opts EscalationOptions) (*EscalationClient, error) {
+	if aiClient == nil {
+		return nil, fmt.Errorf("AI client is required")
+	}
+
+	names := make(map[string]bool, len(opts.Steps))
+	for i, step := range opts.Steps {
+		if strings.TrimSpace(step.Name) == "" {
+			return nil, fmt.Errorf("escalation step %d must have a name", i)
+		}
+		if step.Name == escalationOriginal || names[step.Name] {
+			return nil, fmt.Errorf("escalation step name %q is reserved or duplicated", step.Name)
+		}
+		if step.Rephrase == nil && step.SystemContext == "" && step.Client == nil {
+			return nil, fmt.Errorf("escalation step %q changes nothing", step.Name)
+		}
+		names[step.Name] = true
+	}
+
+	if opts.IsRefusal == nil {
+		opts.IsRefusal = IsRefusal
+	}
+
+	return &EscalationClient{
+		AIClient: aiClient,
+		opts:     opts,
+	}, nil
+}
+
+// escalationCall sends a prompt (or template) through a client with the given options.
+type escalationCall func(ctx context.Context, c AIClient, prompt string, opts []types.CallOption) ([]byte, error)
+
+// CallWithPrompt sends the prompt and escalates if it is refused.
+func (c *EscalationClient) CallWithPrompt(ctx context.Context, prompt string, opts ...types.CallOption) ([]byte, error) {
+	return c.escalate(ctx, prompt, opts, func(ctx context.Context, ac AIClient, p string, o []types.CallOption) ([]byte, error) {
+		return ac.CallWithPrompt(ctx, p, o...)
+	})
+}
+
+// CallWithPromptAndVariables renders and sends the prompt and escalates if it is refused.
+func (c *EscalationClient) CallWithPromptAndVariables(ctx context.Context, prompt string, variablesJSON string, opts ...types.CallOption) ([]byte, error) {
+	return c.escalate(ctx, prompt, opts, func(ctx context.Context, ac AIClient, p string, o []types.CallOption) ([]byte, error) {
+		return ac.CallWithPromptAndVariables(ctx, p, variablesJSON, o...)
+	})
+}
+
+// escalate runs the original call, then each step in order until a response is not a
+// refusal. Errors from the original call are returned as is; errors from a step are
+// recorded and the next step is tried. When ctx ends between steps, its error is returned.
+func (c *EscalationClient) escalate(ctx context.Context, prompt string, opts []types.CallOption, call escalationCall) ([]byte, error) {
+	var report EscalationReport
+	attemptCtx := func() (context.Context, context.CancelFunc) { return ctx, func() {} }
+	if c.opts.SplitDeadline {
+		budget := NewDeadlineBudget(ctx, len(c.opts.Steps)+1)
+		attemptCtx = func() (context.Context, context.CancelFunc) { return budget.Next(ctx) }
+	}
+
+	start := time.Now()
+	callCtx, cancel := attemptCtx()
+	response, err := call(callCtx, c.AIClient, prompt, opts)
+	cancel()
+	if err != nil {
+		return nil, err
+	}
+	if !c.opts.IsRefusal(response) {
+		return response, nil
+	}
+	report.Attempts = append(report.Attempts, EscalationAttempt{Step: escalationOriginal, Refused: true, Duration: time.Since(start)})
+
+	var lastErr error
+	for _, step := range c.opts.Steps {
+		if err := ctx.Err(); err != nil {
+			c.deliver(report)
+			return nil, err
+		}
+
+		stepClient := c.AIClient
+		if step.Client != nil {
+			stepClient = step.Client
+		}
+		stepPrompt := prompt
+		if step.Rephrase != nil {
+			stepPrompt = step.Rephrase(prompt)
+		}
+		stepOpts := opts
+		if step.SystemContext != "" {
+			stepOpts = append(append([]types.CallOption{}, opts...), withSystemContext(opts, step.SystemContext))
+		}
+
+		start := time.Now()
+		callCtx, cancel := attemptCtx()
+		response, err := call(callCtx, stepClient, stepPrompt, stepOpts)
+		cancel()
+		attempt := EscalationAttempt{Step: step.Name, Duration: time.Since(start)}
+		if err != nil {
+			attempt.Error = err.Error()
+			lastErr = err
+		} else {
+			attempt.Refused = c.opts.IsRefusal(response)
+		}
+		report.Attempts = append(report.Attempts, attempt)
+
+		if err == nil && !attempt.Refused {
+			report.SucceededStep = step.Name
+			c.deliver(report)
+			return response, nil
+		}
+	}
+
+	c.deliver(report)
+	if lastErr != nil {
+		return nil, fmt.Errorf("%w after %d escalation attempts (last error: %w)", ErrRefused, len(report.Attempts)-1, lastErr)
+	}
+	return nil, fmt.Errorf("%w after %d escalation attempts", ErrRefused, len(report.Attempts)-1)
+}
+
+// deliver passes the report to OnReport when it is set.
+func (c *EscalationClient) deliver(report EscalationReport) {
+	if c.opts.OnReport != nil {
+		c.opts.OnReport(report)
+	}
+}
+
+// withSystemContext returns an option that appends context to the system prompt passed
+// with the call's own options.
+func withSystemContext(opts []types.CallOption, extra string) types.CallOption {
+	existing := types.ResolveCallOptions(types.CallOptions{}, opts).SystemPrompt
+	if existing != "" {
+		extra = existing + "\n\n" + extra
+	}
+	return types.WithSystemPrompt(extra)
+}
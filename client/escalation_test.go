@@ -0,0 +1,184 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kengibson1111/go-aiprovider/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	refusedResponse  = `{"choices":[{"index":0,"message":{"role":"assistant","content":"I can't help with that request."}}]}`
+	answeredResponse = `{"choices":[{"index":0,"message":{"role":"assistant","content":"Here is the answer."}}]}`
+)
+
+// optionsClient is a stubClient that also records the system prompt of each call.
+type optionsClient struct {
+	stubClient
+	systemPrompts []string
+}
+
+func (o *optionsClient) CallWithPrompt(ctx context.Context, prompt string, opts ...types.CallOption) ([]byte, error) {
+	o.systemPrompts = append(o.systemPrompts, types.ResolveCallOptions(types.CallOptions{}, opts).SystemPrompt)
+	return o.stubClient.CallWithPrompt(ctx, prompt, opts...)
+}
+
+func (o *optionsClient) CallWithPromptAndVariables(ctx context.Context, prompt string, variablesJSON string, opts ...types.CallOption) ([]byte, error) {
+	return o.CallWithPrompt(ctx, prompt, opts...)
+}
+
+func TestIsRefusal(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		expected bool
+	}{
+		{name: "OpenAI refusal field", response: `{"choices":[{"message":{"content":null,"refusal":"I'm sorry, I cannot do that."}}]}`, expected: true},
+		{name: "Claude refusal stop reason", response: `{"content":[{"type":"text","text":"Partial"}],"stop_reason":"refusal"}`, expected: true},
+		{name: "refusal phrase in OpenAI text", response: refusedResponse, expected: true},
+		{name: "curly apostrophe in Claude text", response: `{"content":[{"type":"text","text":"  I’m sorry, but I can’t share that."}],"stop_reason":"end_turn"}`, expected: true},
+		{name: "answer quoting a refusal phrase", response: `{"content":[{"type":"text","text":"The bot replied \"I can't help with that\"."}]}`, expected: false},
+		{name: "normal answer", response: answeredResponse, expected: false},
+		{name: "not JSON", response: `plain text`, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, IsRefusal([]byte(tt.response)))
+		})
+	}
+}
+
+func TestEscalationClient_Steps(t *testing.T) {
+	primary := &optionsClient{stubClient: stubClient{responses: []string{refusedResponse, refusedResponse, refusedResponse}}}
+	fallback := &stubClient{responses: []string{answeredResponse}}
+
+	var reports []EscalationReport
+	ec, err := NewEscalationClient(primary, EscalationOptions{
+		Steps: []EscalationStep{
+			{Name: "clarify", SystemContext: "The user is a clinician."},
+			{Name: "rephrase", Rephrase: func(p string) string { return "For a medical reference: " + p }},
+			{Name: "fallback", Client: fallback},
+		},
+		OnReport: func(r EscalationReport) { reports = append(reports, r) },
+	})
+	require.NoError(t, err)
+
+	response, err := ec.CallWithPrompt(context.Background(), "max dose of ibuprofen?", types.WithSystemPrompt("Be brief."))
+	require.NoError(t, err)
+	assert.Equal(t, answeredResponse, string(response))
+
+	assert.Equal(t, []string{"max dose of ibuprofen?", "max dose of ibuprofen?", "For a medical reference: max dose of ibuprofen?"}, primary.prompts)
+	assert.Equal(t, []string{"Be brief.", "Be brief.\n\nThe user is a clinician.", "Be brief."}, primary.systemPrompts)
+	assert.Equal(t, []string{"max dose of ibuprofen?"}, fallback.prompts)
+
+	require.Len(t, reports, 1)
+	assert.Equal(t, "fallback", reports[0].SucceededStep)
+	var steps []string
+	for _, a := range reports[0].Attempts {
+		steps = append(steps, a.Step)
+	}
+	assert.Equal(t, []string{"original", "clarify", "rephrase", "fallback"}, steps)
+	assert.True(t, reports[0].Attempts[2].Refused)
+	assert.False(t, reports[0].Attempts[3].Refused)
+}
+
+func TestEscalationClient_NoRefusal(t *testing.T) {
+	stub := &stubClient{responses: []string{answeredResponse}}
+	called := false
+	ec, err := NewEscalationClient(stub, EscalationOptions{
+		Steps:    []EscalationStep{{Name: "clarify", SystemContext: "context"}},
+		OnReport: func(EscalationReport) { called = true },
+	})
+	require.NoError(t, err)
+
+	response, err := ec.CallWithPromptAndVariables(context.Background(), "Hi {{name}}", `{"name":"Al"}`)
+	require.NoError(t, err)
+	assert.Equal(t, answeredResponse, string(response))
+	assert.Len(t, stub.prompts, 1)
+	assert.False(t, called, "no report without a refusal")
+}
+
+func TestEscalationClient_AllRefused(t *testing.T) {
+	stub := &stubClient{responses: []string{refusedResponse, refusedResponse}}
+	var report EscalationReport
+	ec, err := NewEscalationClient(stub, EscalationOptions{
+		Steps: []EscalationStep{
+			{Name: "clarify", SystemContext: "context"},
+			{Name: "broken", Rephrase: func(p string) string { return p }},
+		},
+		OnReport: func(r EscalationReport) { report = r },
+	})
+	require.NoError(t, err)
+
+	_, err = ec.CallWithPrompt(context.Background(), "prompt")
+	assert.ErrorIs(t, err, ErrRefused)
+	assert.Contains(t, err.Error(), "no response", "the last step error is included")
+	assert.Empty(t, report.SucceededStep)
+	require.Len(t, report.Attempts, 3)
+	assert.Equal(t, "no response", report.Attempts[2].Error)
+}
+
+func TestEscalationClient_ContextEnded(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stub := &stubClient{responses: []string{refusedResponse, refusedResponse}}
+	var report EscalationReport
+	ec, err := NewEscalationClient(stub, EscalationOptions{
+		Steps: []EscalationStep{
+			{Name: "cancel", Rephrase: func(p string) string { cancel(); return p }},
+			{Name: "clarify", SystemContext: "context"},
+		},
+		OnReport: func(r EscalationReport) { report = r },
+	})
+	require.NoError(t, err)
+
+	_, err = ec.CallWithPrompt(ctx, "prompt")
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.NotErrorIs(t, err, ErrRefused)
+	assert.Len(t, stub.prompts, 2, "no step runs after the context ends")
+	assert.Len(t, report.Attempts, 2)
+
+	// A step that times out is still reported as a refusal, with the timeout reachable
+	ec, err = NewEscalationClient(&stubClient{responses: []string{refusedResponse}}, EscalationOptions{
+		Steps: []EscalationStep{{Name: "other", Client: &deadlineErrorClient{&stubClient{}}}},
+	})
+	require.NoError(t, err)
+	_, err = ec.CallWithPrompt(context.Background(), "prompt")
+	assert.ErrorIs(t, err, ErrRefused)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+// deadlineErrorClient fails every call with context.DeadlineExceeded.
+type deadlineErrorClient struct {
+	*stubClient
+}
+
+func (d *deadlineErrorClient) CallWithPrompt(ctx context.Context, prompt string, opts ...types.CallOption) ([]byte, error) {
+	return nil, context.DeadlineExceeded
+}
+
+func TestEscalationClient_OriginalErrorAndValidation(t *testing.T) {
+	ec, err := NewEscalationClient(&stubClient{}, EscalationOptions{})
+	require.NoError(t, err)
+	_, err = ec.CallWithPrompt(context.Background(), "prompt")
+	assert.EqualError(t, err, "no response")
+	assert.False(t, errors.Is(err, ErrRefused))
+
+	invalid := [][]EscalationStep{
+		{{SystemContext: "x"}},
+		{{Name: "original", SystemContext: "x"}},
+		{{Name: "a", SystemContext: "x"}, {Name: "a", SystemContext: "y"}},
+		{{Name: "noop"}},
+	}
+	for _, steps := range invalid {
+		_, err := NewEscalationClient(&stubClient{}, EscalationOptions{Steps: steps})
+		assert.Error(t, err)
+	}
+	_, err = NewEscalationClient(nil, EscalationOptions{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,33 @@
+package client
+
+import (
+	"context"
+	"io"
+
+	"github.com/kengibson1111/go-aiprovider/types"
+)
+
+// FileManager is implemented by clients that can upload and manage files. The OpenAI and
+// Azure OpenAI clients implement it.
+//
+// Example:
+//
+//	files, ok := aiClient.(client.FileManager)
+//	if !ok {
+//		return client.ErrUnsupportedOperation
+//	}
+//	file, err := files.UploadFile(ctx, "handbook.pdf", f, "")
+type FileManager interface {
+	UploadFile(ctx context.Context, filename string, content io.Reader, purpose string) (types.FileInfo, error)
+	ListFiles(ctx context.Context) ([]types.FileInfo, error)
+	DeleteFile(ctx context.Context, fileID string) error
+}
+
+// FileSearcher is implemented by clients that can index uploaded files in vector stores
+// and ground completions in them. The OpenAI and Azure OpenAI clients implement it.
+type FileSearcher interface {
+	CreateVectorStore(ctx context.Context, name string, fileIDs []string) (types.VectorStoreInfo, error)
+	DeleteVectorStore(ctx context.Context, vectorStoreID string) error
+	SearchVectorStore(ctx context.Context, vectorStoreID string, query string, maxResults int) ([]types.FileSearchResult, error)
+	CallWithFileSearch(ctx context.Context, prompt string, vectorStoreIDs []string, opts ...types.CallOption) ([]byte, error)
+}
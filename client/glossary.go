@@ -0,0 +1,496 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/kengibson1111/go-aiprovider/types"
+)
+
+// ErrUnrecognizedResponse is returned when a raw response is neither a Claude Messages
+// response nor an OpenAI chat completion.
+var ErrUnrecognizedResponse = errors.New("unrecognized response format")
+
+// GlossaryTerm is a required spelling, such as a brand or product name.
+type GlossaryTerm struct {
+	// Term is the required spelling, e.g. "GitHub".
+	Term string
+
+	// Variants are known wrong spellings, e.g. "Git Hub". They are matched exactly.
+	Variants []string
+
+	// IgnoreCase also matches case-only variations of Term and Variants, such as
+	// "github". Lowercase spellings are common in identifiers, so leave it off for terms
+	// that appear in code or URLs.
+	IgnoreCase bool
+}
+
+// GlossaryReplacement records one wrong spelling found in a response.
+type GlossaryReplacement struct {
+	Term  string `json:"term"`
+	Found string `json:"found"`
+	Count int    `json:"count"`
+}
+
+// GlossaryReport describes what glossary enforcement changed in a response.
+type GlossaryReport struct {
+	// Violations are the wrong spellings found in the original response.
+	Violations []GlossaryReplacement `json:"violations,omitempty"`
+
+	// Corrected is true when a correction request was sent to the provider.
+	Corrected bool `json:"corrected"`
+
+	// Replacements are the deterministic fixes applied to the returned response.
+	Replacements []GlossaryReplacement `json:"replacements,omitempty"`
+}
+
+// GlossaryOptions configures a GlossaryClient.
+type GlossaryOptions struct {
+	// ReAsk sends one correction request listing the violations to the provider before
+	// falling back to deterministic replacement. When false, violations are fixed by
+	// replacement only.
+	ReAsk bool
+
+	// OnReport, if set, receives the report for every response that had violations.
+	OnReport func(GlossaryReport)
+}
+
+// GlossaryClient wraps an AIClient and enforces required terminology on responses.
+// Prompt calls return the response with wrong spellings replaced by the glossary term;
+// ValidateCredentials is passed through unchanged.
+//
+// Replacement rewrites the response text in place, so character offsets reported by
+// ParseCitations refer to the corrected text only when the replacement has the same length.
+type GlossaryClient struct {
+	AIClient
+	terms []glossaryMatcher
+	opts  GlossaryOptions
+}
+
+// glossaryMatcher matches any spelling of a single glossary term.
+type glossaryMatcher struct {
+	term    string
+	pattern *regexp.Regexp
+}
+
+// NewGlossaryClient wraps aiClient with the given terms. Every term must be non-empty.
+//
+// Example:
+//
+//	gc, err := client.NewGlossaryClient(aiClient, []client.GlossaryTerm{
+//		{Term: "GitHub", Variants: []string{"Git Hub"}},
+//		{Term: "macOS", Variants: []string{"Mac OS", "OSX"}},
+//	}, client.GlossaryOptions{})
+func NewGlossaryClient(aiClient AIClient, terms []GlossaryTerm, opts GlossaryOptions) (*GlossaryClient, error) {
+	if aiClient == nil {
+		return nil, fmt.Errorf("AI client is required")
+	}
+
+	matchers := make([]glossaryMatcher, 0, len(terms))
+	for i, term := range terms {
+		if strings.TrimSpace(term.Term) == "" {
+			return nil, fmt.Errorf("glossary term %d must not be empty", i)
+		}
+		matchers = append(matchers, newGlossaryMatcher(term))
+	}
+
+	return &GlossaryClient{
+		AIClient: aiClient,
+		terms:    matchers,
+		opts:     opts,
+	}, nil
+}
+
+// newGlossaryMatcher builds a pattern for the term and its variants.
+func newGlossaryMatcher(term GlossaryTerm) glossaryMatcher {
+	spellings := append([]string{term.Term}, term.Variants...)
+	// Longer spellings first so "Mac OS X" wins over "Mac OS"
+	sort.SliceStable(spellings, func(i, j int) bool {
+		return len(spellings[i]) > len(spellings[j])
+	})
+
+	quoted := make([]string, 0, len(spellings))
+	for _, s := range spellings {
+		if s != "" {
+			quoted = append(quoted, regexp.QuoteMeta(s))
+		}
+	}
+
+	flags := ""
+	if term.IgnoreCase {
+		flags = `(?i)`
+	}
+	return glossaryMatcher{
+		term:    term.Term,
+		pattern: regexp.MustCompile(flags + strings.Join(quoted, "|")),
+	}
+}
+
+// replace substitutes whole-word matches in text with the term and counts the ones that
+// were spelled differently. A match inside a longer word (e.g. "github" in "githubusercontent")
+// or inside a URL, hostname, path, or email address (e.g. "github.com/org") is left alone.
+func (m glossaryMatcher) replace(text string, counts map[GlossaryReplacement]int) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range m.pattern.FindAllStringIndex(text, -1) {
+		start, end := loc[0], loc[1]
+		if !isWordBoundary(text, start) || !isWordBoundary(text, end) || inPath(text, start, end) {
+			continue
+		}
+		found := text[start:end]
+		if found != m.term {
+			counts[GlossaryReplacement{Term: m.term, Found: found}]++
+		}
+		b.WriteString(text[last:start])
+		b.WriteString(m.term)
+		last = end
+	}
+	if last == 0 {
+		return text
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// isWordBoundary reports whether position i in text does not split a word, i.e. the
+// characters on either side are not both letters or digits.
+func isWordBoundary(text string, i int) bool {
+	if i == 0 || i == len(text) {
+		return true
+	}
+	before, _ := utf8.DecodeLastRuneInString(text[:i])
+	after, _ := utf8.DecodeRuneInString(text[i:])
+	return !isWordRune(before) || !isWordRune(after)
+}
+
+// inPath reports whether text[start:end] is joined to the text around it by a URL or path
+// separator. A separator after the match only counts when more text follows it, so a term
+// at the end of a sentence ("on macOS.") still matches.
+func inPath(text string, start, end int) bool {
+	if before, _ := utf8.DecodeLastRuneInString(text[:start]); start > 0 && isPathSeparator(before) {
+		return true
+	}
+	after, size := utf8.DecodeRuneInString(text[end:])
+	if end == len(text) || !isPathSeparator(after) || end+size == len(text) {
+		return false
+	}
+	next, _ := utf8.DecodeRuneInString(text[end+size:])
+	return !unicode.IsSpace(next)
+}
+
+// isPathSeparator reports whether r joins the parts of a URL, hostname, path, or email address.
+func isPathSeparator(r rune) bool {
+	return r == '.' || r == '/' || r == ':' || r == '@'
+}
+
+// isWordRune reports whether r is part of a word.
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+// CallWithPrompt sends the prompt and enforces the glossary on the response.
+func (c *GlossaryClient) CallWithPrompt(ctx context.Context, prompt string, opts ...types.CallOption) ([]byte, error) {
+	response, err := c.AIClient.CallWithPrompt(ctx, prompt, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return c.enforce(ctx, response, opts)
+}
+
+// CallWithPromptAndVariables renders and sends the prompt and enforces the glossary on the response.
+func (c *GlossaryClient) CallWithPromptAndVariables(ctx context.Context, prompt string, variablesJSON string, opts ...types.CallOption) ([]byte, error) {
+	response, err := c.AIClient.CallWithPromptAndVariables(ctx, prompt, variablesJSON, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return c.enforce(ctx, response, opts)
+}
+
+// Enforce applies the glossary to a raw response obtained elsewhere, for example from a
+// client that was not wrapped. The report is returned even when nothing changed.
+func (c *GlossaryClient) Enforce(ctx context.Context, response []byte, opts ...types.CallOption) ([]byte, GlossaryReport, error) {
+	var report GlossaryReport
+
+	violations, err := c.findViolations(response)
+	if err != nil {
+		return nil, report, err
+	}
+	if len(violations) == 0 {
+		return response, report, nil
+	}
+	report.Violations = violations
+
+	if c.opts.ReAsk {
+		corrected, err := c.reAsk(ctx, response, violations, opts)
+		if err != nil {
+			return nil, report, err
+		}
+		response = corrected
+		report.Corrected = true
+	}
+
+	counts := make(map[GlossaryReplacement]int)
+	fixed, err := rewriteResponseText(response, func(text string) string {
+		return c.replace(text, counts)
+	})
+	if err != nil {
+		return nil, report, err
+	}
+	report.Replacements = sortedReplacements(counts)
+
+	return fixed, report, nil
+}
+
+// enforce runs Enforce and delivers the report to OnReport.
+func (c *GlossaryClient) enforce(ctx context.Context, response []byte, opts []types.CallOption) ([]byte, error) {
+	fixed, report, err := c.Enforce(ctx, response, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("glossary enforcement failed: %w", err)
+	}
+	if c.opts.OnReport != nil && len(report.Violations) > 0 {
+		c.opts.OnReport(report)
+	}
+	return fixed, nil
+}
+
+// findViolations lists the wrong spellings in the response text without changing it.
+func (c *GlossaryClient) findViolations(response []byte) ([]GlossaryReplacement, error) {
+	counts := make(map[GlossaryReplacement]int)
+	_, err := rewriteResponseText(response, func(text string) string {
+		c.replace(text, counts)
+		return text
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sortedReplacements(counts), nil
+}
+
+// replace substitutes every wrong spelling in text with its glossary term and counts
+// the substitutions by term and spelling found. Fenced code blocks and inline code spans
+// are left unchanged.
+func (c *GlossaryClient) replace(text string, counts map[GlossaryReplacement]int) string {
+	var b strings.Builder
+	for _, segment := range splitCode(text) {
+		if !segment.code {
+			for _, m := range c.terms {
+				segment.text = m.replace(segment.text, counts)
+			}
+		}
+		b.WriteString(segment.text)
+	}
+	return b.String()
+}
+
+// textSegment is a run of markdown text that is either all prose or all code.
+type textSegment struct {
+	text string
+	code bool
+}
+
+// splitCode splits markdown text into prose and code segments that join back to text.
+// Fenced code blocks, including one left open at the end, and inline code spans are code.
+func splitCode(text string) []textSegment {
+	var segments []textSegment
+	add := func(s string, code bool) {
+		if s == "" {
+			return
+		}
+		if n := len(segments); n > 0 && segments[n-1].code == code {
+			segments[n-1].text += s
+			return
+		}
+		segments = append(segments, textSegment{text: s, code: code})
+	}
+
+	var fence string
+	for _, line := range strings.SplitAfter(text, "\n") {
+		marker, info, ok := parseFence(strings.TrimRight(line, "\r\n"))
+		switch {
+		case fence == "" && ok:
+			fence = marker
+			add(line, true)
+		case fence != "":
+			if ok && info == "" && marker[0] == fence[0] && len(marker) >= len(fence) {
+				fence = ""
+			}
+			add(line, true)
+		default:
+			splitInlineCode(line, add)
+		}
+	}
+	return segments
+}
+
+// splitInlineCode passes the prose and inline code spans of one line to add. A span opens
+// with a run of backticks and closes at the next run of the same length; an unclosed run
+// is prose.
+func splitInlineCode(line string, add func(string, bool)) {
+	for {
+		start := strings.IndexByte(line, '`')
+		if start < 0 {
+			add(line, false)
+			return
+		}
+		n := start
+		for n < len(line) && line[n] == '`' {
+			n++
+		}
+		end := strings.Index(line[n:], line[start:n])
+		if end < 0 {
+			add(line[:n], false)
+			line = line[n:]
+			continue
+		}
+		end += n + (n - start)
+		add(line[:start], false)
+		add(line[start:end], true)
+		line = line[end:]
+	}
+}
+
+// reAsk asks the provider to rewrite the response text using the required terms.
+func (c *GlossaryClient) reAsk(ctx context.Context, response []byte, violations []GlossaryReplacement, opts []types.CallOption) ([]byte, error) {
+	var texts []string
+	if _, err := rewriteResponseText(response, func(text string) string {
+		texts = append(texts, text)
+		return text
+	}); err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	b.WriteString("Rewrite the text below, changing nothing except these spellings:\n")
+	for _, v := range violations {
+		fmt.Fprintf(&b, "- %q must be written as %q\n", v.Found, v.Term)
+	}
+	b.WriteString("Reply with the rewritten text only.\n\nText:\n")
+	b.WriteString(strings.Join(texts, ""))
+
+	corrected, err := c.AIClient.CallWithPrompt(ctx, b.String(), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("correction request failed: %w", err)
+	}
+	return corrected, nil
+}
+
+// sortedReplacements converts counts to a slice ordered by term, then spelling found.
+func sortedReplacements(counts map[GlossaryReplacement]int) []GlossaryReplacement {
+	if len(counts) == 0 {
+		return nil
+	}
+	result := make([]GlossaryReplacement, 0, len(counts))
+	for r, n := range counts {
+		r.Count = n
+		result = append(result, r)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Term != result[j].Term {
+			return result[i].Term < result[j].Term
+		}
+		return result[i].Found < result[j].Found
+	})
+	return result
+}
+
+// rewriteResponseText applies fn to every generated text in a raw response (each OpenAI
+// choice's message content, or each Claude text block) and returns the re-encoded
+// response. Fields other than the text are preserved.
+func rewriteResponseText(response []byte, fn func(string) string) ([]byte, error) {
+	var top map[string]json.RawMessage
+	if err := json.Unmarshal(response, &top); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	switch {
+	case top["choices"] != nil:
+		var choices []map[string]json.RawMessage
+		if err := json.Unmarshal(top["choices"], &choices); err != nil {
+			return nil, fmt.Errorf("failed to parse choices: %w", err)
+		}
+		for _, choice := range choices {
+			if choice["message"] == nil {
+				continue
+			}
+			var message map[string]json.RawMessage
+			if err := json.Unmarshal(choice["message"], &message); err != nil {
+				return nil, fmt.Errorf("failed to parse message: %w", err)
+			}
+			if err := rewriteTextField(message, "content", fn); err != nil {
+				return nil, err
+			}
+			if err := setRawField(choice, "message", message); err != nil {
+				return nil, err
+			}
+		}
+		if err := setRawField(top, "choices", choices); err != nil {
+			return nil, err
+		}
+
+	case top["content"] != nil:
+		var blocks []map[string]json.RawMessage
+		if err := json.Unmarshal(top["content"], &blocks); err != nil {
+			return nil, fmt.Errorf("failed to parse content: %w", err)
+		}
+		for _, block := range blocks {
+			var blockType string
+			if err := json.Unmarshal(block["type"], &blockType); err != nil || blockType != "text" {
+				continue
+			}
+			if err := rewriteTextField(block, "text", fn); err != nil {
+				return nil, err
+			}
+		}
+		if err := setRawField(top, "content", blocks); err != nil {
+			return nil, err
+		}
+
+	default:
+		return nil, ErrUnrecognizedResponse
+	}
+
+	return marshalRaw(top)
+}
+
+// rewriteTextField applies fn to the string stored under key. Missing and null fields are skipped.
+func rewriteTextField(obj map[string]json.RawMessage, key string, fn func(string) string) error {
+	raw, ok := obj[key]
+	if !ok || string(raw) == "null" {
+		return nil
+	}
+	var text string
+	if err := json.Unmarshal(raw, &text); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", key, err)
+	}
+	return setRawField(obj, key, fn(text))
+}
+
+// setRawField encodes value and stores it under key.
+func setRawField(obj map[string]json.RawMessage, key string, value any) error {
+	raw, err := marshalRaw(value)
+	if err != nil {
+		return err
+	}
+	obj[key] = raw
+	return nil
+}
+
+// marshalRaw encodes value as JSON without escaping HTML characters, so generated
+// code and markup round-trip unchanged.
+func marshalRaw(value any) (json.RawMessage, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(value); err != nil {
+		return nil, fmt.Errorf("failed to encode response: %w", err)
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
@@ -0,0 +1,175 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/kengibson1111/go-aiprovider/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubClient returns canned responses in order and records the prompts it was sent.
+type stubClient struct {
+	responses []string
+	prompts   []string
+}
+
+func (s *stubClient) CallWithPrompt(ctx context.Context, prompt string, opts ...types.CallOption) ([]byte, error) {
+	s.prompts = append(s.prompts, prompt)
+	if len(s.responses) == 0 {
+		return nil, errors.New("no response")
+	}
+	response := s.responses[0]
+	s.responses = s.responses[1:]
+	return []byte(response), nil
+}
+
+func (s *stubClient) CallWithPromptAndVariables(ctx context.Context, prompt string, variablesJSON string, opts ...types.CallOption) ([]byte, error) {
+	return s.CallWithPrompt(ctx, prompt, opts...)
+}
+
+func (s *stubClient) ValidateCredentials(ctx context.Context) error {
+	return nil
+}
+
+var testGlossary = []GlossaryTerm{
+	{Term: "GitHub", Variants: []string{"Git Hub"}, IgnoreCase: true},
+	{Term: "macOS", Variants: []string{"Mac OS X", "Mac OS", "OSX"}},
+}
+
+func TestGlossaryClient_Fix(t *testing.T) {
+	tests := []struct {
+		name         string
+		response     string
+		expected     string
+		replacements []GlossaryReplacement
+	}{
+		{
+			name:     "OpenAI choices are fixed",
+			response: `{"id":"c1","choices":[{"index":0,"message":{"role":"assistant","content":"Push to github or Git Hub on Mac OS X <now>."}}]}`,
+			expected: `{"choices":[{"index":0,"message":{"content":"Push to GitHub or GitHub on macOS <now>.","role":"assistant"}}],"id":"c1"}`,
+			replacements: []GlossaryReplacement{
+				{Term: "GitHub", Found: "Git Hub", Count: 1},
+				{Term: "GitHub", Found: "github", Count: 1},
+				{Term: "macOS", Found: "Mac OS X", Count: 1},
+			},
+		},
+		{
+			name:     "Claude text blocks are fixed and other blocks untouched",
+			response: `{"content":[{"type":"text","text":"Use OSX."},{"type":"tool_use","name":"osx"}],"role":"assistant"}`,
+			expected: `{"content":[{"text":"Use macOS.","type":"text"},{"name":"osx","type":"tool_use"}],"role":"assistant"}`,
+			replacements: []GlossaryReplacement{
+				{Term: "macOS", Found: "OSX", Count: 1},
+			},
+		},
+		{
+			name:     "Matches inside longer words are ignored",
+			response: `{"content":[{"type":"text","text":"See raw.githubusercontent.com and GitHub."}]}`,
+			expected: `{"content":[{"type":"text","text":"See raw.githubusercontent.com and GitHub."}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var reports []GlossaryReport
+			stub := &stubClient{responses: []string{tt.response}}
+			gc, err := NewGlossaryClient(stub, testGlossary, GlossaryOptions{
+				OnReport: func(r GlossaryReport) { reports = append(reports, r) },
+			})
+			require.NoError(t, err)
+
+			result, err := gc.CallWithPrompt(context.Background(), "prompt")
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expected, string(result))
+			assert.Len(t, stub.prompts, 1)
+
+			if tt.replacements == nil {
+				assert.Empty(t, reports)
+				return
+			}
+			require.Len(t, reports, 1)
+			assert.Equal(t, tt.replacements, reports[0].Violations)
+			assert.Equal(t, tt.replacements, reports[0].Replacements)
+			assert.False(t, reports[0].Corrected)
+		})
+	}
+}
+
+func TestGlossaryClient_LeavesCodeAndURLs(t *testing.T) {
+	text := "Clone https://github.com/org/repo from github, or mail help@github.com.\n\n" +
+		"```go\nimport \"github.com/org/repo\" // github\n```\n\n" +
+		"Run `go get github.com/org/repo` on Mac OS. See github: it is free."
+	expected := "Clone https://github.com/org/repo from GitHub, or mail help@github.com.\n\n" +
+		"```go\nimport \"github.com/org/repo\" // github\n```\n\n" +
+		"Run `go get github.com/org/repo` on macOS. See GitHub: it is free."
+
+	gc, err := NewGlossaryClient(&stubClient{}, testGlossary, GlossaryOptions{})
+	require.NoError(t, err)
+
+	response, err := json.Marshal(map[string]any{"content": []map[string]string{{"type": "text", "text": text}}})
+	require.NoError(t, err)
+	result, report, err := gc.Enforce(context.Background(), response)
+	require.NoError(t, err)
+
+	var parsed struct {
+		Content []struct {
+			Text string `json:"text"`
+		} `json:"content"`
+	}
+	require.NoError(t, json.Unmarshal(result, &parsed))
+	assert.Equal(t, expected, parsed.Content[0].Text)
+	assert.Equal(t, []GlossaryReplacement{
+		{Term: "GitHub", Found: "github", Count: 2},
+		{Term: "macOS", Found: "Mac OS", Count: 1},
+	}, report.Replacements)
+}
+
+func TestGlossaryClient_CaseSensitiveByDefault(t *testing.T) {
+	gc, err := NewGlossaryClient(&stubClient{}, []GlossaryTerm{{Term: "GitHub", Variants: []string{"Git Hub"}}}, GlossaryOptions{})
+	require.NoError(t, err)
+
+	result, report, err := gc.Enforce(context.Background(), []byte(`{"content":[{"type":"text","text":"Push to github or Git Hub."}]}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"content":[{"type":"text","text":"Push to github or GitHub."}]}`, string(result))
+	assert.Equal(t, []GlossaryReplacement{{Term: "GitHub", Found: "Git Hub", Count: 1}}, report.Replacements)
+}
+
+func TestGlossaryClient_ReAsk(t *testing.T) {
+	stub := &stubClient{responses: []string{
+		`{"content":[{"type":"text","text":"Install on macOS from Github."}]}`,
+	}}
+	gc, err := NewGlossaryClient(stub, testGlossary, GlossaryOptions{ReAsk: true})
+	require.NoError(t, err)
+
+	result, report, err := gc.Enforce(context.Background(), []byte(`{"content":[{"type":"text","text":"Install on Mac OS from github."}]}`))
+	require.NoError(t, err)
+
+	var parsed struct {
+		Content []struct {
+			Text string `json:"text"`
+		} `json:"content"`
+	}
+	require.NoError(t, json.Unmarshal(result, &parsed))
+	assert.Equal(t, "Install on macOS from GitHub.", parsed.Content[0].Text)
+
+	require.Len(t, stub.prompts, 1)
+	assert.Contains(t, stub.prompts[0], `"Mac OS" must be written as "macOS"`)
+	assert.Contains(t, stub.prompts[0], "Install on Mac OS from github.")
+
+	assert.True(t, report.Corrected)
+	assert.Len(t, report.Violations, 2)
+	assert.Equal(t, []GlossaryReplacement{{Term: "GitHub", Found: "Github", Count: 1}}, report.Replacements)
+}
+
+func TestGlossaryClient_Errors(t *testing.T) {
+	_, err := NewGlossaryClient(&stubClient{}, []GlossaryTerm{{Term: " "}}, GlossaryOptions{})
+	assert.Error(t, err)
+
+	gc, err := NewGlossaryClient(&stubClient{responses: []string{`{"id":"x"}`}}, testGlossary, GlossaryOptions{})
+	require.NoError(t, err)
+	_, err = gc.CallWithPrompt(context.Background(), "prompt")
+	assert.ErrorIs(t, err, ErrUnrecognizedResponse)
+}
@@ -0,0 +1,188 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/kengibson1111/go-aiprovider/types"
+)
+
+// ErrGuardrailBlocked is returned when a guardrail rule with the block action matches a
+// prompt or response.
+var ErrGuardrailBlocked = errors.New("blocked by guardrail")
+
+// GuardrailAction is what a GuardrailClient does when a rule matches.
+type GuardrailAction string
+
+// Guardrail actions for GuardrailRule.Action
+const (
+	// GuardrailBlock fails the call with ErrGuardrailBlocked.
+	GuardrailBlock GuardrailAction = "block"
+
+	// GuardrailWarn reports the violation and lets the call continue.
+	GuardrailWarn GuardrailAction = "warn"
+)
+
+// Guardrail stages reported in GuardrailViolation.Stage
+const (
+	GuardrailStagePrompt   = "prompt"
+	GuardrailStageResponse = "response"
+)
+
+// GuardrailRule flags text that matches Pattern or for which Check returns true.
+type GuardrailRule struct {
+	Name    string
+	Pattern *regexp.Regexp
+	Check   func(text string) bool
+	Action  GuardrailAction
+}
+
+// GuardrailViolation describes a rule that matched. The matched text is not included so
+// that reports never repeat a leaked secret.
+type GuardrailViolation struct {
+	Rule   string          `json:"rule"`
+	Stage  string          `json:"stage"`
+	Action GuardrailAction `json:"action"`
+}
+
+// GuardrailOptions configures a GuardrailClient.
+type GuardrailOptions struct {
+	// PromptRules are checked against the prompt, and the variables of template calls,
+	// before the call is sent (default: DefaultSecretRules).
+	PromptRules []GuardrailRule
+
+	// ResponseRules are checked against the response text.
+	ResponseRules []GuardrailRule
+
+	// OnViolation, if set, receives every violation, including blocked ones.
+	OnViolation func(GuardrailViolation)
+}
+
+// GuardrailClient wraps an AIClient and checks outgoing prompts and incoming responses
+// against guardrail rules. ValidateCredentials is passed through unchanged.
+type GuardrailClient struct {
+	AIClient
+	opts GuardrailOptions
+}
+
+// DefaultSecretRules returns rules that block prompts containing API keys, bearer tokens,
+// AWS access key IDs, or private keys.
+func DefaultSecretRules() []GuardrailRule {
+	return []GuardrailRule{
+		{Name: "anthropic-api-key", Pattern: regexp.MustCompile(`sk-ant-[A-Za-z0-9_-]{8,}`), Action: GuardrailBlock},
+		{Name: "openai-api-key", Pattern: regexp.MustCompile(`sk-(?:proj-)?[A-Za-z0-9_-]{16,}`), Action: GuardrailBlock},
+		{Name: "bearer-token", Pattern: regexp.MustCompile(`(?i)bearer\s+[A-Za-z0-9._~+/-]{16,}`), Action: GuardrailBlock},
+		{Name: "aws-access-key-id", Pattern: regexp.MustCompile(`(?:AKIA|ASIA)[0-9A-Z]{16}`), Action: GuardrailBlock},
+		{Name: "private-key", Pattern: privateKeyPattern, Action: GuardrailBlock},
+	}
+}
+
+// DefaultInjectionRules returns rules that warn about prompts with common prompt-injection
+// phrasing, such as instructions to ignore previous instructions.
+func DefaultInjectionRules() []GuardrailRule {
+	return []GuardrailRule{
+		{Name: "ignore-instructions", Pattern: regexp.MustCompile(`(?i)\b(?:ignore|disregard|forget)\s+(?:all\s+)?(?:the\s+)?(?:previous|prior|above|earlier)\s+(?:instructions|prompts|rules)`), Action: GuardrailWarn},
+		{Name: "reveal-system-prompt", Pattern: regexp.MustCompile(`(?i)\b(?:reveal|print|show|repeat)\s+(?:me\s+)?(?:your|the)\s+(?:system\s+prompt|hidden\s+instructions)`), Action: GuardrailWarn},
+	}
+}
+
+// NewGuardrailClient wraps aiClient with the given rules. Every rule needs a name, a
+// Pattern or Check, and a block or warn action.
+//
+// Example:
+//
+//	gc, err := client.NewGuardrailClient(aiClient, client.GuardrailOptions{
+//		PromptRules: append(client.DefaultSecretRules(), client.DefaultInjectionRules()...),
+//		ResponseRules: []client.GuardrailRule{
+//			{Name: "internal-hostname", Pattern: regexp.MustCompile(`\.corp\.example\.com`), Action: client.GuardrailBlock},
+//		},
+//		OnViolation: func(v client.GuardrailViolation) { log.Printf("guardrail: %+v", v) },
+//	})
+func NewGuardrailClient(aiClient AIClient, opts GuardrailOptions) (*GuardrailClient, error) {
+	if aiClient == nil {
+		return nil, fmt.Errorf("AI client is required")
+	}
+	if opts.PromptRules == nil {
+		opts.PromptRules = DefaultSecretRules()
+	}
+	for _, rules := range [][]GuardrailRule{opts.PromptRules, opts.ResponseRules} {
+		for i, rule := range rules {
+			if strings.TrimSpace(rule.Name) == "" {
+				return nil, fmt.Errorf("guardrail rule %d must have a name", i)
+			}
+			if rule.Pattern == nil && rule.Check == nil {
+				return nil, fmt.Errorf("guardrail rule %q needs a pattern or check", rule.Name)
+			}
+			if rule.Action != GuardrailBlock && rule.Action != GuardrailWarn {
+				return nil, fmt.Errorf("guardrail rule %q has unsupported action %q", rule.Name, rule.Action)
+			}
+		}
+	}
+
+	return &GuardrailClient{
+		AIClient: aiClient,
+		opts:     opts,
+	}, nil
+}
+
+// CallWithPrompt checks the prompt, sends it, and checks the response.
+func (c *GuardrailClient) CallWithPrompt(ctx context.Context, prompt string, opts ...types.CallOption) ([]byte, error) {
+	if err := c.check(GuardrailStagePrompt, c.opts.PromptRules, prompt); err != nil {
+		return nil, err
+	}
+	return c.checkResponse(c.AIClient.CallWithPrompt(ctx, prompt, opts...))
+}
+
+// CallWithPromptAndVariables checks the template and variables, sends the prompt, and
+// checks the response.
+func (c *GuardrailClient) CallWithPromptAndVariables(ctx context.Context, prompt string, variablesJSON string, opts ...types.CallOption) ([]byte, error) {
+	if err := c.check(GuardrailStagePrompt, c.opts.PromptRules, prompt, variablesJSON); err != nil {
+		return nil, err
+	}
+	return c.checkResponse(c.AIClient.CallWithPromptAndVariables(ctx, prompt, variablesJSON, opts...))
+}
+
+// checkResponse applies the response rules to a successful call.
+func (c *GuardrailClient) checkResponse(response []byte, err error) ([]byte, error) {
+	if err != nil || len(c.opts.ResponseRules) == 0 {
+		return response, err
+	}
+	if err := c.check(GuardrailStageResponse, c.opts.ResponseRules, extractResponseText(response)); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// check runs rules over texts, reporting every match and returning an error for the
+// first rule that blocks.
+func (c *GuardrailClient) check(stage string, rules []GuardrailRule, texts ...string) error {
+	var blocked error
+	for _, rule := range rules {
+		if !ruleMatches(rule, texts) {
+			continue
+		}
+		if c.opts.OnViolation != nil {
+			c.opts.OnViolation(GuardrailViolation{Rule: rule.Name, Stage: stage, Action: rule.Action})
+		}
+		if rule.Action == GuardrailBlock && blocked == nil {
+			blocked = fmt.Errorf("%w: %s matched rule %q", ErrGuardrailBlocked, stage, rule.Name)
+		}
+	}
+	return blocked
+}
+
+// ruleMatches reports whether the rule matches any of the texts.
+func ruleMatches(rule GuardrailRule, texts []string) bool {
+	for _, text := range texts {
+		if rule.Pattern != nil && rule.Pattern.MatchString(text) {
+			return true
+		}
+		if rule.Check != nil && rule.Check(text) {
+			return true
+		}
+	}
+	return false
+}
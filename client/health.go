@@ -0,0 +1,233 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/kengibson1111/go-aiprovider/types"
+)
+
+// Provider status pages (Atlassian Statuspage JSON API) for HealthOptions.StatusPageURL
+const (
+	AnthropicStatusURL = "https://status.anthropic.com/api/v2/status.json"
+	OpenAIStatusURL    = "https://status.openai.com/api/v2/status.json"
+)
+
+// HealthState classifies the result of a health check
+type HealthState string
+
+// Health states returned by HealthCheck
+const (
+	HealthOK             HealthState = "ok"
+	HealthAuthFailure    HealthState = "auth_failure"
+	HealthNetworkFailure HealthState = "network_failure"
+	HealthProviderOutage HealthState = "provider_outage"
+	HealthRateLimited    HealthState = "rate_limited"
+	HealthConfigError    HealthState = "config_error"
+	HealthUnknown        HealthState = "unknown"
+)
+
+// HealthStatus is the structured result of HealthCheck.
+type HealthStatus struct {
+	State     HealthState   `json:"state"`
+	Healthy   bool          `json:"healthy"`
+	Latency   time.Duration `json:"latency"`
+	CheckedAt time.Time     `json:"checkedAt"`
+
+	// Code and Message describe the failure, when there was one.
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+
+	// ProviderStatus is the status page indicator ("none", "minor", "major", "critical")
+	// when the status page was probed, with its description.
+	ProviderStatus            string `json:"providerStatus,omitempty"`
+	ProviderStatusDescription string `json:"providerStatusDescription,omitempty"`
+
+	// Err is the underlying error from the credential check, if any.
+	Err error `json:"-"`
+}
+
+// HealthOptions configures HealthCheck.
+type HealthOptions struct {
+	// StatusPageURL, if set, is probed in addition to the credential check, e.g.
+	// AnthropicStatusURL or OpenAIStatusURL. A failed probe does not change State
+	// unless the credential check also failed.
+	StatusPageURL string
+
+	// HTTPClient is used for the status page probe. Defaults to a client with a 5 second timeout.
+	HTTPClient *http.Client
+}
+
+// healthStateByCode maps the types.ErrorResponse codes returned by the clients to health states.
+var healthStateByCode = map[string]HealthState{
+	"invalid_api_key":          HealthAuthFailure,
+	"insufficient_permissions": HealthAuthFailure,
+	"insufficient_quota":       HealthAuthFailure,
+	"authentication_error":     HealthAuthFailure,
+	"permission_error":         HealthAuthFailure,
+
+	"network_error":   HealthNetworkFailure,
+	"request_timeout": HealthNetworkFailure,
+	"request_failed":  HealthNetworkFailure,
+
+	"server_error":        HealthProviderOutage,
+	"service_unavailable": HealthProviderOutage,
+	"overloaded_error":    HealthProviderOutage,
+	"api_error":           HealthProviderOutage,
+
+	"rate_limit_exceeded": HealthRateLimited,
+	"rate_limit_error":    HealthRateLimited,
+
+	"model_not_found":       HealthConfigError,
+	"model_error":           HealthConfigError,
+	"endpoint_not_found":    HealthConfigError,
+	"invalid_request":       HealthConfigError,
+	"invalid_request_error": HealthConfigError,
+	"not_found_error":       HealthConfigError,
+}
+
+// healthStateByMessage classifies errors without a specific code (for example AWS SDK
+// errors wrapped by the Bedrock client) by well-known fragments of the message.
+var healthStateByMessage = []struct {
+	fragment string
+	state    HealthState
+}{
+	{"unrecognizedclient", HealthAuthFailure},
+	{"expiredtoken", HealthAuthFailure},
+	{"accessdenied", HealthAuthFailure},
+	{"invalidsignature", HealthAuthFailure},
+	{"failed to retrieve credentials", HealthAuthFailure},
+	{"throttling", HealthRateLimited},
+	{"serviceunavailable", HealthProviderOutage},
+	{"internalserver", HealthProviderOutage},
+	{"modelnotready", HealthProviderOutage},
+	{"resourcenotfound", HealthConfigError},
+	{"validationexception", HealthConfigError},
+	{"no such host", HealthNetworkFailure},
+	{"connection refused", HealthNetworkFailure},
+	{"connection reset", HealthNetworkFailure},
+	{"network is unreachable", HealthNetworkFailure},
+	{"dial tcp", HealthNetworkFailure},
+	{"timeout", HealthNetworkFailure},
+	{"deadline exceeded", HealthNetworkFailure},
+}
+
+// HealthCheck checks that the provider behind aiClient is reachable and accepts its
+// credentials, and classifies any failure as an auth failure, network failure, provider
+// outage, rate limit, or configuration error. ValidateCredentials reports all of these as
+// a plain error.
+//
+// The check sends the same minimal request as ValidateCredentials. When
+// opts.StatusPageURL is set, the provider's status page is probed as well, and a failed
+// check is reported as a provider outage if the status page reports one.
+//
+// Example:
+//
+//	status := client.HealthCheck(ctx, aiClient, client.HealthOptions{StatusPageURL: client.OpenAIStatusURL})
+//	if !status.Healthy {
+//		log.Printf("OpenAI unhealthy: %s (%s)", status.State, status.Message)
+//	}
+func HealthCheck(ctx context.Context, aiClient AIClient, opts HealthOptions) HealthStatus {
+	start := time.Now()
+	err := aiClient.ValidateCredentials(ctx)
+	status := HealthStatus{
+		State:     HealthOK,
+		Healthy:   err == nil,
+		Latency:   time.Since(start),
+		CheckedAt: start,
+		Err:       err,
+	}
+
+	if err != nil {
+		status.State, status.Code = classifyHealthError(err)
+		status.Message = err.Error()
+	}
+
+	if opts.StatusPageURL != "" {
+		indicator, description, probeErr := probeStatusPage(ctx, opts)
+		if probeErr != nil {
+			status.ProviderStatusDescription = fmt.Sprintf("status page unavailable: %v", probeErr)
+		} else {
+			status.ProviderStatus = indicator
+			status.ProviderStatusDescription = description
+			if !status.Healthy && (indicator == "major" || indicator == "critical") {
+				status.State = HealthProviderOutage
+			}
+		}
+	}
+
+	return status
+}
+
+// classifyHealthError maps a credential check error to a health state and error code.
+func classifyHealthError(err error) (HealthState, string) {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return HealthNetworkFailure, "request_timeout"
+	}
+
+	code := ""
+	var errResp *types.ErrorResponse
+	if errors.As(err, &errResp) {
+		code = errResp.Code
+		if state, ok := healthStateByCode[code]; ok {
+			// request_failed is also the fallback code, so confirm from the message when possible
+			if code != "request_failed" {
+				return state, code
+			}
+		}
+	}
+
+	message := strings.ToLower(err.Error())
+	for _, m := range healthStateByMessage {
+		if strings.Contains(message, m.fragment) {
+			return m.state, code
+		}
+	}
+
+	if state, ok := healthStateByCode[code]; ok {
+		return state, code
+	}
+	return HealthUnknown, code
+}
+
+// statusPageResponse is the subset of the Statuspage status.json response used here
+type statusPageResponse struct {
+	Status struct {
+		Indicator   string `json:"indicator"`
+		Description string `json:"description"`
+	} `json:"status"`
+}
+
+// probeStatusPage fetches the status page and returns its indicator and description.
+func probeStatusPage(ctx context.Context, opts HealthOptions) (string, string, error) {
+	httpClient := opts.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 5 * time.Second}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, opts.StatusPageURL, nil)
+	if err != nil {
+		return "", "", err
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", "", fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+
+	var page statusPageResponse
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return "", "", fmt.Errorf("failed to parse status page: %w", err)
+	}
+	return page.Status.Indicator, page.Status.Description, nil
+}
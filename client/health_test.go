@@ -0,0 +1,72 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kengibson1111/go-aiprovider/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// credentialClient is a stubClient whose ValidateCredentials returns err.
+type credentialClient struct {
+	stubClient
+	err error
+}
+
+func (c *credentialClient) ValidateCredentials(ctx context.Context) error {
+	return c.err
+}
+
+func TestHealthCheck_Classification(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected HealthState
+	}{
+		{name: "Healthy", err: nil, expected: HealthOK},
+		{name: "Invalid key", err: &types.ErrorResponse{Code: "invalid_api_key", Message: "invalid API key"}, expected: HealthAuthFailure},
+		{name: "Claude authentication error", err: &types.ErrorResponse{Code: "authentication_error", Message: "invalid x-api-key"}, expected: HealthAuthFailure},
+		{name: "Network failure", err: &types.ErrorResponse{Code: "request_failed", Message: "credential validation failed: dial tcp: lookup api.anthropic.com: no such host"}, expected: HealthNetworkFailure},
+		{name: "Overloaded", err: &types.ErrorResponse{Code: "overloaded_error", Message: "Overloaded"}, expected: HealthProviderOutage},
+		{name: "Rate limited", err: &types.ErrorResponse{Code: "rate_limit_exceeded", Message: "too many requests"}, expected: HealthRateLimited},
+		{name: "Unknown model", err: &types.ErrorResponse{Code: "model_not_found", Message: "no such model"}, expected: HealthConfigError},
+		{name: "Bedrock expired token", err: &types.ErrorResponse{Code: "credential_validation_failed", Message: "api error ExpiredTokenException: token expired"}, expected: HealthAuthFailure},
+		{name: "Deadline", err: fmt.Errorf("validation: %w", context.DeadlineExceeded), expected: HealthNetworkFailure},
+		{name: "Unclassified", err: errors.New("something odd"), expected: HealthUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := HealthCheck(context.Background(), &credentialClient{err: tt.err}, HealthOptions{})
+			assert.Equal(t, tt.expected, status.State)
+			assert.Equal(t, tt.err == nil, status.Healthy)
+			assert.False(t, status.CheckedAt.IsZero())
+		})
+	}
+}
+
+func TestHealthCheck_StatusPage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"status": {"indicator": "major", "description": "Partial System Outage"}}`)
+	}))
+	defer server.Close()
+
+	opts := HealthOptions{StatusPageURL: server.URL}
+
+	status := HealthCheck(context.Background(), &credentialClient{}, opts)
+	assert.Equal(t, HealthOK, status.State, "a healthy check stays healthy during an incident")
+	assert.Equal(t, "major", status.ProviderStatus)
+	assert.Equal(t, "Partial System Outage", status.ProviderStatusDescription)
+
+	status = HealthCheck(context.Background(), &credentialClient{err: errors.New("EOF")}, opts)
+	assert.Equal(t, HealthProviderOutage, status.State)
+
+	status = HealthCheck(context.Background(), &credentialClient{}, HealthOptions{StatusPageURL: "http://127.0.0.1:1/status.json"})
+	assert.Equal(t, HealthOK, status.State)
+	assert.Contains(t, status.ProviderStatusDescription, "status page unavailable")
+}
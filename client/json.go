@@ -0,0 +1,164 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/kengibson1111/go-aiprovider/types"
+)
+
+// JSONOptions configures CompleteJSON.
+type JSONOptions struct {
+	// Repair runs RepairJSON on a response that is not valid JSON before failing.
+	Repair bool
+
+	// CallOptions are passed to the call.
+	CallOptions []types.CallOption
+}
+
+// CompleteJSON sends a prompt with CallWithPrompt and decodes the generated text into v,
+// returning the token usage even when decoding fails. Ask for JSON in the prompt; with
+// Repair, almost-valid output (fenced, wrapped in prose, with trailing commas, or cut
+// off) is repaired before decoding.
+//
+// Example:
+//
+//	var invoice struct {
+//		Number string  `json:"number"`
+//		Total  float64 `json:"total"`
+//	}
+//	_, err := client.CompleteJSON(ctx, aiClient, "Extract the invoice number and total as JSON: "+text,
+//		&invoice, client.JSONOptions{Repair: true})
+func CompleteJSON(ctx context.Context, aiClient AIClient, prompt string, v any, opts JSONOptions) (types.TokenUsage, error) {
+	text, usage, err := CompleteText(ctx, aiClient, prompt, opts.CallOptions...)
+	if err != nil {
+		return usage, err
+	}
+
+	err = json.Unmarshal([]byte(strings.TrimSpace(text)), v)
+	if err != nil && opts.Repair {
+		repaired, repairErr := RepairJSON(text)
+		if repairErr != nil {
+			return usage, fmt.Errorf("response is not valid JSON: %w", errors.Join(err, repairErr))
+		}
+		err = json.Unmarshal([]byte(repaired), v)
+	}
+	if err != nil {
+		return usage, fmt.Errorf("response is not valid JSON: %w", err)
+	}
+	return usage, nil
+}
+
+// RepairJSON turns almost-valid JSON from model output into valid JSON. It takes the
+// first fenced code block if there is one, extracts the first object or array from any
+// surrounding prose, removes trailing commas, and closes strings, objects, and arrays left
+// open by a truncated response. Valid JSON is returned unchanged. It fails when no JSON
+// can be recovered.
+//
+// Example:
+//
+//	fixed, err := client.RepairJSON("Here you go:\n```json\n{\"items\": [1, 2,],\n```")
+//	// fixed == `{"items": [1, 2]}`
+func RepairJSON(text string) (string, error) {
+	text = strings.TrimSpace(text)
+	if json.Valid([]byte(text)) {
+		return text, nil
+	}
+
+	for _, block := range ExtractCodeBlocks(text) {
+		if block.Language == "json" || block.Language == "" {
+			text = block.Code
+			break
+		}
+	}
+
+	start := strings.IndexAny(text, "{[")
+	if start < 0 {
+		return "", errors.New("no JSON object or array found")
+	}
+	repaired := balanceJSON(text[start:])
+	if !json.Valid([]byte(repaired)) {
+		var v any
+		err := json.Unmarshal([]byte(repaired), &v)
+		return "", fmt.Errorf("cannot repair JSON: %w", err)
+	}
+	return repaired, nil
+}
+
+// balanceJSON copies the first JSON value from text, which starts with '{' or '[', dropping
+// trailing commas and closing whatever a truncated value left open.
+func balanceJSON(text string) string {
+	var out []byte
+	var stack []byte
+	inString, escaped := false, false
+
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		if inString {
+			out = append(out, c)
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch c {
+		case '"':
+			inString = true
+		case '{':
+			stack = append(stack, '}')
+		case '[':
+			stack = append(stack, ']')
+		case '}', ']':
+			depth := strings.LastIndexByte(string(stack), c)
+			if depth < 0 {
+				continue // Stray closer
+			}
+			out = trimTrailingComma(out)
+			for len(stack) > depth+1 {
+				out = append(out, stack[len(stack)-1])
+				stack = stack[:len(stack)-1]
+			}
+			stack = stack[:depth]
+			out = append(out, c)
+			if len(stack) == 0 {
+				return string(out)
+			}
+			continue
+		}
+		out = append(out, c)
+	}
+
+	// Truncated: finish the open string and value, then close the containers
+	if inString {
+		if escaped {
+			out = out[:len(out)-1]
+		}
+		out = append(out, '"')
+	}
+	out = trimTrailingComma(out)
+	if trimmed := strings.TrimRight(string(out), " \t\r\n"); strings.HasSuffix(trimmed, ":") {
+		out = append([]byte(trimmed), "null"...)
+	}
+	for i := len(stack) - 1; i >= 0; i-- {
+		out = append(out, stack[i])
+	}
+	return string(out)
+}
+
+// trimTrailingComma removes a comma, and any whitespace after it, from the end of out.
+func trimTrailingComma(out []byte) []byte {
+	trimmed := strings.TrimRight(string(out), " \t\r\n")
+	if strings.HasSuffix(trimmed, ",") {
+		return []byte(trimmed[:len(trimmed)-1])
+	}
+	return out
+}
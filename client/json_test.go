@@ -0,0 +1,69 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepairJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"valid JSON is unchanged", `{"a": [1, 2]}`, `{"a": [1, 2]}`},
+		{"code fence", "```json\n{\"a\": 1}\n```", `{"a": 1}`},
+		{"surrounding prose", `Sure! Here is the result: {"a": "b"} Let me know if you need more.`, `{"a": "b"}`},
+		{"trailing commas", `{"a": [1, 2,], "b": {"c": 3,},}`, `{"a": [1, 2], "b": {"c": 3}}`},
+		{"truncated array", `[{"a": 1}, {"b": 2`, `[{"a": 1}, {"b": 2}]`},
+		{"truncated string", `{"text": "cut off here`, `{"text": "cut off here"}`},
+		{"truncated after key", `{"a": 1, "b":`, `{"a": 1, "b":null}`},
+		{"truncated after comma", `{"a": [1, 2, `, `{"a": [1, 2]}`},
+		{"truncated escape", `{"a": "x\`, `{"a": "x"}`},
+		{"braces in strings", `{"a": "} ] ,}"`, `{"a": "} ] ,}"}`},
+		{"missing inner closer", `{"a": [1, 2}`, `{"a": [1, 2]}`},
+		{"first of several values", `{"a": 1} {"b": 2}`, `{"a": 1}`},
+		{"fenced and truncated", "Result:\n```\n{\"items\": [\"x\", \"y\",\n```", `{"items": ["x", "y"]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repaired, err := RepairJSON(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, repaired)
+		})
+	}
+
+	_, err := RepairJSON("no JSON here")
+	assert.EqualError(t, err, "no JSON object or array found")
+
+	_, err = RepairJSON(`{"a" 1}`)
+	assert.ErrorContains(t, err, "cannot repair JSON")
+}
+
+func TestCompleteJSON(t *testing.T) {
+	const fenced = `{"choices":[{"index":0,"message":{"role":"assistant","content":"Here it is:\n` + "```json" + `\n{\"number\": \"INV-7\", \"total\": 12.5,}\n` + "```" + `"}}],"usage":{"prompt_tokens":20,"completion_tokens":8,"total_tokens":28}}`
+
+	type invoice struct {
+		Number string  `json:"number"`
+		Total  float64 `json:"total"`
+	}
+
+	var got invoice
+	usage, err := CompleteJSON(context.Background(), &stubClient{responses: []string{fenced}}, "extract", &got, JSONOptions{Repair: true})
+	require.NoError(t, err)
+	assert.Equal(t, invoice{Number: "INV-7", Total: 12.5}, got)
+	assert.Equal(t, 28, usage.TotalTokens)
+
+	// Without Repair the same output fails, but usage is still reported
+	usage, err = CompleteJSON(context.Background(), &stubClient{responses: []string{fenced}}, "extract", &got, JSONOptions{})
+	assert.ErrorContains(t, err, "response is not valid JSON")
+	assert.Equal(t, 28, usage.TotalTokens)
+
+	const prose = `{"choices":[{"index":0,"message":{"role":"assistant","content":"I cannot help with that."}}]}`
+	_, err = CompleteJSON(context.Background(), &stubClient{responses: []string{prose}}, "extract", &got, JSONOptions{Repair: true})
+	assert.ErrorContains(t, err, "no JSON object or array found")
+}
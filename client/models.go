@@ -0,0 +1,55 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/kengibson1111/go-aiprovider/types"
+)
+
+// ErrUnsupportedOperation is returned when a client does not implement an optional capability
+var ErrUnsupportedOperation = errors.New("operation not supported by this client")
+
+// ModelLister is implemented by clients that can list the models available to them.
+// The Claude, OpenAI, and Azure OpenAI clients implement it; the Bedrock client does not,
+// since model listing is part of the Bedrock control-plane API.
+type ModelLister interface {
+	ListModels(ctx context.Context) ([]types.ModelInfo, error)
+}
+
+// ListModels returns the models available to aiClient from the provider's models endpoint,
+// each annotated with capabilities from types.LookupModelCapabilities. It returns
+// ErrUnsupportedOperation when the client cannot list models.
+//
+// Example:
+//
+//	models, err := client.ListModels(ctx, aiClient)
+//	for _, m := range models {
+//		if m.Capabilities.SupportsVision {
+//			fmt.Println(m.ID)
+//		}
+//	}
+func ListModels(ctx context.Context, aiClient AIClient) ([]types.ModelInfo, error) {
+	lister, ok := aiClient.(ModelLister)
+	if !ok {
+		return nil, fmt.Errorf("listing models: %w", ErrUnsupportedOperation)
+	}
+	return lister.ListModels(ctx)
+}
+
+// ValidateModel checks that model is offered by the provider, so a misconfigured model
+// name is caught at startup rather than on the first request. It returns
+// ErrUnsupportedOperation when the client cannot list models.
+func ValidateModel(ctx context.Context, aiClient AIClient, model string) (types.ModelInfo, error) {
+	models, err := ListModels(ctx, aiClient)
+	if err != nil {
+		return types.ModelInfo{}, err
+	}
+	for _, m := range models {
+		if m.ID == model {
+			return m, nil
+		}
+	}
+	return types.ModelInfo{}, fmt.Errorf("model %q is not available from this provider (%d models listed)", model, len(models))
+}
@@ -0,0 +1,37 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kengibson1111/go-aiprovider/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// listingClient is a stubClient that also lists models.
+type listingClient struct {
+	stubClient
+	models []types.ModelInfo
+}
+
+func (c *listingClient) ListModels(ctx context.Context) ([]types.ModelInfo, error) {
+	return c.models, nil
+}
+
+func TestListModels(t *testing.T) {
+	_, err := ListModels(context.Background(), &stubClient{})
+	assert.ErrorIs(t, err, ErrUnsupportedOperation)
+
+	lc := &listingClient{models: []types.ModelInfo{{ID: "gpt-4o"}, {ID: "gpt-4o-mini"}}}
+	models, err := ListModels(context.Background(), lc)
+	require.NoError(t, err)
+	assert.Len(t, models, 2)
+
+	model, err := ValidateModel(context.Background(), lc, "gpt-4o-mini")
+	require.NoError(t, err)
+	assert.Equal(t, "gpt-4o-mini", model.ID)
+
+	_, err = ValidateModel(context.Background(), lc, "gpt-5")
+	assert.ErrorContains(t, err, `model "gpt-5" is not available`)
+}
@@ -0,0 +1,52 @@
+package client
+
+import (
+	"expvar"
+	"fmt"
+
+	"github.com/kengibson1111/go-aiprovider/types"
+)
+
+// ConnPoolStatsReporter is implemented by clients that record HTTP connection pool
+// statistics. The Claude, OpenAI, and Azure OpenAI clients implement it; the Bedrock
+// client, whose connections are managed by the AWS SDK, does not.
+type ConnPoolStatsReporter interface {
+	ConnPoolStats() types.ConnPoolStats
+}
+
+// ConnPoolStats returns aiClient's connection pool statistics, or
+// ErrUnsupportedOperation when the client does not record them.
+//
+// Example:
+//
+//	stats, err := client.ConnPoolStats(aiClient)
+//	log.Printf("in flight %d, reuse rate %.0f%%", stats.InFlight, stats.ReuseRate()*100)
+func ConnPoolStats(aiClient AIClient) (types.ConnPoolStats, error) {
+	reporter, ok := aiClient.(ConnPoolStatsReporter)
+	if !ok {
+		return types.ConnPoolStats{}, fmt.Errorf("connection pool stats: %w", ErrUnsupportedOperation)
+	}
+	return reporter.ConnPoolStats(), nil
+}
+
+// PublishConnPoolStats publishes aiClient's connection pool statistics as the expvar
+// variable name, served as JSON at /debug/vars, with the reuse rate included. Publish
+// one name per client, such as "aiprovider.openai.pool".
+func PublishConnPoolStats(name string, aiClient AIClient) error {
+	reporter, ok := aiClient.(ConnPoolStatsReporter)
+	if !ok {
+		return fmt.Errorf("connection pool stats: %w", ErrUnsupportedOperation)
+	}
+	if expvar.Get(name) != nil {
+		return fmt.Errorf("expvar %q is already published", name)
+	}
+
+	expvar.Publish(name, expvar.Func(func() any {
+		stats := reporter.ConnPoolStats()
+		return struct {
+			types.ConnPoolStats
+			ReuseRate float64 `json:"reuseRate"`
+		}{stats, stats.ReuseRate()}
+	}))
+	return nil
+}
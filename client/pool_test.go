@@ -0,0 +1,51 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"testing"
+
+	"github.com/kengibson1111/go-aiprovider/aitest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnPoolStats(t *testing.T) {
+	srv := aitest.NewFakeClaudeServer(t)
+	aiClient, err := NewClientFactory().CreateClient(srv.Config())
+	require.NoError(t, err)
+
+	for range 3 {
+		_, err := aiClient.CallWithPrompt(context.Background(), "hello")
+		require.NoError(t, err)
+	}
+
+	stats, err := ConnPoolStats(aiClient)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), stats.Requests)
+	assert.Zero(t, stats.InFlight)
+	assert.Equal(t, int64(1), stats.NewConns)
+	assert.Equal(t, int64(2), stats.ReusedConns)
+	assert.Equal(t, int64(1), stats.IdleConns)
+	assert.InDelta(t, 2.0/3.0, stats.ReuseRate(), 1e-9)
+
+	_, err = ConnPoolStats(&stubClient{})
+	assert.ErrorIs(t, err, ErrUnsupportedOperation)
+}
+
+func TestPublishConnPoolStats(t *testing.T) {
+	srv := aitest.NewFakeOpenAIServer(t)
+	aiClient, err := NewClientFactory().CreateClient(srv.Config())
+	require.NoError(t, err)
+	_, err = aiClient.CallWithPrompt(context.Background(), "hello")
+	require.NoError(t, err)
+
+	require.NoError(t, PublishConnPoolStats("test.openai.pool", aiClient))
+	assert.Error(t, PublishConnPoolStats("test.openai.pool", aiClient))
+
+	var published map[string]any
+	require.NoError(t, json.Unmarshal([]byte(expvar.Get("test.openai.pool").String()), &published))
+	assert.Equal(t, float64(1), published["requests"])
+	assert.Contains(t, published, "reuseRate")
+}
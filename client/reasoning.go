@@ -0,0 +1,52 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/kengibson1111/go-aiprovider/types"
+)
+
+// ParseReasoning extracts the model's reasoning from a raw response returned by a call
+// with reasoning enabled (AIConfig.Reasoning or types.WithReasoning), for any provider.
+// Claude thinking blocks are joined into Text, and OpenAI reasoning token counts are
+// reported in Tokens. A response without reasoning returns a zero Reasoning.
+//
+// Example:
+//
+//	response, err := aiClient.CallWithPrompt(ctx, prompt, types.WithReasoning(4096))
+//	if err != nil {
+//		return err
+//	}
+//	reasoning, err := client.ParseReasoning(response)
+//	log.Printf("thought for %d tokens: %s", reasoning.Tokens, reasoning.Text)
+func ParseReasoning(response []byte) (types.Reasoning, error) {
+	var parsed struct {
+		Content []struct {
+			Type     string `json:"type"`
+			Thinking string `json:"thinking"`
+		} `json:"content"`
+		Usage struct {
+			CompletionTokensDetails struct {
+				ReasoningTokens int `json:"reasoning_tokens"`
+			} `json:"completion_tokens_details"`
+		} `json:"usage"`
+	}
+	if err := json.Unmarshal(response, &parsed); err != nil {
+		return types.Reasoning{}, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	reasoning := types.Reasoning{Tokens: parsed.Usage.CompletionTokensDetails.ReasoningTokens}
+	var thoughts []string
+	for _, block := range parsed.Content {
+		switch block.Type {
+		case "thinking":
+			thoughts = append(thoughts, block.Thinking)
+		case "redacted_thinking":
+			reasoning.Redacted = true
+		}
+	}
+	reasoning.Text = strings.Join(thoughts, "\n\n")
+	return reasoning, nil
+}
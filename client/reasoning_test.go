@@ -0,0 +1,88 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/kengibson1111/go-aiprovider/aitest"
+	"github.com/kengibson1111/go-aiprovider/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseReasoning(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		expected types.Reasoning
+	}{
+		{
+			name: "Claude thinking blocks",
+			response: `{"content":[
+				{"type":"thinking","thinking":"First, factor the number.","signature":"sig1"},
+				{"type":"redacted_thinking","data":"encrypted"},
+				{"type":"thinking","thinking":"Then check primes.","signature":"sig2"},
+				{"type":"text","text":"It is prime."}
+			],"stop_reason":"end_turn"}`,
+			expected: types.Reasoning{Text: "First, factor the number.\n\nThen check primes.", Redacted: true},
+		},
+		{
+			name:     "OpenAI reasoning tokens",
+			response: `{"choices":[{"message":{"content":"42"}}],"usage":{"completion_tokens":300,"completion_tokens_details":{"reasoning_tokens":256}}}`,
+			expected: types.Reasoning{Tokens: 256},
+		},
+		{
+			name:     "no reasoning",
+			response: `{"content":[{"type":"text","text":"Hello"}]}`,
+			expected: types.Reasoning{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reasoning, err := ParseReasoning([]byte(tt.response))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, reasoning)
+		})
+	}
+
+	_, err := ParseReasoning([]byte("not json"))
+	assert.Error(t, err)
+}
+
+func TestReasoningRequests(t *testing.T) {
+	claude := aitest.NewFakeClaudeServer(t)
+	config := claude.Config()
+	config.MaxTokens = 500
+	config.TopP = 0.5
+	config.Reasoning = true
+	claudeClient, err := NewClientFactory().CreateClient(config)
+	require.NoError(t, err)
+
+	_, err = claudeClient.CallWithPrompt(context.Background(), "think", types.WithReasoning(2048))
+	require.NoError(t, err)
+
+	var claudeBody map[string]any
+	require.NoError(t, json.Unmarshal(claude.Requests()[0].Body, &claudeBody))
+	assert.Equal(t, map[string]any{"type": "enabled", "budget_tokens": float64(2048)}, claudeBody["thinking"])
+	assert.Equal(t, float64(2548), claudeBody["max_tokens"])
+	assert.Equal(t, float64(1), claudeBody["temperature"])
+	assert.NotContains(t, claudeBody, "top_p")
+
+	openAI := aitest.NewFakeOpenAIServer(t)
+	config = openAI.Config()
+	config.MaxTokens = 500
+	config.Reasoning = true
+	openAIClient, err := NewClientFactory().CreateClient(config)
+	require.NoError(t, err)
+
+	_, err = openAIClient.CallWithPrompt(context.Background(), "think")
+	require.NoError(t, err)
+
+	var openAIBody map[string]any
+	require.NoError(t, json.Unmarshal(openAI.Requests()[0].Body, &openAIBody))
+	assert.Equal(t, "low", openAIBody["reasoning_effort"])
+	assert.Equal(t, float64(500+types.DefaultReasoningBudget), openAIBody["max_completion_tokens"])
+	assert.NotContains(t, openAIBody, "temperature")
+}
@@ -0,0 +1,166 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/kengibson1111/go-aiprovider/types"
+)
+
+// claudeFinishReasons maps Claude stop reasons to finish reasons.
+var claudeFinishReasons = map[string]string{
+	"end_turn":      types.FinishStop,
+	"stop_sequence": types.FinishStop,
+	"max_tokens":    types.FinishLength,
+	"tool_use":      types.FinishToolCalls,
+	"refusal":       types.FinishRefusal,
+}
+
+// openAITokenLogprob is a token entry of an OpenAI logprobs object.
+type openAITokenLogprob struct {
+	Token       string  `json:"token"`
+	Logprob     float64 `json:"logprob"`
+	TopLogprobs []struct {
+		Token   string  `json:"token"`
+		Logprob float64 `json:"logprob"`
+	} `json:"top_logprobs"`
+}
+
+// convert returns the entry as a types.TokenLogprob.
+func (t openAITokenLogprob) convert() types.TokenLogprob {
+	converted := types.TokenLogprob{Token: t.Token, Logprob: t.Logprob}
+	for _, alt := range t.TopLogprobs {
+		converted.TopLogprobs = append(converted.TopLogprobs, types.TokenLogprob{Token: alt.Token, Logprob: alt.Logprob})
+	}
+	return converted
+}
+
+// Complete sends a prompt with CallWithPrompt and returns the response in a
+// provider-neutral envelope, so callers don't need to know each provider's JSON format.
+// It works with every client, including wrapper clients.
+//
+// Example:
+//
+//	response, err := client.Complete(ctx, aiClient, "Summarize this changelog: ...")
+//	if err != nil {
+//		return err
+//	}
+//	if response.FinishReason == types.FinishLength {
+//		log.Printf("summary was truncated after %d tokens", response.Usage.CompletionTokens)
+//	}
+//	fmt.Println(response.Content)
+func Complete(ctx context.Context, aiClient AIClient, prompt string, opts ...types.CallOption) (types.AIResponse, error) {
+	raw, err := aiClient.CallWithPrompt(ctx, prompt, opts...)
+	if err != nil {
+		return types.AIResponse{}, err
+	}
+	return ParseResponse(raw)
+}
+
+// CompleteText sends a prompt with CallWithPrompt and returns only the generated text and
+// token usage, for the common case of needing neither the finish reason nor the raw
+// response.
+//
+// Example:
+//
+//	text, usage, err := client.CompleteText(ctx, aiClient, "Write a haiku about Go.")
+//	if err != nil {
+//		return err
+//	}
+//	log.Printf("%d tokens", usage.TotalTokens)
+func CompleteText(ctx context.Context, aiClient AIClient, prompt string, opts ...types.CallOption) (string, types.TokenUsage, error) {
+	response, err := Complete(ctx, aiClient, prompt, opts...)
+	if err != nil {
+		return "", types.TokenUsage{}, err
+	}
+	return response.Content, response.Usage, nil
+}
+
+// ParseResponse converts a raw OpenAI or Claude response, as returned by CallWithPrompt and
+// the other call methods, to a types.AIResponse.
+func ParseResponse(response []byte) (types.AIResponse, error) {
+	var parsed struct {
+		Model   string `json:"model"`
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+				Refusal string `json:"refusal"`
+			} `json:"message"`
+			FinishReason string `json:"finish_reason"`
+			Logprobs     *struct {
+				Content []openAITokenLogprob `json:"content"`
+			} `json:"logprobs"`
+		} `json:"choices"`
+		Content []struct {
+			Type     string `json:"type"`
+			Text     string `json:"text"`
+			Thinking string `json:"thinking"`
+		} `json:"content"`
+		StopReason string `json:"stop_reason"`
+		Usage      struct {
+			PromptTokens            int `json:"prompt_tokens"`
+			CompletionTokens        int `json:"completion_tokens"`
+			InputTokens             int `json:"input_tokens"`
+			OutputTokens            int `json:"output_tokens"`
+			CompletionTokensDetails struct {
+				ReasoningTokens int `json:"reasoning_tokens"`
+			} `json:"completion_tokens_details"`
+		} `json:"usage"`
+	}
+	if err := json.Unmarshal(response, &parsed); err != nil {
+		return types.AIResponse{}, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	result := types.AIResponse{Model: parsed.Model, Raw: response}
+	reasoning := types.Reasoning{Tokens: parsed.Usage.CompletionTokensDetails.ReasoningTokens}
+	switch {
+	case parsed.Choices != nil:
+		if len(parsed.Choices) > 0 {
+			choice := parsed.Choices[0]
+			result.Content = choice.Message.Content
+			result.FinishReason = choice.FinishReason
+			if choice.FinishReason == "function_call" {
+				result.FinishReason = types.FinishToolCalls
+			}
+			if choice.Message.Refusal != "" {
+				result.Content = choice.Message.Refusal
+				result.FinishReason = types.FinishRefusal
+			}
+			if choice.Logprobs != nil {
+				for _, token := range choice.Logprobs.Content {
+					result.Logprobs = append(result.Logprobs, token.convert())
+				}
+			}
+		}
+	case parsed.Content != nil:
+		var texts, thoughts []string
+		for _, block := range parsed.Content {
+			switch block.Type {
+			case "text":
+				texts = append(texts, block.Text)
+			case "thinking":
+				thoughts = append(thoughts, block.Thinking)
+			case "redacted_thinking":
+				reasoning.Redacted = true
+			}
+		}
+		result.Content = strings.Join(texts, "")
+		reasoning.Text = strings.Join(thoughts, "\n\n")
+		result.FinishReason = parsed.StopReason
+		if reason, ok := claudeFinishReasons[parsed.StopReason]; ok {
+			result.FinishReason = reason
+		}
+	default:
+		return types.AIResponse{}, fmt.Errorf("unrecognized response format")
+	}
+
+	result.Usage.PromptTokens = parsed.Usage.PromptTokens + parsed.Usage.InputTokens
+	result.Usage.CompletionTokens = parsed.Usage.CompletionTokens + parsed.Usage.OutputTokens
+	result.Usage.TotalTokens = result.Usage.PromptTokens + result.Usage.CompletionTokens
+	if reasoning != (types.Reasoning{}) {
+		result.Reasoning = &reasoning
+	}
+	return result, nil
+}
@@ -0,0 +1,157 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/kengibson1111/go-aiprovider/aitest"
+	"github.com/kengibson1111/go-aiprovider/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseResponse(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		expected types.AIResponse
+	}{
+		{
+			name:     "OpenAI completion",
+			response: `{"model":"gpt-4o","choices":[{"message":{"content":"Hello"},"finish_reason":"length"}],"usage":{"prompt_tokens":10,"completion_tokens":5}}`,
+			expected: types.AIResponse{Content: "Hello", FinishReason: types.FinishLength, Model: "gpt-4o", Usage: types.TokenUsage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15}},
+		},
+		{
+			name:     "OpenAI refusal",
+			response: `{"model":"gpt-4o","choices":[{"message":{"content":null,"refusal":"I can't help with that."},"finish_reason":"stop"}]}`,
+			expected: types.AIResponse{Content: "I can't help with that.", FinishReason: types.FinishRefusal, Model: "gpt-4o"},
+		},
+		{
+			name: "Claude message",
+			response: `{"model":"claude-sonnet-4-5","content":[
+				{"type":"thinking","thinking":"hmm"},
+				{"type":"text","text":"Hello, "},
+				{"type":"text","text":"world"}
+			],"stop_reason":"max_tokens","usage":{"input_tokens":7,"output_tokens":3}}`,
+			expected: types.AIResponse{Content: "Hello, world", FinishReason: types.FinishLength, Model: "claude-sonnet-4-5", Usage: types.TokenUsage{PromptTokens: 7, CompletionTokens: 3, TotalTokens: 10}, Reasoning: &types.Reasoning{Text: "hmm"}},
+		},
+		{
+			name:     "OpenAI reasoning tokens",
+			response: `{"model":"o3-mini","choices":[{"message":{"content":"Yes"},"finish_reason":"stop"}],"usage":{"prompt_tokens":10,"completion_tokens":200,"completion_tokens_details":{"reasoning_tokens":192}}}`,
+			expected: types.AIResponse{Content: "Yes", FinishReason: types.FinishStop, Model: "o3-mini", Usage: types.TokenUsage{PromptTokens: 10, CompletionTokens: 200, TotalTokens: 210}, Reasoning: &types.Reasoning{Tokens: 192}},
+		},
+		{
+			name:     "Claude redacted thinking",
+			response: `{"content":[{"type":"redacted_thinking","data":"abc"},{"type":"thinking","thinking":"first"},{"type":"thinking","thinking":"second"},{"type":"text","text":"Done"}],"stop_reason":"end_turn"}`,
+			expected: types.AIResponse{Content: "Done", FinishReason: types.FinishStop, Reasoning: &types.Reasoning{Text: "first\n\nsecond", Redacted: true}},
+		},
+		{
+			name:     "Claude tool use",
+			response: `{"content":[{"type":"tool_use","id":"t1","name":"lookup","input":{}}],"stop_reason":"tool_use"}`,
+			expected: types.AIResponse{FinishReason: types.FinishToolCalls},
+		},
+		{
+			name:     "unknown stop reason passed through",
+			response: `{"content":[{"type":"text","text":"partial"}],"stop_reason":"pause_turn"}`,
+			expected: types.AIResponse{Content: "partial", FinishReason: "pause_turn"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := ParseResponse([]byte(tt.response))
+			require.NoError(t, err)
+			assert.Equal(t, []byte(tt.response), response.Raw)
+			response.Raw = nil
+			assert.Equal(t, tt.expected, response)
+		})
+	}
+
+	_, err := ParseResponse([]byte("not json"))
+	assert.Error(t, err)
+	_, err = ParseResponse([]byte(`{"id":"x"}`))
+	assert.Error(t, err)
+}
+
+func TestComplete(t *testing.T) {
+	for _, srv := range []*aitest.FakeServer{aitest.NewFakeOpenAIServer(t), aitest.NewFakeClaudeServer(t)} {
+		srv.Enqueue(aitest.Text("Paris"), aitest.Error(http.StatusBadRequest, "invalid_request_error", "bad prompt"))
+
+		aiClient, err := NewClientFactory().CreateClient(srv.Config())
+		require.NoError(t, err)
+
+		response, err := Complete(context.Background(), aiClient, "Capital of France?")
+		require.NoError(t, err)
+		assert.Equal(t, "Paris", response.Content)
+		assert.Equal(t, types.FinishStop, response.FinishReason)
+		assert.NotEmpty(t, response.Model)
+		assert.Positive(t, response.Usage.TotalTokens)
+
+		_, err = Complete(context.Background(), aiClient, "Capital of France?")
+		assert.Error(t, err)
+	}
+}
+
+func TestCompleteText(t *testing.T) {
+	srv := aitest.NewFakeClaudeServer(t)
+	srv.Enqueue(aitest.Text("Gophers burrow deep"))
+
+	aiClient, err := NewClientFactory().CreateClient(srv.Config())
+	require.NoError(t, err)
+
+	text, usage, err := CompleteText(context.Background(), aiClient, "Write a haiku about Go.")
+	require.NoError(t, err)
+	assert.Equal(t, "Gophers burrow deep", text)
+	assert.Equal(t, usage.PromptTokens+usage.CompletionTokens, usage.TotalTokens)
+	assert.Positive(t, usage.CompletionTokens)
+}
+
+func TestParseResponse_Logprobs(t *testing.T) {
+	response, err := ParseResponse([]byte(`{"choices":[{"message":{"content":"Yes"},"finish_reason":"stop","logprobs":{"content":[
+		{"token":"Yes","logprob":-0.05,"bytes":[89,101,115],"top_logprobs":[{"token":"Yes","logprob":-0.05},{"token":"No","logprob":-3.2}]}
+	],"refusal":null}}]}`))
+	require.NoError(t, err)
+
+	require.Len(t, response.Logprobs, 1)
+	token := response.Logprobs[0]
+	assert.Equal(t, "Yes", token.Token)
+	assert.InDelta(t, 0.951, token.Probability(), 0.001)
+	assert.Equal(t, []types.TokenLogprob{{Token: "Yes", Logprob: -0.05}, {Token: "No", Logprob: -3.2}}, token.TopLogprobs)
+}
+
+func TestWithTopLogprobs(t *testing.T) {
+	srv := aitest.NewFakeOpenAIServer(t)
+	aiClient, err := NewClientFactory().CreateClient(srv.Config())
+	require.NoError(t, err)
+
+	_, err = aiClient.CallWithPrompt(context.Background(), "yes or no?", types.WithTopLogprobs(3))
+	require.NoError(t, err)
+
+	body := string(srv.Requests()[0].Body)
+	assert.Contains(t, body, `"logprobs":true`)
+	assert.Contains(t, body, `"top_logprobs":3`)
+}
+
+func TestWithPrediction(t *testing.T) {
+	source := "type User struct {\n\tUsername string\n}\n"
+
+	srv := aitest.NewFakeOpenAIServer(t)
+	aiClient, err := NewClientFactory().CreateClient(srv.Config())
+	require.NoError(t, err)
+	_, err = aiClient.CallWithPrompt(context.Background(), "Rename Username to Email:\n\n"+source, types.WithPrediction(source))
+	require.NoError(t, err)
+	assert.Contains(t, string(srv.Requests()[0].Body), `"prediction":{"content":"type User struct {\n\tUsername string\n}\n","type":"content"}`)
+
+	_, err = aiClient.CallWithPrompt(context.Background(), "hello")
+	require.NoError(t, err)
+	assert.NotContains(t, string(srv.Requests()[1].Body), `"prediction"`)
+
+	// Claude has no predicted outputs
+	claude := aitest.NewFakeClaudeServer(t)
+	aiClient, err = NewClientFactory().CreateClient(claude.Config())
+	require.NoError(t, err)
+	_, err = aiClient.CallWithPrompt(context.Background(), "hello", types.WithPrediction(source))
+	require.NoError(t, err)
+	assert.NotContains(t, string(claude.Requests()[0].Body), `"prediction"`)
+}
@@ -0,0 +1,85 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kengibson1111/go-aiprovider/types"
+)
+
+// reviewInstruction asks for findings in the JSON shape of types.ReviewFinding.
+const reviewInstruction = `Review the following code change as a senior engineer. Report bugs, security problems, performance problems, and violations of the guidelines; do not comment on what is fine.
+
+Reply with JSON only, in this form:
+{"findings": [{"file": "path/to/file", "line": 12, "severity": "critical|major|minor|info", "message": "what is wrong", "suggestion": "how to fix it"}]}
+
+Use the line number in the new version of the file, or 0 when the finding is not about one line. Reply with {"findings": []} when there is nothing to report.`
+
+// GenerateReview asks the model to review a diff and returns its findings, with the token
+// usage of the call. Severities are normalized to the types.Severity constants, and
+// unrecognized ones are reported as types.SeverityInfo. The response is repaired with
+// RepairJSON when it is not quite valid JSON.
+//
+// Example:
+//
+//	findings, _, err := client.GenerateReview(ctx, aiClient, types.ReviewRequest{
+//		Diff:       diff,
+//		Language:   "go",
+//		Guidelines: []string{"Wrap errors with %w", "No panics in library code"},
+//	})
+//	for _, f := range findings {
+//		fmt.Printf("%s:%d [%s] %s\n", f.File, f.Line, f.Severity, f.Message)
+//	}
+func GenerateReview(ctx context.Context, aiClient AIClient, req types.ReviewRequest, opts ...types.CallOption) ([]types.ReviewFinding, types.TokenUsage, error) {
+	if aiClient == nil {
+		return nil, types.TokenUsage{}, fmt.Errorf("AI client is required")
+	}
+	if strings.TrimSpace(req.Diff) == "" {
+		return nil, types.TokenUsage{}, fmt.Errorf("diff is required")
+	}
+
+	var prompt strings.Builder
+	prompt.WriteString(reviewInstruction)
+	if req.Language != "" {
+		fmt.Fprintf(&prompt, "\n\nLanguage: %s", req.Language)
+	}
+	if len(req.Guidelines) > 0 {
+		prompt.WriteString("\n\nGuidelines:")
+		for _, guideline := range req.Guidelines {
+			fmt.Fprintf(&prompt, "\n- %s", guideline)
+		}
+	}
+	fmt.Fprintf(&prompt, "\n\nDiff:\n```diff\n%s\n```", strings.TrimRight(req.Diff, "\n"))
+
+	var review struct {
+		Findings []types.ReviewFinding `json:"findings"`
+	}
+	usage, err := CompleteJSON(ctx, aiClient, prompt.String(), &review, JSONOptions{Repair: true, CallOptions: opts})
+	if err != nil {
+		return nil, usage, fmt.Errorf("failed to generate review: %w", err)
+	}
+
+	findings := review.Findings
+	if findings == nil {
+		findings = []types.ReviewFinding{}
+	}
+	for i := range findings {
+		findings[i].Severity = normalizeSeverity(findings[i].Severity)
+	}
+	return findings, usage, nil
+}
+
+// normalizeSeverity maps a model's severity to a types.Severity constant.
+func normalizeSeverity(severity string) string {
+	switch strings.ToLower(strings.TrimSpace(severity)) {
+	case types.SeverityCritical, "blocker", "high":
+		return types.SeverityCritical
+	case types.SeverityMajor, "error", "medium":
+		return types.SeverityMajor
+	case types.SeverityMinor, "warning", "low":
+		return types.SeverityMinor
+	default:
+		return types.SeverityInfo
+	}
+}
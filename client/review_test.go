@@ -0,0 +1,58 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kengibson1111/go-aiprovider/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateReview(t *testing.T) {
+	response := openAIText("```json\n" + `{"findings": [
+		{"file": "main.go", "line": 12, "severity": "High", "message": "error is ignored", "suggestion": "return the error"},
+		{"file": "main.go", "line": 0, "severity": "warning", "message": "missing tests"},
+		{"file": "util.go", "line": 3, "severity": "nit", "message": "rename x"},
+	]}` + "\n```")
+	stub := &stubClient{responses: []string{response}}
+
+	findings, _, err := GenerateReview(context.Background(), stub, types.ReviewRequest{
+		Diff:       "--- a/main.go\n+++ b/main.go\n@@ -1 +1 @@\n-a\n+b\n",
+		Language:   "go",
+		Guidelines: []string{"Wrap errors"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []types.ReviewFinding{
+		{File: "main.go", Line: 12, Severity: types.SeverityCritical, Message: "error is ignored", Suggestion: "return the error"},
+		{File: "main.go", Severity: types.SeverityMinor, Message: "missing tests"},
+		{File: "util.go", Line: 3, Severity: types.SeverityInfo, Message: "rename x"},
+	}, findings)
+
+	require.Len(t, stub.prompts, 1)
+	assert.Contains(t, stub.prompts[0], "Language: go\n\nGuidelines:\n- Wrap errors\n\nDiff:\n```diff\n--- a/main.go\n")
+	assert.Contains(t, stub.prompts[0], "+b\n```")
+}
+
+func TestGenerateReview_NoFindings(t *testing.T) {
+	stub := &stubClient{responses: []string{openAIText(`{"findings": []}`)}}
+
+	findings, _, err := GenerateReview(context.Background(), stub, types.ReviewRequest{Diff: "+x"})
+	require.NoError(t, err)
+	assert.Empty(t, findings)
+	assert.NotNil(t, findings)
+	assert.NotContains(t, stub.prompts[0], "Guidelines:")
+}
+
+func TestGenerateReview_Errors(t *testing.T) {
+	_, _, err := GenerateReview(context.Background(), nil, types.ReviewRequest{Diff: "+x"})
+	assert.EqualError(t, err, "AI client is required")
+
+	_, _, err = GenerateReview(context.Background(), &stubClient{}, types.ReviewRequest{})
+	assert.EqualError(t, err, "diff is required")
+
+	stub := &stubClient{responses: []string{openAIText("Looks good to me!")}}
+	_, _, err = GenerateReview(context.Background(), stub, types.ReviewRequest{Diff: "+x"})
+	assert.ErrorContains(t, err, "failed to generate review: response is not valid JSON")
+}
@@ -0,0 +1,254 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/kengibson1111/go-aiprovider/types"
+)
+
+// ErrNoRoute is returned by a RouterClient when no route target meets the task and
+// constraints of a call.
+var ErrNoRoute = errors.New("no route target matches the request")
+
+// Task classes for Route.Task and RouteTarget.Tasks. Any other name can be used as well.
+const (
+	TaskCompletion = "completion"
+	TaskCodegen    = "codegen"
+	TaskChat       = "chat"
+	TaskExtraction = "extraction"
+)
+
+// Route describes what a call needs; attach it to the call's context with WithRoute.
+type Route struct {
+	// Task is the task class of the call. Empty matches every target.
+	Task string
+
+	// MaxLatency excludes targets whose expected Latency is higher, or unknown.
+	MaxLatency time.Duration
+
+	// MaxCostPer1K excludes targets whose price, averaged over input and output tokens,
+	// is higher than this many US dollars per 1,000 tokens, or unknown.
+	MaxCostPer1K float64
+}
+
+// routeKey is the context key for Route
+type routeKey struct{}
+
+// WithRoute returns a context whose calls through a RouterClient are routed by route.
+func WithRoute(ctx context.Context, route Route) context.Context {
+	return context.WithValue(ctx, routeKey{}, route)
+}
+
+// RouteTarget is one provider and model in a RouterClient's table.
+type RouteTarget struct {
+	// Name identifies the target in RouteDecision.
+	Name string
+
+	// Client sends the target's calls.
+	Client AIClient
+
+	// Model, if set, is sent with types.WithModel, overriding any model in the call
+	// options, so one client can serve several targets.
+	Model string
+
+	// Tasks lists the task classes the target serves. Empty serves every task.
+	Tasks []string
+
+	// Latency is the target's expected response time, for Route.MaxLatency.
+	Latency time.Duration
+
+	// Price is the target's price, for Route.MaxCostPer1K.
+	Price *ModelPrice
+}
+
+// costPer1K returns the target's price averaged over input and output tokens, per 1,000
+// tokens, or -1 when it is unknown.
+func (t RouteTarget) costPer1K() float64 {
+	if t.Price == nil {
+		return -1
+	}
+	return (t.Price.InputPerMillion + t.Price.OutputPerMillion) / 2 / 1000
+}
+
+// matches reports whether the target meets the route.
+func (t RouteTarget) matches(route Route) bool {
+	if route.Task != "" && len(t.Tasks) > 0 && !slices.Contains(t.Tasks, route.Task) {
+		return false
+	}
+	if route.MaxLatency > 0 && (t.Latency <= 0 || t.Latency > route.MaxLatency) {
+		return false
+	}
+	if route.MaxCostPer1K > 0 {
+		if cost := t.costPer1K(); cost < 0 || cost > route.MaxCostPer1K {
+			return false
+		}
+	}
+	return true
+}
+
+// RouteDecision reports how a RouterClient routed a call.
+type RouteDecision struct {
+	Route Route
+
+	// Target is the target that produced the response, or empty when none did.
+	Target string
+
+	// Failed lists the targets tried before Target, in order, with their errors.
+	Failed []RouteFailure
+}
+
+// RouteFailure is a target that failed during routing.
+type RouteFailure struct {
+	Target string
+	Err    error
+}
+
+// RouterOptions configures a RouterClient.
+type RouterOptions struct {
+	// Targets is the routing table in order of preference. A call goes to the first
+	// target that meets its route, and falls back to the next on failure.
+	Targets []RouteTarget
+
+	// DefaultRoute applies to calls whose context has no route.
+	DefaultRoute Route
+
+	// ShouldFallback decides whether a failed call moves on to the next target (default:
+	// every error except a cancelled or expired context).
+	ShouldFallback func(err error) bool
+
+	// OnRoute, if set, receives the decision for every call.
+	OnRoute func(RouteDecision)
+}
+
+// RouterClient sends each call to a provider and model chosen from a table by the call's
+// task class and latency and cost constraints, falling back to the next matching target
+// when one fails. It implements AIClient, so it can be used wherever a single client is.
+type RouterClient struct {
+	opts RouterOptions
+}
+
+// NewRouterClient builds a router over the targets. Every target needs a unique name
+// and a client.
+//
+// Example:
+//
+//	router, err := client.NewRouterClient(client.RouterOptions{
+//		Targets: []client.RouteTarget{
+//			{Name: "mini", Client: openAIClient, Model: "gpt-4o-mini", Tasks: []string{client.TaskCompletion, client.TaskExtraction},
+//				Latency: time.Second, Price: &client.ModelPrice{InputPerMillion: 0.15, OutputPerMillion: 0.60}},
+//			{Name: "sonnet", Client: claudeClient, Tasks: []string{client.TaskCodegen, client.TaskChat},
+//				Latency: 4 * time.Second, Price: &client.ModelPrice{InputPerMillion: 3, OutputPerMillion: 15}},
+//			{Name: "gpt-4o", Client: openAIClient, Model: "gpt-4o",
+//				Latency: 3 * time.Second, Price: &client.ModelPrice{InputPerMillion: 2.5, OutputPerMillion: 10}},
+//		},
+//	})
+//	ctx = client.WithRoute(ctx, client.Route{Task: client.TaskCodegen, MaxCostPer1K: 0.01})
+//	response, err := router.CallWithPrompt(ctx, "Write a Go function that reverses a slice")
+func NewRouterClient(opts RouterOptions) (*RouterClient, error) {
+	if len(opts.Targets) == 0 {
+		return nil, fmt.Errorf("at least one route target is required")
+	}
+	names := make(map[string]bool, len(opts.Targets))
+	for i, target := range opts.Targets {
+		if strings.TrimSpace(target.Name) == "" {
+			return nil, fmt.Errorf("route target %d must have a name", i)
+		}
+		if names[target.Name] {
+			return nil, fmt.Errorf("route target name %q is duplicated", target.Name)
+		}
+		if target.Client == nil {
+			return nil, fmt.Errorf("route target %q needs a client", target.Name)
+		}
+		names[target.Name] = true
+	}
+	if opts.ShouldFallback == nil {
+		opts.ShouldFallback = func(err error) bool {
+			return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+		}
+	}
+
+	return &RouterClient{opts: opts}, nil
+}
+
+// Targets returns the names of the targets that meet route, in the order they are tried.
+func (c *RouterClient) Targets(route Route) []string {
+	var names []string
+	for _, target := range c.opts.Targets {
+		if target.matches(route) {
+			names = append(names, target.Name)
+		}
+	}
+	return names
+}
+
+// CallWithPrompt sends the prompt through the first matching target that succeeds.
+func (c *RouterClient) CallWithPrompt(ctx context.Context, prompt string, opts ...types.CallOption) ([]byte, error) {
+	return c.route(ctx, opts, func(ac AIClient, opts []types.CallOption) ([]byte, error) {
+		return ac.CallWithPrompt(ctx, prompt, opts...)
+	})
+}
+
+// CallWithPromptAndVariables sends the prompt through the first matching target that
+// succeeds.
+func (c *RouterClient) CallWithPromptAndVariables(ctx context.Context, prompt string, variablesJSON string, opts ...types.CallOption) ([]byte, error) {
+	return c.route(ctx, opts, func(ac AIClient, opts []types.CallOption) ([]byte, error) {
+		return ac.CallWithPromptAndVariables(ctx, prompt, variablesJSON, opts...)
+	})
+}
+
+// ValidateCredentials validates the credentials of every target's client, returning the
+// failures joined.
+func (c *RouterClient) ValidateCredentials(ctx context.Context) error {
+	var errs []error
+	for _, target := range c.opts.Targets {
+		if err := target.Client.ValidateCredentials(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("route target %q: %w", target.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// route tries the targets matching the context's route in order until one succeeds or
+// fails with an error that should not fall back.
+func (c *RouterClient) route(ctx context.Context, opts []types.CallOption, send func(ac AIClient, opts []types.CallOption) ([]byte, error)) ([]byte, error) {
+	route, ok := ctx.Value(routeKey{}).(Route)
+	if !ok {
+		route = c.opts.DefaultRoute
+	}
+	decision := RouteDecision{Route: route}
+	defer func() {
+		if c.opts.OnRoute != nil {
+			c.opts.OnRoute(decision)
+		}
+	}()
+
+	var lastErr error
+	for _, target := range c.opts.Targets {
+		if !target.matches(route) {
+			continue
+		}
+		callOpts := opts
+		if target.Model != "" {
+			callOpts = append(slices.Clip(opts), types.WithModel(target.Model))
+		}
+		response, err := send(target.Client, callOpts)
+		if err == nil {
+			decision.Target = target.Name
+			return response, nil
+		}
+		decision.Failed = append(decision.Failed, RouteFailure{Target: target.Name, Err: err})
+		lastErr = fmt.Errorf("route target %q: %w", target.Name, err)
+		if !c.opts.ShouldFallback(err) {
+			return nil, lastErr
+		}
+	}
+	if lastErr != nil {
+		return nil, lastErr
+	}
+	return nil, fmt.Errorf("%w: task %q", ErrNoRoute, route.Task)
+}
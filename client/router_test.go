@@ -0,0 +1,134 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kengibson1111/go-aiprovider/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// modelClient answers with the model it was asked for, or fails with err.
+type modelClient struct {
+	stubClient
+	err    error
+	models []string
+}
+
+func (m *modelClient) CallWithPrompt(ctx context.Context, prompt string, opts ...types.CallOption) ([]byte, error) {
+	model := types.ResolveCallOptions(types.CallOptions{Model: "default"}, opts).Model
+	m.models = append(m.models, model)
+	if m.err != nil {
+		return nil, m.err
+	}
+	return []byte(openAIText(model)), nil
+}
+
+func (m *modelClient) CallWithPromptAndVariables(ctx context.Context, prompt string, variablesJSON string, opts ...types.CallOption) ([]byte, error) {
+	return m.CallWithPrompt(ctx, prompt, opts...)
+}
+
+func newTestRouter(t *testing.T, openAI, claude *modelClient, decisions *[]RouteDecision) *RouterClient {
+	router, err := NewRouterClient(RouterOptions{
+		Targets: []RouteTarget{
+			{Name: "mini", Client: openAI, Model: "gpt-4o-mini", Tasks: []string{TaskCompletion, TaskExtraction},
+				Latency: time.Second, Price: &ModelPrice{InputPerMillion: 0.15, OutputPerMillion: 0.60}},
+			{Name: "sonnet", Client: claude, Tasks: []string{TaskCodegen, TaskChat},
+				Latency: 4 * time.Second, Price: &ModelPrice{InputPerMillion: 3, OutputPerMillion: 15}},
+			{Name: "gpt-4o", Client: openAI, Model: "gpt-4o",
+				Latency: 3 * time.Second, Price: &ModelPrice{InputPerMillion: 2.5, OutputPerMillion: 10}},
+		},
+		DefaultRoute: Route{Task: TaskCompletion},
+		OnRoute:      func(d RouteDecision) { *decisions = append(*decisions, d) },
+	})
+	require.NoError(t, err)
+	return router
+}
+
+func TestRouterClient_Selects(t *testing.T) {
+	var decisions []RouteDecision
+	router := newTestRouter(t, &modelClient{}, &modelClient{}, &decisions)
+
+	tests := []struct {
+		name     string
+		route    *Route
+		expected []string
+	}{
+		{"default route", nil, []string{"mini", "gpt-4o"}},
+		{"task", &Route{Task: TaskCodegen}, []string{"sonnet", "gpt-4o"}},
+		{"latency", &Route{Task: TaskCodegen, MaxLatency: 3 * time.Second}, []string{"gpt-4o"}},
+		{"cost", &Route{Task: TaskCodegen, MaxCostPer1K: 0.007}, []string{"gpt-4o"}},
+		{"any task", &Route{MaxCostPer1K: 0.001}, []string{"mini"}},
+		{"nothing matches", &Route{Task: TaskChat, MaxLatency: time.Second}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			route := router.opts.DefaultRoute
+			if tt.route != nil {
+				ctx = WithRoute(ctx, *tt.route)
+				route = *tt.route
+			}
+			assert.Equal(t, tt.expected, router.Targets(route))
+
+			_, err := router.CallWithPrompt(ctx, "hello")
+			if tt.expected == nil {
+				assert.ErrorIs(t, err, ErrNoRoute)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected[0], decisions[len(decisions)-1].Target)
+		})
+	}
+}
+
+func TestRouterClient_Fallback(t *testing.T) {
+	var decisions []RouteDecision
+	unavailable := &types.ErrorResponse{Code: "model_not_found", Message: "no such model"}
+	openAI := &modelClient{err: unavailable}
+	claude := &modelClient{}
+	router := newTestRouter(t, openAI, claude, &decisions)
+
+	// The model option from the table overrides the caller's
+	ctx := WithRoute(context.Background(), Route{Task: TaskExtraction})
+	_, err := router.CallWithPromptAndVariables(ctx, "hello {{x}}", `{"x":"y"}`, types.WithModel("caller"))
+	assert.ErrorIs(t, err, unavailable)
+	assert.EqualError(t, err, `route target "gpt-4o": model_not_found: no such model`)
+	assert.Equal(t, []string{"gpt-4o-mini", "gpt-4o"}, openAI.models)
+
+	// A failing target falls back to the next one
+	openAI.err = errors.New("connection refused")
+	ctx = WithRoute(context.Background(), Route{})
+	response, err := router.CallWithPrompt(ctx, "hello")
+	require.NoError(t, err)
+	assert.Equal(t, openAIText("default"), string(response))
+
+	last := decisions[len(decisions)-1]
+	assert.Equal(t, "sonnet", last.Target)
+	require.Len(t, last.Failed, 1)
+	assert.Equal(t, "mini", last.Failed[0].Target)
+
+	// A cancelled context does not fall back
+	openAI.err = context.Canceled
+	_, err = router.CallWithPrompt(ctx, "hello")
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Len(t, decisions[len(decisions)-1].Failed, 1)
+}
+
+func TestNewRouterClient_Errors(t *testing.T) {
+	_, err := NewRouterClient(RouterOptions{})
+	assert.EqualError(t, err, "at least one route target is required")
+
+	_, err = NewRouterClient(RouterOptions{Targets: []RouteTarget{{Client: &stubClient{}}}})
+	assert.EqualError(t, err, "route target 0 must have a name")
+
+	_, err = NewRouterClient(RouterOptions{Targets: []RouteTarget{{Name: "a", Client: &stubClient{}}, {Name: "a", Client: &stubClient{}}}})
+	assert.EqualError(t, err, `route target name "a" is duplicated`)
+
+	_, err = NewRouterClient(RouterOptions{Targets: []RouteTarget{{Name: "a"}}})
+	assert.EqualError(t, err, `route target "a" needs a client`)
+}
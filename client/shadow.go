@@ -0,0 +1,201 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/kengibson1111/go-aiprovider/types"
+)
+
+// Shadow defaults
+const (
+	defaultShadowTimeout     = 60 * time.Second
+	defaultShadowMaxInFlight = 8
+)
+
+// ShadowResult is one side of a ShadowComparison.
+type ShadowResult struct {
+	Model            string        `json:"model,omitempty"`
+	Text             string        `json:"text"`
+	PromptTokens     int           `json:"promptTokens"`
+	CompletionTokens int           `json:"completionTokens"`
+	Latency          time.Duration `json:"latency"`
+
+	// Cost is the estimated cost in US dollars, zero when the model has no price.
+	Cost float64 `json:"cost,omitempty"`
+
+	Error string `json:"error,omitempty"`
+}
+
+// ShadowComparison records the primary and shadow results of one mirrored call.
+type ShadowComparison struct {
+	Time   time.Time `json:"time"`
+	Method string    `json:"method"`
+
+	// Prompt is the prompt, or the template for calls with variables.
+	Prompt string `json:"prompt"`
+
+	Primary ShadowResult `json:"primary"`
+	Shadow  ShadowResult `json:"shadow"`
+
+	// Match reports whether both calls succeeded with the same text, ignoring case and
+	// whitespace.
+	Match bool `json:"match"`
+}
+
+// ShadowOptions configures a ShadowClient.
+type ShadowOptions struct {
+	// Shadow receives a copy of mirrored calls. Required.
+	Shadow AIClient
+
+	// OnComparison receives the comparison of every mirrored call, from a background
+	// goroutine. Required.
+	OnComparison func(ShadowComparison)
+
+	// SampleRate is the fraction of calls mirrored, from 0 to 1 (default: 1).
+	SampleRate float64
+
+	// Timeout limits each shadow call (default: 60s). Shadow calls are not cancelled with
+	// the primary call's context.
+	Timeout time.Duration
+
+	// MaxInFlight limits concurrent shadow calls; calls beyond it are not mirrored
+	// (default: 8).
+	MaxInFlight int
+
+	// Prices maps model names to prices used to estimate Cost.
+	Prices map[string]ModelPrice
+}
+
+// ShadowClient wraps a primary AIClient and mirrors calls to a shadow client in the
+// background, for comparing providers or models before a migration. Callers always get
+// the primary result, without waiting for the shadow, and shadow failures never affect
+// them. ValidateCredentials is passed through to the primary unchanged.
+type ShadowClient struct {
+	AIClient
+	opts     ShadowOptions
+	inFlight chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewShadowClient wraps primary so calls are mirrored to opts.Shadow.
+//
+// Example:
+//
+//	sc, err := client.NewShadowClient(openAIClient, client.ShadowOptions{
+//		Shadow:     claudeClient,
+//		SampleRate: 0.1,
+//		OnComparison: func(c client.ShadowComparison) {
+//			line, _ := json.Marshal(c)
+//			comparisons.Write(append(line, '\n'))
+//		},
+//	})
+//	defer sc.Wait()
+func NewShadowClient(primary AIClient, opts ShadowOptions) (*ShadowClient, error) {
+	if primary == nil {
+		return nil, fmt.Errorf("AI client is required")
+	}
+	if opts.Shadow == nil {
+		return nil, fmt.Errorf("shadow client is required")
+	}
+	if opts.OnComparison == nil {
+		return nil, fmt.Errorf("comparison callback is required")
+	}
+	if opts.SampleRate < 0 || opts.SampleRate > 1 {
+		return nil, fmt.Errorf("sample rate must be between 0 and 1")
+	}
+	if opts.SampleRate == 0 {
+		opts.SampleRate = 1
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultShadowTimeout
+	}
+	if opts.MaxInFlight <= 0 {
+		opts.MaxInFlight = defaultShadowMaxInFlight
+	}
+
+	return &ShadowClient{
+		AIClient: primary,
+		opts:     opts,
+		inFlight: make(chan struct{}, opts.MaxInFlight),
+	}, nil
+}
+
+// Wait blocks until all shadow calls in flight have finished and been reported, for
+// example before the program exits.
+func (c *ShadowClient) Wait() {
+	c.wg.Wait()
+}
+
+// CallWithPrompt sends the prompt to the primary client and mirrors it to the shadow.
+func (c *ShadowClient) CallWithPrompt(ctx context.Context, prompt string, opts ...types.CallOption) ([]byte, error) {
+	return c.mirror(ctx, "CallWithPrompt", prompt, func(ctx context.Context, ac AIClient) ([]byte, error) {
+		return ac.CallWithPrompt(ctx, prompt, opts...)
+	})
+}
+
+// CallWithPromptAndVariables sends the prompt to the primary client and mirrors it to the
+// shadow.
+func (c *ShadowClient) CallWithPromptAndVariables(ctx context.Context, prompt string, variablesJSON string, opts ...types.CallOption) ([]byte, error) {
+	return c.mirror(ctx, "CallWithPromptAndVariables", prompt, func(ctx context.Context, ac AIClient) ([]byte, error) {
+		return ac.CallWithPromptAndVariables(ctx, prompt, variablesJSON, opts...)
+	})
+}
+
+// mirror starts the shadow call if the call is sampled and a slot is free, then makes
+// the primary call. The shadow goroutine reports the comparison once both are done.
+func (c *ShadowClient) mirror(ctx context.Context, method, prompt string, send func(ctx context.Context, ac AIClient) ([]byte, error)) ([]byte, error) {
+	if rand.Float64() >= c.opts.SampleRate {
+		return send(ctx, c.AIClient)
+	}
+	select {
+	case c.inFlight <- struct{}{}:
+	default:
+		return send(ctx, c.AIClient)
+	}
+
+	start := time.Now()
+	primaryDone := make(chan ShadowResult, 1)
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		defer func() { <-c.inFlight }()
+
+		shadowCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.opts.Timeout)
+		response, err := send(shadowCtx, c.opts.Shadow)
+		cancel()
+		shadow := c.result(response, err, time.Since(start))
+		primary := <-primaryDone
+
+		c.opts.OnComparison(ShadowComparison{
+			Time:    start,
+			Method:  method,
+			Prompt:  prompt,
+			Primary: primary,
+			Shadow:  shadow,
+			Match:   primary.Error == "" && shadow.Error == "" && normalizeAnswer(primary.Text) == normalizeAnswer(shadow.Text),
+		})
+	}()
+
+	response, err := send(ctx, c.AIClient)
+	primaryDone <- c.result(response, err, time.Since(start))
+	return response, err
+}
+
+// result summarizes one call for a comparison.
+func (c *ShadowClient) result(response []byte, err error, latency time.Duration) ShadowResult {
+	result := ShadowResult{Latency: latency}
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Model, result.PromptTokens, result.CompletionTokens = responseUsage(response)
+	result.Text = extractResponseText(response)
+	if price, ok := c.opts.Prices[result.Model]; ok {
+		result.Cost = (float64(result.PromptTokens)*price.InputPerMillion + float64(result.CompletionTokens)*price.OutputPerMillion) / 1e6
+	}
+	return result
+}
@@ -0,0 +1,112 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/kengibson1111/go-aiprovider/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gatedClient waits for release before answering with stubClient.
+type gatedClient struct {
+	stubClient
+	release chan struct{}
+}
+
+func (g *gatedClient) CallWithPrompt(ctx context.Context, prompt string, opts ...types.CallOption) ([]byte, error) {
+	<-g.release
+	return g.stubClient.CallWithPrompt(ctx, prompt, opts...)
+}
+
+func TestShadowClient_Compares(t *testing.T) {
+	primary := &stubClient{responses: []string{`{"model":"gpt-4o","choices":[{"index":0,"message":{"role":"assistant","content":"Paris"}}],"usage":{"prompt_tokens":10,"completion_tokens":2}}`}}
+	shadow := &gatedClient{
+		stubClient: stubClient{responses: []string{`{"model":"claude-sonnet","content":[{"type":"text","text":" paris\n"}],"usage":{"input_tokens":12,"output_tokens":3}}`}},
+		release:    make(chan struct{}),
+	}
+
+	var mu sync.Mutex
+	var comparisons []ShadowComparison
+	sc, err := NewShadowClient(primary, ShadowOptions{
+		Shadow: shadow,
+		Prices: map[string]ModelPrice{"gpt-4o": {InputPerMillion: 2.5, OutputPerMillion: 10}},
+		OnComparison: func(c ShadowComparison) {
+			mu.Lock()
+			defer mu.Unlock()
+			comparisons = append(comparisons, c)
+		},
+	})
+	require.NoError(t, err)
+
+	// The primary result returns while the shadow call is still running
+	response, err := sc.CallWithPrompt(context.Background(), "Capital of France?")
+	require.NoError(t, err)
+	assert.Contains(t, string(response), "Paris")
+	mu.Lock()
+	assert.Empty(t, comparisons)
+	mu.Unlock()
+
+	close(shadow.release)
+	sc.Wait()
+
+	require.Len(t, comparisons, 1)
+	c := comparisons[0]
+	assert.Equal(t, "CallWithPrompt", c.Method)
+	assert.Equal(t, "Capital of France?", c.Prompt)
+	assert.True(t, c.Match)
+	assert.Equal(t, "gpt-4o", c.Primary.Model)
+	assert.Equal(t, "Paris", c.Primary.Text)
+	assert.InDelta(t, (10*2.5+2*10)/1e6, c.Primary.Cost, 1e-12)
+	assert.Equal(t, "claude-sonnet", c.Shadow.Model)
+	assert.Equal(t, 12, c.Shadow.PromptTokens)
+	assert.Zero(t, c.Shadow.Cost)
+	assert.GreaterOrEqual(t, c.Shadow.Latency, c.Primary.Latency)
+	assert.Equal(t, []string{"Capital of France?"}, shadow.prompts)
+}
+
+func TestShadowClient_ShadowFailure(t *testing.T) {
+	primary := &stubClient{responses: []string{openAIText("yes")}}
+	shadow := &modelClient{err: errors.New("shadow down")}
+
+	var comparisons []ShadowComparison
+	sc, err := NewShadowClient(primary, ShadowOptions{
+		Shadow:       shadow,
+		OnComparison: func(c ShadowComparison) { comparisons = append(comparisons, c) },
+	})
+	require.NoError(t, err)
+
+	_, err = sc.CallWithPromptAndVariables(context.Background(), "Is {{x}}?", `{"x":"y"}`)
+	require.NoError(t, err)
+	sc.Wait()
+
+	require.Len(t, comparisons, 1)
+	assert.Equal(t, "shadow down", comparisons[0].Shadow.Error)
+	assert.False(t, comparisons[0].Match)
+
+	// Errors from the primary are returned and recorded
+	_, err = sc.CallWithPrompt(context.Background(), "again")
+	assert.EqualError(t, err, "no response")
+	sc.Wait()
+	require.Len(t, comparisons, 2)
+	assert.Equal(t, "no response", comparisons[1].Primary.Error)
+}
+
+func TestNewShadowClient_Errors(t *testing.T) {
+	onComparison := func(ShadowComparison) {}
+
+	_, err := NewShadowClient(nil, ShadowOptions{})
+	assert.EqualError(t, err, "AI client is required")
+
+	_, err = NewShadowClient(&stubClient{}, ShadowOptions{OnComparison: onComparison})
+	assert.EqualError(t, err, "shadow client is required")
+
+	_, err = NewShadowClient(&stubClient{}, ShadowOptions{Shadow: &stubClient{}})
+	assert.EqualError(t, err, "comparison callback is required")
+
+	_, err = NewShadowClient(&stubClient{}, ShadowOptions{Shadow: &stubClient{}, OnComparison: onComparison, SampleRate: 1.5})
+	assert.EqualError(t, err, "sample rate must be between 0 and 1")
+}
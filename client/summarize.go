@@ -0,0 +1,237 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/kengibson1111/go-aiprovider/types"
+)
+
+// Summarize defaults
+const (
+	defaultSummaryChunkTokens = 3000
+	defaultSummaryConcurrency = 4
+	defaultSummaryMapPrompt   = "Summarize the following section of a longer document. Keep the key facts, names, numbers, and decisions. Reply with the summary only."
+	defaultSummaryPrompt      = "Summarize the following document. Keep the key facts, names, numbers, and decisions. Reply with the summary only."
+	defaultSummaryReduce      = "The following are summaries of consecutive sections of one document. Combine them into a single coherent summary without repeating yourself. Reply with the summary only."
+)
+
+// Summarize stages reported in SummarizeProgress.Stage
+const (
+	SummarizeStageMap    = "map"
+	SummarizeStageReduce = "reduce"
+)
+
+// SummarizeOptions configures Summarize. The zero value uses the defaults.
+type SummarizeOptions struct {
+	// Model names the model for token estimates (see EstimateTokens). It does not select
+	// the model; pass types.WithModel in CallOptions for that.
+	Model string
+
+	// ChunkTokens is the estimated size of each section sent for summarizing, and the
+	// largest set of partial summaries combined in one call (default: 3000).
+	ChunkTokens int
+
+	// Overlap repeats about this many tokens between consecutive sections.
+	Overlap int
+
+	// Concurrency is the number of summary calls run at once (default: 4).
+	Concurrency int
+
+	// MapPrompt is the instruction sent before each section. ReducePrompt is sent before
+	// the partial summaries to combine, and Prompt before text short enough to summarize
+	// in one call.
+	MapPrompt    string
+	ReducePrompt string
+	Prompt       string
+
+	// CallOptions are passed to every call.
+	CallOptions []types.CallOption
+
+	// Price, if set, is used to estimate SummaryResult.Cost.
+	Price *ModelPrice
+
+	// OnProgress, if set, is called after each completed call. Calls may come from
+	// several goroutines, but never at the same time.
+	OnProgress func(SummarizeProgress)
+}
+
+// SummarizeProgress reports Summarize progress.
+type SummarizeProgress struct {
+	Stage     string           // SummarizeStageMap or SummarizeStageReduce
+	Completed int              // Calls completed in this stage
+	Total     int              // Calls in this stage
+	Usage     types.TokenUsage // Tokens used so far by the whole summary
+}
+
+// SummaryResult is the outcome of Summarize.
+type SummaryResult struct {
+	Summary string
+	Chunks  int              // Sections the document was split into
+	Calls   int              // API calls made
+	Usage   types.TokenUsage // Tokens used by all calls
+	Cost    float64          // Estimated cost in US dollars, zero without a Price
+}
+
+// Summarize summarizes text of any length with map-reduce: it splits the text into
+// sections (ChunkBySentences), summarizes them concurrently, then combines the partial
+// summaries, in several rounds if they are still too long for one call. Text that fits
+// in one section is summarized with a single call. The first failed call cancels the
+// rest and is returned.
+//
+// Example:
+//
+//	result, err := client.Summarize(ctx, aiClient, report, client.SummarizeOptions{
+//		Model:      "gpt-4o-mini",
+//		Price:      &client.ModelPrice{InputPerMillion: 0.15, OutputPerMillion: 0.60},
+//		OnProgress: func(p client.SummarizeProgress) { log.Printf("%s %d/%d", p.Stage, p.Completed, p.Total) },
+//	})
+//	fmt.Println(result.Summary)
+//	log.Printf("%d calls, %d tokens, $%.4f", result.Calls, result.Usage.TotalTokens, result.Cost)
+func Summarize(ctx context.Context, aiClient AIClient, text string, opts SummarizeOptions) (SummaryResult, error) {
+	if aiClient == nil {
+		return SummaryResult{}, fmt.Errorf("AI client is required")
+	}
+	if strings.TrimSpace(text) == "" {
+		return SummaryResult{}, fmt.Errorf("text to summarize is empty")
+	}
+	if opts.ChunkTokens <= 0 {
+		opts.ChunkTokens = defaultSummaryChunkTokens
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = defaultSummaryConcurrency
+	}
+	if opts.MapPrompt == "" {
+		opts.MapPrompt = defaultSummaryMapPrompt
+	}
+	if opts.ReducePrompt == "" {
+		opts.ReducePrompt = defaultSummaryReduce
+	}
+	if opts.Prompt == "" {
+		opts.Prompt = defaultSummaryPrompt
+	}
+
+	chunks, err := ChunkBySentences(text, opts.Model, opts.ChunkTokens, opts.Overlap)
+	if err != nil {
+		return SummaryResult{}, err
+	}
+
+	s := &summarizer{aiClient: aiClient, opts: opts}
+	result := SummaryResult{Chunks: len(chunks)}
+	if len(chunks) == 1 {
+		summaries, err := s.run(ctx, SummarizeStageMap, opts.Prompt, chunks)
+		if err != nil {
+			return SummaryResult{}, err
+		}
+		return s.result(result, summaries[0]), nil
+	}
+
+	summaries, err := s.run(ctx, SummarizeStageMap, opts.MapPrompt, chunks)
+	if err != nil {
+		return SummaryResult{}, err
+	}
+
+	// Combine groups of summaries until they fit in one call
+	for {
+		groups, err := ChunkBySentences(strings.Join(summaries, "\n\n"), opts.Model, opts.ChunkTokens, 0)
+		if err != nil {
+			return SummaryResult{}, err
+		}
+		if len(groups) == 1 || len(groups) >= len(summaries) {
+			break
+		}
+		if summaries, err = s.run(ctx, SummarizeStageReduce, opts.ReducePrompt, groups); err != nil {
+			return SummaryResult{}, err
+		}
+	}
+
+	final, err := s.run(ctx, SummarizeStageReduce, opts.ReducePrompt, []string{strings.Join(summaries, "\n\n")})
+	if err != nil {
+		return SummaryResult{}, err
+	}
+	return s.result(result, final[0]), nil
+}
+
+// summarizer runs summary calls and accounts for their usage.
+type summarizer struct {
+	aiClient AIClient
+	opts     SummarizeOptions
+
+	mu    sync.Mutex
+	calls int
+	usage types.TokenUsage
+}
+
+// run summarizes each input with instruction, at most opts.Concurrency at a time, and
+// returns the summaries in input order.
+func (s *summarizer) run(ctx context.Context, stage, instruction string, inputs []string) ([]string, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	outputs := make([]string, len(inputs))
+	errs := make([]error, len(inputs))
+	completed := 0
+	sem := make(chan struct{}, s.opts.Concurrency)
+	var wg sync.WaitGroup
+
+	for i, input := range inputs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				errs[i] = ctx.Err()
+				return
+			}
+
+			text, usage, err := CompleteText(ctx, s.aiClient, instruction+"\n\n"+input, s.opts.CallOptions...)
+			if err != nil {
+				errs[i] = err
+				cancel()
+				return
+			}
+			outputs[i] = strings.TrimSpace(text)
+
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			s.calls++
+			s.usage.PromptTokens += usage.PromptTokens
+			s.usage.CompletionTokens += usage.CompletionTokens
+			s.usage.TotalTokens += usage.TotalTokens
+			completed++
+			if s.opts.OnProgress != nil {
+				s.opts.OnProgress(SummarizeProgress{Stage: stage, Completed: completed, Total: len(inputs), Usage: s.usage})
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Report the call that failed rather than the cancellations it caused
+	var firstErr error
+	for _, err := range errs {
+		if err != nil && (firstErr == nil || firstErr == context.Canceled) {
+			firstErr = err
+		}
+	}
+	if firstErr != nil {
+		return nil, fmt.Errorf("summarize %s: %w", stage, firstErr)
+	}
+	return outputs, nil
+}
+
+// result fills in the summary, call count, usage, and cost.
+func (s *summarizer) result(result SummaryResult, summary string) SummaryResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result.Summary = summary
+	result.Calls = s.calls
+	result.Usage = s.usage
+	if price := s.opts.Price; price != nil {
+		result.Cost = (float64(s.usage.PromptTokens)*price.InputPerMillion + float64(s.usage.CompletionTokens)*price.OutputPerMillion) / 1e6
+	}
+	return result
+}
@@ -0,0 +1,127 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kengibson1111/go-aiprovider/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// summaryClient answers each prompt with a short OpenAI response naming the kind of call,
+// using 100 prompt and 10 completion tokens, and records the peak number of calls in
+// flight.
+type summaryClient struct {
+	stubClient
+	failOn string
+
+	mu       sync.Mutex
+	prompts  []string
+	inFlight atomic.Int32
+	peak     atomic.Int32
+}
+
+func (s *summaryClient) CallWithPrompt(ctx context.Context, prompt string, opts ...types.CallOption) ([]byte, error) {
+	n := s.inFlight.Add(1)
+	defer s.inFlight.Add(-1)
+	for {
+		peak := s.peak.Load()
+		if n <= peak || s.peak.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	s.mu.Lock()
+	s.prompts = append(s.prompts, prompt)
+	s.mu.Unlock()
+
+	if s.failOn != "" && strings.Contains(prompt, s.failOn) {
+		return nil, errors.New("upstream failure")
+	}
+	summary := "Part summary."
+	if strings.HasPrefix(prompt, defaultSummaryReduce) {
+		summary = "Combined summary."
+	} else if strings.HasPrefix(prompt, defaultSummaryPrompt) {
+		summary = "Whole summary."
+	}
+	return []byte(fmt.Sprintf(`{"choices":[{"index":0,"message":{"role":"assistant","content":%q},"finish_reason":"stop"}],"usage":{"prompt_tokens":100,"completion_tokens":10,"total_tokens":110}}`, summary)), nil
+}
+
+func longDocument(sentences int) string {
+	var b strings.Builder
+	for i := range sentences {
+		fmt.Fprintf(&b, "Sentence number %d talks about the quarterly report. ", i)
+	}
+	return b.String()
+}
+
+func TestSummarize_MapReduce(t *testing.T) {
+	aiClient := &summaryClient{}
+	var progress []SummarizeProgress
+
+	result, err := Summarize(context.Background(), aiClient, longDocument(40), SummarizeOptions{
+		ChunkTokens: 60,
+		Concurrency: 2,
+		Price:       &ModelPrice{InputPerMillion: 1, OutputPerMillion: 10},
+		OnProgress:  func(p SummarizeProgress) { progress = append(progress, p) },
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "Combined summary.", result.Summary)
+	assert.Greater(t, result.Chunks, 2)
+	assert.Equal(t, result.Chunks+1, result.Calls)
+	assert.Equal(t, types.TokenUsage{PromptTokens: 100 * result.Calls, CompletionTokens: 10 * result.Calls, TotalTokens: 110 * result.Calls}, result.Usage)
+	assert.InDelta(t, float64(result.Calls)*(100+100)/1e6, result.Cost, 1e-12)
+	assert.LessOrEqual(t, aiClient.peak.Load(), int32(2))
+
+	require.Len(t, progress, result.Calls)
+	assert.Equal(t, SummarizeProgress{Stage: SummarizeStageMap, Completed: result.Chunks, Total: result.Chunks, Usage: types.TokenUsage{PromptTokens: 100 * result.Chunks, CompletionTokens: 10 * result.Chunks, TotalTokens: 110 * result.Chunks}}, progress[result.Chunks-1])
+	last := progress[len(progress)-1]
+	assert.Equal(t, SummarizeStageReduce, last.Stage)
+	assert.Equal(t, result.Usage, last.Usage)
+}
+
+func TestSummarize_ReducesInRounds(t *testing.T) {
+	aiClient := &summaryClient{}
+
+	// Each partial summary is about 4 tokens, so 8 tokens combine two at a time
+	result, err := Summarize(context.Background(), aiClient, longDocument(8), SummarizeOptions{ChunkTokens: 8})
+	require.NoError(t, err)
+
+	assert.Equal(t, "Combined summary.", result.Summary)
+	assert.Greater(t, result.Calls, result.Chunks+1)
+}
+
+func TestSummarize_ShortText(t *testing.T) {
+	aiClient := &summaryClient{}
+
+	result, err := Summarize(context.Background(), aiClient, "A short note.", SummarizeOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, SummaryResult{Summary: "Whole summary.", Chunks: 1, Calls: 1, Usage: types.TokenUsage{PromptTokens: 100, CompletionTokens: 10, TotalTokens: 110}}, result)
+	require.Len(t, aiClient.prompts, 1)
+	assert.Equal(t, defaultSummaryPrompt+"\n\nA short note.", aiClient.prompts[0])
+}
+
+func TestSummarize_Errors(t *testing.T) {
+	_, err := Summarize(context.Background(), nil, "text", SummarizeOptions{})
+	assert.EqualError(t, err, "AI client is required")
+
+	_, err = Summarize(context.Background(), &summaryClient{}, "  ", SummarizeOptions{})
+	assert.EqualError(t, err, "text to summarize is empty")
+
+	_, err = Summarize(context.Background(), &summaryClient{}, "text", SummarizeOptions{ChunkTokens: 10, Overlap: 10})
+	assert.Error(t, err)
+
+	_, err = Summarize(context.Background(), &summaryClient{failOn: "number 7 "}, longDocument(40), SummarizeOptions{ChunkTokens: 60})
+	assert.ErrorContains(t, err, "summarize map: ")
+	assert.ErrorContains(t, err, "upstream failure")
+}
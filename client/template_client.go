@@ -0,0 +1,54 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kengibson1111/go-aiprovider/prompts"
+	"github.com/kengibson1111/go-aiprovider/types"
+)
+
+// TemplateClient pairs an AIClient with a prompt registry so that named,
+// versioned templates can be called by reference. All AIClient methods are
+// available on the TemplateClient through embedding.
+type TemplateClient struct {
+	AIClient
+	registry *prompts.Registry
+}
+
+// NewTemplateClient wraps aiClient with the given prompt registry.
+func NewTemplateClient(aiClient AIClient, registry *prompts.Registry) *TemplateClient {
+	return &TemplateClient{
+		AIClient: aiClient,
+		registry: registry,
+	}
+}
+
+// Registry returns the prompt registry used by the client.
+func (c *TemplateClient) Registry() *prompts.Registry {
+	return c.registry
+}
+
+// CallWithTemplate renders the registered template identified by ref ("name@version",
+// or "name" for the latest version) with vars and sends the result via CallWithPrompt.
+//
+// Required variables declared on the template are validated before any request is made.
+//
+// Example:
+//
+//	response, err := tc.CallWithTemplate(ctx, "code-review@v2", map[string]any{
+//		"language": "Go",
+//		"code":     source,
+//	})
+func (c *TemplateClient) CallWithTemplate(ctx context.Context, ref string, vars map[string]any, opts ...types.CallOption) ([]byte, error) {
+	if c.registry == nil {
+		return nil, fmt.Errorf("prompt registry is required")
+	}
+
+	prompt, err := c.registry.Render(ref, vars)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render template %s: %w", ref, err)
+	}
+
+	return c.CallWithPrompt(ctx, prompt, opts...)
+}
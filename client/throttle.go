@@ -0,0 +1,186 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kengibson1111/go-aiprovider/types"
+)
+
+// Default thresholds of a ThrottleClient
+const (
+	defaultThrottleMinRemainingRequests = 10
+	defaultThrottleMinRemainingTokens   = 10000
+	defaultThrottleMaxDelay             = time.Minute
+)
+
+// ThrottleOptions configures a ThrottleClient.
+type ThrottleOptions struct {
+	// MinRemainingRequests is the remaining request count below which calls are spaced
+	// out so the rest of the window lasts until it resets (default: 10).
+	MinRemainingRequests int
+
+	// MinRemainingTokens is the remaining token count below which calls wait for the
+	// token window to reset (default: 10000).
+	MinRemainingTokens int
+
+	// MaxDelay caps how long one call waits (default: 1 minute).
+	MaxDelay time.Duration
+
+	// OnThrottle, if set, receives the delay of every call that waits.
+	OnThrottle func(delay time.Duration)
+}
+
+// ThrottleClient wraps an AIClient and paces calls by the rate-limit headers of the
+// provider's responses. While the remaining requests or tokens are above the thresholds
+// calls pass through; when they run low, calls are spaced out or held until the limit
+// resets, and a Retry-After holds calls for the requested time. This slows a
+// high-throughput pipeline before it hits a burst of 429 errors. The Claude, OpenAI, and
+// Azure OpenAI clients report the headers it needs; with other clients calls pass
+// through. ValidateCredentials is passed through unchanged.
+type ThrottleClient struct {
+	AIClient
+	opts ThrottleOptions
+
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+	until    time.Time
+}
+
+// NewThrottleClient wraps aiClient with adaptive throttling. Share one ThrottleClient
+// between all goroutines that use the same API key, so they are paced together.
+//
+// Example:
+//
+//	tc, err := client.NewThrottleClient(aiClient, client.ThrottleOptions{
+//		OnThrottle: func(d time.Duration) { log.Printf("throttled for %v", d) },
+//	})
+//	for _, doc := range docs {
+//		go func() { tc.CallWithPrompt(ctx, "Classify: "+doc) }()
+//	}
+func NewThrottleClient(aiClient AIClient, opts ThrottleOptions) (*ThrottleClient, error) {
+	if aiClient == nil {
+		return nil, fmt.Errorf("AI client is required")
+	}
+	if opts.MinRemainingRequests <= 0 {
+		opts.MinRemainingRequests = defaultThrottleMinRemainingRequests
+	}
+	if opts.MinRemainingTokens <= 0 {
+		opts.MinRemainingTokens = defaultThrottleMinRemainingTokens
+	}
+	if opts.MaxDelay <= 0 {
+		opts.MaxDelay = defaultThrottleMaxDelay
+	}
+
+	return &ThrottleClient{
+		AIClient: aiClient,
+		opts:     opts,
+	}, nil
+}
+
+// CallWithPrompt waits for the pacer and sends the prompt.
+func (c *ThrottleClient) CallWithPrompt(ctx context.Context, prompt string, opts ...types.CallOption) ([]byte, error) {
+	return c.call(ctx, func(ctx context.Context) ([]byte, error) {
+		return c.AIClient.CallWithPrompt(ctx, prompt, opts...)
+	})
+}
+
+// CallWithPromptAndVariables waits for the pacer and sends the prompt.
+func (c *ThrottleClient) CallWithPromptAndVariables(ctx context.Context, prompt string, variablesJSON string, opts ...types.CallOption) ([]byte, error) {
+	return c.call(ctx, func(ctx context.Context) ([]byte, error) {
+		return c.AIClient.CallWithPromptAndVariables(ctx, prompt, variablesJSON, opts...)
+	})
+}
+
+// call waits for the call's turn, makes it, and updates the pacing from its response.
+func (c *ThrottleClient) call(ctx context.Context, call func(context.Context) ([]byte, error)) ([]byte, error) {
+	if err := c.wait(ctx); err != nil {
+		return nil, err
+	}
+
+	// Reuse the caller's ResponseMeta so it is still filled in
+	meta := types.ResponseMetaFromContext(ctx)
+	if meta == nil {
+		ctx, meta = types.WithResponseMeta(ctx)
+	}
+	response, err := call(ctx)
+	c.observe(meta)
+	return response, err
+}
+
+// wait reserves the next slot and sleeps until it.
+func (c *ThrottleClient) wait(ctx context.Context) error {
+	c.mu.Lock()
+	now := time.Now()
+	start := now
+	if c.until.After(start) {
+		start = c.until
+	}
+	if c.next.After(start) {
+		start = c.next
+	}
+	if start.Sub(now) > c.opts.MaxDelay {
+		start = now.Add(c.opts.MaxDelay)
+	}
+	c.next = start.Add(c.interval)
+	c.mu.Unlock()
+
+	delay := start.Sub(now)
+	if delay <= 0 {
+		return nil
+	}
+	if c.opts.OnThrottle != nil {
+		c.opts.OnThrottle(delay)
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// observe updates the pacing from a response's rate-limit headers. Responses without
+// them, such as from a failed connection, leave the pacing unchanged.
+func (c *ThrottleClient) observe(meta *types.ResponseMeta) {
+	if meta.StatusCode == 0 {
+		return
+	}
+	now := time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if meta.RetryAfter > 0 {
+		c.pause(now.Add(meta.RetryAfter))
+	}
+	if meta.RemainingTokens >= 0 && meta.RemainingTokens < c.opts.MinRemainingTokens && meta.ResetTokens > 0 {
+		c.pause(now.Add(meta.ResetTokens))
+	}
+	if meta.RemainingRequests < 0 {
+		return
+	}
+	switch {
+	case meta.RemainingRequests >= c.opts.MinRemainingRequests || meta.ResetRequests <= 0:
+		c.interval, c.next = 0, time.Time{}
+	case meta.RemainingRequests == 0:
+		c.interval, c.next = 0, time.Time{}
+		c.pause(now.Add(meta.ResetRequests))
+	default:
+		c.interval = meta.ResetRequests / time.Duration(meta.RemainingRequests)
+		if next := now.Add(c.interval); next.After(c.next) {
+			c.next = next
+		}
+	}
+}
+
+// pause holds calls until t, unless they are already held longer.
+func (c *ThrottleClient) pause(t time.Time) {
+	if t.After(c.until) {
+		c.until = t
+	}
+}
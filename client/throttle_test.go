@@ -0,0 +1,122 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/kengibson1111/go-aiprovider/aitest"
+	"github.com/kengibson1111/go-aiprovider/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rateLimitedReply returns an OpenAI reply with the given status and response headers.
+func rateLimitedReply(status int, headers map[string]string) aitest.Reply {
+	return aitest.Reply{Handler: func(w http.ResponseWriter, r *http.Request) {
+		for name, value := range headers {
+			w.Header().Set(name, value)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		if status != http.StatusOK {
+			io.WriteString(w, `{"error":{"type":"invalid_request_error","message":"bad input"}}`)
+			return
+		}
+		io.WriteString(w, `{"id":"c1","object":"chat.completion","created":1,"model":"gpt-4o","choices":[{"index":0,"message":{"role":"assistant","content":"ok"},"finish_reason":"stop"}]}`)
+	}}
+}
+
+func newThrottleTestClient(t *testing.T, replies ...aitest.Reply) (*ThrottleClient, *[]time.Duration) {
+	t.Helper()
+	srv := aitest.NewFakeOpenAIServer(t)
+	srv.Enqueue(replies...)
+	aiClient, err := NewClientFactory().CreateClient(srv.Config())
+	require.NoError(t, err)
+
+	var delays []time.Duration
+	tc, err := NewThrottleClient(aiClient, ThrottleOptions{
+		OnThrottle: func(d time.Duration) { delays = append(delays, d) },
+	})
+	require.NoError(t, err)
+	return tc, &delays
+}
+
+func TestThrottleClient_PassesThroughWithHeadroom(t *testing.T) {
+	headers := map[string]string{"x-ratelimit-remaining-requests": "500", "x-ratelimit-remaining-tokens": "90000", "x-ratelimit-reset-requests": "10s"}
+	tc, delays := newThrottleTestClient(t, rateLimitedReply(http.StatusOK, headers), rateLimitedReply(http.StatusOK, headers))
+
+	for range 2 {
+		_, err := tc.CallWithPrompt(context.Background(), "hello")
+		require.NoError(t, err)
+	}
+	assert.Empty(t, *delays)
+}
+
+func TestThrottleClient_SpacesCallsWhenRequestsRunLow(t *testing.T) {
+	tc, delays := newThrottleTestClient(t,
+		rateLimitedReply(http.StatusOK, map[string]string{"x-ratelimit-remaining-requests": "2", "x-ratelimit-reset-requests": "400ms"}),
+		rateLimitedReply(http.StatusOK, map[string]string{"x-ratelimit-remaining-requests": "500", "x-ratelimit-reset-requests": "10s"}),
+		rateLimitedReply(http.StatusOK, nil),
+	)
+
+	for range 3 {
+		_, err := tc.CallWithPrompt(context.Background(), "hello")
+		require.NoError(t, err)
+	}
+	require.Len(t, *delays, 1)
+	assert.Greater(t, (*delays)[0], 100*time.Millisecond)
+	assert.LessOrEqual(t, (*delays)[0], 200*time.Millisecond)
+}
+
+func TestThrottleClient_PausesUntilReset(t *testing.T) {
+	tests := []struct {
+		name  string
+		reply aitest.Reply
+	}{
+		{"requests exhausted", rateLimitedReply(http.StatusOK, map[string]string{"x-ratelimit-remaining-requests": "0", "x-ratelimit-reset-requests": "200ms"})},
+		{"tokens low", rateLimitedReply(http.StatusOK, map[string]string{"x-ratelimit-remaining-tokens": "500", "x-ratelimit-reset-tokens": "200ms"})},
+		{"retry after", rateLimitedReply(http.StatusBadRequest, map[string]string{"retry-after-ms": "200"})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tc, delays := newThrottleTestClient(t, tt.reply, rateLimitedReply(http.StatusOK, nil))
+
+			_, _ = tc.CallWithPrompt(context.Background(), "hello")
+			start := time.Now()
+			_, err := tc.CallWithPrompt(context.Background(), "hello")
+			require.NoError(t, err)
+			assert.GreaterOrEqual(t, time.Since(start), 150*time.Millisecond)
+			assert.Len(t, *delays, 1)
+		})
+	}
+}
+
+func TestThrottleClient_MaxDelayAndCancellation(t *testing.T) {
+	srv := aitest.NewFakeOpenAIServer(t)
+	srv.Enqueue(rateLimitedReply(http.StatusOK, map[string]string{"x-ratelimit-remaining-requests": "0", "x-ratelimit-reset-requests": "1h"}))
+	aiClient, err := NewClientFactory().CreateClient(srv.Config())
+	require.NoError(t, err)
+	tc, err := NewThrottleClient(aiClient, ThrottleOptions{MaxDelay: 5 * time.Second})
+	require.NoError(t, err)
+
+	// The caller's ResponseMeta is still filled in
+	ctx, meta := types.WithResponseMeta(context.Background())
+	_, err = tc.CallWithPrompt(ctx, "hello")
+	require.NoError(t, err)
+	assert.Equal(t, 0, meta.RemainingRequests)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = tc.CallWithPrompt(ctx, "hello")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Len(t, srv.Requests(), 1)
+}
+
+func TestNewThrottleClient_RequiresClient(t *testing.T) {
+	_, err := NewThrottleClient(nil, ThrottleOptions{})
+	assert.Error(t, err)
+}
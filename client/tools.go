@@ -0,0 +1,175 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/kengibson1111/go-aiprovider/types"
+)
+
+// ParseToolCalls returns the tool calls of a raw OpenAI or Claude response in the order
+// the model made them, so parallel calls can be run together. OpenAI tool_calls and
+// Claude tool_use blocks are both converted to types.ToolCall. A response without tool
+// calls returns none. For the *openai.ChatCompletion returned by CallWithTools, pass
+// []byte(completion.RawJSON()).
+//
+// Example:
+//
+//	completion, err := openaiClient.CallWithTools(ctx, "Weather in Paris and Rome?", tools,
+//		types.WithToolChoice(types.ToolChoiceRequired))
+//	if err != nil {
+//		return err
+//	}
+//	calls, err := client.ParseToolCalls([]byte(completion.RawJSON()))
+//	if err != nil {
+//		return err
+//	}
+//	if err := client.ValidateToolCalls(calls, schemas); err != nil {
+//		return err
+//	}
+//	for _, call := range calls {
+//		go run(call)
+//	}
+func ParseToolCalls(response []byte) ([]types.ToolCall, error) {
+	var parsed struct {
+		Choices []struct {
+			Message struct {
+				ToolCalls []struct {
+					ID       string `json:"id"`
+					Function struct {
+						Name      string `json:"name"`
+						Arguments string `json:"arguments"`
+					} `json:"function"`
+				} `json:"tool_calls"`
+			} `json:"message"`
+		} `json:"choices"`
+		Content []struct {
+			Type  string          `json:"type"`
+			ID    string          `json:"id"`
+			Name  string          `json:"name"`
+			Input json.RawMessage `json:"input"`
+		} `json:"content"`
+	}
+	if err := json.Unmarshal(response, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	var calls []types.ToolCall
+	if len(parsed.Choices) > 0 {
+		for _, call := range parsed.Choices[0].Message.ToolCalls {
+			calls = append(calls, types.ToolCall{ID: call.ID, Name: call.Function.Name, Arguments: call.Function.Arguments})
+		}
+	}
+	for _, block := range parsed.Content {
+		if block.Type != "tool_use" {
+			continue
+		}
+		arguments := string(block.Input)
+		if arguments == "" {
+			arguments = "{}"
+		}
+		calls = append(calls, types.ToolCall{ID: block.ID, Name: block.Name, Arguments: arguments})
+	}
+	return calls, nil
+}
+
+// ValidateToolCalls checks each call against the JSON Schema of its function's
+// parameters in schemas, keyed by function name, and returns the errors joined. Calls to
+// functions not in schemas are errors. The schema keywords checked are those of
+// JSONSchemaValidator.
+func ValidateToolCalls(calls []types.ToolCall, schemas map[string][]byte) error {
+	validators := map[string]func(string) error{}
+	var errs []error
+	for i, call := range calls {
+		validate, ok := validators[call.Name]
+		if !ok {
+			schema, known := schemas[call.Name]
+			if !known {
+				errs = append(errs, fmt.Errorf("tool call %d: unknown function %q", i+1, call.Name))
+				continue
+			}
+			var err error
+			if validate, err = JSONSchemaValidator(schema); err != nil {
+				return fmt.Errorf("function %s: %w", call.Name, err)
+			}
+			validators[call.Name] = validate
+		}
+		if err := validate(call.Arguments); err != nil {
+			errs = append(errs, fmt.Errorf("tool call %d (%s): %w", i+1, call.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// UnmarshalToolArgs decodes a tool call's arguments into a T, usually a struct with json
+// tags matching the function's parameters. Arguments that are not valid JSON, have the
+// wrong type for a field, or name a field T does not have are errors that name the
+// function and the field. Missing arguments decode as an empty object. If T or *T has a
+// Validate() error method, it is called on the result and its error returned, so checks
+// such as required fields and ranges live next to the struct.
+//
+// Example:
+//
+//	type weatherArgs struct {
+//		City string `json:"city"`
+//		Days int    `json:"days"`
+//	}
+//
+//	for _, call := range calls {
+//		args, err := client.UnmarshalToolArgs[weatherArgs](call)
+//		if err != nil {
+//			return err // e.g. tool call get_weather: argument "days" must be int, got string
+//		}
+//		forecast(args.City, args.Days)
+//	}
+func UnmarshalToolArgs[T any](call types.ToolCall) (T, error) {
+	var args T
+	arguments := strings.TrimSpace(call.Arguments)
+	if arguments == "" {
+		arguments = "{}"
+	}
+
+	dec := json.NewDecoder(strings.NewReader(arguments))
+	dec.DisallowUnknownFields()
+	err := dec.Decode(&args)
+	if err == nil && dec.More() {
+		err = errors.New("unexpected data after the arguments object")
+	}
+	if err != nil {
+		var zero T
+		return zero, fmt.Errorf("tool call %s: %w", call.Name, toolArgsError(err))
+	}
+
+	// The method set of *T includes the methods of T
+	if validator, ok := any(&args).(interface{ Validate() error }); ok {
+		if err := validator.Validate(); err != nil {
+			var zero T
+			return zero, fmt.Errorf("tool call %s: invalid arguments: %w", call.Name, err)
+		}
+	}
+	return args, nil
+}
+
+// toolArgsError rewords a decoding error in terms of the tool's arguments.
+func toolArgsError(err error) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		return fmt.Errorf("arguments are not valid JSON at offset %d: %w", syntaxErr.Offset, err)
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return fmt.Errorf("arguments are truncated JSON: %w", err)
+	case errors.As(err, &typeErr):
+		if typeErr.Field == "" {
+			return fmt.Errorf("arguments must be a JSON object, got %s", typeErr.Value)
+		}
+		return fmt.Errorf("argument %q must be %s, got %s", typeErr.Field, typeErr.Type, typeErr.Value)
+	}
+	if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		return fmt.Errorf("unknown argument %s", field)
+	}
+	return err
+}
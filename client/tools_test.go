@@ -0,0 +1,129 @@
+package client
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kengibson1111/go-aiprovider/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseToolCalls(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		expected []types.ToolCall
+	}{
+		{
+			name: "OpenAI parallel calls",
+			response: `{"choices":[{"index":0,"message":{"role":"assistant","content":null,"tool_calls":[
+				{"id":"call_1","type":"function","function":{"name":"get_weather","arguments":"{\"city\":\"Paris\"}"}},
+				{"id":"call_2","type":"function","function":{"name":"get_weather","arguments":"{\"city\":\"Rome\"}"}}
+			]},"finish_reason":"tool_calls"}]}`,
+			expected: []types.ToolCall{
+				{ID: "call_1", Name: "get_weather", Arguments: `{"city":"Paris"}`},
+				{ID: "call_2", Name: "get_weather", Arguments: `{"city":"Rome"}`},
+			},
+		},
+		{
+			name: "Claude tool_use blocks",
+			response: `{"content":[
+				{"type":"text","text":"Checking both."},
+				{"type":"tool_use","id":"toolu_1","name":"get_weather","input":{"city":"Paris"}},
+				{"type":"tool_use","id":"toolu_2","name":"get_time","input":{}}
+			],"stop_reason":"tool_use"}`,
+			expected: []types.ToolCall{
+				{ID: "toolu_1", Name: "get_weather", Arguments: `{"city":"Paris"}`},
+				{ID: "toolu_2", Name: "get_time", Arguments: `{}`},
+			},
+		},
+		{
+			name:     "no tool calls",
+			response: `{"choices":[{"index":0,"message":{"role":"assistant","content":"Sunny."},"finish_reason":"stop"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls, err := ParseToolCalls([]byte(tt.response))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, calls)
+		})
+	}
+
+	_, err := ParseToolCalls([]byte("not json"))
+	assert.Error(t, err)
+}
+
+func TestValidateToolCalls(t *testing.T) {
+	schemas := map[string][]byte{
+		"get_weather": []byte(`{"type":"object","properties":{"city":{"type":"string"},"days":{"type":"integer","maximum":7}},"required":["city"]}`),
+	}
+
+	assert.NoError(t, ValidateToolCalls([]types.ToolCall{
+		{Name: "get_weather", Arguments: `{"city":"Paris"}`},
+		{Name: "get_weather", Arguments: `{"city":"Rome","days":3}`},
+	}, schemas))
+
+	err := ValidateToolCalls([]types.ToolCall{
+		{Name: "get_weather", Arguments: `{"city":"Paris"}`},
+		{Name: "get_weather", Arguments: `{"days":10}`},
+		{Name: "delete_files", Arguments: `{}`},
+	}, schemas)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `tool call 2 (get_weather): $: missing required property "city"`)
+	assert.Contains(t, err.Error(), "$.days: 10 is greater than the maximum 7")
+	assert.Contains(t, err.Error(), `tool call 3: unknown function "delete_files"`)
+	assert.NotContains(t, err.Error(), "tool call 1")
+
+	err = ValidateToolCalls([]types.ToolCall{{Name: "broken"}}, map[string][]byte{"broken": []byte("{")})
+	assert.ErrorContains(t, err, "function broken: invalid JSON schema")
+}
+
+type weatherArgs struct {
+	City string `json:"city"`
+	Days int    `json:"days"`
+}
+
+func (a weatherArgs) Validate() error {
+	if a.City == "" {
+		return errors.New("city is required")
+	}
+	return nil
+}
+
+func TestUnmarshalToolArgs(t *testing.T) {
+	args, err := UnmarshalToolArgs[weatherArgs](types.ToolCall{Name: "get_weather", Arguments: `{"city":"Paris","days":3}`})
+	require.NoError(t, err)
+	assert.Equal(t, weatherArgs{City: "Paris", Days: 3}, args)
+
+	tests := []struct {
+		name      string
+		arguments string
+		expected  string
+	}{
+		{"wrong type", `{"city":"Paris","days":"three"}`, `tool call get_weather: argument "days" must be int, got string`},
+		{"unknown field", `{"city":"Paris","units":"metric"}`, `tool call get_weather: unknown argument "units"`},
+		{"invalid JSON", `{"city":'Paris'}`, "tool call get_weather: arguments are not valid JSON at offset 9"},
+		{"truncated", `{"city":"Par`, "tool call get_weather: arguments are truncated JSON"},
+		{"not an object", `["Paris"]`, "tool call get_weather: arguments must be a JSON object, got array"},
+		{"trailing data", `{"city":"Paris"} {}`, "tool call get_weather: unexpected data after the arguments object"},
+		{"failed validation", `{"days":3}`, "tool call get_weather: invalid arguments: city is required"},
+		{"missing arguments", ``, "tool call get_weather: invalid arguments: city is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args, err := UnmarshalToolArgs[weatherArgs](types.ToolCall{Name: "get_weather", Arguments: tt.arguments})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expected)
+			assert.Equal(t, weatherArgs{}, args)
+		})
+	}
+
+	// Types without a Validate method are only decoded
+	raw, err := UnmarshalToolArgs[map[string]any](types.ToolCall{Name: "lookup", Arguments: `{"id":7}`})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"id": float64(7)}, raw)
+}
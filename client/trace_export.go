@@ -0,0 +1,305 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// Default endpoints of the hosted trace services
+const (
+	defaultLangfuseHost     = "https://cloud.langfuse.com"
+	defaultLangSmithAPIURL  = "https://api.smith.langchain.com"
+	defaultLangSmithProject = "default"
+)
+
+// LangfuseOptions configures a Langfuse trace exporter.
+type LangfuseOptions struct {
+	PublicKey string
+	SecretKey string
+
+	// Host is the Langfuse base URL (default: https://cloud.langfuse.com).
+	Host string
+
+	// HTTPClient sends the requests (default: a client with a 10 second timeout).
+	HTTPClient *http.Client
+}
+
+// LangSmithOptions configures a LangSmith trace exporter.
+type LangSmithOptions struct {
+	APIKey string
+
+	// Endpoint is the LangSmith API URL (default: https://api.smith.langchain.com).
+	Endpoint string
+
+	// Project is the project (session) the runs are filed under (default: "default").
+	Project string
+
+	// HTTPClient sends the requests (default: a client with a 10 second timeout).
+	HTTPClient *http.Client
+}
+
+// langfuseSink exports audit records to the Langfuse ingestion API.
+type langfuseSink struct {
+	opts LangfuseOptions
+}
+
+// NewLangfuseSink returns an AuditSink that sends each record to Langfuse as a trace with
+// one generation, through the public ingestion API. Use it with NewAuditClient, whose
+// Content and ScrubPatterns settings decide which prompt and response text is exported.
+func NewLangfuseSink(opts LangfuseOptions) (AuditSink, error) {
+	if opts.PublicKey == "" || opts.SecretKey == "" {
+		return nil, fmt.Errorf("Langfuse public and secret keys are required")
+	}
+	if opts.Host == "" {
+		opts.Host = defaultLangfuseHost
+	}
+	opts.Host = strings.TrimRight(opts.Host, "/")
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &langfuseSink{opts: opts}, nil
+}
+
+// WriteAudit posts a trace-create and a generation-create event for the record.
+func (s *langfuseSink) WriteAudit(ctx context.Context, record AuditRecord) error {
+	traceID := newTraceID()
+	end := record.Time.Add(record.Duration)
+	input, output := traceInput(record), traceOutput(record)
+	metadata := traceMetadata(record)
+
+	generation := map[string]any{
+		"id":        newTraceID(),
+		"traceId":   traceID,
+		"name":      record.Method,
+		"model":     record.Model,
+		"startTime": record.Time.UTC().Format(time.RFC3339Nano),
+		"endTime":   end.UTC().Format(time.RFC3339Nano),
+		"input":     input,
+		"output":    output,
+		"metadata":  metadata,
+		"usage": map[string]int{
+			"input":  record.PromptTokens,
+			"output": record.CompletionTokens,
+			"total":  record.PromptTokens + record.CompletionTokens,
+		},
+	}
+	if record.Error != "" {
+		generation["level"] = "ERROR"
+		generation["statusMessage"] = record.Error
+	}
+
+	batch := map[string]any{
+		"batch": []map[string]any{
+			{
+				"id":        newTraceID(),
+				"type":      "trace-create",
+				"timestamp": record.Time.UTC().Format(time.RFC3339Nano),
+				"body": map[string]any{
+					"id":        traceID,
+					"name":      record.Method,
+					"timestamp": record.Time.UTC().Format(time.RFC3339Nano),
+					"input":     input,
+					"output":    output,
+					"metadata":  metadata,
+				},
+			},
+			{
+				"id":        newTraceID(),
+				"type":      "generation-create",
+				"timestamp": end.UTC().Format(time.RFC3339Nano),
+				"body":      generation,
+			},
+		},
+	}
+
+	return postTrace(ctx, s.opts.HTTPClient, s.opts.Host+"/api/public/ingestion", batch, func(req *http.Request) {
+		req.SetBasicAuth(s.opts.PublicKey, s.opts.SecretKey)
+	})
+}
+
+// langSmithSink exports audit records to the LangSmith runs API.
+type langSmithSink struct {
+	opts LangSmithOptions
+}
+
+// NewLangSmithSink returns an AuditSink that sends each record to LangSmith as an "llm"
+// run. Use it with NewAuditClient, whose Content and ScrubPatterns settings decide which
+// prompt and response text is exported.
+func NewLangSmithSink(opts LangSmithOptions) (AuditSink, error) {
+	if opts.APIKey == "" {
+		return nil, fmt.Errorf("LangSmith API key is required")
+	}
+	if opts.Endpoint == "" {
+		opts.Endpoint = defaultLangSmithAPIURL
+	}
+	opts.Endpoint = strings.TrimRight(opts.Endpoint, "/")
+	if opts.Project == "" {
+		opts.Project = defaultLangSmithProject
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &langSmithSink{opts: opts}, nil
+}
+
+// WriteAudit posts a completed run for the record.
+func (s *langSmithSink) WriteAudit(ctx context.Context, record AuditRecord) error {
+	outputs := traceOutput(record)
+	if record.PromptTokens > 0 || record.CompletionTokens > 0 {
+		outputs["usage_metadata"] = map[string]int{
+			"input_tokens":  record.PromptTokens,
+			"output_tokens": record.CompletionTokens,
+			"total_tokens":  record.PromptTokens + record.CompletionTokens,
+		}
+	}
+
+	metadata := map[string]any{
+		"ls_model_name": record.Model,
+		"labels":        record.Labels,
+	}
+	for key, value := range tagMetadata(record.Tags) {
+		metadata[key] = value
+	}
+
+	run := map[string]any{
+		"id":           newTraceID(),
+		"name":         record.Method,
+		"run_type":     "llm",
+		"session_name": s.opts.Project,
+		"start_time":   record.Time.UTC().Format(time.RFC3339Nano),
+		"end_time":     record.Time.Add(record.Duration).UTC().Format(time.RFC3339Nano),
+		"inputs":       traceInput(record),
+		"outputs":      outputs,
+		"extra": map[string]any{
+			"metadata": metadata,
+		},
+	}
+	if record.Error != "" {
+		run["error"] = record.Error
+	}
+
+	return postTrace(ctx, s.opts.HTTPClient, s.opts.Endpoint+"/runs", run, func(req *http.Request) {
+		req.Header.Set("x-api-key", s.opts.APIKey)
+	})
+}
+
+// TraceSinkFromEnv returns a Langfuse or LangSmith sink configured from the standard
+// environment variables of those tools, so tracing can be switched on by deployment
+// configuration:
+//
+//   - LANGFUSE_PUBLIC_KEY, LANGFUSE_SECRET_KEY, LANGFUSE_HOST
+//   - LANGSMITH_API_KEY, LANGSMITH_ENDPOINT, LANGSMITH_PROJECT
+//
+// Langfuse is used when both are configured. It returns nil when neither is configured.
+func TraceSinkFromEnv() (AuditSink, error) {
+	if publicKey := os.Getenv("LANGFUSE_PUBLIC_KEY"); publicKey != "" {
+		return NewLangfuseSink(LangfuseOptions{
+			PublicKey: publicKey,
+			SecretKey: os.Getenv("LANGFUSE_SECRET_KEY"),
+			Host:      os.Getenv("LANGFUSE_HOST"),
+		})
+	}
+	if apiKey := os.Getenv("LANGSMITH_API_KEY"); apiKey != "" {
+		return NewLangSmithSink(LangSmithOptions{
+			APIKey:   apiKey,
+			Endpoint: os.Getenv("LANGSMITH_ENDPOINT"),
+			Project:  os.Getenv("LANGSMITH_PROJECT"),
+		})
+	}
+	return nil, nil
+}
+
+// traceMetadata returns the record's labels and tags as Langfuse metadata.
+func traceMetadata(record AuditRecord) map[string]string {
+	metadata := tagMetadata(record.Tags)
+	for key, value := range record.Labels {
+		metadata[key] = value
+	}
+	return metadata
+}
+
+// tagMetadata returns the tags that are set, keyed by their JSON names.
+func tagMetadata(tags UsageTags) map[string]string {
+	metadata := map[string]string{}
+	for key, value := range map[string]string{
+		"tenant":     tags.Tenant,
+		"template":   tags.Template,
+		"experiment": tags.Experiment,
+		"variant":    tags.Variant,
+	} {
+		if value != "" {
+			metadata[key] = value
+		}
+	}
+	return metadata
+}
+
+// traceInput returns the recorded prompt, or its hash when text was not recorded.
+func traceInput(record AuditRecord) map[string]any {
+	input := map[string]any{}
+	switch {
+	case record.Prompt != "":
+		input["prompt"] = record.Prompt
+		if record.Variables != "" {
+			input["variables"] = record.Variables
+		}
+	case record.PromptHash != "":
+		input["promptHash"] = record.PromptHash
+	}
+	return input
+}
+
+// traceOutput returns the recorded response, or its hash when text was not recorded.
+func traceOutput(record AuditRecord) map[string]any {
+	output := map[string]any{}
+	switch {
+	case record.Response != "":
+		output["response"] = record.Response
+	case record.ResponseHash != "":
+		output["responseHash"] = record.ResponseHash
+	}
+	return output
+}
+
+// postTrace posts a JSON payload to a trace service.
+func postTrace(ctx context.Context, httpClient *http.Client, url string, payload any, authorize func(*http.Request)) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	authorize(req)
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("trace export to %s returned HTTP %d", url, resp.StatusCode)
+	}
+	return nil
+}
+
+// newTraceID returns a random version 4 UUID.
+func newTraceID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
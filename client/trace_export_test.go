@@ -0,0 +1,120 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testTraceRecord = AuditRecord{
+	Time:             time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	Method:           "CallWithPrompt",
+	Model:            "gpt-4o-mini",
+	Duration:         1500 * time.Millisecond,
+	Prompt:           "Summarize [EMAIL]'s ticket",
+	Response:         "Done.",
+	PromptTokens:     12,
+	CompletionTokens: 3,
+	Labels:           map[string]string{"app": "support"},
+	Tags:             UsageTags{Template: "summarize@1.2.0", Experiment: "tone", Variant: "b"},
+}
+
+// traceServer captures the last request body and headers it received.
+func traceServer(t *testing.T, status int) (*httptest.Server, *map[string]any, *http.Header) {
+	var body map[string]any
+	var header http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		body = map[string]any{"path": r.URL.Path}
+		var payload map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		body["payload"] = payload
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &body, &header
+}
+
+func TestLangfuseSink(t *testing.T) {
+	srv, body, header := traceServer(t, http.StatusMultiStatus)
+	sink, err := NewLangfuseSink(LangfuseOptions{PublicKey: "pk-lf-1", SecretKey: "sk-lf-1", Host: srv.URL + "/"})
+	require.NoError(t, err)
+
+	require.NoError(t, sink.WriteAudit(context.Background(), testTraceRecord))
+
+	assert.Equal(t, "/api/public/ingestion", (*body)["path"])
+	assert.Contains(t, header.Get("Authorization"), "Basic ")
+	batch := (*body)["payload"].(map[string]any)["batch"].([]any)
+	require.Len(t, batch, 2)
+	trace := batch[0].(map[string]any)
+	generation := batch[1].(map[string]any)
+	assert.Equal(t, "trace-create", trace["type"])
+	assert.Equal(t, "generation-create", generation["type"])
+
+	genBody := generation["body"].(map[string]any)
+	assert.Equal(t, trace["body"].(map[string]any)["id"], genBody["traceId"])
+	assert.Equal(t, "gpt-4o-mini", genBody["model"])
+	assert.Equal(t, "Done.", genBody["output"].(map[string]any)["response"])
+	assert.Equal(t, float64(15), genBody["usage"].(map[string]any)["total"])
+	assert.Equal(t, map[string]any{"app": "support", "template": "summarize@1.2.0", "experiment": "tone", "variant": "b"}, genBody["metadata"])
+}
+
+func TestLangSmithSink(t *testing.T) {
+	srv, body, header := traceServer(t, http.StatusAccepted)
+	sink, err := NewLangSmithSink(LangSmithOptions{APIKey: "lsv2-key", Endpoint: srv.URL, Project: "support"})
+	require.NoError(t, err)
+
+	record := testTraceRecord
+	record.Error = "rate limited"
+	require.NoError(t, sink.WriteAudit(context.Background(), record))
+
+	assert.Equal(t, "/runs", (*body)["path"])
+	assert.Equal(t, "lsv2-key", header.Get("x-api-key"))
+	run := (*body)["payload"].(map[string]any)
+	assert.Equal(t, "llm", run["run_type"])
+	assert.Equal(t, "support", run["session_name"])
+	assert.Equal(t, "rate limited", run["error"])
+	assert.Equal(t, "Summarize [EMAIL]'s ticket", run["inputs"].(map[string]any)["prompt"])
+	assert.Equal(t, "2026-01-02T03:04:06.5Z", run["end_time"])
+	metadata := run["extra"].(map[string]any)["metadata"].(map[string]any)
+	assert.Equal(t, "gpt-4o-mini", metadata["ls_model_name"])
+	assert.Equal(t, "tone", metadata["experiment"])
+	assert.Equal(t, "b", metadata["variant"])
+}
+
+func TestTraceSinks_Errors(t *testing.T) {
+	_, err := NewLangfuseSink(LangfuseOptions{PublicKey: "pk"})
+	assert.Error(t, err)
+	_, err = NewLangSmithSink(LangSmithOptions{})
+	assert.Error(t, err)
+
+	srv, _, _ := traceServer(t, http.StatusUnauthorized)
+	sink, err := NewLangSmithSink(LangSmithOptions{APIKey: "bad", Endpoint: srv.URL})
+	require.NoError(t, err)
+	assert.ErrorContains(t, sink.WriteAudit(context.Background(), testTraceRecord), "HTTP 401")
+}
+
+func TestTraceSinkFromEnv(t *testing.T) {
+	t.Setenv("LANGFUSE_PUBLIC_KEY", "")
+	t.Setenv("LANGSMITH_API_KEY", "")
+	sink, err := TraceSinkFromEnv()
+	require.NoError(t, err)
+	assert.Nil(t, sink)
+
+	t.Setenv("LANGSMITH_API_KEY", "lsv2-key")
+	sink, err = TraceSinkFromEnv()
+	require.NoError(t, err)
+	assert.IsType(t, &langSmithSink{}, sink)
+
+	t.Setenv("LANGFUSE_PUBLIC_KEY", "pk-lf")
+	t.Setenv("LANGFUSE_SECRET_KEY", "sk-lf")
+	sink, err = TraceSinkFromEnv()
+	require.NoError(t, err)
+	assert.IsType(t, &langfuseSink{}, sink)
+}
@@ -0,0 +1,214 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/kengibson1111/go-aiprovider/types"
+)
+
+// defaultUsageSubject is the topic or subject events are published to when
+// UsageOptions.Subject is empty.
+const defaultUsageSubject = "aiprovider.usage"
+
+// UsageEvent is the per-call record published by a UsageClient.
+type UsageEvent struct {
+	Time             time.Time `json:"time"`
+	Method           string    `json:"method"`
+	Model            string    `json:"model,omitempty"`
+	Tenant           string    `json:"tenant,omitempty"`
+	Template         string    `json:"template,omitempty"`
+	Experiment       string    `json:"experiment,omitempty"`
+	Variant          string    `json:"variant,omitempty"`
+	PromptTokens     int       `json:"promptTokens"`
+	CompletionTokens int       `json:"completionTokens"`
+	TotalTokens      int       `json:"totalTokens"`
+
+	// Cost is the estimated cost in US dollars, zero when the model has no price.
+	Cost float64 `json:"cost,omitempty"`
+
+	LatencyMs int64  `json:"latencyMs"`
+	Error     string `json:"error,omitempty"`
+}
+
+// ModelPrice is the price of a model in US dollars per million tokens.
+type ModelPrice struct {
+	InputPerMillion  float64
+	OutputPerMillion float64
+}
+
+// UsagePublisher sends encoded events to an event pipeline. subject is the Kafka topic or
+// NATS subject. Implement it with your Kafka producer or NATS connection:
+//
+//	type natsPublisher struct{ nc *nats.Conn }
+//
+//	func (p natsPublisher) Publish(ctx context.Context, subject string, payload []byte) error {
+//		return p.nc.Publish(subject, payload)
+//	}
+type UsagePublisher interface {
+	Publish(ctx context.Context, subject string, payload []byte) error
+}
+
+// UsagePublisherFunc adapts a function to a UsagePublisher.
+type UsagePublisherFunc func(ctx context.Context, subject string, payload []byte) error
+
+// Publish calls f.
+func (f UsagePublisherFunc) Publish(ctx context.Context, subject string, payload []byte) error {
+	return f(ctx, subject, payload)
+}
+
+// UsageTags identify the tenant, prompt template, and experiment of a call in its
+// UsageEvent and AuditRecord.
+type UsageTags struct {
+	Tenant string `json:"tenant,omitempty"`
+
+	// Template identifies the prompt and its version, such as a prompts.Template Ref
+	// ("summarize@1.2.0").
+	Template string `json:"template,omitempty"`
+
+	// Experiment and Variant identify the A/B test the call belongs to and the prompt
+	// variant it used, for comparison with SummarizeExperiment.
+	Experiment string `json:"experiment,omitempty"`
+	Variant    string `json:"variant,omitempty"`
+}
+
+// usageTagsKey is the context key for UsageTags
+type usageTagsKey struct{}
+
+// WithUsageTags returns a context whose calls through a UsageClient or AuditClient are
+// tagged with tags.
+// Empty fields fall back to UsageOptions.Tenant.
+func WithUsageTags(ctx context.Context, tags UsageTags) context.Context {
+	return context.WithValue(ctx, usageTagsKey{}, tags)
+}
+
+// UsageOptions configures a UsageClient.
+type UsageOptions struct {
+	// Publisher receives an event for every call. Required.
+	Publisher UsagePublisher
+
+	// Subject is the topic or subject to publish to (default: "aiprovider.usage").
+	Subject string
+
+	// Tenant is used for calls whose context has no tenant tag.
+	Tenant string
+
+	// Prices maps model names to prices used to estimate Cost.
+	Prices map[string]ModelPrice
+
+	// OnError, if set, receives publish errors. Publish errors never fail the call.
+	OnError func(error)
+}
+
+// UsageClient wraps an AIClient and publishes a UsageEvent with token counts, cost, and
+// latency for every prompt call. ValidateCredentials is passed through unchanged.
+type UsageClient struct {
+	AIClient
+	opts UsageOptions
+}
+
+// NewUsageClient wraps aiClient so every call publishes a UsageEvent as JSON.
+//
+// Example:
+//
+//	uc, err := client.NewUsageClient(aiClient, client.UsageOptions{
+//		Publisher: natsPublisher{nc},
+//		Subject:   "ai.usage",
+//		Prices:    map[string]client.ModelPrice{"gpt-4o-mini": {InputPerMillion: 0.15, OutputPerMillion: 0.60}},
+//	})
+//	ctx = client.WithUsageTags(ctx, client.UsageTags{Tenant: "acme", Template: "summarize@1.2.0"})
+//	response, err := uc.CallWithPrompt(ctx, prompt)
+func NewUsageClient(aiClient AIClient, opts UsageOptions) (*UsageClient, error) {
+	if aiClient == nil {
+		return nil, fmt.Errorf("AI client is required")
+	}
+	if opts.Publisher == nil {
+		return nil, fmt.Errorf("usage publisher is required")
+	}
+	if opts.Subject == "" {
+		opts.Subject = defaultUsageSubject
+	}
+
+	return &UsageClient{
+		AIClient: aiClient,
+		opts:     opts,
+	}, nil
+}
+
+// CallWithPrompt sends the prompt and publishes its usage.
+func (c *UsageClient) CallWithPrompt(ctx context.Context, prompt string, opts ...types.CallOption) ([]byte, error) {
+	start := time.Now()
+	response, err := c.AIClient.CallWithPrompt(ctx, prompt, opts...)
+	c.publish(ctx, "CallWithPrompt", opts, start, response, err)
+	return response, err
+}
+
+// CallWithPromptAndVariables renders and sends the prompt and publishes its usage.
+func (c *UsageClient) CallWithPromptAndVariables(ctx context.Context, prompt string, variablesJSON string, opts ...types.CallOption) ([]byte, error) {
+	start := time.Now()
+	response, err := c.AIClient.CallWithPromptAndVariables(ctx, prompt, variablesJSON, opts...)
+	c.publish(ctx, "CallWithPromptAndVariables", opts, start, response, err)
+	return response, err
+}
+
+// publish builds the event for a call and sends it to the publisher.
+func (c *UsageClient) publish(ctx context.Context, method string, opts []types.CallOption, start time.Time, response []byte, callErr error) {
+	event := UsageEvent{
+		Time:      start,
+		Method:    method,
+		Model:     types.ResolveCallOptions(types.CallOptions{}, opts).Model,
+		Tenant:    c.opts.Tenant,
+		LatencyMs: time.Since(start).Milliseconds(),
+	}
+	if tags, ok := ctx.Value(usageTagsKey{}).(UsageTags); ok {
+		if tags.Tenant != "" {
+			event.Tenant = tags.Tenant
+		}
+		event.Template = tags.Template
+		event.Experiment = tags.Experiment
+		event.Variant = tags.Variant
+	}
+
+	if callErr != nil {
+		event.Error = callErr.Error()
+	} else {
+		model, promptTokens, completionTokens := responseUsage(response)
+		if model != "" {
+			event.Model = model
+		}
+		event.PromptTokens = promptTokens
+		event.CompletionTokens = completionTokens
+		event.TotalTokens = promptTokens + completionTokens
+		if price, ok := c.opts.Prices[event.Model]; ok {
+			event.Cost = (float64(promptTokens)*price.InputPerMillion + float64(completionTokens)*price.OutputPerMillion) / 1e6
+		}
+	}
+
+	payload, err := json.Marshal(event)
+	if err == nil {
+		err = c.opts.Publisher.Publish(context.WithoutCancel(ctx), c.opts.Subject, payload)
+	}
+	if err != nil && c.opts.OnError != nil {
+		c.opts.OnError(fmt.Errorf("usage publish failed: %w", err))
+	}
+}
+
+// responseUsage returns the model and token counts of a raw OpenAI or Claude response.
+// Unrecognized responses report zero tokens.
+func responseUsage(response []byte) (string, int, int) {
+	var parsed struct {
+		Model string `json:"model"`
+		Usage struct {
+			PromptTokens     int `json:"prompt_tokens"`
+			CompletionTokens int `json:"completion_tokens"`
+			InputTokens      int `json:"input_tokens"`
+			OutputTokens     int `json:"output_tokens"`
+		} `json:"usage"`
+	}
+	if err := json.Unmarshal(response, &parsed); err != nil {
+		return "", 0, 0
+	}
+	return parsed.Model, parsed.Usage.PromptTokens + parsed.Usage.InputTokens, parsed.Usage.CompletionTokens + parsed.Usage.OutputTokens
+}
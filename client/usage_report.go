@@ -0,0 +1,198 @@
+package client
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// UsageSummary totals the usage of one model on one UTC day, the granularity of the
+// OpenAI and Anthropic usage and cost exports, so internal records can be reconciled
+// against provider invoices.
+type UsageSummary struct {
+	// Date is the UTC day in YYYY-MM-DD format.
+	Date  string `json:"date"`
+	Model string `json:"model"`
+
+	Requests int `json:"requests"`
+
+	// FailedRequests counts calls that returned an error. Their tokens are not billed and
+	// are not included in the token totals.
+	FailedRequests int `json:"failedRequests"`
+
+	PromptTokens     int     `json:"promptTokens"`
+	CompletionTokens int     `json:"completionTokens"`
+	TotalTokens      int     `json:"totalTokens"`
+	Cost             float64 `json:"cost"`
+}
+
+// usageCSVHeader is the header row written by WriteUsageCSV.
+var usageCSVHeader = []string{"date", "model", "requests", "failed_requests", "input_tokens", "output_tokens", "total_tokens", "cost_usd"}
+
+// ReadUsageEvents reads UsageEvents from JSON lines, as consumed from the topic a
+// UsageClient publishes to. Blank lines are skipped.
+func ReadUsageEvents(r io.Reader) ([]UsageEvent, error) {
+	var events []UsageEvent
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var event UsageEvent
+		if err := json.Unmarshal([]byte(text), &event); err != nil {
+			return nil, fmt.Errorf("failed to parse usage event on line %d: %w", line, err)
+		}
+		events = append(events, event)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read usage events: %w", err)
+	}
+	return events, nil
+}
+
+// SummarizeUsage aggregates usage events per UTC day and model, sorted by date and then
+// model.
+//
+// Example:
+//
+//	events, err := client.ReadUsageEvents(f)
+//	if err != nil {
+//		return err
+//	}
+//	err = client.WriteUsageCSV(os.Stdout, client.SummarizeUsage(events))
+func SummarizeUsage(events []UsageEvent) []UsageSummary {
+	type key struct{ date, model string }
+	totals := map[key]*UsageSummary{}
+	for _, event := range events {
+		k := key{date: event.Time.UTC().Format("2006-01-02"), model: event.Model}
+		summary, ok := totals[k]
+		if !ok {
+			summary = &UsageSummary{Date: k.date, Model: k.model}
+			totals[k] = summary
+		}
+
+		summary.Requests++
+		if event.Error != "" {
+			summary.FailedRequests++
+			continue
+		}
+		summary.PromptTokens += event.PromptTokens
+		summary.CompletionTokens += event.CompletionTokens
+		summary.TotalTokens += event.TotalTokens
+		summary.Cost += event.Cost
+	}
+
+	summaries := make([]UsageSummary, 0, len(totals))
+	for _, summary := range totals {
+		summaries = append(summaries, *summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].Date != summaries[j].Date {
+			return summaries[i].Date < summaries[j].Date
+		}
+		return summaries[i].Model < summaries[j].Model
+	})
+	return summaries
+}
+
+// VariantSummary totals the usage of one variant of an experiment.
+type VariantSummary struct {
+	Variant string `json:"variant"`
+
+	Requests       int `json:"requests"`
+	FailedRequests int `json:"failedRequests"`
+
+	// ErrorRate is FailedRequests divided by Requests.
+	ErrorRate float64 `json:"errorRate"`
+
+	// AvgLatencyMs, AvgTotalTokens, and AvgCost average over successful requests.
+	AvgLatencyMs   float64 `json:"avgLatencyMs"`
+	AvgTotalTokens float64 `json:"avgTotalTokens"`
+	AvgCost        float64 `json:"avgCost"`
+
+	TotalTokens int     `json:"totalTokens"`
+	Cost        float64 `json:"cost"`
+}
+
+// SummarizeExperiment compares the variants of one experiment, tagged with
+// WithUsageTags, from usage events. Events of other experiments are ignored. Summaries
+// are sorted by variant.
+//
+// Example:
+//
+//	ctx = client.WithUsageTags(ctx, client.UsageTags{Template: "summarize@1.3.0", Experiment: "summary-tone", Variant: "b"})
+//	// ... later, from the collected events:
+//	for _, v := range client.SummarizeExperiment(events, "summary-tone") {
+//		fmt.Printf("%s: %.0f ms, %.0f tokens, $%.5f per call\n", v.Variant, v.AvgLatencyMs, v.AvgTotalTokens, v.AvgCost)
+//	}
+func SummarizeExperiment(events []UsageEvent, experiment string) []VariantSummary {
+	totals := map[string]*VariantSummary{}
+	latency := map[string]int64{}
+	for _, event := range events {
+		if event.Experiment != experiment {
+			continue
+		}
+		summary, ok := totals[event.Variant]
+		if !ok {
+			summary = &VariantSummary{Variant: event.Variant}
+			totals[event.Variant] = summary
+		}
+		summary.Requests++
+		if event.Error != "" {
+			summary.FailedRequests++
+			continue
+		}
+		latency[event.Variant] += event.LatencyMs
+		summary.TotalTokens += event.TotalTokens
+		summary.Cost += event.Cost
+	}
+
+	summaries := make([]VariantSummary, 0, len(totals))
+	for variant, summary := range totals {
+		summary.ErrorRate = float64(summary.FailedRequests) / float64(summary.Requests)
+		if succeeded := summary.Requests - summary.FailedRequests; succeeded > 0 {
+			summary.AvgLatencyMs = float64(latency[variant]) / float64(succeeded)
+			summary.AvgTotalTokens = float64(summary.TotalTokens) / float64(succeeded)
+			summary.AvgCost = summary.Cost / float64(succeeded)
+		}
+		summaries = append(summaries, *summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].Variant < summaries[j].Variant
+	})
+	return summaries
+}
+
+// WriteUsageCSV writes usage summaries as CSV with a header row. Token columns are named
+// input_tokens and output_tokens as in the provider exports, and cost_usd has six decimal
+// places.
+func WriteUsageCSV(w io.Writer, summaries []UsageSummary) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(usageCSVHeader); err != nil {
+		return err
+	}
+	for _, s := range summaries {
+		record := []string{
+			s.Date,
+			s.Model,
+			strconv.Itoa(s.Requests),
+			strconv.Itoa(s.FailedRequests),
+			strconv.Itoa(s.PromptTokens),
+			strconv.Itoa(s.CompletionTokens),
+			strconv.Itoa(s.TotalTokens),
+			strconv.FormatFloat(s.Cost, 'f', 6, 64),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
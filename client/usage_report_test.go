@@ -0,0 +1,72 @@
+package client
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const usageLog = `{"time":"2026-03-01T23:59:00-02:00","method":"CallWithPrompt","model":"gpt-4o","promptTokens":100,"completionTokens":50,"totalTokens":150,"cost":0.00075,"latencyMs":900}
+{"time":"2026-03-01T10:00:00Z","method":"CallWithPrompt","model":"claude-sonnet-4-6","promptTokens":10,"completionTokens":5,"totalTokens":15,"latencyMs":300}
+
+{"time":"2026-03-02T08:00:00Z","method":"CallWithPrompt","model":"gpt-4o","promptTokens":200,"completionTokens":100,"totalTokens":300,"cost":0.0015,"latencyMs":1200}
+{"time":"2026-03-02T09:00:00Z","method":"CallWithPrompt","model":"gpt-4o","latencyMs":100,"error":"rate limited"}
+`
+
+func TestSummarizeUsage(t *testing.T) {
+	events, err := ReadUsageEvents(strings.NewReader(usageLog))
+	require.NoError(t, err)
+	require.Len(t, events, 4)
+
+	summaries := SummarizeUsage(events)
+	assert.Equal(t, []UsageSummary{
+		{Date: "2026-03-01", Model: "claude-sonnet-4-6", Requests: 1, PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15},
+		{Date: "2026-03-02", Model: "gpt-4o", Requests: 3, FailedRequests: 1, PromptTokens: 300, CompletionTokens: 150, TotalTokens: 450, Cost: 0.00225},
+	}, roundCosts(summaries))
+
+	var b strings.Builder
+	require.NoError(t, WriteUsageCSV(&b, summaries))
+	assert.Equal(t, "date,model,requests,failed_requests,input_tokens,output_tokens,total_tokens,cost_usd\n"+
+		"2026-03-01,claude-sonnet-4-6,1,0,10,5,15,0.000000\n"+
+		"2026-03-02,gpt-4o,3,1,300,150,450,0.002250\n", b.String())
+}
+
+func TestSummarizeExperiment(t *testing.T) {
+	events := []UsageEvent{
+		{Experiment: "tone", Variant: "a", TotalTokens: 100, Cost: 0.001, LatencyMs: 800},
+		{Experiment: "tone", Variant: "b", TotalTokens: 150, Cost: 0.003, LatencyMs: 1000},
+		{Experiment: "tone", Variant: "a", TotalTokens: 120, Cost: 0.002, LatencyMs: 1200},
+		{Experiment: "tone", Variant: "b", LatencyMs: 50, Error: "timeout"},
+		{Experiment: "other", Variant: "a", TotalTokens: 999},
+		{TotalTokens: 999},
+	}
+
+	summaries := SummarizeExperiment(events, "tone")
+	require.Len(t, summaries, 2)
+	assert.Equal(t, VariantSummary{Variant: "a", Requests: 2, AvgLatencyMs: 1000, AvgTotalTokens: 110, AvgCost: 0.0015, TotalTokens: 220, Cost: 0.003}, roundVariant(summaries[0]))
+	assert.Equal(t, VariantSummary{Variant: "b", Requests: 2, FailedRequests: 1, ErrorRate: 0.5, AvgLatencyMs: 1000, AvgTotalTokens: 150, AvgCost: 0.003, TotalTokens: 150, Cost: 0.003}, roundVariant(summaries[1]))
+
+	assert.Empty(t, SummarizeExperiment(events, "missing"))
+}
+
+// roundVariant rounds the costs of a summary so float sums compare exactly.
+func roundVariant(s VariantSummary) VariantSummary {
+	s.Cost = float64(int64(s.Cost*1e8+0.5)) / 1e8
+	s.AvgCost = float64(int64(s.AvgCost*1e8+0.5)) / 1e8
+	return s
+}
+
+func TestReadUsageEvents_InvalidLine(t *testing.T) {
+	_, err := ReadUsageEvents(strings.NewReader("{}\nnot json\n"))
+	assert.ErrorContains(t, err, "line 2")
+}
+
+// roundCosts rounds costs to avoid floating-point noise in comparisons.
+func roundCosts(summaries []UsageSummary) []UsageSummary {
+	for i := range summaries {
+		summaries[i].Cost = float64(int64(summaries[i].Cost*1e8+0.5)) / 1e8
+	}
+	return summaries
+}
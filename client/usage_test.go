@@ -0,0 +1,97 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingPublisher collects published usage events.
+type recordingPublisher struct {
+	subjects []string
+	events   []UsageEvent
+	err      error
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, subject string, payload []byte) error {
+	var event UsageEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return err
+	}
+	p.subjects = append(p.subjects, subject)
+	p.events = append(p.events, event)
+	return p.err
+}
+
+func TestUsageClient_PublishesTokensAndCost(t *testing.T) {
+	stub := &stubClient{responses: []string{
+		`{"model":"gpt-4o-mini","choices":[{"message":{"content":"hi"}}],"usage":{"prompt_tokens":1000,"completion_tokens":500,"total_tokens":1500}}`,
+		`{"model":"claude-haiku-4-5","content":[{"type":"text","text":"hi"}],"usage":{"input_tokens":20,"output_tokens":10}}`,
+	}}
+	publisher := &recordingPublisher{}
+	uc, err := NewUsageClient(stub, UsageOptions{
+		Publisher: publisher,
+		Tenant:    "default-tenant",
+		Prices:    map[string]ModelPrice{"gpt-4o-mini": {InputPerMillion: 0.15, OutputPerMillion: 0.60}},
+	})
+	require.NoError(t, err)
+
+	ctx := WithUsageTags(context.Background(), UsageTags{Tenant: "acme", Template: "greet@1.0.0", Experiment: "greeting-tone", Variant: "b"})
+	_, err = uc.CallWithPrompt(ctx, "hello")
+	require.NoError(t, err)
+	_, err = uc.CallWithPromptAndVariables(context.Background(), "hello {{.n}}", `{"n":1}`)
+	require.NoError(t, err)
+
+	require.Len(t, publisher.events, 2)
+	assert.Equal(t, []string{"aiprovider.usage", "aiprovider.usage"}, publisher.subjects)
+
+	first := publisher.events[0]
+	assert.Equal(t, "gpt-4o-mini", first.Model)
+	assert.Equal(t, "acme", first.Tenant)
+	assert.Equal(t, "greet@1.0.0", first.Template)
+	assert.Equal(t, "greeting-tone", first.Experiment)
+	assert.Equal(t, "b", first.Variant)
+	assert.Equal(t, 1500, first.TotalTokens)
+	assert.InDelta(t, 0.00045, first.Cost, 1e-9)
+
+	second := publisher.events[1]
+	assert.Equal(t, "CallWithPromptAndVariables", second.Method)
+	assert.Equal(t, "default-tenant", second.Tenant)
+	assert.Empty(t, second.Experiment)
+	assert.Equal(t, 20, second.PromptTokens)
+	assert.Equal(t, 10, second.CompletionTokens)
+	assert.Zero(t, second.Cost)
+}
+
+func TestUsageClient_ErrorsDoNotFailCall(t *testing.T) {
+	publisher := &recordingPublisher{err: errors.New("broker unavailable")}
+	var publishErrs []error
+	uc, err := NewUsageClient(&stubClient{responses: []string{answeredResponse}}, UsageOptions{
+		Publisher: publisher,
+		Subject:   "ai.usage",
+		OnError:   func(err error) { publishErrs = append(publishErrs, err) },
+	})
+	require.NoError(t, err)
+
+	_, err = uc.CallWithPrompt(context.Background(), "hello")
+	require.NoError(t, err)
+	_, err = uc.CallWithPrompt(context.Background(), "hello")
+	assert.EqualError(t, err, "no response")
+
+	require.Len(t, publisher.events, 2)
+	assert.Equal(t, "ai.usage", publisher.subjects[0])
+	assert.Equal(t, "no response", publisher.events[1].Error)
+	assert.Len(t, publishErrs, 2)
+}
+
+func TestNewUsageClient_Validation(t *testing.T) {
+	_, err := NewUsageClient(nil, UsageOptions{Publisher: &recordingPublisher{}})
+	assert.Error(t, err)
+
+	_, err = NewUsageClient(&stubClient{}, UsageOptions{})
+	assert.Error(t, err)
+}
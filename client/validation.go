@@ -0,0 +1,281 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/kengibson1111/go-aiprovider/types"
+)
+
+// ErrValidationFailed is returned by a ValidatingClient when a response still fails
+// validation after the last retry.
+var ErrValidationFailed = errors.New("response failed validation")
+
+// defaultValidationRetries is the number of re-prompts when ValidationOptions.MaxRetries
+// is not set.
+const defaultValidationRetries = 2
+
+// ValidationOptions configures a ValidatingClient.
+type ValidationOptions struct {
+	// Validate checks the response text and returns an error describing what is wrong
+	// with it. Required; see JSONSchemaValidator.
+	Validate func(text string) error
+
+	// MaxRetries is the number of times a rejected response is re-prompted (default: 2).
+	MaxRetries int
+
+	// Feedback builds the text appended to the prompt for a retry from the rejected
+	// response and its validation error (default: DefaultValidationFeedback).
+	Feedback func(response string, err error) string
+
+	// OnRetry, if set, is called before each retry with the retry number, starting at 1,
+	// and the validation error.
+	OnRetry func(retry int, err error)
+}
+
+// ValidatingClient wraps an AIClient, validates the text of every response, and re-prompts
+// with the validation error appended when it fails. ValidateCredentials is passed through
+// unchanged.
+type ValidatingClient struct {
+	AIClient
+	opts ValidationOptions
+}
+
+// DefaultValidationFeedback asks the model to correct a rejected response.
+func DefaultValidationFeedback(response string, err error) string {
+	return fmt.Sprintf("Your previous response was:\n\n%s\n\nIt was rejected: %v\n\nReply again with the problem corrected.", response, err)
+}
+
+// NewValidatingClient wraps aiClient with a response validator.
+//
+// Example:
+//
+//	validate, err := client.JSONSchemaValidator([]byte(`{
+//		"type": "object",
+//		"required": ["sentiment"],
+//		"properties": {"sentiment": {"enum": ["positive", "negative", "neutral"]}}
+//	}`))
+//	vc, err := client.NewValidatingClient(aiClient, client.ValidationOptions{
+//		Validate:   validate,
+//		MaxRetries: 3,
+//	})
+//	response, err := vc.CallWithPrompt(ctx, "Classify this review as JSON: "+review)
+func NewValidatingClient(aiClient AIClient, opts ValidationOptions) (*ValidatingClient, error) {
+	if aiClient == nil {
+		return nil, fmt.Errorf("AI client is required")
+	}
+	if opts.Validate == nil {
+		return nil, fmt.Errorf("validate function is required")
+	}
+	if opts.MaxRetries < 0 {
+		return nil, fmt.Errorf("max retries must not be negative")
+	}
+	if opts.MaxRetries == 0 {
+		opts.MaxRetries = defaultValidationRetries
+	}
+	if opts.Feedback == nil {
+		opts.Feedback = DefaultValidationFeedback
+	}
+
+	return &ValidatingClient{
+		AIClient: aiClient,
+		opts:     opts,
+	}, nil
+}
+
+// CallWithPrompt sends the prompt and re-prompts until the response passes validation.
+func (c *ValidatingClient) CallWithPrompt(ctx context.Context, prompt string, opts ...types.CallOption) ([]byte, error) {
+	return c.call(prompt, func(prompt string) ([]byte, error) {
+		return c.AIClient.CallWithPrompt(ctx, prompt, opts...)
+	})
+}
+
+// CallWithPromptAndVariables sends the prompt and re-prompts until the response passes
+// validation. Feedback is appended to the template.
+func (c *ValidatingClient) CallWithPromptAndVariables(ctx context.Context, prompt string, variablesJSON string, opts ...types.CallOption) ([]byte, error) {
+	return c.call(prompt, func(prompt string) ([]byte, error) {
+		return c.AIClient.CallWithPromptAndVariables(ctx, prompt, variablesJSON, opts...)
+	})
+}
+
+// call sends prompt and retries with feedback while validation fails. When retries run
+// out, the last response is returned with an error wrapping ErrValidationFailed and the
+// validation error.
+func (c *ValidatingClient) call(prompt string, send func(prompt string) ([]byte, error)) ([]byte, error) {
+	current := prompt
+	for retry := 0; ; retry++ {
+		response, err := send(current)
+		if err != nil {
+			return nil, err
+		}
+		text := extractResponseText(response)
+		invalid := c.opts.Validate(text)
+		if invalid == nil {
+			return response, nil
+		}
+		if retry == c.opts.MaxRetries {
+			return response, fmt.Errorf("%w after %d attempts: %w", ErrValidationFailed, retry+1, invalid)
+		}
+		if c.opts.OnRetry != nil {
+			c.opts.OnRetry(retry+1, invalid)
+		}
+		current = prompt + "\n\n" + c.opts.Feedback(text, invalid)
+	}
+}
+
+// JSONSchemaValidator returns a validator that checks response text against a JSON
+// Schema. Response text is repaired with RepairJSON first, so fenced or wrapped JSON is
+// accepted. The keywords type, enum, const, properties, required, additionalProperties
+// (as a boolean), items, minItems, maxItems, minLength, maxLength, minimum, and maximum
+// are checked; others are ignored.
+func JSONSchemaValidator(schema []byte) (func(text string) error, error) {
+	var root map[string]any
+	if err := json.Unmarshal(schema, &root); err != nil {
+		return nil, fmt.Errorf("invalid JSON schema: %w", err)
+	}
+
+	return func(text string) error {
+		repaired, err := RepairJSON(text)
+		if err != nil {
+			return fmt.Errorf("response is not JSON: %w", err)
+		}
+		var value any
+		if err := json.Unmarshal([]byte(repaired), &value); err != nil {
+			return fmt.Errorf("response is not JSON: %w", err)
+		}
+		return errors.Join(checkSchema(root, value, "$")...)
+	}, nil
+}
+
+// checkSchema validates value against schema, returning an error for each violation
+// with its JSON path.
+func checkSchema(schema map[string]any, value any, path string) []error {
+	var errs []error
+	fail := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%s: "+format, append([]any{path}, args...)...))
+	}
+
+	if want, ok := schema["type"]; ok && !schemaTypeMatches(want, value) {
+		fail("expected type %v, got %s", want, jsonTypeName(value))
+		return errs
+	}
+	if enum, ok := schema["enum"].([]any); ok && !slices.ContainsFunc(enum, func(v any) bool { return jsonEqual(v, value) }) {
+		fail("value %s is not one of %s", jsonText(value), jsonText(enum))
+	}
+	if want, ok := schema["const"]; ok && !jsonEqual(want, value) {
+		fail("value %s is not %s", jsonText(value), jsonText(want))
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		properties, _ := schema["properties"].(map[string]any)
+		if required, ok := schema["required"].([]any); ok {
+			for _, name := range required {
+				if key, ok := name.(string); ok {
+					if _, present := v[key]; !present {
+						fail("missing required property %q", key)
+					}
+				}
+			}
+		}
+		for _, key := range sortedKeys(v) {
+			sub, ok := properties[key].(map[string]any)
+			if !ok {
+				if schema["additionalProperties"] == false {
+					fail("unexpected property %q", key)
+				}
+				continue
+			}
+			errs = append(errs, checkSchema(sub, v[key], path+"."+key)...)
+		}
+	case []any:
+		if min, ok := schema["minItems"].(float64); ok && float64(len(v)) < min {
+			fail("expected at least %v items, got %d", min, len(v))
+		}
+		if max, ok := schema["maxItems"].(float64); ok && float64(len(v)) > max {
+			fail("expected at most %v items, got %d", max, len(v))
+		}
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range v {
+				errs = append(errs, checkSchema(items, item, fmt.Sprintf("%s[%d]", path, i))...)
+			}
+		}
+	case string:
+		length := len([]rune(v))
+		if min, ok := schema["minLength"].(float64); ok && float64(length) < min {
+			fail("expected at least %v characters, got %d", min, length)
+		}
+		if max, ok := schema["maxLength"].(float64); ok && float64(length) > max {
+			fail("expected at most %v characters, got %d", max, length)
+		}
+	case float64:
+		if min, ok := schema["minimum"].(float64); ok && v < min {
+			fail("%v is less than the minimum %v", v, min)
+		}
+		if max, ok := schema["maximum"].(float64); ok && v > max {
+			fail("%v is greater than the maximum %v", v, max)
+		}
+	}
+	return errs
+}
+
+// schemaTypeMatches reports whether value has the schema type, a name or list of names.
+func schemaTypeMatches(want any, value any) bool {
+	names, ok := want.([]any)
+	if !ok {
+		names = []any{want}
+	}
+	got := jsonTypeName(value)
+	for _, name := range names {
+		if name == got || (name == "number" && got == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonTypeName returns the JSON Schema type name of a decoded JSON value.
+func jsonTypeName(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == float64(int64(v)) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+// jsonEqual reports whether two decoded JSON values are equal.
+func jsonEqual(a, b any) bool {
+	return jsonText(a) == jsonText(b)
+}
+
+// jsonText encodes a decoded JSON value for comparison and messages. Map keys are
+// encoded in sorted order, so equal values encode the same.
+func jsonText(value any) string {
+	encoded, _ := json.Marshal(value)
+	return string(encoded)
+}
+
+// sortedKeys returns the keys of m in sorted order, so errors are reported consistently.
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
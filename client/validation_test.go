@@ -0,0 +1,113 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func openAIText(content string) string {
+	return `{"choices":[{"index":0,"message":{"role":"assistant","content":` + jsonText(content) + `}}]}`
+}
+
+func TestValidatingClient_RetriesWithFeedback(t *testing.T) {
+	stub := &stubClient{responses: []string{openAIText("maybe"), openAIText("yes")}}
+	var retries []int
+	vc, err := NewValidatingClient(stub, ValidationOptions{
+		Validate: func(text string) error {
+			if text != "yes" && text != "no" {
+				return errors.New(`answer "yes" or "no"`)
+			}
+			return nil
+		},
+		OnRetry: func(retry int, err error) { retries = append(retries, retry) },
+	})
+	require.NoError(t, err)
+
+	response, err := vc.CallWithPrompt(context.Background(), "Is it raining?")
+	require.NoError(t, err)
+	assert.Equal(t, openAIText("yes"), string(response))
+	assert.Equal(t, []int{1}, retries)
+
+	require.Len(t, stub.prompts, 2)
+	assert.Equal(t, "Is it raining?", stub.prompts[0])
+	assert.Equal(t, "Is it raining?\n\nYour previous response was:\n\nmaybe\n\nIt was rejected: answer \"yes\" or \"no\"\n\nReply again with the problem corrected.", stub.prompts[1])
+}
+
+func TestValidatingClient_RetriesExhausted(t *testing.T) {
+	stub := &stubClient{responses: []string{openAIText("a"), openAIText("b")}}
+	invalid := errors.New("never valid")
+	vc, err := NewValidatingClient(stub, ValidationOptions{
+		Validate:   func(string) error { return invalid },
+		MaxRetries: 1,
+		Feedback:   func(response string, err error) string { return "Try again." },
+	})
+	require.NoError(t, err)
+
+	response, err := vc.CallWithPromptAndVariables(context.Background(), "Say {{word}}", `{"word":"hi"}`)
+	assert.ErrorIs(t, err, ErrValidationFailed)
+	assert.ErrorIs(t, err, invalid)
+	assert.EqualError(t, err, "response failed validation after 2 attempts: never valid")
+	assert.Equal(t, openAIText("b"), string(response))
+	assert.Equal(t, []string{"Say {{word}}", "Say {{word}}\n\nTry again."}, stub.prompts)
+
+	// Call errors are returned without retrying
+	_, err = vc.CallWithPrompt(context.Background(), "hello")
+	assert.EqualError(t, err, "no response")
+}
+
+func TestNewValidatingClient_Errors(t *testing.T) {
+	_, err := NewValidatingClient(nil, ValidationOptions{})
+	assert.EqualError(t, err, "AI client is required")
+
+	_, err = NewValidatingClient(&stubClient{}, ValidationOptions{})
+	assert.EqualError(t, err, "validate function is required")
+
+	_, err = NewValidatingClient(&stubClient{}, ValidationOptions{Validate: func(string) error { return nil }, MaxRetries: -1})
+	assert.EqualError(t, err, "max retries must not be negative")
+}
+
+func TestJSONSchemaValidator(t *testing.T) {
+	validate, err := JSONSchemaValidator([]byte(`{
+		"type": "object",
+		"required": ["sentiment", "score"],
+		"additionalProperties": false,
+		"properties": {
+			"sentiment": {"enum": ["positive", "negative", "neutral"]},
+			"score": {"type": "integer", "minimum": 1, "maximum": 5},
+			"tags": {"type": "array", "maxItems": 2, "items": {"type": "string", "minLength": 2}}
+		}
+	}`))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{"valid", `{"sentiment": "positive", "score": 4, "tags": ["ok"]}`, ""},
+		{"valid in a fence", "```json\n{\"sentiment\": \"neutral\", \"score\": 3}\n```", ""},
+		{"not JSON", "I think it is positive.", "response is not JSON: no JSON object or array found"},
+		{"wrong root type", `["positive"]`, "$: expected type object, got array"},
+		{"missing and unexpected properties", `{"sentiment": "positive", "mood": "good"}`, "$: missing required property \"score\"\n$: unexpected property \"mood\""},
+		{"nested violations", `{"sentiment": "happy", "score": 4.5, "tags": ["a", "bc", "de"]}`, "$.score: expected type integer, got number\n$.sentiment: value \"happy\" is not one of [\"positive\",\"negative\",\"neutral\"]\n$.tags: expected at most 2 items, got 3\n$.tags[0]: expected at least 2 characters, got 1"},
+		{"out of range", `{"sentiment": "negative", "score": 9}`, "$.score: 9 is greater than the maximum 5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validate(tt.text)
+			if tt.expected == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.expected)
+		})
+	}
+
+	_, err = JSONSchemaValidator([]byte(`{"type":`))
+	assert.ErrorContains(t, err, "invalid JSON schema")
+}
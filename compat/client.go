@@ -0,0 +1,104 @@
+package compat
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+
+	"github.com/kengibson1111/go-aiprovider/client"
+	"github.com/kengibson1111/go-aiprovider/internal/shared/utils"
+	"github.com/kengibson1111/go-aiprovider/types"
+)
+
+// defaultTemperature is the temperature clients send when AIConfig.Temperature is zero.
+const defaultTemperature = 0.7
+
+// Options configures a compatibility-checking Client.
+type Options struct {
+	// Suppress lists warning codes that are not reported.
+	Suppress []Code
+
+	// OnWarning receives each warning the first time it applies to a model (default: log
+	// with slog.Default at warn level).
+	OnWarning func(Warning)
+}
+
+// Client wraps an AIClient and reports the compatibility warnings for each call before
+// sending it. Calls are never changed or blocked. ValidateCredentials is passed through
+// unchanged.
+type Client struct {
+	client.AIClient
+	provider string
+	defaults types.CallOptions
+	opts     Options
+
+	mu       sync.Mutex
+	reported map[Warning]bool
+}
+
+// NewClient wraps aiClient, created from config, so that calls report the behavioral
+// differences that affect them. Each warning is reported once per model.
+func NewClient(aiClient client.AIClient, config *types.AIConfig, opts Options) (*Client, error) {
+	if aiClient == nil {
+		return nil, fmt.Errorf("AI client is required")
+	}
+	if config == nil {
+		return nil, fmt.Errorf("configuration is required")
+	}
+	defaults, err := utils.CallDefaultsFromConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	defaults.Model = config.Model
+	defaults.MaxTokens = config.MaxTokens
+	defaults.Temperature = config.Temperature
+	if defaults.Temperature == 0 {
+		defaults.Temperature = defaultTemperature
+	}
+	if opts.OnWarning == nil {
+		opts.OnWarning = func(w Warning) {
+			slog.Default().Warn("provider compatibility", "code", w.Code, "provider", w.Provider, "model", w.Model, "message", w.Message)
+		}
+	}
+
+	return &Client{
+		AIClient: aiClient,
+		provider: config.Provider,
+		defaults: defaults,
+		opts:     opts,
+		reported: map[Warning]bool{},
+	}, nil
+}
+
+// CallWithPrompt reports warnings for the call and sends the prompt.
+func (c *Client) CallWithPrompt(ctx context.Context, prompt string, opts ...types.CallOption) ([]byte, error) {
+	c.report(opts)
+	return c.AIClient.CallWithPrompt(ctx, prompt, opts...)
+}
+
+// CallWithPromptAndVariables reports warnings for the call and sends the prompt.
+func (c *Client) CallWithPromptAndVariables(ctx context.Context, prompt string, variablesJSON string, opts ...types.CallOption) ([]byte, error) {
+	c.report(opts)
+	return c.AIClient.CallWithPromptAndVariables(ctx, prompt, variablesJSON, opts...)
+}
+
+// report passes the call's unsuppressed warnings that have not been reported yet to
+// OnWarning.
+func (c *Client) report(opts []types.CallOption) {
+	var pending []Warning
+	c.mu.Lock()
+	for _, w := range Check(c.provider, types.ResolveCallOptions(c.defaults, opts)) {
+		if slices.Contains(c.opts.Suppress, w.Code) || c.reported[w] {
+			continue
+		}
+		c.reported[w] = true
+		pending = append(pending, w)
+	}
+	c.mu.Unlock()
+
+	for _, w := range pending {
+		c.opts.OnWarning(w)
+	}
+}
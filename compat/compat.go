@@ -0,0 +1,253 @@
+// Package compat reports known differences in how providers and models handle the
+// settings this library sends, so that constraints show up when a call is made rather
+// than as a 400 error or an option that is silently ignored.
+//
+// Check and CheckFeature return the warnings for a provider, model, and set of options.
+// NewClient wraps an AIClient and reports the warnings for each call as it is made:
+//
+//	aiClient, err := client.NewClientFactory().CreateClient(config)
+//	if err != nil {
+//		return err
+//	}
+//	aiClient, err = compat.NewClient(aiClient, config, compat.Options{
+//		Suppress: []compat.Code{compat.IgnoredSeed},
+//	})
+//
+// Model rules match model IDs by prefix, as types.LookupModelCapabilities does, so Azure
+// deployments are only matched when they are named after their model.
+package compat
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kengibson1111/go-aiprovider/types"
+)
+
+// Code identifies a kind of behavioral difference.
+type Code string
+
+// Warning codes
+const (
+	// NoSystemRole: the model rejects system messages, so a system prompt fails the call
+	// (OpenAI o1-mini and o1-preview).
+	NoSystemRole Code = "no_system_role"
+
+	// FixedSampling: the model rejects non-default temperature, top_p, and repetition
+	// penalties (OpenAI o-series reasoning models).
+	FixedSampling Code = "fixed_sampling"
+
+	// ExclusiveSampling: the provider takes only one of temperature and top_p, so when
+	// TopP is set the temperature is not sent (Claude).
+	ExclusiveSampling Code = "exclusive_sampling"
+
+	// NoReasoning: the model has no extended thinking or reasoning effort, so a reasoning
+	// budget fails the call (Claude 3 models other than Claude 3.7 Sonnet, and OpenAI GPT-4
+	// and GPT-3.5 models).
+	NoReasoning Code = "no_reasoning"
+
+	// IgnoredChoices: the provider returns a single choice, so N is ignored (Claude).
+	IgnoredChoices Code = "ignored_choices"
+
+	// IgnoredSeed: the provider has no sampling seed, so Seed is ignored (Claude).
+	IgnoredSeed Code = "ignored_seed"
+
+	// IgnoredPenalties: the provider has no repetition penalties, so FrequencyPenalty and
+	// PresencePenalty are ignored (Claude).
+	IgnoredPenalties Code = "ignored_penalties"
+
+	// NoTools: the model does not support tool calling (OpenAI o1-mini and o1-preview).
+	NoTools Code = "no_tools"
+
+	// NoParallelToolCalls: the model rejects the parallel_tool_calls parameter (OpenAI
+	// o-series reasoning models).
+	NoParallelToolCalls Code = "no_parallel_tool_calls"
+
+	// NoLogprobs: the provider or model does not return token log probabilities (Claude,
+	// and OpenAI o-series reasoning models).
+	NoLogprobs Code = "no_logprobs"
+
+	// IgnoredPrediction: the provider has no predicted outputs, so Prediction is ignored
+	// (Claude).
+	IgnoredPrediction Code = "ignored_prediction"
+
+	// NoPrediction: the model or the other settings of the call rule out a predicted
+	// output, so Prediction fails the call (OpenAI models other than GPT-4o and GPT-4.1,
+	// and calls with several choices, logprobs, or repetition penalties).
+	NoPrediction Code = "no_prediction"
+)
+
+// Feature is a capability used through a dedicated API rather than a call option.
+type Feature string
+
+// Features for CheckFeature
+const (
+	FeatureTools             Feature = "tools"
+	FeatureParallelToolCalls Feature = "parallel_tool_calls"
+	FeatureLogprobs          Feature = "logprobs"
+)
+
+// Warning describes a behavioral difference that affects a call.
+type Warning struct {
+	Code     Code   `json:"code"`
+	Provider string `json:"provider"`
+	Model    string `json:"model"`
+	Message  string `json:"message"`
+}
+
+// String returns the warning as a log line.
+func (w Warning) String() string {
+	return fmt.Sprintf("%s (%s %s): %s", w.Code, w.Provider, w.Model, w.Message)
+}
+
+// Check returns the warnings for a call to model through provider with the resolved
+// call options. Options the model handles as expected produce no warnings.
+//
+// Example:
+//
+//	opts := types.ResolveCallOptions(types.CallOptions{Model: "o3-mini", Temperature: 0.2}, nil)
+//	for _, w := range compat.Check(types.ProviderOpenAI, opts) {
+//		log.Println(w) // fixed_sampling (openai o3-mini): ...
+//	}
+func Check(provider string, opts types.CallOptions) []Warning {
+	model := opts.Model
+	var warnings []Warning
+	warn := func(code Code, format string, args ...any) {
+		warnings = append(warnings, Warning{Code: code, Provider: provider, Model: model, Message: fmt.Sprintf(format, args...)})
+	}
+
+	switch {
+	case isClaude(provider):
+		if opts.N > 1 {
+			warn(IgnoredChoices, "Claude returns one choice; N=%d is ignored", opts.N)
+		}
+		if opts.Seed != nil {
+			warn(IgnoredSeed, "Claude has no sampling seed; Seed is ignored and responses are not reproducible")
+		}
+		if opts.FrequencyPenalty != 0 || opts.PresencePenalty != 0 {
+			warn(IgnoredPenalties, "Claude has no repetition penalties; FrequencyPenalty and PresencePenalty are ignored")
+		}
+		if opts.TopP != nil && opts.ReasoningBudget == 0 {
+			warn(ExclusiveSampling, "Claude takes only one of temperature and top_p; TopP is sent and Temperature is ignored")
+		}
+		if opts.Logprobs {
+			warn(NoLogprobs, "Claude does not return token log probabilities; confidence scores fall back to heuristics")
+		}
+		if opts.Prediction != "" {
+			warn(IgnoredPrediction, "Claude has no predicted outputs; Prediction is ignored")
+		}
+		if opts.ReasoningBudget > 0 && matchesModel(model, "claude-3-5-sonnet", "claude-3-5-haiku", "claude-3-opus", "claude-3-sonnet", "claude-3-haiku") {
+			warn(NoReasoning, "the model has no extended thinking; use Claude 3.7 Sonnet or a Claude 4 model")
+		}
+
+	case isOpenAI(provider):
+		if opts.SystemPrompt != "" && matchesModel(model, "o1-mini", "o1-preview") {
+			warn(NoSystemRole, "the model rejects system messages; move the system prompt into the user prompt")
+		}
+		if isReasoningModel(model) {
+			var params []string
+			if opts.ReasoningBudget == 0 && opts.Temperature != 0 && opts.Temperature != 1 {
+				params = append(params, "temperature")
+			}
+			if opts.TopP != nil {
+				params = append(params, "top_p")
+			}
+			if opts.FrequencyPenalty != 0 || opts.PresencePenalty != 0 {
+				params = append(params, "repetition penalties")
+			}
+			if len(params) > 0 {
+				warn(FixedSampling, "reasoning models only accept default sampling; %s will be rejected", strings.Join(params, ", "))
+			}
+		}
+		if opts.ParallelToolCalls != nil && isReasoningModel(model) {
+			warn(NoParallelToolCalls, "reasoning models reject parallel_tool_calls; leave it unset")
+		}
+		if opts.Logprobs && isReasoningModel(model) {
+			warn(NoLogprobs, "reasoning models do not return token log probabilities")
+		}
+		if opts.ReasoningBudget > 0 && matchesModel(model, "gpt-4", "gpt-4o", "gpt-4.1", "gpt-3.5-turbo") {
+			warn(NoReasoning, "the model has no reasoning effort; use an o-series or GPT-5 model")
+		}
+		if opts.Prediction != "" {
+			var params []string
+			if opts.N > 1 {
+				params = append(params, "several choices")
+			}
+			if opts.Logprobs {
+				params = append(params, "logprobs")
+			}
+			if opts.FrequencyPenalty > 0 || opts.PresencePenalty > 0 {
+				params = append(params, "repetition penalties")
+			}
+			switch {
+			case !matchesModel(model, "gpt-4o", "gpt-4.1"):
+				warn(NoPrediction, "predicted outputs are only supported by GPT-4o and GPT-4.1 models")
+			case len(params) > 0:
+				warn(NoPrediction, "predicted outputs cannot be combined with %s", strings.Join(params, ", "))
+			}
+		}
+	}
+	return warnings
+}
+
+// CheckFeature returns a warning when model, through provider, does not support feature.
+//
+// Example:
+//
+//	if w := compat.CheckFeature(config.Provider, config.Model, compat.FeatureLogprobs); w != nil {
+//		return fmt.Errorf("confidence scores need logprobs: %s", w.Message)
+//	}
+func CheckFeature(provider, model string, feature Feature) *Warning {
+	warning := func(code Code, message string) *Warning {
+		return &Warning{Code: code, Provider: provider, Model: model, Message: message}
+	}
+
+	switch feature {
+	case FeatureTools:
+		if isOpenAI(provider) && matchesModel(model, "o1-mini", "o1-preview") {
+			return warning(NoTools, "the model does not support tool calling")
+		}
+	case FeatureParallelToolCalls:
+		if isOpenAI(provider) && isReasoningModel(model) {
+			return warning(NoParallelToolCalls, "reasoning models reject parallel_tool_calls; leave it unset")
+		}
+	case FeatureLogprobs:
+		if isClaude(provider) {
+			return warning(NoLogprobs, "Claude does not return token log probabilities")
+		}
+		if isOpenAI(provider) && isReasoningModel(model) {
+			return warning(NoLogprobs, "reasoning models do not return token log probabilities")
+		}
+	}
+	return nil
+}
+
+// isClaude reports whether provider serves Claude models.
+func isClaude(provider string) bool {
+	return provider == types.ProviderClaude || provider == types.ProviderClaudeBedrock
+}
+
+// isOpenAI reports whether provider serves OpenAI models.
+func isOpenAI(provider string) bool {
+	return provider == types.ProviderOpenAI || provider == types.ProviderOpenAIAzure || provider == types.ProviderOpenAIAzureUP
+}
+
+// isReasoningModel reports whether model is an OpenAI o-series reasoning model.
+func isReasoningModel(model string) bool {
+	return matchesModel(model, "o1", "o3", "o4")
+}
+
+// matchesModel reports whether model belongs to one of the model families. Bedrock IDs
+// such as us.anthropic.claude-sonnet-4-5-20250929-v1:0 match by their Claude model name.
+func matchesModel(model string, families ...string) bool {
+	id := strings.ToLower(strings.TrimSpace(model))
+	if i := strings.Index(id, "anthropic."); i >= 0 {
+		id = id[i+len("anthropic."):]
+	}
+	for _, family := range families {
+		if id == family || strings.HasPrefix(id, family+"-") || strings.HasPrefix(id, family+"@") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,161 @@
+package compat
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kengibson1111/go-aiprovider/mock"
+	"github.com/kengibson1111/go-aiprovider/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// codes returns the codes of warnings.
+func codes(warnings []Warning) []Code {
+	var result []Code
+	for _, w := range warnings {
+		result = append(result, w.Code)
+	}
+	return result
+}
+
+func TestCheck(t *testing.T) {
+	seed := int64(7)
+	topP := 0.9
+
+	tests := []struct {
+		name     string
+		provider string
+		opts     types.CallOptions
+		expected []Code
+	}{
+		{
+			name:     "Claude ignores N, seed, and penalties",
+			provider: types.ProviderClaude,
+			opts:     types.CallOptions{Model: "claude-sonnet-4-6", N: 3, Seed: &seed, PresencePenalty: 0.5},
+			expected: []Code{IgnoredChoices, IgnoredSeed, IgnoredPenalties},
+		},
+		{
+			name:     "Claude 4.5 with temperature and top_p",
+			provider: types.ProviderClaudeBedrock,
+			opts:     types.CallOptions{Model: "us.anthropic.claude-sonnet-4-5-20250929-v1:0", Temperature: 0.7, TopP: &topP},
+			expected: []Code{ExclusiveSampling},
+		},
+		{
+			name:     "Claude 3.5 with top_p",
+			provider: types.ProviderClaude,
+			opts:     types.CallOptions{Model: "claude-3-5-haiku-20241022", TopP: &topP},
+			expected: []Code{ExclusiveSampling},
+		},
+		{
+			name:     "Claude with top_p and reasoning",
+			provider: types.ProviderClaude,
+			opts:     types.CallOptions{Model: "claude-sonnet-4-5", TopP: &topP, ReasoningBudget: 2048},
+		},
+		{
+			name:     "Claude 3.5 with reasoning",
+			provider: types.ProviderClaude,
+			opts:     types.CallOptions{Model: "claude-3-5-haiku-20241022", ReasoningBudget: 2048, Logprobs: true},
+			expected: []Code{NoLogprobs, NoReasoning},
+		},
+		{
+			name:     "o1-mini with system prompt and temperature",
+			provider: types.ProviderOpenAI,
+			opts:     types.CallOptions{Model: "o1-mini", SystemPrompt: "Be brief.", Temperature: 0.7},
+			expected: []Code{NoSystemRole, FixedSampling},
+		},
+		{
+			name:     "o3 with reasoning omits temperature",
+			provider: types.ProviderOpenAI,
+			opts:     types.CallOptions{Model: "o3-mini", Temperature: 0.7, ReasoningBudget: 4096},
+		},
+		{
+			name:     "GPT-4o with reasoning",
+			provider: types.ProviderOpenAIAzure,
+			opts:     types.CallOptions{Model: "gpt-4o-2024-08-06", ReasoningBudget: 4096},
+			expected: []Code{NoReasoning},
+		},
+		{
+			name:     "OpenAI supports seed and N",
+			provider: types.ProviderOpenAI,
+			opts:     types.CallOptions{Model: "gpt-4.1", N: 2, Seed: &seed, Temperature: 0.2},
+		},
+		{
+			name:     "GPT-4o mini supports predictions",
+			provider: types.ProviderOpenAI,
+			opts:     types.CallOptions{Model: "gpt-4o-mini", Prediction: "package main"},
+		},
+		{
+			name:     "prediction with several choices",
+			provider: types.ProviderOpenAI,
+			opts:     types.CallOptions{Model: "gpt-4.1", N: 2, Prediction: "package main"},
+			expected: []Code{NoPrediction},
+		},
+		{
+			name:     "prediction on a reasoning model",
+			provider: types.ProviderOpenAI,
+			opts:     types.CallOptions{Model: "o3-mini", Prediction: "package main"},
+			expected: []Code{NoPrediction},
+		},
+		{
+			name:     "o4-mini with parallel tool calls",
+			provider: types.ProviderOpenAI,
+			opts:     types.CallOptions{Model: "o4-mini", ParallelToolCalls: new(bool)},
+			expected: []Code{NoParallelToolCalls},
+		},
+		{
+			name:     "Claude ignores predictions",
+			provider: types.ProviderClaude,
+			opts:     types.CallOptions{Model: "claude-sonnet-4-6", Prediction: "package main"},
+			expected: []Code{IgnoredPrediction},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, codes(Check(tt.provider, tt.opts)))
+		})
+	}
+}
+
+func TestCheckFeature(t *testing.T) {
+	assert.Equal(t, NoLogprobs, CheckFeature(types.ProviderClaude, "claude-opus-4-1", FeatureLogprobs).Code)
+	assert.Equal(t, NoLogprobs, CheckFeature(types.ProviderOpenAI, "o4-mini", FeatureLogprobs).Code)
+	assert.Nil(t, CheckFeature(types.ProviderOpenAI, "gpt-4o", FeatureLogprobs))
+	assert.Equal(t, NoParallelToolCalls, CheckFeature(types.ProviderOpenAI, "o3", FeatureParallelToolCalls).Code)
+	assert.Equal(t, NoTools, CheckFeature(types.ProviderOpenAI, "o1-preview", FeatureTools).Code)
+	assert.Nil(t, CheckFeature(types.ProviderOpenAI, "o1", FeatureTools))
+}
+
+func TestNewClient(t *testing.T) {
+	_, err := NewClient(nil, &types.AIConfig{}, Options{})
+	assert.EqualError(t, err, "AI client is required")
+	_, err = NewClient(mock.New(), nil, Options{})
+	assert.Error(t, err)
+	_, err = NewClient(mock.New(), &types.AIConfig{Provider: types.ProviderOpenAI, TopP: 2}, Options{})
+	assert.Error(t, err)
+}
+
+func TestClient_ReportsEachWarningOnce(t *testing.T) {
+	m := mock.New()
+	m.Default = mock.ClaudeText("ok")
+
+	var reported []Warning
+	c, err := NewClient(m, &types.AIConfig{Provider: types.ProviderClaude, Model: "claude-sonnet-4-6"}, Options{
+		Suppress:  []Code{IgnoredPenalties},
+		OnWarning: func(w Warning) { reported = append(reported, w) },
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	for range 2 {
+		_, err = c.CallWithPrompt(ctx, "hi", types.WithSeed(1), types.WithFrequencyPenalty(0.5))
+		require.NoError(t, err)
+	}
+	_, err = c.CallWithPromptAndVariables(ctx, "hi {{name}}", `{"name":"x"}`, types.WithN(2))
+	require.NoError(t, err)
+
+	assert.Equal(t, []Code{IgnoredSeed, IgnoredChoices}, codes(reported))
+	assert.Equal(t, "claude-sonnet-4-6", reported[0].Model)
+	assert.Len(t, m.Calls(), 3)
+}
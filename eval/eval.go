@@ -0,0 +1,327 @@
+// Package eval runs prompt regression tests: cases with assertions on the model's output,
+// run against one or more clients, with a scored report. Use it to check that a prompt
+// change, or a new model, still produces the answers you expect.
+//
+//	report, err := eval.Run(ctx, []eval.Target{{Name: "gpt-4o-mini", Client: openAIClient}}, []eval.Case{{
+//		Name:      "capital",
+//		Prompt:    "What is the capital of {{country}}? Reply with the city only.",
+//		Variables: map[string]any{"country": "France"},
+//		Assertions: []eval.Assertion{eval.Contains("Paris")},
+//	}}, eval.Options{})
+//	report.WriteText(os.Stdout)
+//	if report.Failed() > 0 {
+//		os.Exit(1)
+//	}
+package eval
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kengibson1111/go-aiprovider/client"
+	"github.com/kengibson1111/go-aiprovider/types"
+)
+
+// defaultConcurrency is the number of cases run at once when Options.Concurrency is not
+// set.
+const defaultConcurrency = 4
+
+// Assertion checks a case's output. Check returns nil when the output passes, or an
+// error explaining why it does not.
+type Assertion struct {
+	Name  string
+	Check func(ctx context.Context, output string) error
+}
+
+// Contains asserts that the output contains substr, ignoring case.
+func Contains(substr string) Assertion {
+	return Assertion{
+		Name: fmt.Sprintf("contains %q", substr),
+		Check: func(ctx context.Context, output string) error {
+			if !strings.Contains(strings.ToLower(output), strings.ToLower(substr)) {
+				return fmt.Errorf("output does not contain %q", substr)
+			}
+			return nil
+		},
+	}
+}
+
+// NotContains asserts that the output does not contain substr, ignoring case.
+func NotContains(substr string) Assertion {
+	return Assertion{
+		Name: fmt.Sprintf("does not contain %q", substr),
+		Check: func(ctx context.Context, output string) error {
+			if strings.Contains(strings.ToLower(output), strings.ToLower(substr)) {
+				return fmt.Errorf("output contains %q", substr)
+			}
+			return nil
+		},
+	}
+}
+
+// Matches asserts that the output matches a regular expression.
+func Matches(pattern *regexp.Regexp) Assertion {
+	return Assertion{
+		Name: fmt.Sprintf("matches %s", pattern),
+		Check: func(ctx context.Context, output string) error {
+			if !pattern.MatchString(output) {
+				return fmt.Errorf("output does not match %s", pattern)
+			}
+			return nil
+		},
+	}
+}
+
+// JSONSchema asserts that the output is JSON valid against schema, as checked by
+// client.JSONSchemaValidator. The schema is parsed when the assertion is built.
+func JSONSchema(schema []byte) (Assertion, error) {
+	validate, err := client.JSONSchemaValidator(schema)
+	if err != nil {
+		return Assertion{}, err
+	}
+	return Assertion{
+		Name: "matches JSON schema",
+		Check: func(ctx context.Context, output string) error {
+			return validate(output)
+		},
+	}, nil
+}
+
+// Judge asserts that a judge model considers the output to meet criteria. The judge is
+// asked to reply PASS or FAIL with a reason; anything else fails the assertion. Use a
+// strong model and a low temperature for the judge.
+func Judge(judge client.AIClient, criteria string, opts ...types.CallOption) Assertion {
+	return Assertion{
+		Name: fmt.Sprintf("judge: %s", criteria),
+		Check: func(ctx context.Context, output string) error {
+			prompt := fmt.Sprintf("You are grading an AI model's output against a criterion.\n\nCriterion: %s\n\nOutput:\n%s\n\nReply with PASS or FAIL on the first line, then a one-sentence reason.", criteria, output)
+			verdict, _, err := client.CompleteText(ctx, judge, prompt, opts...)
+			if err != nil {
+				return fmt.Errorf("judge call failed: %w", err)
+			}
+			first, reason, _ := strings.Cut(strings.TrimSpace(verdict), "\n")
+			switch strings.ToUpper(strings.Trim(first, " *.:")) {
+			case "PASS":
+				return nil
+			case "FAIL":
+				return fmt.Errorf("judge: %s", strings.TrimSpace(reason))
+			default:
+				return fmt.Errorf("judge gave no verdict: %q", strings.TrimSpace(verdict))
+			}
+		},
+	}
+}
+
+// Case is one prompt and the assertions its output must pass.
+type Case struct {
+	Name string
+
+	// Prompt is the prompt, or a template when Variables is set.
+	Prompt string
+
+	// Variables, if set, are substituted into Prompt with client.CallWithValues.
+	Variables map[string]any
+
+	Assertions []Assertion
+
+	// CallOptions are passed to the call.
+	CallOptions []types.CallOption
+}
+
+// Target is a client to evaluate, such as one provider or model.
+type Target struct {
+	Name   string
+	Client client.AIClient
+}
+
+// Options configures Run.
+type Options struct {
+	// Concurrency is the number of cases run at once for each target (default: 4).
+	Concurrency int
+
+	// OnResult, if set, receives each result as it completes. Calls are never
+	// concurrent.
+	OnResult func(target string, result CaseResult)
+}
+
+// CaseResult is the outcome of one case against one target.
+type CaseResult struct {
+	Case   string `json:"case"`
+	Output string `json:"output"`
+
+	// Error is the call error, if the call failed; its assertions were not checked.
+	Error string `json:"error,omitempty"`
+
+	// Failures lists the assertions that failed with their reasons.
+	Failures []AssertionFailure `json:"failures,omitempty"`
+
+	// Score is the fraction of assertions that passed, from 0 to 1.
+	Score float64 `json:"score"`
+
+	Latency time.Duration    `json:"latency"`
+	Usage   types.TokenUsage `json:"usage"`
+}
+
+// Passed reports whether the call succeeded and every assertion passed.
+func (r CaseResult) Passed() bool {
+	return r.Error == "" && len(r.Failures) == 0
+}
+
+// AssertionFailure is an assertion that failed.
+type AssertionFailure struct {
+	Assertion string `json:"assertion"`
+	Reason    string `json:"reason"`
+}
+
+// TargetReport is the results of all cases for one target.
+type TargetReport struct {
+	Target  string       `json:"target"`
+	Results []CaseResult `json:"results"`
+
+	// Score is the mean case score, from 0 to 1.
+	Score float64 `json:"score"`
+
+	Passed int              `json:"passed"`
+	Usage  types.TokenUsage `json:"usage"`
+}
+
+// Report is the outcome of Run.
+type Report struct {
+	Targets []TargetReport `json:"targets"`
+}
+
+// Failed returns the number of case results that did not pass, across all targets.
+func (r Report) Failed() int {
+	failed := 0
+	for _, target := range r.Targets {
+		failed += len(target.Results) - target.Passed
+	}
+	return failed
+}
+
+// WriteText writes a human-readable summary of the report, listing every failure.
+func (r Report) WriteText(w io.Writer) error {
+	var b strings.Builder
+	for _, target := range r.Targets {
+		fmt.Fprintf(&b, "%s: %d/%d passed, score %.2f, %d tokens\n", target.Target, target.Passed, len(target.Results), target.Score, target.Usage.TotalTokens)
+		for _, result := range target.Results {
+			if result.Error != "" {
+				fmt.Fprintf(&b, "  FAIL %s: %s\n", result.Case, result.Error)
+			}
+			for _, failure := range result.Failures {
+				fmt.Fprintf(&b, "  FAIL %s: %s: %s\n", result.Case, failure.Assertion, failure.Reason)
+			}
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Run runs every case against every target and returns the report. Call failures and
+// failed assertions are recorded in the report; Run fails only for invalid input or a
+// cancelled context.
+func Run(ctx context.Context, targets []Target, cases []Case, opts Options) (Report, error) {
+	if len(targets) == 0 {
+		return Report{}, errors.New("at least one target is required")
+	}
+	if len(cases) == 0 {
+		return Report{}, errors.New("at least one case is required")
+	}
+	for i, target := range targets {
+		if target.Name == "" || target.Client == nil {
+			return Report{}, fmt.Errorf("target %d needs a name and a client", i)
+		}
+	}
+	for i, c := range cases {
+		if c.Name == "" {
+			return Report{}, fmt.Errorf("case %d must have a name", i)
+		}
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = defaultConcurrency
+	}
+
+	var mu sync.Mutex
+	report := Report{Targets: make([]TargetReport, len(targets))}
+	for t, target := range targets {
+		results := make([]CaseResult, len(cases))
+		sem := make(chan struct{}, opts.Concurrency)
+		var wg sync.WaitGroup
+		for i, c := range cases {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func() {
+				defer wg.Done()
+				defer func() { <-sem }()
+				results[i] = runCase(ctx, target.Client, c)
+				if opts.OnResult != nil {
+					mu.Lock()
+					defer mu.Unlock()
+					opts.OnResult(target.Name, results[i])
+				}
+			}()
+		}
+		wg.Wait()
+		if err := ctx.Err(); err != nil {
+			return Report{}, err
+		}
+
+		tr := TargetReport{Target: target.Name, Results: results}
+		for _, result := range results {
+			tr.Score += result.Score / float64(len(results))
+			if result.Passed() {
+				tr.Passed++
+			}
+			tr.Usage.PromptTokens += result.Usage.PromptTokens
+			tr.Usage.CompletionTokens += result.Usage.CompletionTokens
+			tr.Usage.TotalTokens += result.Usage.TotalTokens
+		}
+		report.Targets[t] = tr
+	}
+	return report, nil
+}
+
+// runCase calls the client for one case and checks its assertions.
+func runCase(ctx context.Context, aiClient client.AIClient, c Case) CaseResult {
+	result := CaseResult{Case: c.Name}
+	start := time.Now()
+	var response []byte
+	var err error
+	if c.Variables != nil {
+		response, err = client.CallWithValues(ctx, aiClient, c.Prompt, c.Variables, c.CallOptions...)
+	} else {
+		response, err = aiClient.CallWithPrompt(ctx, c.Prompt, c.CallOptions...)
+	}
+	result.Latency = time.Since(start)
+	if err == nil {
+		var parsed types.AIResponse
+		if parsed, err = client.ParseResponse(response); err == nil {
+			result.Output = parsed.Content
+			result.Usage = parsed.Usage
+		}
+	}
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	passed := 0
+	for _, assertion := range c.Assertions {
+		if err := assertion.Check(ctx, result.Output); err != nil {
+			result.Failures = append(result.Failures, AssertionFailure{Assertion: assertion.Name, Reason: err.Error()})
+			continue
+		}
+		passed++
+	}
+	result.Score = 1
+	if len(c.Assertions) > 0 {
+		result.Score = float64(passed) / float64(len(c.Assertions))
+	}
+	return result
+}
@@ -0,0 +1,109 @@
+package eval
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/kengibson1111/go-aiprovider/aitest"
+	"github.com/kengibson1111/go-aiprovider/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newClient(t *testing.T, srv *aitest.FakeServer) client.AIClient {
+	aiClient, err := client.NewClientFactory().CreateClient(srv.Config())
+	require.NoError(t, err)
+	return aiClient
+}
+
+func TestRun(t *testing.T) {
+	openAI := aitest.NewFakeOpenAIServer(t)
+	openAI.Enqueue(aitest.Text("Paris"), aitest.Text(`{"sentiment": "upbeat"}`), aitest.Text("It is 4."))
+	claude := aitest.NewFakeClaudeServer(t)
+	claude.Enqueue(aitest.Text("The capital is Paris."), aitest.Text("```json\n{\"sentiment\": \"positive\"}\n```"), aitest.Error(http.StatusBadRequest, "invalid_request_error", "bad input"))
+	judge := aitest.NewFakeClaudeServer(t)
+	judge.Enqueue(aitest.Text("FAIL\nThe answer is not a bare number."))
+
+	schema, err := JSONSchema([]byte(`{"type": "object", "required": ["sentiment"], "properties": {"sentiment": {"enum": ["positive", "negative"]}}}`))
+	require.NoError(t, err)
+
+	cases := []Case{
+		{
+			Name:       "capital",
+			Prompt:     "What is the capital of {{country}}?",
+			Variables:  map[string]any{"country": "France"},
+			Assertions: []Assertion{Contains("paris"), NotContains("London"), Matches(regexp.MustCompile(`^Paris$`))},
+		},
+		{Name: "sentiment", Prompt: "Classify: great product", Assertions: []Assertion{schema}},
+		{Name: "math", Prompt: "2+2?", Assertions: []Assertion{Judge(newClient(t, judge), "The answer is a bare number")}},
+	}
+
+	var seen []string
+	report, err := Run(context.Background(), []Target{
+		{Name: "openai", Client: newClient(t, openAI)},
+		{Name: "claude", Client: newClient(t, claude)},
+	}, cases, Options{
+		Concurrency: 1,
+		OnResult:    func(target string, result CaseResult) { seen = append(seen, target+"/"+result.Case) },
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"openai/capital", "openai/sentiment", "openai/math", "claude/capital", "claude/sentiment", "claude/math"}, seen)
+	assert.Equal(t, "What is the capital of France?", openAI.Requests()[0].Prompt)
+	require.Len(t, report.Targets, 2)
+
+	o := report.Targets[0]
+	assert.Equal(t, "openai", o.Target)
+	assert.True(t, o.Results[0].Passed())
+	assert.Equal(t, []AssertionFailure{{Assertion: "matches JSON schema", Reason: `$.sentiment: value "upbeat" is not one of ["positive","negative"]`}}, o.Results[1].Failures)
+	assert.Equal(t, []AssertionFailure{{Assertion: "judge: The answer is a bare number", Reason: "judge: The answer is not a bare number."}}, o.Results[2].Failures)
+	assert.Equal(t, 1, o.Passed)
+	assert.InDelta(t, 1.0/3, o.Score, 1e-9)
+	assert.Positive(t, o.Usage.TotalTokens)
+
+	c := report.Targets[1]
+	assert.InDelta(t, 2.0/3, c.Results[0].Score, 1e-9)
+	assert.True(t, c.Results[1].Passed())
+	assert.Contains(t, c.Results[2].Error, "bad input")
+	assert.Zero(t, c.Results[2].Score)
+	assert.Equal(t, 1, c.Passed)
+	assert.Equal(t, 4, report.Failed())
+
+	var text bytes.Buffer
+	require.NoError(t, report.WriteText(&text))
+	assert.Contains(t, text.String(), "openai: 1/3 passed, score 0.33, ")
+	assert.Contains(t, text.String(), "  FAIL capital: matches ^Paris$: output does not match ^Paris$\n")
+	assert.Contains(t, text.String(), "  FAIL math: ")
+}
+
+func TestJudge_NoVerdict(t *testing.T) {
+	judge := aitest.NewFakeOpenAIServer(t)
+	judge.Enqueue(aitest.Text("Hard to say."), aitest.Text("**PASS**\nCorrect."))
+	assertion := Judge(newClient(t, judge), "is correct")
+
+	assert.EqualError(t, assertion.Check(context.Background(), "4"), `judge gave no verdict: "Hard to say."`)
+	assert.NoError(t, assertion.Check(context.Background(), "4"))
+}
+
+func TestRun_Errors(t *testing.T) {
+	target := []Target{{Name: "a", Client: &client.ValidatingClient{}}}
+	cases := []Case{{Name: "c", Prompt: "p"}}
+
+	_, err := Run(context.Background(), nil, cases, Options{})
+	assert.EqualError(t, err, "at least one target is required")
+
+	_, err = Run(context.Background(), target, nil, Options{})
+	assert.EqualError(t, err, "at least one case is required")
+
+	_, err = Run(context.Background(), []Target{{Name: "a"}}, cases, Options{})
+	assert.EqualError(t, err, "target 0 needs a name and a client")
+
+	_, err = Run(context.Background(), target, []Case{{Prompt: "p"}}, Options{})
+	assert.EqualError(t, err, "case 0 must have a name")
+
+	_, err = JSONSchema([]byte("{"))
+	assert.Error(t, err)
+}
@@ -29,6 +29,7 @@ package claudeclient
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -44,11 +45,13 @@ import (
 // ClaudeBedrockClient wraps the AWS Bedrock runtime client and reuses
 // the ClaudeRequest/ClaudeResponse types from claude_client.go.
 type ClaudeBedrockClient struct {
-	bedrockClient *bedrockruntime.Client
-	model         string
-	maxTokens     int
-	temperature   float64
-	logger        *logging.DefaultLogger
+	bedrockClient   *bedrockruntime.Client
+	model           string
+	maxTokens       int
+	temperature     float64
+	defaults        types.CallOptions
+	templateOptions utils.TemplateOptions
+	logger          *logging.DefaultLogger
 }
 
 // BedrockRequest is the request body format expected by Bedrock's Claude models.
@@ -56,7 +59,11 @@ type ClaudeBedrockClient struct {
 // separately in the InvokeModel call).
 type BedrockRequest struct {
 	MaxTokens        int             `json:"max_tokens"`
-	Temperature      float64         `json:"temperature"`
+	Temperature      *float64        `json:"temperature,omitempty"`
+	TopP             *float64        `json:"top_p,omitempty"`
+	StopSequences    []string        `json:"stop_sequences,omitempty"`
+	System           string          `json:"system,omitempty"`
+	Thinking         *ClaudeThinking `json:"thinking,omitempty"`
 	Messages         []ClaudeMessage `json:"messages"`
 	AnthropicVersion string          `json:"anthropic_version"`
 }
@@ -79,6 +86,16 @@ func NewClaudeBedrockClient(aiConfig *types.AIConfig) (*ClaudeBedrockClient, err
 		return nil, fmt.Errorf("configuration is required")
 	}
 
+	templateOptions, err := utils.TemplateOptionsFromConfig(aiConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	callDefaults, err := utils.CallDefaultsFromConfig(aiConfig)
+	if err != nil {
+		return nil, err
+	}
+
 	region := strings.TrimSpace(os.Getenv("CLAUDE_BEDROCK_REGION"))
 	if region == "" {
 		return nil, fmt.Errorf("CLAUDE_BEDROCK_REGION environment variable is required")
@@ -92,7 +109,7 @@ func NewClaudeBedrockClient(aiConfig *types.AIConfig) (*ClaudeBedrockClient, err
 		return nil, fmt.Errorf("CLAUDE_BEDROCK_MODEL environment variable is required (or set AIConfig.Model)")
 	}
 
-	logger := logging.NewDefaultLogger()
+	logger := utils.LoggerFromConfig(aiConfig)
 
 	// Load AWS config using the default credential chain
 	cfg, err := config.LoadDefaultConfig(context.Background(), config.WithRegion(region))
@@ -123,11 +140,13 @@ func NewClaudeBedrockClient(aiConfig *types.AIConfig) (*ClaudeBedrockClient, err
 	}
 
 	client := &ClaudeBedrockClient{
-		bedrockClient: brClient,
-		model:         model,
-		maxTokens:     maxTokens,
-		temperature:   temperature,
-		logger:        logger,
+		bedrockClient:   brClient,
+		model:           model,
+		maxTokens:       maxTokens,
+		temperature:     temperature,
+		defaults:        callDefaults,
+		templateOptions: templateOptions,
+		logger:          logger,
 	}
 
 	logger.Info("Claude Bedrock client created with model: %s, region: %s", model, region)
@@ -141,10 +160,10 @@ func (c *ClaudeBedrockClient) ValidateCredentials(ctx context.Context) error {
 
 	_, err := c.invokeModel(ctx, []ClaudeMessage{
 		{Role: "user", Content: "Hello"},
-	}, 10, 0.1)
+	}, types.CallOptions{Model: c.model, MaxTokens: 10, Temperature: 0.1})
 	if err != nil {
 		c.logger.Error("Credential validation failed: %v", err)
-		return &types.ErrorResponse{Code: "credential_validation_failed", Message: fmt.Sprintf("credential validation failed: %v", err)}
+		return fmt.Errorf("credential validation failed: %w", err)
 	}
 
 	c.logger.Info("Claude Bedrock credentials validated successfully")
@@ -152,37 +171,49 @@ func (c *ClaudeBedrockClient) ValidateCredentials(ctx context.Context) error {
 }
 
 // CallWithPrompt sends a prompt to Claude via Bedrock and returns the raw response.
-func (c *ClaudeBedrockClient) CallWithPrompt(ctx context.Context, prompt string) ([]byte, error) {
+// Call options override the client configuration for this request only; types.WithModel
+// selects a different Bedrock model ID. Bedrock does not accept end-user metadata, so
+// types.WithUser is ignored along with N, Seed, and the repetition penalties.
+func (c *ClaudeBedrockClient) CallWithPrompt(ctx context.Context, prompt string, opts ...types.CallOption) ([]byte, error) {
+	c.logger.Prompt(prompt)
 	messages := []ClaudeMessage{
 		{Role: "user", Content: prompt},
 	}
 
-	return c.invokeModel(ctx, messages, c.maxTokens, c.temperature)
+	return c.invokeModel(ctx, messages, c.callOptions(opts))
 }
 
 // CallWithPromptAndVariables sends a prompt template with variable substitution
 // to Claude via Bedrock. Reuses the same template processing as the direct
 // Claude client.
-func (c *ClaudeBedrockClient) CallWithPromptAndVariables(ctx context.Context, prompt string, variablesJSON string) ([]byte, error) {
+func (c *ClaudeBedrockClient) CallWithPromptAndVariables(ctx context.Context, prompt string, variablesJSON string, opts ...types.CallOption) ([]byte, error) {
 	c.logger.Info("Processing prompt with variables for Claude Bedrock")
 
-	processedPrompt, err := utils.SubstituteVariables(prompt, variablesJSON)
+	processedPrompt, err := utils.SubstituteVariablesWithOptions(prompt, variablesJSON, c.templateOptions)
 	if err != nil {
 		c.logger.Error("Variable substitution failed: %v", err)
 		return nil, fmt.Errorf("variable substitution failed: %w", err)
 	}
 
 	c.logger.Debug("Variables substituted successfully, calling Claude Bedrock")
-	return c.CallWithPrompt(ctx, processedPrompt)
+	return c.CallWithPrompt(ctx, processedPrompt, opts...)
 }
 
 // invokeModel is the shared implementation that calls Bedrock's InvokeModel API.
 // It builds the Bedrock-specific request body, invokes the model, and returns
 // the raw response bytes (same ClaudeResponse JSON format).
-func (c *ClaudeBedrockClient) invokeModel(ctx context.Context, messages []ClaudeMessage, maxTokens int, temperature float64) ([]byte, error) {
+func (c *ClaudeBedrockClient) invokeModel(ctx context.Context, messages []ClaudeMessage, callOpts types.CallOptions) (_ []byte, err error) {
+	defer utils.RecoverPanic(&err, c.logger)
+
+	callOpts, thinking := withThinking(callOpts)
+	temperature, topP := samplingParams(callOpts)
 	reqBody := BedrockRequest{
-		MaxTokens:        maxTokens,
+		MaxTokens:        callOpts.MaxTokens,
 		Temperature:      temperature,
+		TopP:             topP,
+		StopSequences:    callOpts.Stop,
+		System:           callOpts.SystemPrompt,
+		Thinking:         thinking,
 		Messages:         messages,
 		AnthropicVersion: "bedrock-2023-05-31",
 	}
@@ -194,15 +225,53 @@ func (c *ClaudeBedrockClient) invokeModel(ctx context.Context, messages []Claude
 	}
 
 	output, err := c.bedrockClient.InvokeModel(ctx, &bedrockruntime.InvokeModelInput{
-		ModelId:     aws.String(c.model),
+		ModelId:     aws.String(callOpts.Model),
 		ContentType: aws.String("application/json"),
 		Accept:      aws.String("application/json"),
 		Body:        bodyBytes,
 	})
 	if err != nil {
 		c.logger.Error("Bedrock InvokeModel failed: %v", err)
-		return nil, &types.ErrorResponse{Code: "request_failed", Message: fmt.Sprintf("bedrock request failed: %v", err)}
+		return nil, &types.ErrorResponse{Code: bedrockErrorCode(err), Message: fmt.Sprintf("bedrock request failed: %v", err)}
 	}
 
 	return output.Body, nil
 }
+
+// bedrockErrorCode returns the ErrorResponse code for a Bedrock error, so the error
+// matches the types sentinel errors. Errors without a known AWS error code, such as
+// network failures, are request_failed.
+func bedrockErrorCode(err error) string {
+	var apiErr interface{ ErrorCode() string }
+	if !errors.As(err, &apiErr) {
+		return "request_failed"
+	}
+	switch apiErr.ErrorCode() {
+	case "ThrottlingException", "ServiceQuotaExceededException":
+		return "rate_limit_exceeded"
+	case "UnrecognizedClientException", "ExpiredTokenException", "InvalidSignatureException":
+		return "invalid_api_key"
+	case "AccessDeniedException":
+		return "insufficient_permissions"
+	case "ResourceNotFoundException":
+		return "model_not_found"
+	case "InternalServerException", "ServiceUnavailableException", "ModelNotReadyException", "ModelTimeoutException":
+		return "server_error"
+	case "ValidationException":
+		lower := strings.ToLower(err.Error())
+		if strings.Contains(lower, "too long") || strings.Contains(lower, "too many") {
+			return "context_length_exceeded"
+		}
+		return "invalid_request"
+	}
+	return "request_failed"
+}
+
+// callOptions resolves per-call options against the client configuration.
+func (c *ClaudeBedrockClient) callOptions(opts []types.CallOption) types.CallOptions {
+	defaults := c.defaults
+	defaults.Model = c.model
+	defaults.MaxTokens = c.maxTokens
+	defaults.Temperature = c.temperature
+	return types.ResolveCallOptions(defaults, opts)
+}
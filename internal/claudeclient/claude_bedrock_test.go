@@ -0,0 +1,71 @@
+package claudeclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/kengibson1111/go-aiprovider/internal/shared/logging"
+	"github.com/kengibson1111/go-aiprovider/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestBedrockClient returns a ClaudeBedrockClient whose Bedrock endpoint answers every
+// request with the given status and AWS error type.
+func newTestBedrockClient(t *testing.T, status int, errorType string) *ClaudeBedrockClient {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Amzn-ErrorType", errorType)
+		w.WriteHeader(status)
+		w.Write([]byte(`{"message":"request rejected"}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	return &ClaudeBedrockClient{
+		bedrockClient: bedrockruntime.New(bedrockruntime.Options{
+			Region:       "us-east-1",
+			BaseEndpoint: aws.String(srv.URL),
+			Credentials: aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+				return aws.Credentials{AccessKeyID: "AKIDTEST", SecretAccessKey: "secret"}, nil
+			}),
+			RetryMaxAttempts: 1,
+		}),
+		model:     "anthropic.claude-sonnet-4-20250514-v1:0",
+		maxTokens: 1000,
+		logger:    logging.NewDefaultLogger(),
+	}
+}
+
+func TestClaudeBedrockClient_ValidateCredentialsErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		errorType string
+		code      string
+		sentinel  error
+	}{
+		{name: "access denied", status: http.StatusForbidden, errorType: "AccessDeniedException", code: "insufficient_permissions"},
+		{name: "expired token", status: http.StatusForbidden, errorType: "ExpiredTokenException", code: "invalid_api_key", sentinel: types.ErrInvalidAPIKey},
+		{name: "throttled", status: http.StatusTooManyRequests, errorType: "ThrottlingException", code: "rate_limit_exceeded", sentinel: types.ErrRateLimited},
+		{name: "unknown model", status: http.StatusNotFound, errorType: "ResourceNotFoundException", code: "model_not_found", sentinel: types.ErrModelNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := newTestBedrockClient(t, tt.status, tt.errorType).ValidateCredentials(context.Background())
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "credential validation failed")
+
+			var errResp *types.ErrorResponse
+			require.ErrorAs(t, err, &errResp)
+			assert.Equal(t, tt.code, errResp.Code)
+			if tt.sentinel != nil {
+				assert.ErrorIs(t, err, tt.sentinel)
+			}
+		})
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"strings"
 	"time"
 
 	"github.com/kengibson1111/go-aiprovider/internal/shared/logging"
@@ -15,10 +17,13 @@ import (
 // ClaudeClient implements the AIClient interface for Claude API
 type ClaudeClient struct {
 	*utils.BaseHTTPClient
-	model       string
-	maxTokens   int
-	temperature float64
-	logger      *logging.DefaultLogger
+	model           string
+	maxTokens       int
+	temperature     float64
+	defaults        types.CallOptions
+	templateOptions utils.TemplateOptions
+	keyProvider     types.KeyProvider
+	logger          *logging.DefaultLogger
 }
 
 // ClaudeMessage represents a message in Claude API format
@@ -29,10 +34,26 @@ type ClaudeMessage struct {
 
 // ClaudeRequest represents a request to Claude API
 type ClaudeRequest struct {
-	Model       string          `json:"model"`
-	MaxTokens   int             `json:"max_tokens"`
-	Temperature float64         `json:"temperature"`
-	Messages    []ClaudeMessage `json:"messages"`
+	Model         string          `json:"model"`
+	MaxTokens     int             `json:"max_tokens"`
+	Temperature   *float64        `json:"temperature,omitempty"`
+	TopP          *float64        `json:"top_p,omitempty"`
+	StopSequences []string        `json:"stop_sequences,omitempty"`
+	System        string          `json:"system,omitempty"`
+	Metadata      *ClaudeMetadata `json:"metadata,omitempty"`
+	Thinking      *ClaudeThinking `json:"thinking,omitempty"`
+	Messages      []ClaudeMessage `json:"messages"`
+}
+
+// ClaudeThinking enables extended thinking for a Claude request
+type ClaudeThinking struct {
+	Type         string `json:"type"`
+	BudgetTokens int    `json:"budget_tokens"`
+}
+
+// ClaudeMetadata carries request metadata for the Claude API
+type ClaudeMetadata struct {
+	UserID string `json:"user_id,omitempty"`
 }
 
 // ClaudeResponse represents a response from Claude API
@@ -68,20 +89,51 @@ func NewClaudeClient(config *types.AIConfig) (*ClaudeClient, error) {
 		return nil, fmt.Errorf("configuration is required")
 	}
 
+	templateOptions, err := utils.TemplateOptionsFromConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	callDefaults, err := utils.CallDefaultsFromConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
 	baseURL := config.BaseURL
 	if baseURL == "" {
 		baseURL = "https://api.anthropic.com"
 	}
 
 	timeout := 30 * time.Second
+	if config.RequestTimeout > 0 {
+		timeout = config.RequestTimeout
+	}
 	baseClient := utils.NewBaseHTTPClient(baseURL, config.APIKey, timeout)
+	if config.HTTPClient != nil {
+		baseClient.HttpClient = config.HTTPClient
+	} else {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		if err := utils.ConfigureTransport(transport, config); err != nil {
+			return nil, err
+		}
+		baseClient.HttpClient.Transport = transport
+		if config.Transport != nil {
+			baseClient.HttpClient.Transport = config.Transport
+		}
+		baseClient.HttpClient.Transport = utils.NewPoolStatsTransport(baseClient.HttpClient.Transport)
+	}
+	logger := utils.LoggerFromConfig(config)
+	baseClient.SetLogger(logger)
 
 	client := &ClaudeClient{
-		BaseHTTPClient: baseClient,
-		model:          config.Model,
-		maxTokens:      config.MaxTokens,
-		temperature:    config.Temperature,
-		logger:         logging.NewDefaultLogger(),
+		BaseHTTPClient:  baseClient,
+		model:           config.Model,
+		maxTokens:       config.MaxTokens,
+		temperature:     config.Temperature,
+		defaults:        callDefaults,
+		templateOptions: templateOptions,
+		keyProvider:     config.KeyProvider,
+		logger:          logger,
 	}
 
 	// Set default model if not specified
@@ -103,6 +155,32 @@ func NewClaudeClient(config *types.AIConfig) (*ClaudeClient, error) {
 	return client, nil
 }
 
+// ConnPoolStats returns the client's HTTP connection statistics. It returns zero stats
+// for a client created with AIConfig.HTTPClient, whose transport is not instrumented.
+func (c *ClaudeClient) ConnPoolStats() types.ConnPoolStats {
+	if t, ok := c.HttpClient.Transport.(*utils.PoolStatsTransport); ok {
+		return t.Stats()
+	}
+	return types.ConnPoolStats{}
+}
+
+// requestHeaders returns the headers sent with every Claude API request. The API key
+// comes from the key provider when one is configured, so rotated keys are used as soon
+// as the provider returns them.
+func (c *ClaudeClient) requestHeaders(ctx context.Context) (map[string]string, error) {
+	key := c.ApiKey
+	if c.keyProvider != nil {
+		var err error
+		if key, err = c.keyProvider.GetKey(ctx); err != nil {
+			return nil, &types.ErrorResponse{Code: "key_provider_error", Message: fmt.Sprintf("failed to get API key: %v", err), Err: err}
+		}
+	}
+	return map[string]string{
+		"x-api-key":         key,
+		"anthropic-version": "2023-06-01",
+	}, nil
+}
+
 // ValidateCredentials validates the Claude API credentials
 func (c *ClaudeClient) ValidateCredentials(ctx context.Context) error {
 	c.logger.Info("Validating Claude API credentials")
@@ -115,10 +193,11 @@ func (c *ClaudeClient) ValidateCredentials(ctx context.Context) error {
 		},
 	}
 
+	temperature := 0.1
 	claudeReq := ClaudeRequest{
 		Model:       c.model,
 		MaxTokens:   10,
-		Temperature: 0.1,
+		Temperature: &temperature,
 		Messages:    messages,
 	}
 
@@ -127,9 +206,9 @@ func (c *ClaudeClient) ValidateCredentials(ctx context.Context) error {
 		return &types.ErrorResponse{Code: "marshal_error", Message: fmt.Sprintf("failed to marshal validation request: %v", err)}
 	}
 
-	headers := map[string]string{
-		"x-api-key":         c.ApiKey,
-		"anthropic-version": "2023-06-01",
+	headers, err := c.requestHeaders(ctx)
+	if err != nil {
+		return err
 	}
 
 	httpReq := utils.HTTPRequest{
@@ -154,11 +233,7 @@ func (c *ClaudeClient) ValidateCredentials(ctx context.Context) error {
 	}
 
 	if resp.StatusCode >= 400 {
-		var errorResp ClaudeErrorResponse
-		if err := json.Unmarshal(resp.Body, &errorResp); err == nil {
-			return &types.ErrorResponse{Code: errorResp.Error.Type, Message: errorResp.Error.Message}
-		}
-		return &types.ErrorResponse{Code: "api_error", Message: fmt.Sprintf("API error: HTTP %d", resp.StatusCode)}
+		return apiError(resp)
 	}
 
 	c.logger.Info("Claude API credentials validated successfully")
@@ -168,7 +243,7 @@ func (c *ClaudeClient) ValidateCredentials(ctx context.Context) error {
 // CallWithPromptAndVariables calls the Claude API with variable substitution.
 //
 // This method implements the prompt template functionality by:
-// 1. Substituting variables in the prompt template using utils.SubstituteVariables
+// 1. Substituting variables in the prompt template using utils.SubstituteVariablesWithOptions
 // 2. Calling the existing CallWithPrompt method with the processed prompt
 // 3. Returning the same response format as CallWithPrompt
 //
@@ -189,11 +264,11 @@ func (c *ClaudeClient) ValidateCredentials(ctx context.Context) error {
 //	prompt := "As a {{expertise}} expert, explain {{concept}} in {{language}}."
 //	variables := `{"expertise": "concurrency", "concept": "goroutines", "language": "Go"}`
 //	response, err := client.CallWithPromptAndVariables(ctx, prompt, variables)
-func (c *ClaudeClient) CallWithPromptAndVariables(ctx context.Context, prompt string, variablesJSON string) ([]byte, error) {
+func (c *ClaudeClient) CallWithPromptAndVariables(ctx context.Context, prompt string, variablesJSON string, opts ...types.CallOption) ([]byte, error) {
 	c.logger.Info("Processing prompt with variables for Claude API")
 
 	// Substitute variables in the prompt using the template processor utility
-	processedPrompt, err := utils.SubstituteVariables(prompt, variablesJSON)
+	processedPrompt, err := utils.SubstituteVariablesWithOptions(prompt, variablesJSON, c.templateOptions)
 	if err != nil {
 		c.logger.Error("Variable substitution failed: %v", err)
 		return nil, fmt.Errorf("variable substitution failed: %w", err)
@@ -203,11 +278,15 @@ func (c *ClaudeClient) CallWithPromptAndVariables(ctx context.Context, prompt st
 
 	// Call the existing CallWithPrompt method with the processed prompt
 	// This ensures consistent behavior with direct prompt calls
-	return c.CallWithPrompt(ctx, processedPrompt)
+	return c.CallWithPrompt(ctx, processedPrompt, opts...)
 }
 
-// CallWithPrompt calls the Claude API
-func (c *ClaudeClient) CallWithPrompt(ctx context.Context, prompt string) ([]byte, error) {
+// CallWithPrompt calls the Claude API. Call options such as types.WithSystemPrompt
+// override the client configuration for this request only. Claude does not support
+// multiple choices, sampling seeds, or repetition penalties, so N, Seed,
+// FrequencyPenalty, and PresencePenalty are ignored.
+func (c *ClaudeClient) CallWithPrompt(ctx context.Context, prompt string, opts ...types.CallOption) ([]byte, error) {
+	c.logger.Prompt(prompt)
 	messages := []ClaudeMessage{
 		{
 			Role:    "user",
@@ -215,12 +294,7 @@ func (c *ClaudeClient) CallWithPrompt(ctx context.Context, prompt string) ([]byt
 		},
 	}
 
-	claudeReq := ClaudeRequest{
-		Model:       c.model,
-		MaxTokens:   c.maxTokens,
-		Temperature: c.temperature,
-		Messages:    messages,
-	}
+	claudeReq := newClaudeRequest(messages, c.callOptions(opts))
 
 	reqBody, err := json.Marshal(claudeReq)
 	if err != nil {
@@ -228,9 +302,10 @@ func (c *ClaudeClient) CallWithPrompt(ctx context.Context, prompt string) ([]byt
 		return nil, &types.ErrorResponse{Code: "marshal_error", Message: fmt.Sprintf("failed to marshal request: %v", err)}
 	}
 
-	headers := map[string]string{
-		"x-api-key":         c.ApiKey,
-		"anthropic-version": "2023-06-01",
+	headers, err := c.requestHeaders(ctx)
+	if err != nil {
+		c.logger.Error("Failed to get API key: %v", err)
+		return nil, err
 	}
 
 	httpReq := utils.HTTPRequest{
@@ -248,8 +323,98 @@ func (c *ClaudeClient) CallWithPrompt(ctx context.Context, prompt string) ([]byt
 
 	if err := c.ValidateResponse(resp); err != nil {
 		c.logger.Error("Invalid response: %v", err)
-		return []byte{}, &types.ErrorResponse{Code: "api_error", Message: fmt.Sprintf("API error: %v", err)}
+		return []byte{}, apiError(resp)
 	}
 
 	return resp.Body, nil
 }
+
+// apiError converts an error response of the Claude API to a types.ErrorResponse. The
+// code is the Claude error type, refined where the type alone is ambiguous so the error
+// matches the types sentinel errors: an over-long prompt is context_length_exceeded, an
+// unknown model is model_not_found, and an internal error is server_error. RetryAfter is
+// set from the retry-after and rate limit headers.
+func apiError(resp *utils.HTTPResponse) error {
+	var errorResp ClaudeErrorResponse
+	if err := json.Unmarshal(resp.Body, &errorResp); err != nil || errorResp.Error.Type == "" {
+		errorResp.Error.Type = "api_error"
+		errorResp.Error.Message = strings.TrimSpace(string(resp.Body))
+		switch resp.StatusCode {
+		case 401:
+			errorResp.Error.Type = "authentication_error"
+		case 429:
+			errorResp.Error.Type = "rate_limit_error"
+		}
+	}
+
+	code, message := errorResp.Error.Type, errorResp.Error.Message
+	lower := strings.ToLower(message)
+	switch {
+	case code == "invalid_request_error" && (strings.Contains(lower, "prompt is too long") || strings.Contains(lower, "context window")):
+		code = "context_length_exceeded"
+	case code == "not_found_error" && strings.Contains(lower, "model"):
+		code = "model_not_found"
+	case code == "api_error" && resp.StatusCode >= 500:
+		code = "server_error"
+	}
+
+	retry := code == "rate_limit_error" || code == "overloaded_error" || code == "server_error"
+	return &types.ErrorResponse{
+		Code:       code,
+		Message:    fmt.Sprintf("API error: HTTP %d - %s", resp.StatusCode, message),
+		Retry:      retry,
+		RetryAfter: utils.RetryDelay(resp.Headers),
+	}
+}
+
+// callOptions resolves per-call options against the client configuration.
+func (c *ClaudeClient) callOptions(opts []types.CallOption) types.CallOptions {
+	defaults := c.defaults
+	defaults.Model = c.model
+	defaults.MaxTokens = c.maxTokens
+	defaults.Temperature = c.temperature
+	return types.ResolveCallOptions(defaults, opts)
+}
+
+// newClaudeRequest builds a Messages API request from resolved call options.
+func newClaudeRequest(messages []ClaudeMessage, callOpts types.CallOptions) ClaudeRequest {
+	callOpts, thinking := withThinking(callOpts)
+	temperature, topP := samplingParams(callOpts)
+	req := ClaudeRequest{
+		Model:         callOpts.Model,
+		MaxTokens:     callOpts.MaxTokens,
+		Temperature:   temperature,
+		TopP:          topP,
+		StopSequences: callOpts.Stop,
+		System:        callOpts.SystemPrompt,
+		Thinking:      thinking,
+		Messages:      messages,
+	}
+	if callOpts.User != "" {
+		req.Metadata = &ClaudeMetadata{UserID: callOpts.User}
+	}
+	return req
+}
+
+// withThinking returns the thinking settings for a request with a reasoning budget, and
+// adjusts the options to what extended thinking requires: the budget is added to
+// MaxTokens (which includes thinking tokens), temperature is 1, and top_p is unset.
+func withThinking(callOpts types.CallOptions) (types.CallOptions, *ClaudeThinking) {
+	if callOpts.ReasoningBudget <= 0 {
+		return callOpts, nil
+	}
+	budget := max(callOpts.ReasoningBudget, types.DefaultReasoningBudget)
+	callOpts.MaxTokens += budget
+	callOpts.Temperature = 1
+	callOpts.TopP = nil
+	return callOpts, &ClaudeThinking{Type: "enabled", BudgetTokens: budget}
+}
+
+// samplingParams returns the temperature and top_p to send. Current Claude models reject
+// requests that set both, so when top_p is set it is sent in place of the temperature.
+func samplingParams(callOpts types.CallOptions) (temperature, topP *float64) {
+	if callOpts.TopP != nil {
+		return nil, callOpts.TopP
+	}
+	return &callOpts.Temperature, nil
+}
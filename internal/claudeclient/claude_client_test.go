@@ -0,0 +1,65 @@
+package claudeclient
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kengibson1111/go-aiprovider/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClaudeClient_SamplingParams(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []types.CallOption
+		expected map[string]any
+		omitted  string
+	}{
+		{
+			name:     "temperature only",
+			opts:     []types.CallOption{types.WithTemperature(0.3)},
+			expected: map[string]any{"temperature": 0.3},
+			omitted:  "top_p",
+		},
+		{
+			name:     "top_p replaces the temperature",
+			opts:     []types.CallOption{types.WithTopP(0.9)},
+			expected: map[string]any{"top_p": 0.9},
+			omitted:  "temperature",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body map[string]any
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				raw, _ := io.ReadAll(r.Body)
+				require.NoError(t, json.Unmarshal(raw, &body))
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"id":"msg_1","type":"message","role":"assistant","content":[{"type":"text","text":"ok"}],"stop_reason":"end_turn","usage":{"input_tokens":1,"output_tokens":1}}`))
+			}))
+			defer srv.Close()
+
+			client, err := NewClaudeClient(&types.AIConfig{
+				Provider:    types.ProviderClaude,
+				APIKey:      "sk-ant-test",
+				BaseURL:     srv.URL,
+				Model:       "claude-sonnet-4-5",
+				Temperature: 0.7,
+			})
+			require.NoError(t, err)
+
+			_, err = client.CallWithPrompt(context.Background(), "hello", tt.opts...)
+			require.NoError(t, err)
+			for key, value := range tt.expected {
+				assert.Equal(t, value, body[key], key)
+			}
+			assert.NotContains(t, body, tt.omitted)
+		})
+	}
+}
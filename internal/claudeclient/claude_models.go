@@ -0,0 +1,78 @@
+package claudeclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/kengibson1111/go-aiprovider/internal/shared/utils"
+	"github.com/kengibson1111/go-aiprovider/types"
+)
+
+// claudeModelList is a page of the Claude Models API response
+type claudeModelList struct {
+	Data []struct {
+		ID          string `json:"id"`
+		DisplayName string `json:"display_name"`
+	} `json:"data"`
+	HasMore bool   `json:"has_more"`
+	LastID  string `json:"last_id"`
+}
+
+// ListModels lists the models available to the API key from the Claude Models API,
+// following pagination until all models are returned. Capabilities come from
+// types.LookupModelCapabilities.
+func (c *ClaudeClient) ListModels(ctx context.Context) ([]types.ModelInfo, error) {
+	c.logger.Info("Listing Claude models")
+
+	headers, err := c.requestHeaders(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var models []types.ModelInfo
+	afterID := ""
+	for {
+		query := url.Values{"limit": {"1000"}}
+		if afterID != "" {
+			query.Set("after_id", afterID)
+		}
+
+		resp, err := c.DoRequest(ctx, utils.HTTPRequest{
+			Method:  "GET",
+			Path:    "/v1/models?" + query.Encode(),
+			Headers: headers,
+		})
+		if err != nil {
+			c.logger.Error("Model list request failed: %v", err)
+			return nil, &types.ErrorResponse{Code: "request_failed", Message: fmt.Sprintf("request failed: %v", err)}
+		}
+
+		if resp.StatusCode >= 400 {
+			return nil, apiError(resp)
+		}
+
+		var page claudeModelList
+		if err := json.Unmarshal(resp.Body, &page); err != nil {
+			return nil, &types.ErrorResponse{Code: "parse_error", Message: fmt.Sprintf("failed to parse model list: %v", err)}
+		}
+
+		for _, m := range page.Data {
+			models = append(models, types.ModelInfo{
+				ID:           m.ID,
+				DisplayName:  m.DisplayName,
+				OwnedBy:      "anthropic",
+				Capabilities: types.LookupModelCapabilities(m.ID),
+			})
+		}
+
+		if !page.HasMore || page.LastID == "" {
+			break
+		}
+		afterID = page.LastID
+	}
+
+	c.logger.Info("Listed %d Claude models", len(models))
+	return models, nil
+}
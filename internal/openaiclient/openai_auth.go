@@ -0,0 +1,49 @@
+package openaiclient
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/kengibson1111/go-aiprovider/internal/shared/utils"
+	"github.com/kengibson1111/go-aiprovider/types"
+	"github.com/openai/openai-go/v2/option"
+)
+
+// withTokenSource authenticates every request with a bearer token from source, replacing
+// any API key or Azure credential header. Tokens are cached until shortly before they
+// expire.
+func withTokenSource(source types.TokenSource) option.RequestOption {
+	cached := utils.NewCachedTokenSource(source)
+	return option.WithMiddleware(func(req *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+		token, err := cached.Token(req.Context())
+		if err != nil {
+			return nil, &types.ErrorResponse{Code: "key_provider_error", Message: fmt.Sprintf("failed to get token: %v", err), Err: err}
+		}
+		req.Header.Del("Api-Key")
+		req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+		return next(req)
+	})
+}
+
+// withKeyProvider authenticates every request with the API key returned by provider, so
+// rotated keys are used without recreating the client.
+func withKeyProvider(provider types.KeyProvider) option.RequestOption {
+	return option.WithMiddleware(func(req *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+		key, err := provider.GetKey(req.Context())
+		if err != nil {
+			return nil, &types.ErrorResponse{Code: "key_provider_error", Message: fmt.Sprintf("failed to get API key: %v", err), Err: err}
+		}
+		req.Header.Set("Authorization", "Bearer "+key)
+		return next(req)
+	})
+}
+
+// withResponseMeta records the metadata of every response for types.WithResponseMeta. It
+// runs for each attempt, so after retries the metadata is that of the last one.
+func withResponseMeta() option.RequestOption {
+	return option.WithMiddleware(func(req *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+		resp, err := next(req)
+		utils.RecordResponseMeta(req.Context(), resp)
+		return resp, err
+	})
+}
@@ -0,0 +1,58 @@
+package openaiclient
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kengibson1111/go-aiprovider/aitest"
+	"github.com/kengibson1111/go-aiprovider/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenSource_BearerAuth(t *testing.T) {
+	srv := aitest.NewFakeOpenAIServer(t)
+	calls := 0
+	config := srv.Config()
+	config.APIKey = ""
+	config.TokenSource = types.TokenSourceFunc(func(ctx context.Context) (types.Token, error) {
+		calls++
+		return types.Token{AccessToken: "org-token"}, nil
+	})
+
+	client, err := NewOpenAIClient(config)
+	require.NoError(t, err)
+
+	for range 2 {
+		_, err := client.CallWithPrompt(context.Background(), "hello")
+		require.NoError(t, err)
+	}
+
+	requests := srv.Requests()
+	require.Len(t, requests, 2)
+	for _, req := range requests {
+		assert.Equal(t, "Bearer org-token", req.Header.Get("Authorization"))
+	}
+	assert.Equal(t, 1, calls, "token without expiry should be fetched once")
+}
+
+func TestTokenSource_Error(t *testing.T) {
+	srv := aitest.NewFakeOpenAIServer(t)
+	config := srv.Config()
+	config.TokenSource = types.TokenSourceFunc(func(ctx context.Context) (types.Token, error) {
+		return types.Token{}, errors.New("identity provider unavailable")
+	})
+
+	client, err := NewOpenAIClient(config)
+	require.NoError(t, err)
+
+	_, err = client.CallWithPrompt(context.Background(), "hello")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "identity provider unavailable")
+	var errResp *types.ErrorResponse
+	require.ErrorAs(t, err, &errResp)
+	assert.Equal(t, "key_provider_error", errResp.Code)
+	assert.NotErrorIs(t, err, types.ErrInvalidAPIKey)
+	assert.Empty(t, srv.Requests())
+}
@@ -33,10 +33,9 @@ import (
 	"fmt"
 	"os"
 	"strings"
-	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
-	"github.com/kengibson1111/go-aiprovider/internal/shared/logging"
+	"github.com/kengibson1111/go-aiprovider/internal/shared/utils"
 	"github.com/kengibson1111/go-aiprovider/types"
 	"github.com/openai/openai-go/v2"
 	"github.com/openai/openai-go/v2/azure"
@@ -62,8 +61,9 @@ var requiredAzureIdentityEnvVars = map[string]string{
 // setAzureEnvFromConfig validates that all required OPENAI_AZURE_ environment variables
 // are set, then maps them to the standard AZURE_ variables that
 // azidentity.DefaultAzureCredential expects. Only sets a standard variable if it is
-// not already set, so explicit AZURE_ vars take precedence.
-func setAzureEnvFromConfig() error {
+// not already set, so explicit AZURE_ vars take precedence. The identity variables are
+// only checked and mapped when requireIdentity is true.
+func setAzureEnvFromConfig(requireIdentity bool) error {
 	var missing []string
 	for src := range requiredAzureEnvVars {
 		if strings.TrimSpace(os.Getenv(src)) == "" {
@@ -71,15 +71,20 @@ func setAzureEnvFromConfig() error {
 		}
 	}
 
-	for src := range requiredAzureIdentityEnvVars {
-		if strings.TrimSpace(os.Getenv(src)) == "" {
-			missing = append(missing, src)
+	if requireIdentity {
+		for src := range requiredAzureIdentityEnvVars {
+			if strings.TrimSpace(os.Getenv(src)) == "" {
+				missing = append(missing, src)
+			}
 		}
 	}
 
 	if len(missing) > 0 {
 		return fmt.Errorf("required Azure environment variables not set: %s", strings.Join(missing, ", "))
 	}
+	if !requireIdentity {
+		return nil
+	}
 
 	for src, dst := range requiredAzureIdentityEnvVars {
 		if os.Getenv(dst) == "" {
@@ -111,8 +116,19 @@ func NewOpenAIAzureClient(config *types.AIConfig) (*OpenAIClient, error) {
 		return nil, fmt.Errorf("configuration is required")
 	}
 
-	// Validate and map OPENAI_AZURE_ env vars to standard AZURE_ env vars for DefaultAzureCredential
-	if err := setAzureEnvFromConfig(); err != nil {
+	// Validate and map OPENAI_AZURE_ env vars to standard AZURE_ env vars for DefaultAzureCredential.
+	// A token source replaces the credential, so only the model-access variables are needed.
+	if err := setAzureEnvFromConfig(config.TokenSource == nil); err != nil {
+		return nil, err
+	}
+
+	templateOptions, err := utils.TemplateOptionsFromConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	callDefaults, err := utils.CallDefaultsFromConfig(config)
+	if err != nil {
 		return nil, err
 	}
 
@@ -124,22 +140,28 @@ func NewOpenAIAzureClient(config *types.AIConfig) (*OpenAIClient, error) {
 	// setAzureEnvFromConfig() validates OPENAI_AZURE_API_VERSION
 	apiVersion := strings.TrimSpace(os.Getenv("OPENAI_AZURE_API_VERSION"))
 
-	// Create Azure identity credential
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+	// Create optimized HTTP client (reuses the same function from openai_client.go)
+	httpClient, err := createOptimizedHTTPClient(config)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create Azure credential: %w", err)
+		return nil, err
 	}
 
-	// Create optimized HTTP client (reuses the same function from openai_client.go)
-	httpClient := createOptimizedHTTPClient()
-
 	// Build SDK options with Azure endpoint and Entra ID token credential
 	opts := []option.RequestOption{
 		azure.WithEndpoint(config.BaseURL, apiVersion),
-		azure.WithTokenCredential(cred),
 		option.WithHTTPClient(httpClient),
 		option.WithMaxRetries(3),
-		option.WithRequestTimeout(25 * time.Second),
+		option.WithRequestTimeout(requestTimeout(config)),
+		withResponseMeta(),
+	}
+	if config.TokenSource != nil {
+		opts = append(opts, withTokenSource(config.TokenSource))
+	} else {
+		cred, err := azidentity.NewDefaultAzureCredential(nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Azure credential: %w", err)
+		}
+		opts = append(opts, azure.WithTokenCredential(cred))
 	}
 
 	sdkClient := openai.NewClient(opts...)
@@ -161,15 +183,18 @@ func NewOpenAIAzureClient(config *types.AIConfig) (*OpenAIClient, error) {
 		temperature = 0.7
 	}
 
-	logger := logging.NewDefaultLogger()
+	logger := utils.LoggerFromConfig(config)
 
 	client := &OpenAIClient{
-		client:      &OpenAISDKClientWrapper{client: &sdkClient},
-		httpClient:  httpClient,
-		model:       model,
-		maxTokens:   maxTokens,
-		temperature: temperature,
-		logger:      logger,
+		client:          &OpenAISDKClientWrapper{client: &sdkClient},
+		httpClient:      httpClient,
+		streamOptions:   streamRequestOptions(config, httpClient),
+		model:           model,
+		maxTokens:       maxTokens,
+		temperature:     temperature,
+		defaults:        callDefaults,
+		templateOptions: templateOptions,
+		logger:          logger,
 	}
 
 	logger.Info("Azure OpenAI client created with model: %s, endpoint: %s, api-version: %s", model, config.BaseURL, apiVersion)
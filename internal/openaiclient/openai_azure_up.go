@@ -43,10 +43,9 @@ import (
 	"fmt"
 	"os"
 	"strings"
-	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
-	"github.com/kengibson1111/go-aiprovider/internal/shared/logging"
+	"github.com/kengibson1111/go-aiprovider/internal/shared/utils"
 	"github.com/kengibson1111/go-aiprovider/types"
 	"github.com/openai/openai-go/v2"
 	"github.com/openai/openai-go/v2/azure"
@@ -117,6 +116,16 @@ func NewOpenAIAzureUPClient(config *types.AIConfig) (*OpenAIClient, error) {
 		return nil, err
 	}
 
+	templateOptions, err := utils.TemplateOptionsFromConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	callDefaults, err := utils.CallDefaultsFromConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
 	if strings.TrimSpace(config.BaseURL) == "" {
 		config.BaseURL = strings.TrimSpace(os.Getenv("OPENAI_AZURE_ENDPOINT"))
 	}
@@ -136,7 +145,10 @@ func NewOpenAIAzureUPClient(config *types.AIConfig) (*OpenAIClient, error) {
 	}
 
 	// Create optimized HTTP client (reuses the same function from openai_client.go)
-	httpClient := createOptimizedHTTPClient()
+	httpClient, err := createOptimizedHTTPClient(config)
+	if err != nil {
+		return nil, err
+	}
 
 	// Build SDK options with Azure endpoint and Entra ID token credential
 	opts := []option.RequestOption{
@@ -144,7 +156,8 @@ func NewOpenAIAzureUPClient(config *types.AIConfig) (*OpenAIClient, error) {
 		azure.WithTokenCredential(cred),
 		option.WithHTTPClient(httpClient),
 		option.WithMaxRetries(3),
-		option.WithRequestTimeout(25 * time.Second),
+		option.WithRequestTimeout(requestTimeout(config)),
+		withResponseMeta(),
 	}
 
 	sdkClient := openai.NewClient(opts...)
@@ -164,15 +177,18 @@ func NewOpenAIAzureUPClient(config *types.AIConfig) (*OpenAIClient, error) {
 		temperature = 0.7
 	}
 
-	logger := logging.NewDefaultLogger()
+	logger := utils.LoggerFromConfig(config)
 
 	client := &OpenAIClient{
-		client:      &OpenAISDKClientWrapper{client: &sdkClient},
-		httpClient:  httpClient,
-		model:       model,
-		maxTokens:   maxTokens,
-		temperature: temperature,
-		logger:      logger,
+		client:          &OpenAISDKClientWrapper{client: &sdkClient},
+		httpClient:      httpClient,
+		streamOptions:   streamRequestOptions(config, httpClient),
+		model:           model,
+		maxTokens:       maxTokens,
+		temperature:     temperature,
+		defaults:        callDefaults,
+		templateOptions: templateOptions,
+		logger:          logger,
 	}
 
 	logger.Info("Azure OpenAI client (UsernamePassword) created with model: %s, endpoint: %s, api-version: %s", model, config.BaseURL, apiVersion)
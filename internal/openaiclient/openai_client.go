@@ -120,6 +120,17 @@
 //
 //	response := completion.Choices[0].Message.Content
 //
+// ## Per-call Options
+//
+// Model, sampling, and other settings default to the client configuration and can be
+// overridden for a single request:
+//
+//	response, err := client.CallWithPrompt(ctx, "Summarize this log",
+//		types.WithModel("gpt-4o"),
+//		types.WithTemperature(0),
+//		types.WithStop("\n\n"),
+//	)
+//
 // # Configuration Options
 //
 // The client supports various configuration options through types.AIConfig:
@@ -129,6 +140,9 @@
 //   - Model: Optional model name (defaults to gpt-5.4-mini)
 //   - MaxTokens: Optional max tokens (defaults to 1000)
 //   - Temperature: Optional temperature (defaults to 0.7)
+//   - TemplateMode: Optional template processing mode ("simple" or "enhanced")
+//   - SystemPrompt: Optional system message for prompt calls (override per call with types.WithSystemPrompt)
+//   - TopP, FrequencyPenalty, PresencePenalty, Stop, Seed, N: Optional generation parameters
 //
 // # Error Handling
 //
@@ -161,6 +175,7 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/kengibson1111/go-aiprovider/internal/shared/logging"
@@ -174,6 +189,9 @@ import (
 // OpenAIClientInterface defines the interface for OpenAI SDK client operations
 type OpenAIClientInterface interface {
 	Chat() ChatServiceInterface
+	Models() ModelsServiceInterface
+	Files() FilesServiceInterface
+	VectorStores() VectorStoresServiceInterface
 }
 
 // ChatServiceInterface defines the interface for chat operations
@@ -184,7 +202,12 @@ type ChatServiceInterface interface {
 // CompletionsServiceInterface defines the interface for completion operations
 type CompletionsServiceInterface interface {
 	New(ctx context.Context, params openai.ChatCompletionNewParams) (*openai.ChatCompletion, error)
-	NewStreaming(ctx context.Context, params openai.ChatCompletionNewParams) *ssestream.Stream[openai.ChatCompletionChunk]
+	NewStreaming(ctx context.Context, params openai.ChatCompletionNewParams, opts ...option.RequestOption) *ssestream.Stream[openai.ChatCompletionChunk]
+}
+
+// ModelsServiceInterface defines the interface for model listing operations
+type ModelsServiceInterface interface {
+	ListAll(ctx context.Context) ([]openai.Model, error)
 }
 
 // OpenAISDKClientWrapper wraps the real OpenAI SDK client to implement our interface
@@ -196,6 +219,18 @@ func (w *OpenAISDKClientWrapper) Chat() ChatServiceInterface {
 	return &ChatServiceWrapper{service: &w.client.Chat}
 }
 
+func (w *OpenAISDKClientWrapper) Models() ModelsServiceInterface {
+	return &ModelsServiceWrapper{service: &w.client.Models}
+}
+
+func (w *OpenAISDKClientWrapper) Files() FilesServiceInterface {
+	return &FilesServiceWrapper{service: &w.client.Files}
+}
+
+func (w *OpenAISDKClientWrapper) VectorStores() VectorStoresServiceInterface {
+	return &VectorStoresServiceWrapper{service: &w.client.VectorStores}
+}
+
 type ChatServiceWrapper struct {
 	service *openai.ChatService
 }
@@ -212,8 +247,25 @@ func (w *CompletionsServiceWrapper) New(ctx context.Context, params openai.ChatC
 	return w.service.New(ctx, params)
 }
 
-func (w *CompletionsServiceWrapper) NewStreaming(ctx context.Context, params openai.ChatCompletionNewParams) *ssestream.Stream[openai.ChatCompletionChunk] {
-	return w.service.NewStreaming(ctx, params)
+func (w *CompletionsServiceWrapper) NewStreaming(ctx context.Context, params openai.ChatCompletionNewParams, opts ...option.RequestOption) *ssestream.Stream[openai.ChatCompletionChunk] {
+	return w.service.NewStreaming(ctx, params, opts...)
+}
+
+type ModelsServiceWrapper struct {
+	service *openai.ModelService
+}
+
+// ListAll follows pagination and returns every model.
+func (w *ModelsServiceWrapper) ListAll(ctx context.Context) ([]openai.Model, error) {
+	var models []openai.Model
+	iter := w.service.ListAutoPaging(ctx)
+	for iter.Next() {
+		models = append(models, iter.Current())
+	}
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+	return models, nil
 }
 
 // OpenAIClient implements the AIClient interface for OpenAI API using the official OpenAI Go SDK v2.
@@ -241,14 +293,23 @@ func (w *CompletionsServiceWrapper) NewStreaming(ctx context.Context, params ope
 // to all requests unless overridden. Logging is provided through the utils.Logger interface
 // for consistent debugging and monitoring across the application.
 type OpenAIClient struct {
-	client      OpenAIClientInterface  // Wrapped OpenAI SDK client
-	httpClient  *http.Client           // Optimized HTTP client for resource management
-	model       string                 // Default model (e.g., gpt-5.4-mini)
-	maxTokens   int                    // Default max tokens for responses
-	temperature float64                // Default temperature for randomness control
-	logger      *logging.DefaultLogger // Logger for debugging and monitoring
+	client          OpenAIClientInterface  // Wrapped OpenAI SDK client
+	httpClient      *http.Client           // Optimized HTTP client for resource management
+	model           string                 // Default model (e.g., gpt-5.4-mini)
+	maxTokens       int                    // Default max tokens for responses
+	temperature     float64                // Default temperature for randomness control
+	defaults        types.CallOptions      // Default system prompt and generation parameters
+	templateOptions utils.TemplateOptions  // Prompt template processing options
+	logger          *logging.DefaultLogger // Logger for debugging and monitoring
+	anomalies       sync.Map               // Response schema anomalies already logged
+	compatible      bool                   // Custom BaseURL whose responses may be off-schema
+	streamOptions   []option.RequestOption // Timeouts applied to streaming requests
 }
 
+// defaultRequestTimeout is the SDK timeout for each request attempt when
+// AIConfig.RequestTimeout is not set. The HTTP client allows five seconds more.
+const defaultRequestTimeout = 25 * time.Second
+
 // createOptimizedHTTPClient creates an HTTP client optimized for performance and resource efficiency.
 //
 // This function configures an HTTP client with optimal settings for OpenAI API usage:
@@ -269,9 +330,19 @@ type OpenAIClient struct {
 //   - Reasonable connection limits prevent excessive resource usage
 //   - Timeouts ensure requests don't hang indefinitely
 //
+// Proxy, TLS, keep-alive, and connection settings from config are applied to the
+// transport, and config.RequestTimeout raises or lowers the total timeout. A config
+// HTTPClient is returned as-is, and a config Transport replaces the optimized transport.
+// The transport is wrapped to record connection pool statistics.
+//
 // Returns:
 //   - *http.Client: Optimized HTTP client for SDK usage
-func createOptimizedHTTPClient() *http.Client {
+//   - error: The proxy or TLS settings could not be applied
+func createOptimizedHTTPClient(config *types.AIConfig) (*http.Client, error) {
+	if config.HTTPClient != nil {
+		return config.HTTPClient, nil
+	}
+
 	// Create a custom transport with optimized settings
 	transport := &http.Transport{
 		// Connection pooling settings for performance
@@ -293,11 +364,28 @@ func createOptimizedHTTPClient() *http.Client {
 		ExpectContinueTimeout: 1 * time.Second,
 	}
 
+	if err := utils.ConfigureTransport(transport, config); err != nil {
+		return nil, err
+	}
+
 	// Create HTTP client with optimized transport and timeout
-	return &http.Client{
+	httpClient := &http.Client{
 		Transport: transport,
-		Timeout:   30 * time.Second, // Total request timeout including connection, request, and response
+		Timeout:   requestTimeout(config) + 5*time.Second, // Total request timeout including connection, request, and response
 	}
+	if config.Transport != nil {
+		httpClient.Transport = config.Transport
+	}
+	httpClient.Transport = utils.NewPoolStatsTransport(httpClient.Transport)
+	return httpClient, nil
+}
+
+// requestTimeout returns the per-attempt request timeout passed to the SDK.
+func requestTimeout(config *types.AIConfig) time.Duration {
+	if config.RequestTimeout > 0 {
+		return config.RequestTimeout
+	}
+	return defaultRequestTimeout
 }
 
 // NewOpenAIClient creates a new OpenAI API client using the official OpenAI Go SDK v2.
@@ -318,8 +406,10 @@ func createOptimizedHTTPClient() *http.Client {
 //   - Model: Optional model name (defaults to gpt-5.4-mini using SDK constant)
 //   - MaxTokens: Optional max tokens per response (defaults to 1000)
 //   - Temperature: Optional randomness control (defaults to 0.7)
+//   - SystemPrompt: Optional system message sent with every prompt call
+//   - TopP, FrequencyPenalty, PresencePenalty, Stop, Seed, N: Optional generation parameters
 //
-// The constructor performs validation of required fields and logs the initialization
+// The constructor performs validation of required fields and generation parameter ranges and logs the initialization
 // with the configured model and base URL for debugging purposes.
 //
 // Returns:
@@ -344,12 +434,25 @@ func NewOpenAIClient(config *types.AIConfig) (*OpenAIClient, error) {
 		return nil, fmt.Errorf("configuration is required")
 	}
 
-	if strings.TrimSpace(config.APIKey) == "" {
+	if strings.TrimSpace(config.APIKey) == "" && config.TokenSource == nil && config.KeyProvider == nil {
 		return nil, fmt.Errorf("API key is required")
 	}
 
+	templateOptions, err := utils.TemplateOptionsFromConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	callDefaults, err := utils.CallDefaultsFromConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create optimized HTTP client for performance and resource efficiency
-	httpClient := createOptimizedHTTPClient()
+	httpClient, err := createOptimizedHTTPClient(config)
+	if err != nil {
+		return nil, err
+	}
 
 	// Build SDK options with performance optimizations
 	opts := []option.RequestOption{
@@ -357,9 +460,10 @@ func NewOpenAIClient(config *types.AIConfig) (*OpenAIClient, error) {
 		option.WithAPIKey(config.APIKey),
 
 		// Performance optimizations
-		option.WithHTTPClient(httpClient),           // Use optimized HTTP client with connection pooling
-		option.WithMaxRetries(3),                    // Retry failed requests up to 3 times for resilience
-		option.WithRequestTimeout(25 * time.Second), // Request timeout (less than HTTP client timeout)
+		option.WithHTTPClient(httpClient),                 // Use optimized HTTP client with connection pooling
+		option.WithMaxRetries(3),                          // Retry failed requests up to 3 times for resilience
+		option.WithRequestTimeout(requestTimeout(config)), // Request timeout (less than HTTP client timeout)
+		withResponseMeta(),                                // Record response headers for types.WithResponseMeta
 	}
 
 	// Add custom base URL if provided (for Azure OpenAI Service, etc.)
@@ -367,6 +471,16 @@ func NewOpenAIClient(config *types.AIConfig) (*OpenAIClient, error) {
 		opts = append(opts, option.WithBaseURL(config.BaseURL))
 	}
 
+	// Bearer tokens from a token source take the place of the API key
+	if config.TokenSource != nil {
+		opts = append(opts, withTokenSource(config.TokenSource))
+	}
+
+	// A key provider supplies the API key per request so rotated keys take effect
+	if config.KeyProvider != nil {
+		opts = append(opts, withKeyProvider(config.KeyProvider))
+	}
+
 	// Create SDK client with performance optimizations
 	sdkClient := openai.NewClient(opts...)
 
@@ -391,12 +505,16 @@ func NewOpenAIClient(config *types.AIConfig) (*OpenAIClient, error) {
 	}
 
 	client := &OpenAIClient{
-		client:      &OpenAISDKClientWrapper{client: &sdkClient},
-		httpClient:  httpClient, // Store reference for resource management
-		model:       model,
-		maxTokens:   maxTokens,
-		temperature: temperature,
-		logger:      logging.NewDefaultLogger(),
+		client:          &OpenAISDKClientWrapper{client: &sdkClient},
+		httpClient:      httpClient, // Store reference for resource management
+		streamOptions:   streamRequestOptions(config, httpClient),
+		model:           model,
+		maxTokens:       maxTokens,
+		temperature:     temperature,
+		defaults:        callDefaults,
+		templateOptions: templateOptions,
+		logger:          utils.LoggerFromConfig(config),
+		compatible:      config.BaseURL != "",
 	}
 
 	// Log initialization with model and base URL (if custom)
@@ -451,6 +569,17 @@ func (c *OpenAIClient) CloseIdleConnections() {
 	}
 }
 
+// ConnPoolStats returns the client's HTTP connection statistics. It returns zero stats
+// for a client created with AIConfig.HTTPClient, whose transport is not instrumented.
+func (c *OpenAIClient) ConnPoolStats() types.ConnPoolStats {
+	if c.httpClient != nil {
+		if t, ok := c.httpClient.Transport.(*utils.PoolStatsTransport); ok {
+			return t.Stats()
+		}
+	}
+	return types.ConnPoolStats{}
+}
+
 // ValidateCredentials validates the OpenAI API credentials using the official SDK.
 //
 // This method performs credential validation by making a minimal test request to the
@@ -482,7 +611,9 @@ func (c *OpenAIClient) CloseIdleConnections() {
 //
 // The method leverages the SDK's built-in error handling and retry logic,
 // providing reliable validation even under network instability.
-func (c *OpenAIClient) ValidateCredentials(ctx context.Context) error {
+func (c *OpenAIClient) ValidateCredentials(ctx context.Context) (err error) {
+	defer utils.RecoverPanic(&err, c.logger)
+
 	c.logger.Info("Validating OpenAI API credentials")
 
 	// Minimal test request using SDK with performance optimizations
@@ -499,7 +630,7 @@ func (c *OpenAIClient) ValidateCredentials(ctx context.Context) error {
 		Logprobs: openai.Bool(false),
 	}
 
-	_, err := c.client.Chat().Completions().New(ctx, params)
+	_, err = c.client.Chat().Completions().New(ctx, params)
 	if err != nil {
 		// Safely log the error without triggering potential nil pointer dereference
 		c.logger.Error("Credential validation failed: %s", c.safeErrorString(err))
@@ -510,6 +641,33 @@ func (c *OpenAIClient) ValidateCredentials(ctx context.Context) error {
 	return nil
 }
 
+// ListModels lists the models available from the configured endpoint. Capabilities come
+// from types.LookupModelCapabilities; Azure deployments are listed by the endpoint's
+// model names rather than deployment names.
+func (c *OpenAIClient) ListModels(ctx context.Context) (_ []types.ModelInfo, err error) {
+	defer utils.RecoverPanic(&err, c.logger)
+
+	c.logger.Info("Listing OpenAI models")
+
+	sdkModels, err := c.client.Models().ListAll(ctx)
+	if err != nil {
+		c.logger.Error("Model list request failed: %s", c.safeErrorString(err))
+		return nil, c.handleSDKError(err)
+	}
+
+	models := make([]types.ModelInfo, 0, len(sdkModels))
+	for _, m := range sdkModels {
+		models = append(models, types.ModelInfo{
+			ID:           m.ID,
+			OwnedBy:      m.OwnedBy,
+			Capabilities: types.LookupModelCapabilities(m.ID),
+		})
+	}
+
+	c.logger.Info("Listed %d OpenAI models", len(models))
+	return models, nil
+}
+
 // CallWithPrompt calls the OpenAI API and returns the response as JSON bytes.
 //
 // This method implements the AIClient interface by calling the internal callWithPrompt
@@ -520,6 +678,7 @@ func (c *OpenAIClient) ValidateCredentials(ctx context.Context) error {
 // Parameters:
 //   - ctx: Context for request cancellation and timeouts
 //   - prompt: The user message/prompt to send to the model
+//   - opts: Optional per-call overrides such as types.WithSystemPrompt
 //
 // Returns:
 //   - []byte: JSON-encoded response from the OpenAI API
@@ -535,9 +694,9 @@ func (c *OpenAIClient) ValidateCredentials(ctx context.Context) error {
 //	// Parse JSON response if needed
 //	var result map[string]interface{}
 //	json.Unmarshal(response, &result)
-func (c *OpenAIClient) CallWithPrompt(ctx context.Context, prompt string) ([]byte, error) {
+func (c *OpenAIClient) CallWithPrompt(ctx context.Context, prompt string, opts ...types.CallOption) ([]byte, error) {
 	// Call the internal SDK-optimized method
-	completion, err := c.callWithPrompt(ctx, prompt)
+	completion, err := c.callWithPrompt(ctx, prompt, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -590,25 +749,19 @@ func (c *OpenAIClient) CallWithPrompt(ctx context.Context, prompt string) ([]byt
 //   - Direct memory access to response fields
 //   - Type-safe field access at compile time
 //   - Reduced memory allocations
-func (c *OpenAIClient) callWithPrompt(ctx context.Context, prompt string) (*openai.ChatCompletion, error) {
-	params := openai.ChatCompletionNewParams{
-		Model: openai.ChatModel(c.model),
-		Messages: []openai.ChatCompletionMessageParamUnion{
-			openai.UserMessage(prompt),
-		},
-		MaxCompletionTokens: openai.Int(int64(c.maxTokens)),
-		Temperature:         openai.Float(c.temperature),
-		// Performance optimization: Request only one choice to reduce response size and processing time
-		N: openai.Int(1),
-		// Performance optimization: Disable logprobs to reduce response payload size
-		Logprobs: openai.Bool(false),
-	}
+func (c *OpenAIClient) callWithPrompt(ctx context.Context, prompt string, opts ...types.CallOption) (_ *openai.ChatCompletion, err error) {
+	defer utils.RecoverPanic(&err, c.logger)
+
+	c.logger.Prompt(prompt)
+	callOpts := c.callOptions(opts)
+	params := completionParams(promptMessages(prompt, callOpts), callOpts)
 
 	completion, err := c.client.Chat().Completions().New(ctx, params)
 	if err != nil {
 		c.logger.Error("Completion request failed: %s", c.safeErrorString(err))
 		return nil, c.handleSDKError(err)
 	}
+	c.normalizeCompletion(completion, string(params.Model))
 
 	return completion, nil
 }
@@ -617,11 +770,13 @@ func (c *OpenAIClient) callWithPrompt(ctx context.Context, prompt string) (*open
 //
 // This method enables complex multi-turn conversations by accepting a slice of messages
 // that can include system, user, and assistant messages. It maintains the same error
-// handling and logging patterns as CallWithPrompt for consistency.
+// handling and logging patterns as CallWithPrompt for consistency. The configured
+// SystemPrompt is not added; include a system message in messages when one is needed.
 //
 // Parameters:
 //   - ctx: Context for request cancellation and timeouts
 //   - messages: Slice of ChatCompletionMessageParamUnion containing the conversation
+//   - opts: Optional per-call overrides such as types.WithModel or types.WithTemperature
 //
 // Returns:
 //   - OpenAI ChatCompletion response from the SDK
@@ -636,25 +791,19 @@ func (c *OpenAIClient) callWithPrompt(ctx context.Context, prompt string) (*open
 //		openai.UserMessage("What about Germany?"),
 //	}
 //	response, err := client.CallWithMessages(ctx, messages)
-func (c *OpenAIClient) CallWithMessages(ctx context.Context, messages []openai.ChatCompletionMessageParamUnion) (*openai.ChatCompletion, error) {
+func (c *OpenAIClient) CallWithMessages(ctx context.Context, messages []openai.ChatCompletionMessageParamUnion, opts ...types.CallOption) (_ *openai.ChatCompletion, err error) {
+	defer utils.RecoverPanic(&err, c.logger)
+
 	c.logger.Info("Processing conversation with %d messages", len(messages))
 
-	params := openai.ChatCompletionNewParams{
-		Model:               openai.ChatModel(c.model),
-		Messages:            messages,
-		MaxCompletionTokens: openai.Int(int64(c.maxTokens)),
-		Temperature:         openai.Float(c.temperature),
-		// Performance optimization: Request only one choice to reduce response size
-		N: openai.Int(1),
-		// Performance optimization: Disable logprobs to reduce response payload size
-		Logprobs: openai.Bool(false),
-	}
+	params := completionParams(messages, c.callOptions(opts))
 
 	completion, err := c.client.Chat().Completions().New(ctx, params)
 	if err != nil {
 		c.logger.Error("Conversation completion request failed: %s", c.safeErrorString(err))
 		return nil, c.handleSDKError(err)
 	}
+	c.normalizeCompletion(completion, string(params.Model))
 
 	c.logger.Debug("Conversation completed successfully with %d choices", len(completion.Choices))
 	return completion, nil
@@ -698,21 +847,22 @@ func (c *OpenAIClient) CallWithMessages(ctx context.Context, messages []openai.C
 //		},
 //	}
 //	response, err := client.CallWithTools(ctx, "What's the weather in Paris?", tools)
-func (c *OpenAIClient) CallWithTools(ctx context.Context, prompt string, tools []openai.ChatCompletionToolUnionParam) (*openai.ChatCompletion, error) {
+//
+// Pass types.WithToolChoice to require a tool call or a specific function, and
+// types.WithParallelToolCalls(false) to get at most one tool call per response.
+func (c *OpenAIClient) CallWithTools(ctx context.Context, prompt string, tools []openai.ChatCompletionToolUnionParam, opts ...types.CallOption) (_ *openai.ChatCompletion, err error) {
+	defer utils.RecoverPanic(&err, c.logger)
+
 	c.logger.Info("Processing prompt with %d tools available for function calling", len(tools))
 
-	params := openai.ChatCompletionNewParams{
-		Model: openai.ChatModel(c.model),
-		Messages: []openai.ChatCompletionMessageParamUnion{
-			openai.UserMessage(prompt),
-		},
-		Tools:               tools,
-		MaxCompletionTokens: openai.Int(int64(c.maxTokens)),
-		Temperature:         openai.Float(c.temperature),
-		// Performance optimization: Request only one choice to reduce response size
-		N: openai.Int(1),
-		// Performance optimization: Disable logprobs to reduce response payload size
-		Logprobs: openai.Bool(false),
+	callOpts := c.callOptions(opts)
+	params := completionParams(promptMessages(prompt, callOpts), callOpts)
+	params.Tools = tools
+	if callOpts.ToolChoice != "" {
+		params.ToolChoice = toolChoice(callOpts.ToolChoice)
+	}
+	if callOpts.ParallelToolCalls != nil {
+		params.ParallelToolCalls = openai.Bool(*callOpts.ParallelToolCalls)
 	}
 
 	completion, err := c.client.Chat().Completions().New(ctx, params)
@@ -720,6 +870,7 @@ func (c *OpenAIClient) CallWithTools(ctx context.Context, prompt string, tools [
 		c.logger.Error("Function calling completion request failed: %s", c.safeErrorString(err))
 		return nil, c.handleSDKError(err)
 	}
+	c.normalizeCompletion(completion, string(params.Model))
 
 	// Log information about the response
 	if len(completion.Choices) > 0 {
@@ -769,23 +920,22 @@ func (c *OpenAIClient) CallWithTools(ctx context.Context, prompt string, tools [
 //	if err := accumulator.Err(); err != nil {
 //		return err
 //	}
-func (c *OpenAIClient) CallWithPromptStream(ctx context.Context, prompt string) (*ssestream.Stream[openai.ChatCompletionChunk], error) {
+func (c *OpenAIClient) CallWithPromptStream(ctx context.Context, prompt string, opts ...types.CallOption) (_ *ssestream.Stream[openai.ChatCompletionChunk], err error) {
+	defer utils.RecoverPanic(&err, c.logger)
+
 	c.logger.Info("Processing streaming prompt request")
+	c.logger.Prompt(prompt)
 
-	params := openai.ChatCompletionNewParams{
-		Model: openai.ChatModel(c.model),
-		Messages: []openai.ChatCompletionMessageParamUnion{
-			openai.UserMessage(prompt),
-		},
-		MaxCompletionTokens: openai.Int(int64(c.maxTokens)),
-		Temperature:         openai.Float(c.temperature),
-		// Performance optimization: Request only one choice to reduce response size
-		N: openai.Int(1),
-		// Performance optimization: Disable logprobs to reduce response payload size
-		Logprobs: openai.Bool(false),
+	callOpts := c.callOptions(opts)
+	params := completionParams(promptMessages(prompt, callOpts), callOpts)
+
+	// With several choices, request a final usage chunk so combined usage is available
+	// once every choice has finished
+	if callOpts.N > 1 {
+		params.StreamOptions = openai.ChatCompletionStreamOptionsParam{IncludeUsage: openai.Bool(true)}
 	}
 
-	stream := c.client.Chat().Completions().NewStreaming(ctx, params)
+	stream := c.client.Chat().Completions().NewStreaming(ctx, params, c.streamOptions...)
 
 	// Check for immediate errors in stream setup
 	if err := stream.Err(); err != nil {
@@ -828,9 +978,9 @@ func (c *OpenAIClient) CallWithPromptStream(ctx context.Context, prompt string)
 //	// Parse JSON response if needed
 //	var result map[string]interface{}
 //	json.Unmarshal(response, &result)
-func (c *OpenAIClient) CallWithPromptAndVariables(ctx context.Context, prompt string, variablesJSON string) ([]byte, error) {
+func (c *OpenAIClient) CallWithPromptAndVariables(ctx context.Context, prompt string, variablesJSON string, opts ...types.CallOption) ([]byte, error) {
 	// Call the internal SDK-optimized method with variable substitution
-	completion, err := c.callWithPromptAndVariables(ctx, prompt, variablesJSON)
+	completion, err := c.callWithPromptAndVariables(ctx, prompt, variablesJSON, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -848,7 +998,7 @@ func (c *OpenAIClient) CallWithPromptAndVariables(ctx context.Context, prompt st
 // CallWithPromptAndVariables calls the OpenAI API with variable substitution.
 //
 // This method implements the prompt template functionality by:
-// 1. Substituting variables in the prompt template using utils.SubstituteVariables
+// 1. Substituting variables in the prompt template using utils.SubstituteVariablesWithOptions
 // 2. Calling the existing CallWithPrompt method with the processed prompt
 // 3. Returning the same response format as CallWithPrompt
 //
@@ -869,11 +1019,11 @@ func (c *OpenAIClient) CallWithPromptAndVariables(ctx context.Context, prompt st
 //	prompt := "You are a {{role}} assistant. Help with {{task}} in {{language}}."
 //	variables := `{"role": "senior developer", "task": "code review", "language": "Go"}`
 //	response, err := client.CallWithPromptAndVariables(ctx, prompt, variables)
-func (c *OpenAIClient) callWithPromptAndVariables(ctx context.Context, prompt string, variablesJSON string) (*openai.ChatCompletion, error) {
+func (c *OpenAIClient) callWithPromptAndVariables(ctx context.Context, prompt string, variablesJSON string, opts ...types.CallOption) (*openai.ChatCompletion, error) {
 	c.logger.Info("Processing prompt with variables for OpenAI API")
 
 	// Substitute variables in the prompt using the template processor utility
-	processedPrompt, err := utils.SubstituteVariables(prompt, variablesJSON)
+	processedPrompt, err := utils.SubstituteVariablesWithOptions(prompt, variablesJSON, c.templateOptions)
 	if err != nil {
 		c.logger.Error("Variable substitution failed: %s", c.safeErrorString(err))
 		return nil, fmt.Errorf("variable substitution failed: %w", err)
@@ -883,7 +1033,104 @@ func (c *OpenAIClient) callWithPromptAndVariables(ctx context.Context, prompt st
 
 	// Call the existing CallWithPrompt method with the processed prompt
 	// This ensures consistent behavior with direct prompt calls
-	return c.callWithPrompt(ctx, processedPrompt)
+	return c.callWithPrompt(ctx, processedPrompt, opts...)
+}
+
+// callOptions resolves per-call options against the client configuration.
+func (c *OpenAIClient) callOptions(opts []types.CallOption) types.CallOptions {
+	defaults := c.defaults
+	defaults.Model = c.model
+	defaults.MaxTokens = c.maxTokens
+	defaults.Temperature = c.temperature
+	return types.ResolveCallOptions(defaults, opts)
+}
+
+// completionParams builds chat completion parameters for messages from resolved call options.
+func completionParams(messages []openai.ChatCompletionMessageParamUnion, callOpts types.CallOptions) openai.ChatCompletionNewParams {
+	params := openai.ChatCompletionNewParams{
+		Model:               openai.ChatModel(callOpts.Model),
+		Messages:            messages,
+		MaxCompletionTokens: openai.Int(int64(callOpts.MaxTokens)),
+		// Performance optimization: Request only one choice unless more are asked for
+		N: openai.Int(int64(max(1, callOpts.N))),
+		// Performance optimization: Request logprobs only when asked for, since they
+		// enlarge the response payload
+		Logprobs: openai.Bool(callOpts.Logprobs),
+	}
+
+	if callOpts.ReasoningBudget > 0 {
+		// Reasoning models count reasoning tokens against max_completion_tokens and
+		// reject a non-default temperature
+		params.ReasoningEffort = reasoningEffort(callOpts.ReasoningBudget)
+		params.MaxCompletionTokens = openai.Int(int64(callOpts.MaxTokens + callOpts.ReasoningBudget))
+	} else {
+		params.Temperature = openai.Float(callOpts.Temperature)
+	}
+	if len(callOpts.Stop) > 0 {
+		params.Stop = openai.ChatCompletionNewParamsStopUnion{OfStringArray: callOpts.Stop}
+	}
+	if callOpts.TopP != nil {
+		params.TopP = openai.Float(*callOpts.TopP)
+	}
+	if callOpts.FrequencyPenalty != 0 {
+		params.FrequencyPenalty = openai.Float(callOpts.FrequencyPenalty)
+	}
+	if callOpts.PresencePenalty != 0 {
+		params.PresencePenalty = openai.Float(callOpts.PresencePenalty)
+	}
+	if callOpts.Seed != nil {
+		params.Seed = openai.Int(*callOpts.Seed)
+	}
+	if callOpts.User != "" {
+		params.User = openai.String(callOpts.User)
+	}
+	if callOpts.TopLogprobs > 0 {
+		params.TopLogprobs = openai.Int(int64(callOpts.TopLogprobs))
+	}
+	if callOpts.Prediction != "" {
+		params.Prediction = openai.ChatCompletionPredictionContentParam{
+			Content: openai.ChatCompletionPredictionContentContentUnionParam{OfString: openai.String(callOpts.Prediction)},
+		}
+	}
+
+	return params
+}
+
+// toolChoice converts a types.ToolChoice value to the SDK's tool_choice parameter.
+func toolChoice(choice string) openai.ChatCompletionToolChoiceOptionUnionParam {
+	switch choice {
+	case types.ToolChoiceAuto, types.ToolChoiceNone, types.ToolChoiceRequired:
+		return openai.ChatCompletionToolChoiceOptionUnionParam{OfAuto: openai.String(choice)}
+	}
+	return openai.ChatCompletionToolChoiceOptionUnionParam{
+		OfFunctionToolChoice: &openai.ChatCompletionNamedToolChoiceParam{
+			Function: openai.ChatCompletionNamedToolChoiceFunctionParam{Name: choice},
+		},
+	}
+}
+
+// reasoningEffort maps a thinking-token budget to the closest OpenAI reasoning effort.
+func reasoningEffort(budget int) openai.ReasoningEffort {
+	switch {
+	case budget < 4096:
+		return openai.ReasoningEffortLow
+	case budget < 16384:
+		return openai.ReasoningEffortMedium
+	default:
+		return openai.ReasoningEffortHigh
+	}
+}
+
+// promptMessages builds the message list for a single-prompt request, preceded by
+// the system message when one is set.
+func promptMessages(prompt string, callOpts types.CallOptions) []openai.ChatCompletionMessageParamUnion {
+	if callOpts.SystemPrompt == "" {
+		return []openai.ChatCompletionMessageParamUnion{openai.UserMessage(prompt)}
+	}
+	return []openai.ChatCompletionMessageParamUnion{
+		openai.SystemMessage(callOpts.SystemPrompt),
+		openai.UserMessage(prompt),
+	}
 }
 
 // handleSDKError converts SDK errors to user-friendly messages.
@@ -915,6 +1162,26 @@ func (c *OpenAIClient) callWithPromptAndVariables(ctx context.Context, prompt st
 // This method demonstrates SDK integration by using the native openai.Error type
 // for structured error information when available.
 func (c *OpenAIClient) handleSDKError(err error) error {
+	converted := c.classifySDKError(err)
+
+	// Pass on how long the provider asked to wait, for callers that retry themselves
+	var apiErr *openai.Error
+	var errResp *types.ErrorResponse
+	if errors.As(err, &apiErr) && apiErr.Response != nil && errors.As(converted, &errResp) {
+		errResp.RetryAfter = utils.RetryDelay(apiErr.Response.Header)
+	}
+	return converted
+}
+
+// classifySDKError converts an SDK error to a types.ErrorResponse by its structured
+// code and type, or by the HTTP status and network failures in its message.
+func (c *OpenAIClient) classifySDKError(err error) error {
+	// Errors from the client's own middleware, such as a failing KeyProvider, are already classified
+	var errResp *types.ErrorResponse
+	if errors.As(err, &errResp) {
+		return errResp
+	}
+
 	// First try to parse as structured API error to get specific error codes
 	var apiErr *openai.Error
 	if errors.As(err, &apiErr) {
@@ -1123,6 +1390,10 @@ func (c *OpenAIClient) safeErrorString(err error) string {
 // This method demonstrates SDK streaming integration by handling errors from
 // the SDK's streaming API methods with appropriate context for real-time usage.
 func (c *OpenAIClient) handleStreamingError(err error) error {
+	if errors.Is(err, errStreamIdle) {
+		return &types.ErrorResponse{Code: "streaming_timeout", Message: err.Error(), Details: "increase StreamIdleTimeout for models that pause between chunks", Retry: true}
+	}
+
 	// First try standard SDK error handling
 	if sdkErr := c.handleSDKError(err); sdkErr != nil {
 		// Check if this is a streaming-specific error by examining the message
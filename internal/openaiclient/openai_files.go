@@ -0,0 +1,287 @@
+package openaiclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kengibson1111/go-aiprovider/internal/shared/utils"
+	"github.com/kengibson1111/go-aiprovider/types"
+	"github.com/openai/openai-go/v2"
+)
+
+// defaultFilePurpose is the purpose used by UploadFile when none is given; it is the
+// purpose vector stores accept.
+const defaultFilePurpose = "assistants"
+
+// defaultFileSearchResults is the number of chunks retrieved per vector store by
+// CallWithFileSearch.
+const defaultFileSearchResults = 5
+
+// FilesServiceInterface defines the interface for file operations
+type FilesServiceInterface interface {
+	New(ctx context.Context, params openai.FileNewParams) (*openai.FileObject, error)
+	ListAll(ctx context.Context) ([]openai.FileObject, error)
+	Delete(ctx context.Context, fileID string) (*openai.FileDeleted, error)
+}
+
+// VectorStoresServiceInterface defines the interface for vector store operations
+type VectorStoresServiceInterface interface {
+	New(ctx context.Context, params openai.VectorStoreNewParams) (*openai.VectorStore, error)
+	Delete(ctx context.Context, vectorStoreID string) (*openai.VectorStoreDeleted, error)
+	Search(ctx context.Context, vectorStoreID string, params openai.VectorStoreSearchParams) ([]openai.VectorStoreSearchResponse, error)
+}
+
+type FilesServiceWrapper struct {
+	service *openai.FileService
+}
+
+func (w *FilesServiceWrapper) New(ctx context.Context, params openai.FileNewParams) (*openai.FileObject, error) {
+	return w.service.New(ctx, params)
+}
+
+// ListAll follows pagination and returns every file.
+func (w *FilesServiceWrapper) ListAll(ctx context.Context) ([]openai.FileObject, error) {
+	var files []openai.FileObject
+	iter := w.service.ListAutoPaging(ctx, openai.FileListParams{})
+	for iter.Next() {
+		files = append(files, iter.Current())
+	}
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+func (w *FilesServiceWrapper) Delete(ctx context.Context, fileID string) (*openai.FileDeleted, error) {
+	return w.service.Delete(ctx, fileID)
+}
+
+type VectorStoresServiceWrapper struct {
+	service *openai.VectorStoreService
+}
+
+func (w *VectorStoresServiceWrapper) New(ctx context.Context, params openai.VectorStoreNewParams) (*openai.VectorStore, error) {
+	return w.service.New(ctx, params)
+}
+
+func (w *VectorStoresServiceWrapper) Delete(ctx context.Context, vectorStoreID string) (*openai.VectorStoreDeleted, error) {
+	return w.service.Delete(ctx, vectorStoreID)
+}
+
+// Search returns the first page of results, which holds up to max_num_results chunks.
+func (w *VectorStoresServiceWrapper) Search(ctx context.Context, vectorStoreID string, params openai.VectorStoreSearchParams) ([]openai.VectorStoreSearchResponse, error) {
+	page, err := w.service.Search(ctx, vectorStoreID, params)
+	if err != nil {
+		return nil, err
+	}
+	return page.Data, nil
+}
+
+// UploadFile uploads content as a file named filename. purpose is one of the OpenAI file
+// purposes ("assistants", "user_data", "batch", "fine-tune", "vision", "evals"); empty
+// uses "assistants", which vector stores accept.
+//
+// Example:
+//
+//	f, err := os.Open("handbook.pdf")
+//	if err != nil {
+//		return err
+//	}
+//	defer f.Close()
+//	file, err := client.UploadFile(ctx, "handbook.pdf", f, "")
+func (c *OpenAIClient) UploadFile(ctx context.Context, filename string, content io.Reader, purpose string) (_ types.FileInfo, err error) {
+	defer utils.RecoverPanic(&err, c.logger)
+
+	if strings.TrimSpace(filename) == "" || content == nil {
+		return types.FileInfo{}, &types.ErrorResponse{Code: "invalid_request", Message: "file name and content are required"}
+	}
+	if purpose == "" {
+		purpose = defaultFilePurpose
+	}
+	c.logger.Info("Uploading file %s for %s", filename, purpose)
+
+	file, err := c.client.Files().New(ctx, openai.FileNewParams{
+		File:    openai.File(content, filename, fileContentType(filename)),
+		Purpose: openai.FilePurpose(purpose),
+	})
+	if err != nil {
+		c.logger.Error("File upload failed: %s", c.safeErrorString(err))
+		return types.FileInfo{}, c.handleSDKError(err)
+	}
+	return fileInfo(*file), nil
+}
+
+// ListFiles lists the files uploaded to the account or resource.
+func (c *OpenAIClient) ListFiles(ctx context.Context) (_ []types.FileInfo, err error) {
+	defer utils.RecoverPanic(&err, c.logger)
+
+	c.logger.Info("Listing OpenAI files")
+
+	sdkFiles, err := c.client.Files().ListAll(ctx)
+	if err != nil {
+		c.logger.Error("File list request failed: %s", c.safeErrorString(err))
+		return nil, c.handleSDKError(err)
+	}
+
+	files := make([]types.FileInfo, 0, len(sdkFiles))
+	for _, f := range sdkFiles {
+		files = append(files, fileInfo(f))
+	}
+	return files, nil
+}
+
+// DeleteFile deletes an uploaded file.
+func (c *OpenAIClient) DeleteFile(ctx context.Context, fileID string) (err error) {
+	defer utils.RecoverPanic(&err, c.logger)
+
+	c.logger.Info("Deleting file %s", fileID)
+
+	if _, err := c.client.Files().Delete(ctx, fileID); err != nil {
+		c.logger.Error("File delete failed: %s", c.safeErrorString(err))
+		return c.handleSDKError(err)
+	}
+	return nil
+}
+
+// CreateVectorStore creates a vector store that indexes the given uploaded files for
+// file search. Indexing runs in the background; Status is "completed" once every file
+// is searchable.
+func (c *OpenAIClient) CreateVectorStore(ctx context.Context, name string, fileIDs []string) (_ types.VectorStoreInfo, err error) {
+	defer utils.RecoverPanic(&err, c.logger)
+
+	c.logger.Info("Creating vector store %s with %d files", name, len(fileIDs))
+
+	params := openai.VectorStoreNewParams{FileIDs: fileIDs}
+	if name != "" {
+		params.Name = openai.String(name)
+	}
+	store, err := c.client.VectorStores().New(ctx, params)
+	if err != nil {
+		c.logger.Error("Vector store creation failed: %s", c.safeErrorString(err))
+		return types.VectorStoreInfo{}, c.handleSDKError(err)
+	}
+
+	return types.VectorStoreInfo{
+		ID:              store.ID,
+		Name:            store.Name,
+		Status:          string(store.Status),
+		FileCount:       int(store.FileCounts.Total),
+		FilesInProgress: int(store.FileCounts.InProgress),
+	}, nil
+}
+
+// DeleteVectorStore deletes a vector store. The files it indexed are not deleted.
+func (c *OpenAIClient) DeleteVectorStore(ctx context.Context, vectorStoreID string) (err error) {
+	defer utils.RecoverPanic(&err, c.logger)
+
+	c.logger.Info("Deleting vector store %s", vectorStoreID)
+
+	if _, err := c.client.VectorStores().Delete(ctx, vectorStoreID); err != nil {
+		c.logger.Error("Vector store delete failed: %s", c.safeErrorString(err))
+		return c.handleSDKError(err)
+	}
+	return nil
+}
+
+// SearchVectorStore returns up to maxResults file chunks from the vector store that are
+// most relevant to query, best match first. maxResults of zero uses the API default.
+func (c *OpenAIClient) SearchVectorStore(ctx context.Context, vectorStoreID string, query string, maxResults int) (_ []types.FileSearchResult, err error) {
+	defer utils.RecoverPanic(&err, c.logger)
+
+	c.logger.Debug("Searching vector store %s", vectorStoreID)
+
+	params := openai.VectorStoreSearchParams{
+		Query: openai.VectorStoreSearchParamsQueryUnion{OfString: openai.String(query)},
+	}
+	if maxResults > 0 {
+		params.MaxNumResults = openai.Int(int64(maxResults))
+	}
+	hits, err := c.client.VectorStores().Search(ctx, vectorStoreID, params)
+	if err != nil {
+		c.logger.Error("Vector store search failed: %s", c.safeErrorString(err))
+		return nil, c.handleSDKError(err)
+	}
+
+	results := make([]types.FileSearchResult, 0, len(hits))
+	for _, hit := range hits {
+		var text []string
+		for _, content := range hit.Content {
+			if content.Type == "text" {
+				text = append(text, content.Text)
+			}
+		}
+		results = append(results, types.FileSearchResult{
+			FileID:   hit.FileID,
+			Filename: hit.Filename,
+			Score:    hit.Score,
+			Text:     strings.Join(text, "\n"),
+		})
+	}
+	return results, nil
+}
+
+// CallWithFileSearch grounds a completion in uploaded documents: it searches the vector
+// stores for the prompt, sends the matching chunks with the prompt as numbered sources,
+// and returns the chat completion like CallWithPrompt. When nothing matches, the prompt
+// is sent on its own.
+//
+// Example:
+//
+//	store, err := client.CreateVectorStore(ctx, "handbook", []string{file.ID})
+//	response, err := client.CallWithFileSearch(ctx, "How many vacation days do I get?", []string{store.ID})
+func (c *OpenAIClient) CallWithFileSearch(ctx context.Context, prompt string, vectorStoreIDs []string, opts ...types.CallOption) ([]byte, error) {
+	if len(vectorStoreIDs) == 0 {
+		return nil, &types.ErrorResponse{Code: "invalid_request", Message: "at least one vector store ID is required"}
+	}
+
+	var results []types.FileSearchResult
+	for _, id := range vectorStoreIDs {
+		hits, err := c.SearchVectorStore(ctx, id, prompt, defaultFileSearchResults)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, hits...)
+	}
+	if len(results) == 0 {
+		c.logger.Debug("File search found no matches; sending the prompt without sources")
+		return c.CallWithPrompt(ctx, prompt, opts...)
+	}
+
+	return c.CallWithPrompt(ctx, groundedPrompt(prompt, results), opts...)
+}
+
+// groundedPrompt prepends search results to a prompt as numbered sources.
+func groundedPrompt(prompt string, results []types.FileSearchResult) string {
+	var b strings.Builder
+	b.WriteString("Answer using the following sources from uploaded files. Cite sources by number, and say so if they do not contain the answer.\n\n")
+	for i, r := range results {
+		fmt.Fprintf(&b, "[%d] %s\n%s\n\n", i+1, r.Filename, strings.TrimSpace(r.Text))
+	}
+	b.WriteString("Question: ")
+	b.WriteString(prompt)
+	return b.String()
+}
+
+// fileContentType guesses a file's MIME type from its extension.
+func fileContentType(filename string) string {
+	if contentType := mime.TypeByExtension(filepath.Ext(filename)); contentType != "" {
+		return contentType
+	}
+	return "application/octet-stream"
+}
+
+// fileInfo converts an SDK file object.
+func fileInfo(f openai.FileObject) types.FileInfo {
+	return types.FileInfo{
+		ID:        f.ID,
+		Filename:  f.Filename,
+		Bytes:     f.Bytes,
+		Purpose:   string(f.Purpose),
+		CreatedAt: time.Unix(f.CreatedAt, 0).UTC(),
+	}
+}
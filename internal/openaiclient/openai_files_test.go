@@ -0,0 +1,120 @@
+package openaiclient
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kengibson1111/go-aiprovider/aitest"
+	"github.com/kengibson1111/go-aiprovider/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFilesTestClient returns a client for a server that serves the files and vector
+// store endpoints, and records the last chat completion request body.
+func newFilesTestClient(t *testing.T, searchResults string) (*OpenAIClient, *[]byte) {
+	t.Helper()
+
+	var completionBody []byte
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /files", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseMultipartForm(1<<20))
+		file, header, err := r.FormFile("file")
+		require.NoError(t, err)
+		content, _ := io.ReadAll(file)
+		json.NewEncoder(w).Encode(map[string]any{
+			"id": "file-1", "object": "file", "filename": header.Filename, "bytes": len(content),
+			"purpose": r.FormValue("purpose"), "created_at": 1700000000, "status": "processed",
+		})
+	})
+	mux.HandleFunc("GET /files", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"object":"list","data":[{"id":"file-1","object":"file","filename":"handbook.txt","bytes":42,"purpose":"assistants","created_at":1700000000,"status":"processed"}],"has_more":false}`))
+	})
+	mux.HandleFunc("DELETE /files/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":"` + r.PathValue("id") + `","object":"file","deleted":true}`))
+	})
+	mux.HandleFunc("POST /vector_stores", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":"vs-1","object":"vector_store","name":"handbook","status":"in_progress","created_at":1700000000,"usage_bytes":0,"file_counts":{"total":1,"in_progress":1,"completed":0,"failed":0,"cancelled":0}}`))
+	})
+	mux.HandleFunc("POST /vector_stores/{id}/search", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"object":"vector_store.search_results.page","search_query":["q"],"data":` + searchResults + `,"has_more":false,"next_page":null}`))
+	})
+	mux.HandleFunc("POST /chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		completionBody, _ = io.ReadAll(r.Body)
+		w.Write([]byte(`{"id":"chatcmpl-1","object":"chat.completion","created":1700000000,"model":"gpt-4o-mini","choices":[{"index":0,"message":{"role":"assistant","content":"25 days [1]"},"finish_reason":"stop"}]}`))
+	})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	client, err := NewOpenAIClient(&types.AIConfig{Provider: types.ProviderOpenAI, APIKey: aitest.FakeOpenAIKey, BaseURL: srv.URL})
+	require.NoError(t, err)
+	return client, &completionBody
+}
+
+func TestFiles_UploadListDelete(t *testing.T) {
+	client, _ := newFilesTestClient(t, `[]`)
+	ctx := context.Background()
+
+	file, err := client.UploadFile(ctx, "handbook.txt", strings.NewReader("vacation: 25 days"), "")
+	require.NoError(t, err)
+	assert.Equal(t, "file-1", file.ID)
+	assert.Equal(t, "handbook.txt", file.Filename)
+	assert.Equal(t, int64(17), file.Bytes)
+	assert.Equal(t, "assistants", file.Purpose)
+	assert.Equal(t, int64(1700000000), file.CreatedAt.Unix())
+
+	files, err := client.ListFiles(ctx)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, "file-1", files[0].ID)
+
+	require.NoError(t, client.DeleteFile(ctx, "file-1"))
+
+	_, err = client.UploadFile(ctx, "", strings.NewReader("x"), "")
+	assert.Error(t, err)
+}
+
+func TestFiles_CreateVectorStore(t *testing.T) {
+	client, _ := newFilesTestClient(t, `[]`)
+
+	store, err := client.CreateVectorStore(context.Background(), "handbook", []string{"file-1"})
+	require.NoError(t, err)
+	assert.Equal(t, types.VectorStoreInfo{ID: "vs-1", Name: "handbook", Status: "in_progress", FileCount: 1, FilesInProgress: 1}, store)
+}
+
+func TestCallWithFileSearch(t *testing.T) {
+	hits := `[{"file_id":"file-1","filename":"handbook.txt","score":0.9,"attributes":{},"content":[{"type":"text","text":"Employees get 25 vacation days."}]}]`
+	client, body := newFilesTestClient(t, hits)
+	ctx := context.Background()
+
+	results, err := client.SearchVectorStore(ctx, "vs-1", "vacation", 3)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, types.FileSearchResult{FileID: "file-1", Filename: "handbook.txt", Score: 0.9, Text: "Employees get 25 vacation days."}, results[0])
+
+	response, err := client.CallWithFileSearch(ctx, "How many vacation days?", []string{"vs-1"})
+	require.NoError(t, err)
+	assert.Contains(t, string(response), "25 days [1]")
+	assert.Contains(t, string(*body), `[1] handbook.txt\nEmployees get 25 vacation days.`)
+	assert.Contains(t, string(*body), "Question: How many vacation days?")
+
+	_, err = client.CallWithFileSearch(ctx, "anything", nil)
+	assert.Error(t, err)
+}
+
+func TestCallWithFileSearch_NoMatches(t *testing.T) {
+	client, body := newFilesTestClient(t, `[]`)
+
+	_, err := client.CallWithFileSearch(context.Background(), "How many vacation days?", []string{"vs-1"})
+	require.NoError(t, err)
+	assert.NotContains(t, string(*body), "Question:")
+	assert.Contains(t, string(*body), "How many vacation days?")
+}
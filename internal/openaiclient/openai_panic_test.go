@@ -0,0 +1,103 @@
+package openaiclient
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/kengibson1111/go-aiprovider/aitest"
+	"github.com/kengibson1111/go-aiprovider/types"
+	"github.com/openai/openai-go/v2"
+	"github.com/openai/openai-go/v2/option"
+	"github.com/openai/openai-go/v2/packages/ssestream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// panickingSDK is an SDK client whose every call panics, as a buggy SDK release might.
+type panickingSDK struct{}
+
+func (panickingSDK) Chat() ChatServiceInterface                 { return panickingSDK{} }
+func (panickingSDK) Completions() CompletionsServiceInterface   { return panickingSDK{} }
+func (panickingSDK) Models() ModelsServiceInterface             { return panickingSDK{} }
+func (panickingSDK) Files() FilesServiceInterface               { return panickingFiles{} }
+func (panickingSDK) VectorStores() VectorStoresServiceInterface { return panickingVectorStores{} }
+
+func (panickingSDK) New(context.Context, openai.ChatCompletionNewParams) (*openai.ChatCompletion, error) {
+	var completion *openai.ChatCompletion
+	_ = completion.Choices[0] // nil pointer dereference
+	return nil, nil
+}
+
+func (panickingSDK) NewStreaming(context.Context, openai.ChatCompletionNewParams, ...option.RequestOption) *ssestream.Stream[openai.ChatCompletionChunk] {
+	panic("streaming")
+}
+
+func (panickingSDK) ListAll(context.Context) ([]openai.Model, error) { panic("model list") }
+
+// panickingFiles is the files service of panickingSDK.
+type panickingFiles struct{}
+
+func (panickingFiles) New(context.Context, openai.FileNewParams) (*openai.FileObject, error) {
+	panic("file upload")
+}
+
+func (panickingFiles) ListAll(context.Context) ([]openai.FileObject, error) { panic("file list") }
+
+func (panickingFiles) Delete(context.Context, string) (*openai.FileDeleted, error) {
+	panic("file delete")
+}
+
+// panickingVectorStores is the vector stores service of panickingSDK.
+type panickingVectorStores struct{}
+
+func (panickingVectorStores) New(context.Context, openai.VectorStoreNewParams) (*openai.VectorStore, error) {
+	panic("vector store create")
+}
+
+func (panickingVectorStores) Delete(context.Context, string) (*openai.VectorStoreDeleted, error) {
+	panic("vector store delete")
+}
+
+func (panickingVectorStores) Search(context.Context, string, openai.VectorStoreSearchParams) ([]openai.VectorStoreSearchResponse, error) {
+	panic("vector store search")
+}
+
+func TestOpenAIClient_RecoversSDKPanics(t *testing.T) {
+	client, err := NewOpenAIClient(&types.AIConfig{Provider: types.ProviderOpenAI, APIKey: aitest.FakeOpenAIKey, BaseURL: "http://127.0.0.1:1"})
+	require.NoError(t, err)
+	client.client = panickingSDK{}
+	ctx := context.Background()
+
+	calls := map[string]func() error{
+		"ValidateCredentials": func() error { return client.ValidateCredentials(ctx) },
+		"ListModels":          func() error { _, err := client.ListModels(ctx); return err },
+		"CallWithPrompt":      func() error { _, err := client.CallWithPrompt(ctx, "hello"); return err },
+		"CallWithPromptAndVariables": func() error {
+			_, err := client.CallWithPromptAndVariables(ctx, "hello {{name}}", `{"name":"world"}`)
+			return err
+		},
+		"CallWithMessages":     func() error { _, err := client.CallWithMessages(ctx, nil); return err },
+		"CallWithTools":        func() error { _, err := client.CallWithTools(ctx, "hello", nil); return err },
+		"CallWithPromptStream": func() error { _, err := client.CallWithPromptStream(ctx, "hello"); return err },
+		"StreamWithCallback":   func() error { _, err := client.StreamWithCallback(ctx, "hello", func(string) {}); return err },
+		"UploadFile": func() error {
+			_, err := client.UploadFile(ctx, "notes.txt", strings.NewReader("notes"), "")
+			return err
+		},
+		"ListFiles":         func() error { _, err := client.ListFiles(ctx); return err },
+		"DeleteFile":        func() error { return client.DeleteFile(ctx, "file-1") },
+		"CreateVectorStore": func() error { _, err := client.CreateVectorStore(ctx, "docs", nil); return err },
+		"DeleteVectorStore": func() error { return client.DeleteVectorStore(ctx, "vs-1") },
+		"SearchVectorStore": func() error { _, err := client.SearchVectorStore(ctx, "vs-1", "query", 3); return err },
+	}
+
+	for name, call := range calls {
+		t.Run(name, func(t *testing.T) {
+			err := call()
+			require.Error(t, err)
+			assert.ErrorIs(t, err, types.ErrProviderPanic)
+			assert.Contains(t, err.Error(), "panicked")
+		})
+	}
+}
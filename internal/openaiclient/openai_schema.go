@@ -0,0 +1,54 @@
+package openaiclient
+
+import (
+	"github.com/openai/openai-go/v2"
+)
+
+// normalizeCompletion makes a chat completion from an OpenAI-compatible server usable when
+// the server's response departs from the OpenAI schema, as self-hosted servers often do.
+// The SDK already ignores unknown fields and leaves missing ones at their zero values;
+// this fills in the fields callers rely on and logs each kind of anomaly once per client
+// instead of failing the call:
+//   - a missing model is set to the requested model
+//   - a missing choices list is set to an empty list
+//   - a choice without a finish reason gets "stop"
+//   - a missing ID, creation time, or usage is only logged
+//
+// Only clients with a custom BaseURL are normalized; responses from api.openai.com and
+// Azure OpenAI are returned as sent.
+func (c *OpenAIClient) normalizeCompletion(completion *openai.ChatCompletion, requestedModel string) {
+	if !c.compatible {
+		return
+	}
+	if !completion.JSON.ID.Valid() {
+		c.schemaAnomaly("id", "response has no id")
+	}
+	if !completion.JSON.Created.Valid() {
+		c.schemaAnomaly("created", "response has no creation time")
+	}
+	if !completion.JSON.Usage.Valid() {
+		c.schemaAnomaly("usage", "response has no usage; token counts will be zero")
+	}
+	if !completion.JSON.Model.Valid() || completion.Model == "" {
+		c.schemaAnomaly("model", "response has no model; using the requested model %s", requestedModel)
+		completion.Model = requestedModel
+	}
+	if completion.Choices == nil {
+		c.schemaAnomaly("choices", "response has no choices")
+		completion.Choices = []openai.ChatCompletionChoice{}
+	}
+	for i := range completion.Choices {
+		if completion.Choices[i].FinishReason == "" {
+			c.schemaAnomaly("finish_reason", "response choice has no finish_reason; assuming stop")
+			completion.Choices[i].FinishReason = "stop"
+		}
+	}
+}
+
+// schemaAnomaly logs a response schema anomaly the first time this client sees it.
+func (c *OpenAIClient) schemaAnomaly(field string, format string, args ...any) {
+	if _, seen := c.anomalies.LoadOrStore(field, true); seen {
+		return
+	}
+	c.logger.Warn("OpenAI-compatible server sent an off-schema response: "+format, args...)
+}
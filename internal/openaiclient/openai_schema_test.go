@@ -0,0 +1,90 @@
+package openaiclient
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/kengibson1111/go-aiprovider/aitest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeCompletion_OffSchemaResponse(t *testing.T) {
+	offSchema := aitest.Reply{Handler: func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices":[{"index":0,"message":{"role":"assistant","content":"hi","vendor_score":0.3}}],"backend":"llama.cpp"}`))
+	}}
+	srv := aitest.NewFakeOpenAIServer(t)
+	srv.Enqueue(offSchema, offSchema)
+
+	client, err := NewOpenAIClient(srv.Config())
+	require.NoError(t, err)
+
+	for range 2 {
+		response, err := client.CallWithPrompt(context.Background(), "hello")
+		require.NoError(t, err)
+
+		var parsed struct {
+			Model   string `json:"model"`
+			Choices []struct {
+				Message      struct{ Content string } `json:"message"`
+				FinishReason string                   `json:"finish_reason"`
+			} `json:"choices"`
+		}
+		require.NoError(t, json.Unmarshal(response, &parsed))
+		assert.Equal(t, client.model, parsed.Model)
+		require.Len(t, parsed.Choices, 1)
+		assert.Equal(t, "hi", parsed.Choices[0].Message.Content)
+		assert.Equal(t, "stop", parsed.Choices[0].FinishReason)
+	}
+
+	var logged []string
+	client.anomalies.Range(func(key, _ any) bool {
+		logged = append(logged, key.(string))
+		return true
+	})
+	assert.ElementsMatch(t, []string{"id", "created", "usage", "model", "finish_reason"}, logged)
+}
+
+func TestNormalizeCompletion_NoChoices(t *testing.T) {
+	srv := aitest.NewFakeOpenAIServer(t)
+	srv.Enqueue(aitest.Reply{Handler: func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"x","model":"local"}`))
+	}})
+
+	client, err := NewOpenAIClient(srv.Config())
+	require.NoError(t, err)
+
+	completion, err := client.callWithPrompt(context.Background(), "hello")
+	require.NoError(t, err)
+	assert.NotNil(t, completion.Choices)
+	assert.Empty(t, completion.Choices)
+	assert.Equal(t, "local", completion.Model)
+}
+
+func TestNormalizeCompletion_FirstPartyUnchanged(t *testing.T) {
+	srv := aitest.NewFakeOpenAIServer(t)
+	srv.Enqueue(aitest.Reply{Handler: func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"x","choices":[{"index":0,"message":{"role":"assistant","content":"hi"}}]}`))
+	}})
+
+	client, err := NewOpenAIClient(srv.Config())
+	require.NoError(t, err)
+	// As if built without a custom BaseURL, for api.openai.com
+	client.compatible = false
+
+	completion, err := client.callWithPrompt(context.Background(), "hello")
+	require.NoError(t, err)
+	assert.Empty(t, completion.Model)
+	require.Len(t, completion.Choices, 1)
+	assert.Empty(t, completion.Choices[0].FinishReason)
+
+	client.anomalies.Range(func(key, _ any) bool {
+		t.Errorf("anomaly %v logged for a first-party response", key)
+		return true
+	})
+}
@@ -0,0 +1,203 @@
+package openaiclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/kengibson1111/go-aiprovider/internal/shared/utils"
+	"github.com/kengibson1111/go-aiprovider/types"
+	"github.com/openai/openai-go/v2"
+	"github.com/openai/openai-go/v2/option"
+)
+
+// Default streaming limits when AIConfig.StreamIdleTimeout and StreamMaxDuration are
+// not set.
+const (
+	defaultStreamIdleTimeout = 60 * time.Second
+	defaultStreamMaxDuration = 10 * time.Minute
+)
+
+// errStreamIdle is returned by a stream that received no data within the idle timeout.
+var errStreamIdle = errors.New("stream idle timeout")
+
+// streamRequestOptions returns the request options for streaming calls. They replace the
+// per-attempt request timeout with StreamMaxDuration and enforce StreamIdleTimeout
+// between reads. The client's total timeout would also end long streams, so streams use
+// a copy of the built client without it; an injected HTTPClient is used as-is.
+func streamRequestOptions(config *types.AIConfig, httpClient *http.Client) []option.RequestOption {
+	idle := config.StreamIdleTimeout
+	if idle <= 0 {
+		idle = defaultStreamIdleTimeout
+	}
+	maxDuration := config.StreamMaxDuration
+	if maxDuration <= 0 {
+		maxDuration = defaultStreamMaxDuration
+	}
+
+	opts := []option.RequestOption{
+		option.WithRequestTimeout(maxDuration),
+		option.WithMiddleware(func(req *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+			resp, err := next(req)
+			if err == nil && resp.StatusCode < http.StatusMultipleChoices {
+				resp.Body = newIdleTimeoutBody(resp.Body, idle)
+			}
+			return resp, err
+		}),
+	}
+	if config.HTTPClient == nil {
+		streamClient := *httpClient
+		streamClient.Timeout = 0
+		opts = append(opts, option.WithHTTPClient(&streamClient))
+	}
+	return opts
+}
+
+// idleTimeoutBody closes a response body when no data arrives within timeout, so a
+// stalled stream fails instead of hanging until the overall deadline.
+type idleTimeoutBody struct {
+	body    io.ReadCloser
+	timeout time.Duration
+	timer   *time.Timer
+	expired atomic.Bool
+}
+
+func newIdleTimeoutBody(body io.ReadCloser, timeout time.Duration) *idleTimeoutBody {
+	b := &idleTimeoutBody{body: body, timeout: timeout}
+	b.timer = time.AfterFunc(timeout, func() {
+		b.expired.Store(true)
+		body.Close()
+	})
+	return b
+}
+
+func (b *idleTimeoutBody) Read(p []byte) (int, error) {
+	n, err := b.body.Read(p)
+	if b.expired.Load() {
+		return n, fmt.Errorf("%w: no data received for %s", errStreamIdle, b.timeout)
+	}
+	if n > 0 {
+		b.timer.Reset(b.timeout)
+	}
+	return n, err
+}
+
+func (b *idleTimeoutBody) Close() error {
+	b.timer.Stop()
+	return b.body.Close()
+}
+
+// StreamWithCallback streams a completion for the prompt, calling onDelta with each
+// content delta of the first choice as it arrives, and returns the aggregated completion
+// once the stream ends. Usage is requested from the API, so the returned completion's
+// Usage is populated.
+//
+// With types.WithPartialResults, an interrupted stream returns the partial completion
+// together with the error, so content already delivered is not lost. A cancelled or
+// expired context is reported as an error wrapping ctx.Err().
+//
+// Example:
+//
+//	completion, err := client.StreamWithCallback(ctx, "Tell me a story", func(delta string) {
+//		fmt.Print(delta)
+//	})
+//	if err != nil {
+//		return err
+//	}
+//	log.Printf("used %d tokens", completion.Usage.TotalTokens)
+func (c *OpenAIClient) StreamWithCallback(ctx context.Context, prompt string, onDelta func(string), opts ...types.CallOption) (*openai.ChatCompletion, error) {
+	return c.accumulateStream(ctx, prompt, opts, func(delta string) error {
+		if onDelta != nil {
+			onDelta(delta)
+		}
+		return nil
+	})
+}
+
+// StreamToWriter streams a completion for the prompt, writing each content delta of the
+// first choice to w as it arrives, and returns the aggregated completion with usage once
+// the stream ends. Writers that implement http.Flusher (such as an http.ResponseWriter)
+// are flushed after every delta. A write error stops the stream and is returned.
+func (c *OpenAIClient) StreamToWriter(ctx context.Context, prompt string, w io.Writer, opts ...types.CallOption) (*openai.ChatCompletion, error) {
+	if w == nil {
+		return nil, fmt.Errorf("writer is required")
+	}
+	flusher, _ := w.(http.Flusher)
+
+	return c.accumulateStream(ctx, prompt, opts, func(delta string) error {
+		if _, err := io.WriteString(w, delta); err != nil {
+			return err
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		return nil
+	})
+}
+
+// accumulateStream runs a streaming request, passes each first-choice delta to sink, and
+// accumulates the chunks into a complete ChatCompletion. When the stream ends early and
+// PartialResults is set, the accumulated completion is returned with the error.
+func (c *OpenAIClient) accumulateStream(ctx context.Context, prompt string, opts []types.CallOption, sink func(string) error) (_ *openai.ChatCompletion, err error) {
+	defer utils.RecoverPanic(&err, c.logger)
+
+	c.logger.Info("Processing accumulated streaming prompt request")
+	c.logger.Prompt(prompt)
+
+	callOpts := c.callOptions(opts)
+	params := completionParams(promptMessages(prompt, callOpts), callOpts)
+	params.StreamOptions = openai.ChatCompletionStreamOptionsParam{IncludeUsage: openai.Bool(true)}
+
+	stream := c.client.Chat().Completions().NewStreaming(ctx, params, c.streamOptions...)
+	defer stream.Close()
+
+	acc := openai.ChatCompletionAccumulator{}
+	contentChunks := 0
+	fail := func(err error) (*openai.ChatCompletion, error) {
+		if !callOpts.PartialResults {
+			return nil, err
+		}
+		partial := acc.ChatCompletion
+		if partial.Usage.CompletionTokens == 0 {
+			// The usage chunk arrives last, so estimate from the deltas received, which
+			// OpenAI sends about one token at a time
+			partial.Usage.CompletionTokens = int64(contentChunks)
+			partial.Usage.TotalTokens = partial.Usage.PromptTokens + partial.Usage.CompletionTokens
+		}
+		return &partial, err
+	}
+
+	for stream.Next() {
+		chunk := stream.Current()
+		acc.AddChunk(chunk)
+
+		for _, choice := range chunk.Choices {
+			if choice.Delta.Content != "" {
+				contentChunks++
+			}
+			if choice.Index != 0 || choice.Delta.Content == "" {
+				continue
+			}
+			if err := sink(choice.Delta.Content); err != nil {
+				c.logger.Error("Stream sink failed: %v", err)
+				return fail(&types.ErrorResponse{Code: "stream_sink_error", Message: fmt.Sprintf("failed to deliver streamed content: %v", err)})
+			}
+		}
+	}
+
+	if err := stream.Err(); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			c.logger.Warn("Stream interrupted after %d content chunks: %v", contentChunks, ctxErr)
+			return fail(fmt.Errorf("stream interrupted: %w", ctxErr))
+		}
+		c.logger.Error("Streaming completion request failed: %s", c.safeErrorString(err))
+		return fail(c.handleStreamingError(err))
+	}
+
+	c.logger.Debug("Stream completed with %d choices", len(acc.Choices))
+	return &acc.ChatCompletion, nil
+}
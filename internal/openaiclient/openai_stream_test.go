@@ -0,0 +1,172 @@
+package openaiclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kengibson1111/go-aiprovider/aitest"
+	"github.com/kengibson1111/go-aiprovider/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingWriter fails every write.
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) { return 0, errors.New("disk full") }
+
+func TestStreamWithCallback(t *testing.T) {
+	srv := aitest.NewFakeOpenAIServer(t)
+	srv.Enqueue(aitest.Stream("Once ", "upon ", "a time"))
+
+	client, err := NewOpenAIClient(srv.Config())
+	require.NoError(t, err)
+
+	var deltas []string
+	completion, err := client.StreamWithCallback(context.Background(), "tell a story", func(delta string) {
+		deltas = append(deltas, delta)
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"Once ", "upon ", "a time"}, deltas)
+	require.Len(t, completion.Choices, 1)
+	assert.Equal(t, "Once upon a time", completion.Choices[0].Message.Content)
+	assert.Equal(t, "stop", completion.Choices[0].FinishReason)
+	assert.Positive(t, completion.Usage.TotalTokens)
+	assert.Contains(t, string(srv.Requests()[0].Body), `"include_usage":true`)
+}
+
+func TestStreamToWriter(t *testing.T) {
+	srv := aitest.NewFakeOpenAIServer(t)
+	srv.Enqueue(aitest.Stream("a", "b", "c"), aitest.Stream("x"))
+
+	client, err := NewOpenAIClient(srv.Config())
+	require.NoError(t, err)
+
+	var b strings.Builder
+	completion, err := client.StreamToWriter(context.Background(), "letters", &b)
+	require.NoError(t, err)
+	assert.Equal(t, "abc", b.String())
+	assert.Equal(t, "abc", completion.Choices[0].Message.Content)
+
+	_, err = client.StreamToWriter(context.Background(), "letters", failingWriter{})
+	var errResp *types.ErrorResponse
+	require.True(t, errors.As(err, &errResp))
+	assert.Equal(t, "stream_sink_error", errResp.Code)
+
+	_, err = client.StreamToWriter(context.Background(), "letters", nil)
+	assert.Error(t, err)
+}
+
+func TestStreamWithCallback_APIError(t *testing.T) {
+	srv := aitest.NewFakeOpenAIServer(t)
+	srv.Enqueue(aitest.Error(http.StatusBadRequest, "invalid_request_error", "bad prompt"))
+
+	client, err := NewOpenAIClient(srv.Config())
+	require.NoError(t, err)
+
+	called := false
+	_, err = client.StreamWithCallback(context.Background(), "x", func(string) { called = true })
+	assert.Error(t, err)
+	assert.False(t, called)
+}
+
+func TestStreamWithCallback_PartialResultsOnCancel(t *testing.T) {
+	// The handler sends two deltas and then stalls until the client goes away
+	stalled := aitest.Reply{Handler: func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, text := range []string{"Hello", " world"} {
+			fmt.Fprintf(w, "data: {\"id\":\"c1\",\"object\":\"chat.completion.chunk\",\"created\":1,\"model\":\"gpt-4o\",\"choices\":[{\"index\":0,\"delta\":{\"content\":%q}}]}\n\n", text)
+		}
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}}
+
+	srv := aitest.NewFakeOpenAIServer(t)
+	srv.Enqueue(stalled, stalled)
+
+	client, err := NewOpenAIClient(srv.Config())
+	require.NoError(t, err)
+
+	run := func(opts ...types.CallOption) (string, error) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		received := 0
+		completion, err := client.StreamWithCallback(ctx, "greet", func(string) {
+			received++
+			if received == 2 {
+				cancel()
+			}
+		}, opts...)
+		if completion == nil {
+			return "", err
+		}
+		assert.Equal(t, int64(2), completion.Usage.CompletionTokens)
+		return completion.Choices[0].Message.Content, err
+	}
+
+	content, err := run(types.WithPartialResults())
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, "Hello world", content)
+
+	content, err = run()
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, content, "no partial result without the option")
+}
+
+// slowStream replies with an OpenAI stream that sends one chunk per delay. With stall
+// set, it stops after the first chunk and holds the connection open.
+func slowStream(chunks []string, delay time.Duration, stall bool) aitest.Reply {
+	return aitest.Reply{Handler: func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		flusher := w.(http.Flusher)
+		for i, chunk := range chunks {
+			fmt.Fprintf(w, "data: {\"id\":\"chatcmpl-1\",\"object\":\"chat.completion.chunk\",\"created\":1700000000,\"model\":\"gpt-4o-mini\",\"choices\":[{\"index\":0,\"delta\":{\"content\":%q}}]}\n\n", chunk)
+			flusher.Flush()
+			if stall && i == 0 {
+				<-r.Context().Done()
+				return
+			}
+			time.Sleep(delay)
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+		flusher.Flush()
+	}}
+}
+
+func TestStream_OutlastsRequestTimeout(t *testing.T) {
+	srv := aitest.NewFakeOpenAIServer(t)
+	srv.Enqueue(slowStream([]string{"a", "b", "c", "d"}, 100*time.Millisecond, false))
+
+	config := srv.Config()
+	config.RequestTimeout = 150 * time.Millisecond
+	client, err := NewOpenAIClient(config)
+	require.NoError(t, err)
+
+	completion, err := client.StreamWithCallback(context.Background(), "letters", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "abcd", completion.Choices[0].Message.Content)
+}
+
+func TestStream_IdleTimeout(t *testing.T) {
+	srv := aitest.NewFakeOpenAIServer(t)
+	srv.Enqueue(slowStream([]string{"partial ", "never sent"}, 0, true))
+
+	config := srv.Config()
+	config.StreamIdleTimeout = 100 * time.Millisecond
+	client, err := NewOpenAIClient(config)
+	require.NoError(t, err)
+
+	completion, err := client.StreamWithCallback(context.Background(), "story", nil, types.WithPartialResults())
+	var errResp *types.ErrorResponse
+	require.True(t, errors.As(err, &errResp), "got %v", err)
+	assert.Equal(t, "streaming_timeout", errResp.Code)
+	assert.Contains(t, errResp.Message, "no data received for 100ms")
+	require.NotNil(t, completion)
+	assert.Equal(t, "partial ", completion.Choices[0].Message.Content)
+}
@@ -0,0 +1,59 @@
+package openaiclient
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kengibson1111/go-aiprovider/aitest"
+	"github.com/kengibson1111/go-aiprovider/types"
+	"github.com/openai/openai-go/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCallWithTools_ToolChoice(t *testing.T) {
+	tools := []openai.ChatCompletionToolUnionParam{
+		openai.ChatCompletionFunctionTool(openai.FunctionDefinitionParam{Name: "get_weather"}),
+	}
+
+	tests := []struct {
+		name     string
+		opts     []types.CallOption
+		contains []string
+		omits    []string
+	}{
+		{
+			name:  "provider defaults",
+			omits: []string{`"tool_choice"`, `"parallel_tool_calls"`},
+		},
+		{
+			name:     "required without parallel calls",
+			opts:     []types.CallOption{types.WithToolChoice(types.ToolChoiceRequired), types.WithParallelToolCalls(false)},
+			contains: []string{`"tool_choice":"required"`, `"parallel_tool_calls":false`},
+		},
+		{
+			name:     "specific function",
+			opts:     []types.CallOption{types.WithToolChoice("get_weather")},
+			contains: []string{`"tool_choice":{"function":{"name":"get_weather"},"type":"function"}`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := aitest.NewFakeOpenAIServer(t)
+			client, err := NewOpenAIClient(srv.Config())
+			require.NoError(t, err)
+
+			_, err = client.CallWithTools(context.Background(), "Weather in Paris?", tools, tt.opts...)
+			require.NoError(t, err)
+
+			body := string(srv.Requests()[0].Body)
+			for _, s := range tt.contains {
+				assert.Contains(t, body, s)
+			}
+			for _, s := range tt.omits {
+				assert.NotContains(t, body, s)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package openaiclient
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kengibson1111/go-aiprovider/aitest"
+	"github.com/kengibson1111/go-aiprovider/internal/shared/utils"
+	"github.com/kengibson1111/go-aiprovider/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type countingTransport struct {
+	requests atomic.Int32
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.requests.Add(1)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestCreateOptimizedHTTPClient_Timeouts(t *testing.T) {
+	httpClient, err := createOptimizedHTTPClient(&types.AIConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Second, httpClient.Timeout)
+
+	httpClient, err = createOptimizedHTTPClient(&types.AIConfig{
+		RequestTimeout:        5 * time.Minute,
+		ResponseHeaderTimeout: 2 * time.Minute,
+		IdleConnTimeout:       time.Minute,
+		MaxIdleConnsPerHost:   50,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Minute+5*time.Second, httpClient.Timeout)
+	transport := httpClient.Transport.(*utils.PoolStatsTransport).Unwrap().(*http.Transport)
+	assert.Equal(t, 2*time.Minute, transport.ResponseHeaderTimeout)
+	assert.Equal(t, time.Minute, transport.IdleConnTimeout)
+	assert.Equal(t, 50, transport.MaxIdleConnsPerHost)
+}
+
+func TestNewOpenAIClient_HTTPClient(t *testing.T) {
+	srv := aitest.NewFakeOpenAIServer(t)
+	transport := &countingTransport{}
+	injected := &http.Client{Transport: transport, Timeout: 10 * time.Minute}
+
+	config := srv.Config()
+	config.HTTPClient = injected
+	client, err := NewOpenAIClient(config)
+	require.NoError(t, err)
+	assert.Same(t, injected, client.httpClient)
+
+	_, err = client.CallWithPrompt(context.Background(), "hello")
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), transport.requests.Load())
+}
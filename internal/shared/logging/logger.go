@@ -1,8 +1,11 @@
 package logging
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
 	"os"
+	"regexp"
 	"strings"
 	"time"
 )
@@ -41,10 +44,26 @@ type Logger interface {
 	Error(format string, args ...any)
 }
 
-// DefaultLogger implements the Logger interface with configurable verbosity
+// DefaultLogger implements the Logger interface with configurable verbosity.
+// API keys and bearer tokens are redacted from every message.
 type DefaultLogger struct {
-	Level   LogLevel
-	verbose bool
+	Level         LogLevel
+	verbose       bool
+	slog          *slog.Logger
+	redactPrompts bool
+}
+
+// Options configures a logger created with NewLogger
+type Options struct {
+	// Level is "debug", "info", "warn", or "error". Empty uses LOG_LEVEL. "debug" also
+	// enables Debug messages, which otherwise require VERBOSE.
+	Level string
+
+	// Slog, if set, receives log records instead of stdout.
+	Slog *slog.Logger
+
+	// RedactPrompts replaces prompt text logged with Prompt by its length.
+	RedactPrompts bool
 }
 
 // NewDefaultLogger creates a new logger with configuration from environment variables
@@ -81,6 +100,54 @@ func NewDefaultLogger() *DefaultLogger {
 	return logger
 }
 
+// NewLogger creates a logger from environment defaults (as NewDefaultLogger) with opts
+// applied on top. Unknown levels are ignored.
+func NewLogger(opts Options) *DefaultLogger {
+	logger := NewDefaultLogger()
+	if level, ok := ParseLogLevel(opts.Level); ok {
+		logger.Level = level
+		if level == LogLevelDebug {
+			logger.verbose = true
+		}
+	}
+	logger.slog = opts.Slog
+	logger.redactPrompts = opts.RedactPrompts
+	return logger
+}
+
+// ParseLogLevel parses a level name ("debug", "info", "warn" or "warning", "error"),
+// ignoring case.
+func ParseLogLevel(name string) (LogLevel, bool) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "debug":
+		return LogLevelDebug, true
+	case "info":
+		return LogLevelInfo, true
+	case "warn", "warning":
+		return LogLevelWarn, true
+	case "error":
+		return LogLevelError, true
+	}
+	return 0, false
+}
+
+// secretPatterns match credentials that must never reach the logs.
+var secretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`sk-ant-[A-Za-z0-9_-]{8,}`),
+	regexp.MustCompile(`sk-[A-Za-z0-9_-]{16,}`),
+	regexp.MustCompile(`(?i)(bearer\s+)[A-Za-z0-9._~+/-]+=*`),
+	regexp.MustCompile(`(?i)((?:x-)?api-key["']?\s*[:=]\s*["']?)[^\s"',}]+`),
+	regexp.MustCompile(`(?:AKIA|ASIA)[0-9A-Z]{16}`),
+}
+
+// RedactSecrets replaces API keys, bearer tokens, and AWS access key IDs in s with [REDACTED].
+func RedactSecrets(s string) string {
+	for _, pattern := range secretPatterns {
+		s = pattern.ReplaceAllString(s, "${1}[REDACTED]")
+	}
+	return s
+}
+
 // SetVerbose overrides the verbose setting (for CLI flag integration)
 func (l *DefaultLogger) SetVerbose(verbose bool) {
 	l.verbose = verbose
@@ -136,11 +203,33 @@ func (l *DefaultLogger) Status(format string, args ...any) {
 	l.log(LogLevelInfo, format, args...)
 }
 
+// Prompt logs prompt text at debug level, or only its length when prompts are redacted
+func (l *DefaultLogger) Prompt(prompt string) {
+	if l.redactPrompts {
+		l.Debug("Prompt: [%d characters redacted]", len(prompt))
+		return
+	}
+	l.Debug("Prompt: %s", prompt)
+}
+
+// slogLevels maps log levels to slog levels
+var slogLevels = map[LogLevel]slog.Level{
+	LogLevelDebug: slog.LevelDebug,
+	LogLevelInfo:  slog.LevelInfo,
+	LogLevelWarn:  slog.LevelWarn,
+	LogLevelError: slog.LevelError,
+}
+
 // log is the internal logging method
 func (l *DefaultLogger) log(level LogLevel, format string, args ...any) {
+	message := RedactSecrets(fmt.Sprintf(format, args...))
+
+	if l.slog != nil {
+		l.slog.Log(context.Background(), slogLevels[level], message)
+		return
+	}
+
 	timestamp := time.Now().Format("2006-01-02 15:04:05")
 	prefix := fmt.Sprintf("[%s] [%s] ", timestamp, level.String())
-
-	message := fmt.Sprintf(format, args...)
 	fmt.Printf("%s%s\n", prefix, message)
 }
@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"github.com/kengibson1111/go-aiprovider/types"
+)
+
+// ErrInvalidGenerationParams is returned when AIConfig generation parameters are out of range.
+// It is the same error as types.ErrInvalidGenerationParams.
+var ErrInvalidGenerationParams = types.ErrInvalidGenerationParams
+
+// CallDefaultsFromConfig validates the generation parameters on an AIConfig and returns
+// them as the default CallOptions for a client. Model, MaxTokens, and Temperature are
+// left for the caller to fill in, since each provider applies its own defaults.
+func CallDefaultsFromConfig(config *types.AIConfig) (types.CallOptions, error) {
+	if err := config.ValidateGenerationParams(); err != nil {
+		return types.CallOptions{}, err
+	}
+
+	defaults := types.CallOptions{
+		SystemPrompt:     config.SystemPrompt,
+		N:                config.N,
+		Stop:             config.Stop,
+		FrequencyPenalty: config.FrequencyPenalty,
+		PresencePenalty:  config.PresencePenalty,
+	}
+	if config.TopP != 0 {
+		topP := config.TopP
+		defaults.TopP = &topP
+	}
+	if config.Seed != nil {
+		seed := *config.Seed
+		defaults.Seed = &seed
+	}
+	if config.Reasoning {
+		defaults.ReasoningBudget = config.ReasoningBudget
+		if defaults.ReasoningBudget == 0 {
+			defaults.ReasoningBudget = types.DefaultReasoningBudget
+		}
+	}
+
+	return defaults, nil
+}
@@ -0,0 +1,102 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/kengibson1111/go-aiprovider/types"
+)
+
+func TestCallDefaultsFromConfig(t *testing.T) {
+	seed := int64(42)
+	config := &types.AIConfig{
+		SystemPrompt:     "Be brief.",
+		TopP:             0.9,
+		FrequencyPenalty: 0.5,
+		PresencePenalty:  -0.5,
+		Stop:             []string{"\n\n"},
+		Seed:             &seed,
+		N:                2,
+	}
+
+	defaults, err := CallDefaultsFromConfig(config)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if defaults.SystemPrompt != "Be brief." || defaults.N != 2 {
+		t.Errorf("Expected system prompt and N to be copied, got %+v", defaults)
+	}
+	if defaults.TopP == nil || *defaults.TopP != 0.9 {
+		t.Errorf("Expected TopP 0.9, got %v", defaults.TopP)
+	}
+	if defaults.FrequencyPenalty != 0.5 || defaults.PresencePenalty != -0.5 {
+		t.Errorf("Expected penalties 0.5/-0.5, got %g/%g", defaults.FrequencyPenalty, defaults.PresencePenalty)
+	}
+	if len(defaults.Stop) != 1 || defaults.Stop[0] != "\n\n" {
+		t.Errorf("Expected stop sequences to be copied, got %q", defaults.Stop)
+	}
+	if defaults.Seed == nil || *defaults.Seed != 42 {
+		t.Errorf("Expected seed 42, got %v", defaults.Seed)
+	}
+
+	// Changing the config afterwards must not leak into the defaults
+	seed = 7
+	if *defaults.Seed != 42 {
+		t.Errorf("Expected seed to be copied, got %d", *defaults.Seed)
+	}
+
+	empty, err := CallDefaultsFromConfig(&types.AIConfig{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if empty.TopP != nil || empty.Seed != nil {
+		t.Errorf("Expected unset TopP and Seed, got %v and %v", empty.TopP, empty.Seed)
+	}
+	if empty.ReasoningBudget != 0 {
+		t.Errorf("Expected reasoning to be disabled, got budget %d", empty.ReasoningBudget)
+	}
+
+	reasoning, err := CallDefaultsFromConfig(&types.AIConfig{Reasoning: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if reasoning.ReasoningBudget != types.DefaultReasoningBudget {
+		t.Errorf("Expected default reasoning budget, got %d", reasoning.ReasoningBudget)
+	}
+
+	budgetOnly, _ := CallDefaultsFromConfig(&types.AIConfig{ReasoningBudget: 4096})
+	if budgetOnly.ReasoningBudget != 0 {
+		t.Errorf("Expected reasoning to need the Reasoning flag, got budget %d", budgetOnly.ReasoningBudget)
+	}
+}
+
+func TestCallDefaultsFromConfig_Invalid(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    types.AIConfig
+		errorText string
+	}{
+		{name: "Negative max tokens", config: types.AIConfig{MaxTokens: -1}, errorText: "maxTokens"},
+		{name: "Temperature too high", config: types.AIConfig{Temperature: 2.5}, errorText: "temperature"},
+		{name: "TopP too high", config: types.AIConfig{TopP: 1.1}, errorText: "topP"},
+		{name: "Negative TopP", config: types.AIConfig{TopP: -0.1}, errorText: "topP"},
+		{name: "Frequency penalty too low", config: types.AIConfig{FrequencyPenalty: -2.1}, errorText: "frequencyPenalty"},
+		{name: "Presence penalty too high", config: types.AIConfig{PresencePenalty: 3}, errorText: "presencePenalty"},
+		{name: "Too many choices", config: types.AIConfig{N: 129}, errorText: "n must be"},
+		{name: "Empty stop sequence", config: types.AIConfig{Stop: []string{"END", ""}}, errorText: "stop sequence 1"},
+		{name: "Negative reasoning budget", config: types.AIConfig{Reasoning: true, ReasoningBudget: -1}, errorText: "reasoningBudget"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := CallDefaultsFromConfig(&tt.config)
+			if !errors.Is(err, ErrInvalidGenerationParams) {
+				t.Fatalf("Expected ErrInvalidGenerationParams, got %v", err)
+			}
+			if !strings.Contains(err.Error(), tt.errorText) {
+				t.Errorf("Expected error to mention %q, got %q", tt.errorText, err.Error())
+			}
+		})
+	}
+}
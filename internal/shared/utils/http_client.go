@@ -31,6 +31,17 @@ func NewBaseHTTPClient(baseURL, apiKey string, timeout time.Duration) *BaseHTTPC
 	}
 }
 
+// SetLogger replaces the logger used for request logging
+func (c *BaseHTTPClient) SetLogger(logger *logging.DefaultLogger) {
+	c.logger = logger
+}
+
+// CloseIdleConnections closes idle keep-alive connections. Requests in flight are not
+// affected, and later requests open new connections.
+func (c *BaseHTTPClient) CloseIdleConnections() {
+	c.HttpClient.CloseIdleConnections()
+}
+
 // HTTPRequest represents an HTTP request configuration
 type HTTPRequest struct {
 	Method  string
@@ -72,6 +83,7 @@ func (c *BaseHTTPClient) DoRequest(ctx context.Context, req HTTPRequest) (*HTTPR
 
 	for attempt := 0; attempt <= maxRetries; attempt++ {
 		resp, err = c.HttpClient.Do(httpReq)
+		RecordResponseMeta(ctx, resp)
 		if err != nil {
 			// Check if this is a network-related error
 			isNetworkError := c.isNetworkError(err)
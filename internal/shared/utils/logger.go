@@ -0,0 +1,15 @@
+package utils
+
+import (
+	"github.com/kengibson1111/go-aiprovider/internal/shared/logging"
+	"github.com/kengibson1111/go-aiprovider/types"
+)
+
+// LoggerFromConfig creates a client logger from the logging settings on an AIConfig.
+func LoggerFromConfig(config *types.AIConfig) *logging.DefaultLogger {
+	return logging.NewLogger(logging.Options{
+		Level:         config.LogLevel,
+		Slog:          config.Logger,
+		RedactPrompts: config.RedactPrompts,
+	})
+}
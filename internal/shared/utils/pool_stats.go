@@ -0,0 +1,100 @@
+package utils
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"sync/atomic"
+
+	"github.com/kengibson1111/go-aiprovider/types"
+)
+
+// PoolStatsTransport wraps a transport and counts requests and connection reuse for
+// ConnPoolStats. A request is in flight until its response body is closed, so open
+// streams are counted.
+type PoolStatsTransport struct {
+	base http.RoundTripper
+
+	requests    atomic.Int64
+	inFlight    atomic.Int64
+	newConns    atomic.Int64
+	reusedConns atomic.Int64
+	idleConns   atomic.Int64
+}
+
+// NewPoolStatsTransport returns a transport that records statistics for requests sent
+// through base.
+func NewPoolStatsTransport(base http.RoundTripper) *PoolStatsTransport {
+	return &PoolStatsTransport{base: base}
+}
+
+// RoundTrip sends the request through the wrapped transport.
+func (t *PoolStatsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.requests.Add(1)
+	t.inFlight.Add(1)
+
+	trace := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			if info.Reused {
+				t.reusedConns.Add(1)
+			} else {
+				t.newConns.Add(1)
+			}
+			if info.WasIdle {
+				t.idleConns.Add(-1)
+			}
+		},
+		PutIdleConn: func(err error) {
+			if err == nil {
+				t.idleConns.Add(1)
+			}
+		},
+	}
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		t.inFlight.Add(-1)
+		return nil, err
+	}
+	resp.Body = &inFlightBody{ReadCloser: resp.Body, done: func() { t.inFlight.Add(-1) }}
+	return resp, nil
+}
+
+// CloseIdleConnections closes the wrapped transport's idle connections, so
+// http.Client.CloseIdleConnections still reaches it.
+func (t *PoolStatsTransport) CloseIdleConnections() {
+	if closer, ok := t.base.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+		t.idleConns.Store(0)
+	}
+}
+
+// Unwrap returns the wrapped transport.
+func (t *PoolStatsTransport) Unwrap() http.RoundTripper {
+	return t.base
+}
+
+// Stats returns the statistics recorded so far.
+func (t *PoolStatsTransport) Stats() types.ConnPoolStats {
+	return types.ConnPoolStats{
+		Requests:    t.requests.Load(),
+		InFlight:    t.inFlight.Load(),
+		NewConns:    t.newConns.Load(),
+		ReusedConns: t.reusedConns.Load(),
+		IdleConns:   max(t.idleConns.Load(), 0),
+	}
+}
+
+// inFlightBody calls done once when the response body is closed.
+type inFlightBody struct {
+	io.ReadCloser
+	once sync.Once
+	done func()
+}
+
+func (b *inFlightBody) Close() error {
+	b.once.Do(b.done)
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,28 @@
+package utils
+
+import (
+	"fmt"
+	"runtime/debug"
+
+	"github.com/kengibson1111/go-aiprovider/internal/shared/logging"
+	"github.com/kengibson1111/go-aiprovider/types"
+)
+
+// RecoverPanic converts a panic into a provider_panic error stored in *err, and logs the
+// panic with its stack trace. Defer it in functions that call into a provider SDK, so a
+// bug in the SDK fails the call instead of crashing the host process:
+//
+//	func (c *Client) call(ctx context.Context) (_ *Response, err error) {
+//		defer utils.RecoverPanic(&err, c.logger)
+//		return c.sdk.Call(ctx)
+//	}
+func RecoverPanic(err *error, logger *logging.DefaultLogger) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	if logger != nil {
+		logger.Error("Recovered from panic in provider call: %v\n%s", r, debug.Stack())
+	}
+	*err = &types.ErrorResponse{Code: "provider_panic", Message: fmt.Sprintf("provider call panicked: %v", r)}
+}
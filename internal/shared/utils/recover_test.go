@@ -0,0 +1,31 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kengibson1111/go-aiprovider/types"
+)
+
+func TestRecoverPanic(t *testing.T) {
+	call := func(fn func() error) (err error) {
+		defer RecoverPanic(&err, nil)
+		return fn()
+	}
+
+	err := call(func() error { panic("boom") })
+	if !errors.Is(err, types.ErrProviderPanic) {
+		t.Fatalf("error = %v, want ErrProviderPanic", err)
+	}
+	if err.Error() != "provider_panic: provider call panicked: boom" {
+		t.Errorf("error = %q", err.Error())
+	}
+
+	want := errors.New("plain failure")
+	if err := call(func() error { return want }); err != want {
+		t.Errorf("error = %v, want %v unchanged", err, want)
+	}
+	if err := call(func() error { return nil }); err != nil {
+		t.Errorf("error = %v, want nil", err)
+	}
+}
@@ -0,0 +1,102 @@
+package utils
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kengibson1111/go-aiprovider/types"
+)
+
+// RecordResponseMeta fills in the ResponseMeta attached to ctx, if any, from the headers
+// of resp. It understands the OpenAI (x-ratelimit-*) and Anthropic
+// (anthropic-ratelimit-*) rate limit headers.
+func RecordResponseMeta(ctx context.Context, resp *http.Response) {
+	meta := types.ResponseMetaFromContext(ctx)
+	if meta == nil || resp == nil {
+		return
+	}
+	h := resp.Header
+	now := time.Now()
+
+	*meta = types.ResponseMeta{
+		StatusCode:        resp.StatusCode,
+		RequestID:         firstHeader(h, "x-request-id", "request-id", "apim-request-id"),
+		RemainingRequests: headerInt(firstHeader(h, "x-ratelimit-remaining-requests", "anthropic-ratelimit-requests-remaining")),
+		RemainingTokens:   headerInt(firstHeader(h, "x-ratelimit-remaining-tokens", "anthropic-ratelimit-tokens-remaining")),
+		ResetRequests:     resetDuration(firstHeader(h, "x-ratelimit-reset-requests", "anthropic-ratelimit-requests-reset"), now),
+		ResetTokens:       resetDuration(firstHeader(h, "x-ratelimit-reset-tokens", "anthropic-ratelimit-tokens-reset"), now),
+		RetryAfter:        retryAfter(h, now),
+		Header:            h.Clone(),
+	}
+}
+
+// firstHeader returns the first of the named headers that is set.
+func firstHeader(h http.Header, names ...string) string {
+	for _, name := range names {
+		if value := h.Get(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// headerInt parses a count header, returning -1 when it is missing or invalid.
+func headerInt(value string) int {
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
+// resetDuration parses a rate limit reset header: a duration such as "6m0s" or "20ms"
+// (OpenAI) or an RFC 3339 time (Anthropic).
+func resetDuration(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return d
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
+
+// retryAfter parses retry-after-ms, or retry-after in seconds or as an HTTP date.
+func retryAfter(h http.Header, now time.Time) time.Duration {
+	if ms, err := strconv.ParseFloat(h.Get("retry-after-ms"), 64); err == nil && ms > 0 {
+		return time.Duration(ms * float64(time.Millisecond))
+	}
+	value := strings.TrimSpace(h.Get("retry-after"))
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+		return time.Duration(seconds * float64(time.Second))
+	}
+	if t, err := http.ParseTime(value); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
+
+// RetryDelay returns how long the headers of an error response ask the client to wait:
+// the Retry-After delay, or else the reset time of an exhausted request or token limit.
+// It returns zero when the headers say neither.
+func RetryDelay(h http.Header) time.Duration {
+	now := time.Now()
+	if d := retryAfter(h, now); d > 0 {
+		return d
+	}
+	var delay time.Duration
+	if headerInt(firstHeader(h, "x-ratelimit-remaining-requests", "anthropic-ratelimit-requests-remaining")) == 0 {
+		delay = resetDuration(firstHeader(h, "x-ratelimit-reset-requests", "anthropic-ratelimit-requests-reset"), now)
+	}
+	if headerInt(firstHeader(h, "x-ratelimit-remaining-tokens", "anthropic-ratelimit-tokens-remaining")) == 0 {
+		delay = max(delay, resetDuration(firstHeader(h, "x-ratelimit-reset-tokens", "anthropic-ratelimit-tokens-reset"), now))
+	}
+	return delay
+}
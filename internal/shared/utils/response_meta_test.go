@@ -0,0 +1,114 @@
+package utils
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/kengibson1111/go-aiprovider/types"
+)
+
+func TestRecordResponseMeta(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name   string
+		header http.Header
+		want   types.ResponseMeta
+	}{
+		{
+			name: "OpenAI",
+			header: http.Header{
+				"X-Request-Id":                   {"req_1"},
+				"X-Ratelimit-Remaining-Requests": {"99"},
+				"X-Ratelimit-Remaining-Tokens":   {"1000"},
+				"X-Ratelimit-Reset-Requests":     {"6m0s"},
+				"X-Ratelimit-Reset-Tokens":       {"20ms"},
+				"Retry-After-Ms":                 {"1500"},
+			},
+			want: types.ResponseMeta{RequestID: "req_1", RemainingRequests: 99, RemainingTokens: 1000, ResetRequests: 6 * time.Minute, ResetTokens: 20 * time.Millisecond, RetryAfter: 1500 * time.Millisecond},
+		},
+		{
+			name: "Azure",
+			header: http.Header{
+				"Apim-Request-Id": {"azure-1"},
+				"Retry-After":     {"3"},
+			},
+			want: types.ResponseMeta{RequestID: "azure-1", RemainingRequests: -1, RemainingTokens: -1, RetryAfter: 3 * time.Second},
+		},
+		{
+			name: "Anthropic with invalid values",
+			header: http.Header{
+				"Request-Id":                             {"req_2"},
+				"Anthropic-Ratelimit-Requests-Remaining": {"many"},
+				"Anthropic-Ratelimit-Tokens-Reset":       {now.Add(-time.Minute).UTC().Format(time.RFC3339)},
+				"Retry-After":                            {"soon"},
+			},
+			want: types.ResponseMeta{RequestID: "req_2", RemainingRequests: -1, RemainingTokens: -1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, meta := types.WithResponseMeta(context.Background())
+			RecordResponseMeta(ctx, &http.Response{StatusCode: http.StatusTooManyRequests, Header: tt.header})
+
+			got := *meta
+			got.Header = nil
+			tt.want.StatusCode = http.StatusTooManyRequests
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RecordResponseMeta() = %+v, want %+v", got, tt.want)
+			}
+			if meta.Header.Get("Retry-After") != tt.header.Get("Retry-After") {
+				t.Error("Header not recorded")
+			}
+		})
+	}
+}
+
+func TestRecordResponseMeta_DateValues(t *testing.T) {
+	ctx, meta := types.WithResponseMeta(context.Background())
+	later := time.Now().Add(time.Hour)
+	RecordResponseMeta(ctx, &http.Response{Header: http.Header{
+		"Anthropic-Ratelimit-Requests-Reset": {later.UTC().Format(time.RFC3339)},
+		"Retry-After":                        {later.UTC().Format(http.TimeFormat)},
+	}})
+
+	if meta.ResetRequests < 59*time.Minute || meta.ResetRequests > time.Hour {
+		t.Errorf("ResetRequests = %v, want about 1h", meta.ResetRequests)
+	}
+	if meta.RetryAfter < 59*time.Minute || meta.RetryAfter > time.Hour {
+		t.Errorf("RetryAfter = %v, want about 1h", meta.RetryAfter)
+	}
+
+	// Without WithResponseMeta nothing is recorded and nothing fails
+	RecordResponseMeta(context.Background(), &http.Response{})
+	RecordResponseMeta(ctx, nil)
+}
+
+func TestRetryDelay(t *testing.T) {
+	later := time.Now().Add(time.Minute).UTC().Format(time.RFC3339)
+	tests := []struct {
+		name   string
+		header http.Header
+		min    time.Duration
+		max    time.Duration
+	}{
+		{"none", http.Header{}, 0, 0},
+		{"retry-after wins", http.Header{"Retry-After": {"2"}, "X-Ratelimit-Remaining-Requests": {"0"}, "X-Ratelimit-Reset-Requests": {"1m"}}, 2 * time.Second, 2 * time.Second},
+		{"exhausted requests", http.Header{"X-Ratelimit-Remaining-Requests": {"0"}, "X-Ratelimit-Reset-Requests": {"1m"}}, time.Minute, time.Minute},
+		{"longest exhausted limit", http.Header{"X-Ratelimit-Remaining-Requests": {"0"}, "X-Ratelimit-Reset-Requests": {"1s"}, "X-Ratelimit-Remaining-Tokens": {"0"}, "X-Ratelimit-Reset-Tokens": {"3s"}}, 3 * time.Second, 3 * time.Second},
+		{"limit not exhausted", http.Header{"X-Ratelimit-Remaining-Requests": {"5"}, "X-Ratelimit-Reset-Requests": {"1m"}}, 0, 0},
+		{"anthropic reset time", http.Header{"Anthropic-Ratelimit-Tokens-Remaining": {"0"}, "Anthropic-Ratelimit-Tokens-Reset": {later}}, 50 * time.Second, time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := RetryDelay(tt.header)
+			if got < tt.min || got > tt.max {
+				t.Errorf("RetryDelay() = %v, want between %v and %v", got, tt.min, tt.max)
+			}
+		})
+	}
+}
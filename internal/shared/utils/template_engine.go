@@ -0,0 +1,553 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kengibson1111/go-aiprovider/types"
+)
+
+// TemplateMode selects how prompt templates are processed.
+type TemplateMode int
+
+const (
+	// TemplateModeSimple performs flat {{variable_name}} replacement, identical to SubstituteVariables.
+	TemplateModeSimple TemplateMode = iota
+
+	// TemplateModeEnhanced adds conditionals, iteration, nested object access, and default values.
+	TemplateModeEnhanced
+)
+
+// Enhanced template errors
+var (
+	// ErrTemplateSyntax is returned when an enhanced template has unbalanced or malformed blocks
+	ErrTemplateSyntax = errors.New("invalid template syntax")
+
+	// ErrUnsupportedTemplateMode is returned when a template mode name is not recognized
+	ErrUnsupportedTemplateMode = errors.New("unsupported template mode")
+
+	// ErrUnresolvedVariables is returned in strict mode when placeholders have no value
+	ErrUnresolvedVariables = errors.New("unresolved template variables")
+
+	// ErrUnusedVariables is returned when ErrorOnUnused is set and supplied variables are never referenced
+	ErrUnusedVariables = errors.New("unused template variables")
+)
+
+// TemplateOptions configures SubstituteVariablesWithOptions.
+//
+// The zero value selects TemplateModeSimple without strict checks, so callers that do
+// not opt in keep the existing flat substitution behavior.
+type TemplateOptions struct {
+	Mode TemplateMode
+
+	// Strict returns ErrUnresolvedVariables listing every placeholder left without a
+	// value instead of leaving {{missing_var}} in the output. Placeholders with a default
+	// value and conditions on missing variables are not considered unresolved.
+	Strict bool
+
+	// ErrorOnUnused returns ErrUnusedVariables listing supplied variables that the
+	// template never references.
+	ErrorOnUnused bool
+}
+
+// TemplateOptionsFromConfig builds TemplateOptions from the template settings on an AIConfig.
+func TemplateOptionsFromConfig(config *types.AIConfig) (TemplateOptions, error) {
+	mode, err := ParseTemplateMode(config.TemplateMode)
+	if err != nil {
+		return TemplateOptions{}, err
+	}
+
+	return TemplateOptions{
+		Mode:          mode,
+		Strict:        config.StrictVariables,
+		ErrorOnUnused: config.ErrorOnUnusedVariables,
+	}, nil
+}
+
+// SubstituteVariablesStrict is SubstituteVariables with Strict and ErrorOnUnused enabled.
+// It returns an error listing unresolved placeholders or unused variables instead of
+// producing a prompt that still contains {{missing_var}}.
+func SubstituteVariablesStrict(template string, variablesJSON string) (string, error) {
+	return SubstituteVariablesWithOptions(template, variablesJSON, TemplateOptions{Strict: true, ErrorOnUnused: true})
+}
+
+// ParseTemplateMode converts an AIConfig.TemplateMode value into a TemplateMode.
+// An empty string selects TemplateModeSimple.
+func ParseTemplateMode(mode string) (TemplateMode, error) {
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case "", types.TemplateModeSimple:
+		return TemplateModeSimple, nil
+	case types.TemplateModeEnhanced:
+		return TemplateModeEnhanced, nil
+	default:
+		return TemplateModeSimple, fmt.Errorf("%w: %s", ErrUnsupportedTemplateMode, mode)
+	}
+}
+
+// SubstituteVariablesWithOptions processes a template using the mode selected in opts.
+//
+// TemplateModeSimple delegates to SubstituteVariables, adding the Strict and ErrorOnUnused
+// checks when requested. TemplateModeEnhanced supports
+// the following tags in addition to plain {{variable_name}} placeholders:
+//
+//   - Nested access: {{user.name}}, {{items.0}}
+//   - Default values: {{name | default:"friend"}}
+//   - Conditionals: {{#if premium}}...{{else}}...{{/if}} and {{#unless premium}}...{{/unless}}
+//   - Iteration: {{#each items}}{{@index}}: {{this}}{{/each}} (arrays), {{@key}} for objects
+//
+// Inside an {{#each}} block, names are resolved against the current item first and then
+// against enclosing scopes, so {{name}} finds a field of the item before a top-level
+// variable. Placeholders that cannot be resolved and have no default are left unchanged,
+// matching SubstituteVariables, unless opts.Strict is set. Falsy values for conditionals are: missing, null, false,
+// 0, "", and empty arrays or objects.
+//
+// Example:
+//
+//	template := "Review for {{user.name}}:{{#each files}}\n- {{this}}{{/each}}{{#if strict}}\nBe strict.{{/if}}"
+//	variables := `{"user": {"name": "Alice"}, "files": ["a.go", "b.go"], "strict": true}`
+//	result, err := SubstituteVariablesWithOptions(template, variables, TemplateOptions{Mode: TemplateModeEnhanced})
+//	// result: "Review for Alice:\n- a.go\n- b.go\nBe strict."
+func SubstituteVariablesWithOptions(template string, variablesJSON string, opts TemplateOptions) (string, error) {
+	switch opts.Mode {
+	case TemplateModeSimple:
+		if !opts.Strict && !opts.ErrorOnUnused {
+			return SubstituteVariables(template, variablesJSON)
+		}
+	case TemplateModeEnhanced:
+		// handled below
+	default:
+		return "", fmt.Errorf("%w: %d", ErrUnsupportedTemplateMode, opts.Mode)
+	}
+
+	if template == "" {
+		return "", ErrEmptyTemplate
+	}
+
+	variables := map[string]any{}
+	if variablesJSON != "" && variablesJSON != "null" {
+		var err error
+		if variables, err = decodeJSONVariables(variablesJSON); err != nil {
+			return "", fmt.Errorf("%w: %v", ErrInvalidJSON, err)
+		}
+	}
+
+	return substituteVariableMap(template, variables, opts)
+}
+
+// substituteVariableMap processes a template against already-decoded variables.
+func substituteVariableMap(template string, variables map[string]any, opts TemplateOptions) (string, error) {
+	switch opts.Mode {
+	case TemplateModeSimple:
+		if !opts.Strict && !opts.ErrorOnUnused {
+			return replaceVariables(template, variables), nil
+		}
+		return substituteSimpleChecked(template, variables, opts)
+	case TemplateModeEnhanced:
+		return renderEnhancedTemplate(template, variables, opts)
+	default:
+		return "", fmt.Errorf("%w: %d", ErrUnsupportedTemplateMode, opts.Mode)
+	}
+}
+
+// substituteSimpleChecked runs flat substitution and applies the strict checks in opts.
+func substituteSimpleChecked(template string, variables map[string]any, opts TemplateOptions) (string, error) {
+	referenced := map[string]bool{}
+	var unresolved []string
+	for _, match := range variablePattern.FindAllStringSubmatchIndex(template, -1) {
+		if (match[0] > 0 && template[match[0]-1] == '{') || (match[1] < len(template) && template[match[1]] == '}') {
+			continue
+		}
+		name := template[match[2]:match[3]]
+		if _, exists := variables[name]; !exists && !referenced[name] {
+			unresolved = append(unresolved, name)
+		}
+		referenced[name] = true
+	}
+
+	if err := checkTemplateVariables(opts, unresolved, variables, referenced); err != nil {
+		return "", err
+	}
+	return replaceVariables(template, variables), nil
+}
+
+// checkTemplateVariables reports unresolved placeholders and unused variables according to opts.
+func checkTemplateVariables(opts TemplateOptions, unresolved []string, variables map[string]any, referenced map[string]bool) error {
+	if opts.Strict && len(unresolved) > 0 {
+		return fmt.Errorf("%w: %s", ErrUnresolvedVariables, strings.Join(unresolved, ", "))
+	}
+
+	if opts.ErrorOnUnused {
+		var unused []string
+		for name := range variables {
+			if !referenced[name] {
+				unused = append(unused, name)
+			}
+		}
+		if len(unused) > 0 {
+			sort.Strings(unused)
+			return fmt.Errorf("%w: %s", ErrUnusedVariables, strings.Join(unused, ", "))
+		}
+	}
+	return nil
+}
+
+// templateTagPattern matches any {{ ... }} tag whose content contains no braces.
+// Group 1 captures the trimmed tag content.
+var templateTagPattern = regexp.MustCompile(`\{\{\s*([^{}]*?)\s*\}\}`)
+
+// templatePathPattern matches a dotted variable path such as user.name, this.id, or @index.
+var templatePathPattern = regexp.MustCompile(`^(@index|@key|[a-zA-Z0-9_-]+(\.[a-zA-Z0-9_-]+)*)$`)
+
+// templateDefaultPattern matches the default filter: default:"value"
+var templateDefaultPattern = regexp.MustCompile(`^default\s*:\s*("(?:[^"\\]|\\.)*")$`)
+
+type templateNodeKind int
+
+const (
+	templateNodeText templateNodeKind = iota
+	templateNodeVariable
+	templateNodeIf
+	templateNodeEach
+)
+
+// templateNode is one element of a parsed enhanced template.
+type templateNode struct {
+	kind     templateNodeKind
+	text     string   // literal text, or the original tag for variables
+	path     []string // variable or block path
+	fallback *string  // default value for variables
+	negate   bool     // true for {{#unless}}
+	body     []templateNode
+	elseBody []templateNode
+}
+
+// templateBlock tracks an open block while parsing.
+type templateBlock struct {
+	node   templateNode
+	name   string // "if", "unless", or "each"
+	inElse bool
+}
+
+// parseEnhancedTemplate parses a template into a node tree.
+func parseEnhancedTemplate(template string) ([]templateNode, error) {
+	root := &templateBlock{}
+	stack := []*templateBlock{root}
+
+	appendNode := func(n templateNode) {
+		top := stack[len(stack)-1]
+		if top.inElse {
+			top.node.elseBody = append(top.node.elseBody, n)
+		} else {
+			top.node.body = append(top.node.body, n)
+		}
+	}
+
+	pos := 0
+	for _, match := range templateTagPattern.FindAllStringSubmatchIndex(template, -1) {
+		start, end := match[0], match[1]
+		content := template[match[2]:match[3]]
+
+		// Skip {{{variable}}} patterns for consistency with SubstituteVariables
+		if (start > 0 && template[start-1] == '{') || (end < len(template) && template[end] == '}') {
+			continue
+		}
+
+		if start > pos {
+			appendNode(templateNode{kind: templateNodeText, text: template[pos:start]})
+		}
+		pos = end
+		tag := template[start:end]
+
+		switch {
+		case strings.HasPrefix(content, "#"):
+			name, arg, _ := strings.Cut(content[1:], " ")
+			arg = strings.TrimSpace(arg)
+			if name != "if" && name != "unless" && name != "each" {
+				return nil, fmt.Errorf("%w: unknown block %q", ErrTemplateSyntax, tag)
+			}
+			if !templatePathPattern.MatchString(arg) {
+				return nil, fmt.Errorf("%w: invalid path in %q", ErrTemplateSyntax, tag)
+			}
+			kind := templateNodeIf
+			if name == "each" {
+				kind = templateNodeEach
+			}
+			stack = append(stack, &templateBlock{
+				node: templateNode{kind: kind, path: strings.Split(arg, "."), negate: name == "unless"},
+				name: name,
+			})
+
+		case strings.HasPrefix(content, "/"):
+			name := strings.TrimSpace(content[1:])
+			if len(stack) == 1 {
+				return nil, fmt.Errorf("%w: unexpected %q", ErrTemplateSyntax, tag)
+			}
+			top := stack[len(stack)-1]
+			if top.name != name {
+				return nil, fmt.Errorf("%w: %q closes {{#%s}}", ErrTemplateSyntax, tag, top.name)
+			}
+			stack = stack[:len(stack)-1]
+			appendNode(top.node)
+
+		case content == "else":
+			top := stack[len(stack)-1]
+			if len(stack) == 1 || top.inElse {
+				return nil, fmt.Errorf("%w: unexpected {{else}}", ErrTemplateSyntax)
+			}
+			top.inElse = true
+
+		default:
+			node, ok := parseTemplateVariable(content, tag)
+			if !ok {
+				// Not a recognizable placeholder; keep it verbatim like SubstituteVariables
+				node = templateNode{kind: templateNodeText, text: tag}
+			}
+			appendNode(node)
+		}
+	}
+
+	if len(stack) > 1 {
+		return nil, fmt.Errorf("%w: unclosed {{#%s}}", ErrTemplateSyntax, stack[len(stack)-1].name)
+	}
+
+	if pos < len(template) {
+		appendNode(templateNode{kind: templateNodeText, text: template[pos:]})
+	}
+
+	return root.node.body, nil
+}
+
+// parseTemplateVariable parses "path" or "path | default:\"value\"" tag content.
+func parseTemplateVariable(content, tag string) (templateNode, bool) {
+	pathPart, filterPart, hasFilter := strings.Cut(content, "|")
+	pathPart = strings.TrimSpace(pathPart)
+	if !templatePathPattern.MatchString(pathPart) {
+		return templateNode{}, false
+	}
+
+	node := templateNode{kind: templateNodeVariable, text: tag, path: strings.Split(pathPart, ".")}
+	if hasFilter {
+		m := templateDefaultPattern.FindStringSubmatch(strings.TrimSpace(filterPart))
+		if m == nil {
+			return templateNode{}, false
+		}
+		value, err := strconv.Unquote(m[1])
+		if err != nil {
+			return templateNode{}, false
+		}
+		node.fallback = &value
+	}
+	return node, true
+}
+
+// templateScope is one level of variable lookup while rendering.
+type templateScope struct {
+	value    any
+	index    int
+	key      string
+	hasIndex bool
+	hasKey   bool
+}
+
+// templateRenderer renders parsed nodes and records placeholders that could not be resolved.
+type templateRenderer struct {
+	sb         strings.Builder
+	unresolved []string
+	seen       map[string]bool
+}
+
+// renderEnhancedTemplate parses and renders a template against variables.
+func renderEnhancedTemplate(template string, variables map[string]any, opts TemplateOptions) (string, error) {
+	nodes, err := parseEnhancedTemplate(template)
+	if err != nil {
+		return "", err
+	}
+
+	r := &templateRenderer{seen: map[string]bool{}}
+	r.render(nodes, []templateScope{{value: variables}})
+
+	if opts.Strict || opts.ErrorOnUnused {
+		referenced := map[string]bool{}
+		collectTemplateReferences(nodes, referenced)
+		if err := checkTemplateVariables(opts, r.unresolved, variables, referenced); err != nil {
+			return "", err
+		}
+	}
+
+	return r.sb.String(), nil
+}
+
+func (r *templateRenderer) render(nodes []templateNode, scopes []templateScope) {
+	for _, n := range nodes {
+		switch n.kind {
+		case templateNodeText:
+			r.sb.WriteString(n.text)
+
+		case templateNodeVariable:
+			value, found := lookupTemplatePath(scopes, n.path)
+			switch {
+			case found && value != nil:
+				r.sb.WriteString(formatTemplateValue(value))
+			case n.fallback != nil:
+				r.sb.WriteString(*n.fallback)
+			case found:
+				// Explicit null renders as empty string, matching SubstituteVariables
+			default:
+				r.sb.WriteString(n.text)
+				if name := strings.Join(n.path, "."); !r.seen[name] {
+					r.seen[name] = true
+					r.unresolved = append(r.unresolved, name)
+				}
+			}
+
+		case templateNodeIf:
+			value, found := lookupTemplatePath(scopes, n.path)
+			if (found && isTemplateTruthy(value)) != n.negate {
+				r.render(n.body, scopes)
+			} else {
+				r.render(n.elseBody, scopes)
+			}
+
+		case templateNodeEach:
+			value, _ := lookupTemplatePath(scopes, n.path)
+			switch v := value.(type) {
+			case []any:
+				if len(v) == 0 {
+					r.render(n.elseBody, scopes)
+					continue
+				}
+				for i, item := range v {
+					r.render(n.body, append(scopes, templateScope{value: item, index: i, hasIndex: true}))
+				}
+			case map[string]any:
+				if len(v) == 0 {
+					r.render(n.elseBody, scopes)
+					continue
+				}
+				keys := make([]string, 0, len(v))
+				for k := range v {
+					keys = append(keys, k)
+				}
+				sort.Strings(keys)
+				for i, k := range keys {
+					r.render(n.body, append(scopes, templateScope{value: v[k], index: i, key: k, hasIndex: true, hasKey: true}))
+				}
+			default:
+				r.render(n.elseBody, scopes)
+			}
+		}
+	}
+}
+
+// collectTemplateReferences records the first path segment of every variable and
+// block in the tree, including branches that were not rendered.
+func collectTemplateReferences(nodes []templateNode, referenced map[string]bool) {
+	for _, n := range nodes {
+		if n.kind != templateNodeText {
+			referenced[n.path[0]] = true
+		}
+		collectTemplateReferences(n.body, referenced)
+		collectTemplateReferences(n.elseBody, referenced)
+	}
+}
+
+// lookupTemplatePath resolves a dotted path against the scope stack, innermost first.
+func lookupTemplatePath(scopes []templateScope, path []string) (any, bool) {
+	switch path[0] {
+	case "@index":
+		for i := len(scopes) - 1; i >= 0; i-- {
+			if scopes[i].hasIndex {
+				return float64(scopes[i].index), true
+			}
+		}
+		return nil, false
+	case "@key":
+		for i := len(scopes) - 1; i >= 0; i-- {
+			if scopes[i].hasKey {
+				return scopes[i].key, true
+			}
+		}
+		return nil, false
+	case "this":
+		return resolveTemplateValue(scopes[len(scopes)-1].value, path[1:])
+	}
+
+	for i := len(scopes) - 1; i >= 0; i-- {
+		obj, ok := scopes[i].value.(map[string]any)
+		if !ok {
+			continue
+		}
+		if value, exists := obj[path[0]]; exists {
+			return resolveTemplateValue(value, path[1:])
+		}
+	}
+	return nil, false
+}
+
+// resolveTemplateValue walks the remaining path segments through objects and arrays.
+func resolveTemplateValue(value any, path []string) (any, bool) {
+	for _, segment := range path {
+		switch v := value.(type) {
+		case map[string]any:
+			next, exists := v[segment]
+			if !exists {
+				return nil, false
+			}
+			value = next
+		case []any:
+			idx, err := strconv.Atoi(segment)
+			if err != nil || idx < 0 || idx >= len(v) {
+				return nil, false
+			}
+			value = v[idx]
+		default:
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// isTemplateTruthy reports whether a value enables an {{#if}} block.
+func isTemplateTruthy(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case string:
+		return v != ""
+	case float64:
+		return v != 0
+	case []any:
+		return len(v) > 0
+	case map[string]any:
+		return len(v) > 0
+	case int64:
+		return v != 0
+	case uint64:
+		return v != 0
+	default:
+		return true
+	}
+}
+
+// formatTemplateValue converts a variable value to its prompt representation.
+// Objects and arrays are rendered as JSON so they remain readable to the model.
+func formatTemplateValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case map[string]any, []any:
+		if b, err := json.Marshal(v); err == nil {
+			return string(b)
+		}
+		return fmt.Sprintf("%v", v)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
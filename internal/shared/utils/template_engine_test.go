@@ -0,0 +1,336 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSubstituteVariablesWithOptions_Enhanced(t *testing.T) {
+	tests := []struct {
+		name      string
+		template  string
+		variables string
+		expected  string
+	}{
+		{
+			name:      "Flat variable substitution",
+			template:  "Hello {{name}}!",
+			variables: `{"name": "Alice"}`,
+			expected:  "Hello Alice!",
+		},
+		{
+			name:      "Nested object access",
+			template:  "Hello {{user.name}} from {{user.address.city}}",
+			variables: `{"user": {"name": "Alice", "address": {"city": "Paris"}}}`,
+			expected:  "Hello Alice from Paris",
+		},
+		{
+			name:      "Array index access",
+			template:  "First file: {{files.0}}",
+			variables: `{"files": ["main.go", "util.go"]}`,
+			expected:  "First file: main.go",
+		},
+		{
+			name:      "Default value used when variable missing",
+			template:  `Hello {{name | default:"friend"}}!`,
+			variables: `{}`,
+			expected:  "Hello friend!",
+		},
+		{
+			name:      "Default value ignored when variable present",
+			template:  `Hello {{name|default:"friend"}}!`,
+			variables: `{"name": "Bob"}`,
+			expected:  "Hello Bob!",
+		},
+		{
+			name:      "Default value used for null",
+			template:  `Hello {{name | default:"friend"}}!`,
+			variables: `{"name": null}`,
+			expected:  "Hello friend!",
+		},
+		{
+			name:      "Missing variable without default remains unchanged",
+			template:  "Hello {{name}}, your {{unknown}} is ready.",
+			variables: `{"name": "Charlie"}`,
+			expected:  "Hello Charlie, your {{unknown}} is ready.",
+		},
+		{
+			name:      "If block true",
+			template:  "Review{{#if strict}} strictly{{/if}}.",
+			variables: `{"strict": true}`,
+			expected:  "Review strictly.",
+		},
+		{
+			name:      "If block false with else",
+			template:  "{{#if premium}}Premium{{else}}Free{{/if}} plan",
+			variables: `{"premium": false}`,
+			expected:  "Free plan",
+		},
+		{
+			name:      "If block on missing variable",
+			template:  "{{#if notes}}Notes: {{notes}}{{else}}No notes{{/if}}",
+			variables: `{}`,
+			expected:  "No notes",
+		},
+		{
+			name:      "Unless block",
+			template:  "{{#unless tests}}Please add tests.{{/unless}}",
+			variables: `{"tests": []}`,
+			expected:  "Please add tests.",
+		},
+		{
+			name:      "Each over array of strings",
+			template:  "Files:{{#each files}} [{{@index}}]{{this}}{{/each}}",
+			variables: `{"files": ["a.go", "b.go"]}`,
+			expected:  "Files: [0]a.go [1]b.go",
+		},
+		{
+			name:      "Each over array of objects with outer scope lookup",
+			template:  "{{#each users}}{{name}} ({{role}}) in {{team}}; {{/each}}",
+			variables: `{"team": "core", "users": [{"name": "Ann", "role": "lead"}, {"name": "Ben", "role": "dev"}]}`,
+			expected:  "Ann (lead) in core; Ben (dev) in core; ",
+		},
+		{
+			name:      "Each over object uses sorted keys",
+			template:  "{{#each limits}}{{@key}}={{this}} {{/each}}",
+			variables: `{"limits": {"b": 2, "a": 1}}`,
+			expected:  "a=1 b=2 ",
+		},
+		{
+			name:      "Each else branch for empty array",
+			template:  "{{#each items}}{{this}}{{else}}none{{/each}}",
+			variables: `{"items": []}`,
+			expected:  "none",
+		},
+		{
+			name:      "Nested blocks",
+			template:  "{{#each files}}{{#if this.changed}}{{this.path}} {{/if}}{{/each}}",
+			variables: `{"files": [{"path": "a.go", "changed": true}, {"path": "b.go", "changed": false}]}`,
+			expected:  "a.go ",
+		},
+		{
+			name:      "Objects render as JSON",
+			template:  "Data: {{data}}",
+			variables: `{"data": {"k": "v"}}`,
+			expected:  `Data: {"k":"v"}`,
+		},
+		{
+			name:      "Numbers and booleans",
+			template:  "{{count}} items, done={{done}}",
+			variables: `{"count": 3, "done": true}`,
+			expected:  "3 items, done=true",
+		},
+		{
+			name:      "Triple braces are not processed",
+			template:  "This {{{invalid}}} stays, {{valid}} works",
+			variables: `{"valid": "it", "invalid": "broken"}`,
+			expected:  "This {{{invalid}}} stays, it works",
+		},
+		{
+			name:      "Empty variables string",
+			template:  "Hello {{name | default:\"there\"}}",
+			variables: "",
+			expected:  "Hello there",
+		},
+	}
+
+	opts := TemplateOptions{Mode: TemplateModeEnhanced}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := SubstituteVariablesWithOptions(tt.template, tt.variables, opts)
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+				return
+			}
+			if result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestSubstituteVariablesWithOptions_EnhancedErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		template  string
+		variables string
+		errorType error
+	}{
+		{name: "Empty template", template: "", variables: `{}`, errorType: ErrEmptyTemplate},
+		{name: "Invalid JSON", template: "{{name}}", variables: `{invalid`, errorType: ErrInvalidJSON},
+		{name: "Unclosed if", template: "{{#if a}}text", variables: `{}`, errorType: ErrTemplateSyntax},
+		{name: "Mismatched close", template: "{{#if a}}text{{/each}}", variables: `{}`, errorType: ErrTemplateSyntax},
+		{name: "Unexpected close", template: "text{{/if}}", variables: `{}`, errorType: ErrTemplateSyntax},
+		{name: "Unknown block", template: "{{#with a}}{{/with}}", variables: `{}`, errorType: ErrTemplateSyntax},
+		{name: "Else outside block", template: "a{{else}}b", variables: `{}`, errorType: ErrTemplateSyntax},
+	}
+
+	opts := TemplateOptions{Mode: TemplateModeEnhanced}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := SubstituteVariablesWithOptions(tt.template, tt.variables, opts)
+			if !errors.Is(err, tt.errorType) {
+				t.Errorf("Expected error %v, got %v", tt.errorType, err)
+			}
+		})
+	}
+}
+
+func TestSubstituteVariablesWithOptions_SimpleModeUnchanged(t *testing.T) {
+	template := "{{#if a}}Hello {{user.name}} {{name}}{{/if}}"
+	variables := `{"a": true, "name": "Alice"}`
+
+	expected, err := SubstituteVariables(template, variables)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	result, err := SubstituteVariablesWithOptions(template, variables, TemplateOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+func TestParseTemplateMode(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    TemplateMode
+		expectError bool
+	}{
+		{input: "", expected: TemplateModeSimple},
+		{input: "simple", expected: TemplateModeSimple},
+		{input: "Enhanced", expected: TemplateModeEnhanced},
+		{input: "handlebars", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			mode, err := ParseTemplateMode(tt.input)
+			if tt.expectError {
+				if !errors.Is(err, ErrUnsupportedTemplateMode) {
+					t.Errorf("Expected ErrUnsupportedTemplateMode, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			if mode != tt.expected {
+				t.Errorf("Expected mode %d, got %d", tt.expected, mode)
+			}
+		})
+	}
+}
+
+func TestSubstituteVariablesWithOptions_Strict(t *testing.T) {
+	tests := []struct {
+		name      string
+		template  string
+		variables string
+		opts      TemplateOptions
+		expected  string
+		errorType error
+		errorText string
+	}{
+		{
+			name:      "Simple strict reports all unresolved placeholders",
+			template:  "Hello {{name}}, your {{item}} ships to {{city}} ({{item}}).",
+			variables: `{"name": "Alice"}`,
+			opts:      TemplateOptions{Strict: true},
+			errorType: ErrUnresolvedVariables,
+			errorText: "item, city",
+		},
+		{
+			name:      "Simple strict succeeds when all placeholders resolve",
+			template:  "Hello {{name}}!",
+			variables: `{"name": "Alice", "extra": "ignored"}`,
+			opts:      TemplateOptions{Strict: true},
+			expected:  "Hello Alice!",
+		},
+		{
+			name:      "Simple strict ignores triple braces",
+			template:  "Literal {{{raw}}} and {{name}}",
+			variables: `{"name": "Alice"}`,
+			opts:      TemplateOptions{Strict: true},
+			expected:  "Literal {{{raw}}} and Alice",
+		},
+		{
+			name:      "Simple unused variables reported in sorted order",
+			template:  "Hello {{name}}!",
+			variables: `{"name": "Alice", "zeta": 1, "alpha": 2}`,
+			opts:      TemplateOptions{ErrorOnUnused: true},
+			errorType: ErrUnusedVariables,
+			errorText: "alpha, zeta",
+		},
+		{
+			name:      "Enhanced strict reports unresolved nested paths",
+			template:  "Hello {{user.name}} from {{user.city}}",
+			variables: `{"user": {"name": "Alice"}}`,
+			opts:      TemplateOptions{Mode: TemplateModeEnhanced, Strict: true},
+			errorType: ErrUnresolvedVariables,
+			errorText: "user.city",
+		},
+		{
+			name:      "Enhanced strict allows defaults and missing conditions",
+			template:  `Hello {{name | default:"friend"}}{{#if vip}} (VIP){{/if}}`,
+			variables: `{}`,
+			opts:      TemplateOptions{Mode: TemplateModeEnhanced, Strict: true},
+			expected:  "Hello friend",
+		},
+		{
+			name:      "Enhanced unused counts references in unrendered branches",
+			template:  "{{#if vip}}{{discount}}{{/if}}done",
+			variables: `{"vip": false, "discount": "10%"}`,
+			opts:      TemplateOptions{Mode: TemplateModeEnhanced, ErrorOnUnused: true},
+			expected:  "done",
+		},
+		{
+			name:      "Enhanced unused variables reported",
+			template:  "{{#each files}}{{this}} {{/each}}",
+			variables: `{"files": ["a.go"], "owner": "bob"}`,
+			opts:      TemplateOptions{Mode: TemplateModeEnhanced, ErrorOnUnused: true},
+			errorType: ErrUnusedVariables,
+			errorText: "owner",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := SubstituteVariablesWithOptions(tt.template, tt.variables, tt.opts)
+			if tt.errorType != nil {
+				if !errors.Is(err, tt.errorType) {
+					t.Errorf("Expected error %v, got %v", tt.errorType, err)
+					return
+				}
+				if !strings.Contains(err.Error(), tt.errorText) {
+					t.Errorf("Expected error to mention %q, got %q", tt.errorText, err.Error())
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+				return
+			}
+			if result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestSubstituteVariablesStrict(t *testing.T) {
+	if _, err := SubstituteVariablesStrict("Hello {{name}}", `{}`); !errors.Is(err, ErrUnresolvedVariables) {
+		t.Errorf("Expected ErrUnresolvedVariables, got %v", err)
+	}
+	if _, err := SubstituteVariablesStrict("Hello {{name}}", `{"name": "a", "x": 1}`); !errors.Is(err, ErrUnusedVariables) {
+		t.Errorf("Expected ErrUnusedVariables, got %v", err)
+	}
+	result, err := SubstituteVariablesStrict("Hello {{name}}", `{"name": "Alice"}`)
+	if err != nil || result != "Hello Alice" {
+		t.Errorf("Expected %q, got %q (err %v)", "Hello Alice", result, err)
+	}
+}
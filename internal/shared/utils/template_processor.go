@@ -1,7 +1,6 @@
 package utils
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
 	"regexp"
@@ -75,8 +74,8 @@ func SubstituteVariables(template string, variablesJSON string) (string, error)
 	}
 
 	// Parse variables JSON
-	var variables map[string]any
-	if err := json.Unmarshal([]byte(variablesJSON), &variables); err != nil {
+	variables, err := decodeJSONVariables(variablesJSON)
+	if err != nil {
 		return "", fmt.Errorf("%w: %v", ErrInvalidJSON, err)
 	}
 
@@ -85,6 +84,12 @@ func SubstituteVariables(template string, variablesJSON string) (string, error)
 		return template, nil
 	}
 
+	return replaceVariables(template, variables), nil
+}
+
+// replaceVariables substitutes {{variable_name}} placeholders in template with values
+// from variables, leaving placeholders without a matching key unchanged.
+func replaceVariables(template string, variables map[string]any) string {
 	// Find all variable matches in template and their positions
 	result := template
 	matches := variablePattern.FindAllStringSubmatchIndex(template, -1)
@@ -132,5 +137,5 @@ func SubstituteVariables(template string, variablesJSON string) (string, error)
 		// If variable doesn't exist in values, leave placeholder unchanged
 	}
 
-	return result, nil
+	return result
 }
@@ -0,0 +1,311 @@
+package utils
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidVariables is returned when a Go value cannot be used as template variables
+var ErrInvalidVariables = errors.New("invalid template variables")
+
+// maxVariableDepth bounds recursion when converting nested values, guarding against cycles.
+const maxVariableDepth = 64
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// SubstituteValues processes a template like SubstituteVariablesWithOptions, taking the
+// variables as a Go value instead of a JSON string.
+//
+// values may be a map with string keys (such as map[string]any or map[string]string), a
+// struct or pointer to struct, or nil. Struct fields are named by their json tag when one
+// is present, so a type that already marshals to the expected JSON object works unchanged.
+// Nested maps, structs, and slices are available to enhanced templates via dotted paths
+// and {{#each}}.
+//
+// Example:
+//
+//	type Review struct {
+//		Language string   `json:"language"`
+//		Files    []string `json:"files"`
+//	}
+//	result, err := SubstituteValues("Review {{language}}: {{files.0}}",
+//		Review{Language: "Go", Files: []string{"main.go"}}, TemplateOptions{Mode: TemplateModeEnhanced})
+//	// result: "Review Go: main.go"
+func SubstituteValues(template string, values any, opts TemplateOptions) (string, error) {
+	if template == "" {
+		return "", ErrEmptyTemplate
+	}
+
+	variables, err := VariablesFromValue(values)
+	if err != nil {
+		return "", err
+	}
+
+	return substituteVariableMap(template, variables, opts)
+}
+
+// VariablesFromValue converts a map or struct into the variable map used by the template
+// engine. Values are normalized to the same shapes encoding/json produces (maps become
+// map[string]any and slices become []any) so that templates behave identically whether
+// variables were supplied as JSON or as Go values. Integers keep their full precision.
+func VariablesFromValue(values any) (map[string]any, error) {
+	if values == nil {
+		return map[string]any{}, nil
+	}
+	if vars, ok := values.(map[string]any); ok && vars == nil {
+		return map[string]any{}, nil
+	}
+
+	normalized, err := normalizeVariableValue(reflect.ValueOf(values), 0)
+	if err != nil {
+		return nil, err
+	}
+
+	switch v := normalized.(type) {
+	case map[string]any:
+		return v, nil
+	case nil:
+		return map[string]any{}, nil
+	default:
+		return nil, fmt.Errorf("%w: expected a map or struct, got %T", ErrInvalidVariables, values)
+	}
+}
+
+// normalizeVariableValue converts v into nil, bool, string, int64, uint64, float64,
+// []any, or map[string]any.
+func normalizeVariableValue(v reflect.Value, depth int) (any, error) {
+	if depth > maxVariableDepth {
+		return nil, fmt.Errorf("%w: value nested too deeply", ErrInvalidVariables)
+	}
+	if !v.IsValid() {
+		return nil, nil
+	}
+
+	if (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) && v.IsNil() {
+		return nil, nil
+	}
+
+	// Types with custom JSON or text encodings (time.Time, json.RawMessage, ...) keep
+	// the representation they would have in a JSON variables string.
+	if v.Type().Implements(jsonMarshalerType) || v.Type().Implements(textMarshalerType) {
+		return normalizeViaJSON(v.Interface())
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return normalizeVariableValue(v.Elem(), depth+1)
+	case reflect.Bool:
+		return v.Bool(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint(), nil
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), nil
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil, nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return string(v.Bytes()), nil
+		}
+		fallthrough
+	case reflect.Array:
+		items := make([]any, v.Len())
+		for i := range items {
+			item, err := normalizeVariableValue(v.Index(i), depth+1)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = item
+		}
+		return items, nil
+	case reflect.Map:
+		if v.IsNil() {
+			return nil, nil
+		}
+		obj := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			key := iter.Key()
+			name := fmt.Sprint(key.Interface())
+			if key.Kind() == reflect.String {
+				name = key.String()
+			}
+			item, err := normalizeVariableValue(iter.Value(), depth+1)
+			if err != nil {
+				return nil, err
+			}
+			obj[name] = item
+		}
+		return obj, nil
+	case reflect.Struct:
+		obj := map[string]any{}
+		if err := collectStructFields(v, obj, depth); err != nil {
+			return nil, err
+		}
+		return obj, nil
+	default:
+		return nil, fmt.Errorf("%w: unsupported type %s", ErrInvalidVariables, v.Type())
+	}
+}
+
+// collectStructFields adds the exported fields of struct v to obj, honoring json tags.
+// Fields of untagged embedded structs are promoted unless an outer field has the same name.
+func collectStructFields(v reflect.Value, obj map[string]any, depth int) error {
+	promoted := map[string]any{}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, omitEmpty, skip := parseJSONFieldTag(field)
+		if skip {
+			continue
+		}
+
+		fv := v.Field(i)
+		if field.Anonymous && name == "" {
+			for fv.Kind() == reflect.Pointer {
+				if fv.IsNil() {
+					break
+				}
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Struct {
+				if err := collectStructFields(fv, promoted, depth+1); err != nil {
+					return err
+				}
+				continue
+			}
+			if !field.IsExported() {
+				continue
+			}
+		}
+
+		if omitEmpty && fv.IsZero() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		value, err := normalizeVariableValue(fv, depth+1)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", field.Name, err)
+		}
+		obj[name] = value
+	}
+
+	for name, value := range promoted {
+		if _, exists := obj[name]; !exists {
+			obj[name] = value
+		}
+	}
+	return nil
+}
+
+// parseJSONFieldTag returns the json tag name (empty when not set), whether omitempty is
+// present, and whether the field is excluded from the variables.
+func parseJSONFieldTag(field reflect.StructField) (name string, omitEmpty bool, skip bool) {
+	if !field.IsExported() && !field.Anonymous {
+		return "", false, true
+	}
+
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false, true
+	}
+
+	name, opts, _ := strings.Cut(tag, ",")
+	for _, opt := range strings.Split(opts, ",") {
+		if opt == "omitempty" {
+			omitEmpty = true
+		}
+	}
+	return name, omitEmpty, false
+}
+
+// normalizeViaJSON round-trips a value with a custom encoding through encoding/json.
+func normalizeViaJSON(value any) (any, error) {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidVariables, err)
+	}
+
+	normalized, err := decodeJSONValue(b)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidVariables, err)
+	}
+	return normalized, nil
+}
+
+// decodeJSONVariables decodes a JSON variables object into the same shapes
+// VariablesFromValue produces, so integers keep their full precision.
+func decodeJSONVariables(variablesJSON string) (map[string]any, error) {
+	value, err := decodeJSONValue([]byte(variablesJSON))
+	if err != nil {
+		return nil, err
+	}
+	variables, ok := value.(map[string]any)
+	if !ok {
+		return nil, errors.New("variables must be a JSON object")
+	}
+	return variables, nil
+}
+
+// decodeJSONValue decodes JSON like json.Unmarshal into an any, except that numbers are
+// int64 or uint64 when they are integers that fit, and float64 otherwise.
+func decodeJSONValue(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("invalid data after top-level value")
+	}
+	return convertJSONNumbers(value)
+}
+
+// convertJSONNumbers replaces the json.Number values in a decoded value.
+func convertJSONNumbers(value any) (any, error) {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := strconv.ParseInt(string(v), 10, 64); err == nil {
+			return i, nil
+		}
+		if u, err := strconv.ParseUint(string(v), 10, 64); err == nil {
+			return u, nil
+		}
+		return v.Float64()
+	case map[string]any:
+		for key, item := range v {
+			converted, err := convertJSONNumbers(item)
+			if err != nil {
+				return nil, err
+			}
+			v[key] = converted
+		}
+	case []any:
+		for i, item := range v {
+			converted, err := convertJSONNumbers(item)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = converted
+		}
+	}
+	return value, nil
+}
@@ -0,0 +1,215 @@
+package utils
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type reviewAuthor struct {
+	Name string `json:"name"`
+}
+
+type reviewMeta struct {
+	Team string `json:"team"`
+	Repo string `json:"repo"`
+}
+
+type reviewVariables struct {
+	reviewMeta
+	Language string         `json:"language"`
+	Files    []string       `json:"files"`
+	Author   *reviewAuthor  `json:"author,omitempty"`
+	Limits   map[string]int `json:"limits"`
+	Repo     string         `json:"repo"`
+	Internal string         `json:"-"`
+	Untagged int
+	Labels   map[string]string `json:"labels,omitempty"`
+	secret   string
+}
+
+func TestVariablesFromValue(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		values   any
+		expected map[string]any
+	}{
+		{
+			name:     "Nil values",
+			values:   nil,
+			expected: map[string]any{},
+		},
+		{
+			name:     "Map of strings",
+			values:   map[string]string{"name": "Alice"},
+			expected: map[string]any{"name": "Alice"},
+		},
+		{
+			name:   "Map of any with nested Go types",
+			values: map[string]any{"count": 3, "tags": []string{"a", "b"}, "created": created},
+			expected: map[string]any{
+				"count":   int64(3),
+				"tags":    []any{"a", "b"},
+				"created": "2024-01-02T03:04:05Z",
+			},
+		},
+		{
+			name: "Struct honors json tags and embedding",
+			values: &reviewVariables{
+				reviewMeta: reviewMeta{Team: "core", Repo: "inner"},
+				Language:   "Go",
+				Files:      []string{"main.go"},
+				Limits:     map[string]int{"max": 2},
+				Repo:       "outer",
+				Internal:   "hidden",
+				Untagged:   7,
+				secret:     "hidden",
+			},
+			expected: map[string]any{
+				"team":     "core",
+				"repo":     "outer",
+				"language": "Go",
+				"files":    []any{"main.go"},
+				"limits":   map[string]any{"max": int64(2)},
+				"Untagged": int64(7),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := VariablesFromValue(tt.values)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("Expected %#v, got %#v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestVariablesFromValue_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		values any
+	}{
+		{name: "Scalar", values: "not a map"},
+		{name: "Slice", values: []string{"a"}},
+		{name: "Unsupported field type", values: map[string]any{"fn": func() {}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := VariablesFromValue(tt.values)
+			if !errors.Is(err, ErrInvalidVariables) {
+				t.Errorf("Expected ErrInvalidVariables, got %v", err)
+			}
+		})
+	}
+}
+
+func TestSubstituteValues(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		values   any
+		opts     TemplateOptions
+		expected string
+	}{
+		{
+			name:     "Simple mode with map",
+			template: "Hello {{name}}, you have {{count}} messages",
+			values:   map[string]any{"name": "Alice", "count": 5},
+			expected: "Hello Alice, you have 5 messages",
+		},
+		{
+			name:     "Simple mode with struct",
+			template: "Review this {{language}} code in {{repo}}",
+			values:   reviewVariables{Language: "Go", Repo: "api"},
+			expected: "Review this Go code in api",
+		},
+		{
+			name:     "Large integers keep precision",
+			template: "ID {{id}}",
+			values:   map[string]int64{"id": 9007199254740993},
+			expected: "ID 9007199254740993",
+		},
+		{
+			name:     "Enhanced mode with nested struct values",
+			template: "{{#if author}}By {{author.name}}. {{/if}}{{#each files}}[{{this}}]{{/each}} max={{limits.max}}",
+			values: reviewVariables{
+				Author: &reviewAuthor{Name: "Bob"},
+				Files:  []string{"a.go", "b.go"},
+				Limits: map[string]int{"max": 3},
+			},
+			opts:     TemplateOptions{Mode: TemplateModeEnhanced},
+			expected: "By Bob. [a.go][b.go] max=3",
+		},
+		{
+			name:     "Enhanced mode zero integer is falsy",
+			template: "{{#if count}}some{{else}}none{{/if}}",
+			values:   map[string]int{"count": 0},
+			opts:     TemplateOptions{Mode: TemplateModeEnhanced},
+			expected: "none",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := SubstituteValues(tt.template, tt.values, tt.opts)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestSubstituteVariables_IntegersKeepPrecision(t *testing.T) {
+	// Variables round-tripped through JSON render like the Go values they came from
+	variablesJSON := `{"n": 1234567, "id": 9007199254740993, "big": 18446744073709551615, "ratio": 0.25, "items": [{"qty": 2000000}]}`
+	template := "{{n}} {{id}} {{big}} {{ratio}}"
+
+	for _, opts := range []TemplateOptions{{}, {Mode: TemplateModeEnhanced}} {
+		result, err := SubstituteVariablesWithOptions(template, variablesJSON, opts)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if expected := "1234567 9007199254740993 18446744073709551615 0.25"; result != expected {
+			t.Errorf("Mode %d: expected %q, got %q", opts.Mode, expected, result)
+		}
+	}
+
+	result, err := SubstituteVariablesWithOptions("{{#each items}}{{qty}}{{/each}}", variablesJSON, TemplateOptions{Mode: TemplateModeEnhanced})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result != "2000000" {
+		t.Errorf("Expected %q, got %q", "2000000", result)
+	}
+
+	if _, err := SubstituteVariables(template, `[1, 2]`); !errors.Is(err, ErrInvalidJSON) {
+		t.Errorf("Expected ErrInvalidJSON for a JSON array, got %v", err)
+	}
+	if _, err := SubstituteVariables(template, `{"n": 1} {}`); !errors.Is(err, ErrInvalidJSON) {
+		t.Errorf("Expected ErrInvalidJSON for trailing data, got %v", err)
+	}
+}
+
+func TestSubstituteValues_Strict(t *testing.T) {
+	_, err := SubstituteValues("Hello {{name}}", map[string]any{}, TemplateOptions{Strict: true})
+	if !errors.Is(err, ErrUnresolvedVariables) {
+		t.Errorf("Expected ErrUnresolvedVariables, got %v", err)
+	}
+
+	_, err = SubstituteValues("", map[string]any{"name": "Alice"}, TemplateOptions{})
+	if !errors.Is(err, ErrEmptyTemplate) {
+		t.Errorf("Expected ErrEmptyTemplate, got %v", err)
+	}
+}
@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kengibson1111/go-aiprovider/types"
+)
+
+// tokenRefreshWindow is how long before expiry a cached token is replaced, so that a
+// request never goes out with a token that expires in flight.
+const tokenRefreshWindow = 5 * time.Minute
+
+// cachedTokenSource reuses a token until it is about to expire.
+type cachedTokenSource struct {
+	source types.TokenSource
+
+	mu    sync.Mutex
+	token types.Token
+}
+
+// NewCachedTokenSource returns a TokenSource that calls source only when it has no token
+// or the token expires within five minutes. It is safe for concurrent use; concurrent
+// callers wait for a single refresh.
+func NewCachedTokenSource(source types.TokenSource) types.TokenSource {
+	return &cachedTokenSource{source: source}
+}
+
+// Token returns the cached token, refreshing it first when needed.
+func (s *cachedTokenSource) Token(ctx context.Context) (types.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token.AccessToken != "" && (s.token.Expiry.IsZero() || time.Until(s.token.Expiry) > tokenRefreshWindow) {
+		return s.token, nil
+	}
+
+	token, err := s.source.Token(ctx)
+	if err != nil {
+		return types.Token{}, fmt.Errorf("failed to get access token: %w", err)
+	}
+	if token.AccessToken == "" {
+		return types.Token{}, fmt.Errorf("failed to get access token: token source returned an empty token")
+	}
+	s.token = token
+	return token, nil
+}
@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kengibson1111/go-aiprovider/types"
+)
+
+func TestCachedTokenSource(t *testing.T) {
+	calls := 0
+	expiry := time.Now().Add(time.Hour)
+	source := NewCachedTokenSource(types.TokenSourceFunc(func(ctx context.Context) (types.Token, error) {
+		calls++
+		return types.Token{AccessToken: "token", Expiry: expiry}, nil
+	}))
+
+	for i := 0; i < 3; i++ {
+		token, err := source.Token(context.Background())
+		if err != nil {
+			t.Fatalf("Token() error = %v", err)
+		}
+		if token.AccessToken != "token" {
+			t.Errorf("AccessToken = %q, want %q", token.AccessToken, "token")
+		}
+	}
+	if calls != 1 {
+		t.Errorf("source called %d times, want 1", calls)
+	}
+
+	// A token that expires within the refresh window is replaced
+	expiry = time.Now().Add(time.Minute)
+	source = NewCachedTokenSource(types.TokenSourceFunc(func(ctx context.Context) (types.Token, error) {
+		calls++
+		return types.Token{AccessToken: "short-lived", Expiry: expiry}, nil
+	}))
+	calls = 0
+	_, _ = source.Token(context.Background())
+	_, _ = source.Token(context.Background())
+	if calls != 2 {
+		t.Errorf("source called %d times for a token near expiry, want 2", calls)
+	}
+}
+
+func TestCachedTokenSource_Errors(t *testing.T) {
+	failing := NewCachedTokenSource(types.TokenSourceFunc(func(ctx context.Context) (types.Token, error) {
+		return types.Token{}, errors.New("identity provider unavailable")
+	}))
+	if _, err := failing.Token(context.Background()); err == nil {
+		t.Error("expected an error from a failing source")
+	}
+
+	empty := NewCachedTokenSource(types.TokenSourceFunc(func(ctx context.Context) (types.Token, error) {
+		return types.Token{}, nil
+	}))
+	if _, err := empty.Token(context.Background()); err == nil {
+		t.Error("expected an error for an empty token")
+	}
+}
@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kengibson1111/go-aiprovider/types"
+)
+
+func TestConfigureTransport_Proxy(t *testing.T) {
+	transport := &http.Transport{}
+	if err := ConfigureTransport(transport, &types.AIConfig{ProxyURL: "http://proxy.corp.example:3128", DisableKeepAlives: true}); err != nil {
+		t.Fatalf("ConfigureTransport() error = %v", err)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "https://api.openai.com/v1/models", nil)
+	proxy, err := transport.Proxy(req)
+	if err != nil || proxy == nil || proxy.Host != "proxy.corp.example:3128" {
+		t.Errorf("Proxy() = %v, %v; want proxy.corp.example:3128", proxy, err)
+	}
+	if !transport.DisableKeepAlives {
+		t.Error("DisableKeepAlives = false, want true")
+	}
+	if transport.TLSClientConfig != nil {
+		t.Error("TLSClientConfig set without TLS settings")
+	}
+
+	// Without ProxyURL the environment decides
+	transport = &http.Transport{}
+	if err := ConfigureTransport(transport, &types.AIConfig{}); err != nil {
+		t.Fatalf("ConfigureTransport() error = %v", err)
+	}
+	if transport.Proxy == nil {
+		t.Error("Proxy = nil, want http.ProxyFromEnvironment")
+	}
+}
+
+func TestConfigureTransport_CACertFile(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(caFile, caPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	transport := &http.Transport{}
+	if err := ConfigureTransport(transport, &types.AIConfig{CACertFile: caFile}); err != nil {
+		t.Fatalf("ConfigureTransport() error = %v", err)
+	}
+	resp, err := (&http.Client{Transport: transport}).Get(srv.URL)
+	if err != nil {
+		t.Fatalf("request to server signed by the CA bundle failed: %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestConfigureTransport_Errors(t *testing.T) {
+	dir := t.TempDir()
+	notPEM := filepath.Join(dir, "not.pem")
+	if err := os.WriteFile(notPEM, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		config  types.AIConfig
+		wantErr string
+	}{
+		{"missing CA bundle", types.AIConfig{CACertFile: filepath.Join(dir, "missing.pem")}, "failed to read CA bundle"},
+		{"CA bundle without certificates", types.AIConfig{CACertFile: notPEM}, "no PEM certificates found"},
+		{"invalid client certificate", types.AIConfig{ClientCertFile: notPEM, ClientKeyFile: notPEM}, "failed to load client certificate"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ConfigureTransport(&http.Transport{}, &tt.config)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ConfigureTransport() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
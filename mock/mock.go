@@ -0,0 +1,286 @@
+// Package mock provides MockClient, a configurable client.AIClient for testing code that
+// calls an AI provider without network access or API keys.
+//
+// A MockClient answers each call from, in order: replies queued with Enqueue, the first
+// matching rule added with When or WhenContains, and finally Default. Every call is
+// recorded and can be inspected with Calls.
+//
+//	m := mock.New()
+//	m.Enqueue(mock.OpenAIText("first answer"), mock.Error(errors.New("boom")))
+//	m.WhenContains("summarize", mock.ClaudeText("a summary"))
+//	m.Latency = 50 * time.Millisecond
+//
+//	response, err := m.CallWithPrompt(ctx, "hello") // "first answer"
+//	calls := m.Calls()
+package mock
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kengibson1111/go-aiprovider/client"
+	"github.com/kengibson1111/go-aiprovider/internal/shared/utils"
+	"github.com/kengibson1111/go-aiprovider/types"
+)
+
+// ErrNoReply is returned when no queued reply, rule, or Default applies to a call
+var ErrNoReply = errors.New("mock: no reply configured")
+
+var (
+	_ client.AIClient    = (*MockClient)(nil)
+	_ client.ModelLister = (*MockClient)(nil)
+)
+
+// Reply is the outcome of a single mocked call.
+type Reply struct {
+	// Body is returned as the raw response when Err is nil.
+	Body []byte
+
+	// Err, if set, is returned instead of Body.
+	Err error
+
+	// Delay is added to MockClient.Latency for this reply.
+	Delay time.Duration
+}
+
+// Call records one call made to a MockClient.
+type Call struct {
+	// Method is the AIClient method name, e.g. "CallWithPrompt".
+	Method string
+
+	// Prompt is the prompt after variable substitution.
+	Prompt string
+
+	// Template is the prompt as passed to CallWithPromptAndVariables, before substitution.
+	Template string
+
+	// Variables are the variables passed to the call, if any.
+	Variables string
+
+	// Options are the call options passed to the call, applied to zero defaults.
+	Options types.CallOptions
+
+	// Time is when the call started.
+	Time time.Time
+}
+
+// rule answers calls whose prompt matches.
+type rule struct {
+	match func(prompt string) bool
+	reply Reply
+}
+
+// MockClient is a scriptable client.AIClient. Configure the exported fields before
+// making calls; the methods are safe for concurrent use.
+type MockClient struct {
+	// Default is returned when no queued reply or rule applies. A zero Default makes such
+	// calls fail with ErrNoReply.
+	Default Reply
+
+	// Latency is added to every call. Calls return ctx.Err() if the context ends first.
+	Latency time.Duration
+
+	// CredentialsErr is returned by ValidateCredentials.
+	CredentialsErr error
+
+	// Models is returned by ListModels.
+	Models []types.ModelInfo
+
+	mu    sync.Mutex
+	queue []Reply
+	rules []rule
+	calls []Call
+}
+
+// New returns a MockClient with no replies configured.
+func New() *MockClient {
+	return &MockClient{}
+}
+
+// Enqueue adds replies that are returned, in order, by the next calls.
+func (m *MockClient) Enqueue(replies ...Reply) *MockClient {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.queue = append(m.queue, replies...)
+	return m
+}
+
+// When answers every call whose prompt satisfies match with reply, when no queued reply
+// is pending. Rules are checked in the order they were added.
+func (m *MockClient) When(match func(prompt string) bool, reply Reply) *MockClient {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rules = append(m.rules, rule{match: match, reply: reply})
+	return m
+}
+
+// WhenContains answers every call whose prompt contains substr with reply.
+func (m *MockClient) WhenContains(substr string, reply Reply) *MockClient {
+	return m.When(func(prompt string) bool {
+		return strings.Contains(prompt, substr)
+	}, reply)
+}
+
+// Calls returns a copy of the calls made so far.
+func (m *MockClient) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call(nil), m.calls...)
+}
+
+// CallCount returns the number of calls made so far.
+func (m *MockClient) CallCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.calls)
+}
+
+// Reset clears queued replies, rules, and recorded calls. Exported fields are kept.
+func (m *MockClient) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.queue = nil
+	m.rules = nil
+	m.calls = nil
+}
+
+// CallWithPrompt records the call and returns the next reply.
+func (m *MockClient) CallWithPrompt(ctx context.Context, prompt string, opts ...types.CallOption) ([]byte, error) {
+	return m.call(ctx, Call{Method: "CallWithPrompt", Prompt: prompt}, opts)
+}
+
+// CallWithPromptAndVariables substitutes variables as the real clients do in simple template
+// mode, records the call, and returns the next reply. Substitution errors are returned
+// without consuming a reply.
+func (m *MockClient) CallWithPromptAndVariables(ctx context.Context, prompt string, variablesJSON string, opts ...types.CallOption) ([]byte, error) {
+	processed, err := utils.SubstituteVariables(prompt, variablesJSON)
+	if err != nil {
+		return nil, err
+	}
+	return m.call(ctx, Call{Method: "CallWithPromptAndVariables", Prompt: processed, Template: prompt, Variables: variablesJSON}, opts)
+}
+
+// ValidateCredentials records the call and returns CredentialsErr after the configured latency.
+func (m *MockClient) ValidateCredentials(ctx context.Context) error {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "ValidateCredentials", Time: time.Now()})
+	latency := m.Latency
+	m.mu.Unlock()
+
+	if err := wait(ctx, latency); err != nil {
+		return err
+	}
+	return m.CredentialsErr
+}
+
+// ListModels records the call and returns Models, so the mock also satisfies client.ModelLister.
+func (m *MockClient) ListModels(ctx context.Context) ([]types.ModelInfo, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "ListModels", Time: time.Now()})
+	latency := m.Latency
+	m.mu.Unlock()
+
+	if err := wait(ctx, latency); err != nil {
+		return nil, err
+	}
+	return m.Models, nil
+}
+
+// call records c, picks a reply, and waits for the configured latency.
+func (m *MockClient) call(ctx context.Context, c Call, opts []types.CallOption) ([]byte, error) {
+	c.Options = types.ResolveCallOptions(types.CallOptions{}, opts)
+	c.Time = time.Now()
+
+	m.mu.Lock()
+	m.calls = append(m.calls, c)
+	reply := m.nextReply(c.Prompt)
+	latency := m.Latency + reply.Delay
+	m.mu.Unlock()
+
+	if err := wait(ctx, latency); err != nil {
+		return nil, err
+	}
+	if reply.Err != nil {
+		return nil, reply.Err
+	}
+	return reply.Body, nil
+}
+
+// wait sleeps for latency, returning early with ctx.Err() if the context ends first.
+func wait(ctx context.Context, latency time.Duration) error {
+	if latency <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(latency)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// nextReply returns the reply for prompt. The caller must hold m.mu.
+func (m *MockClient) nextReply(prompt string) Reply {
+	if len(m.queue) > 0 {
+		reply := m.queue[0]
+		m.queue = m.queue[1:]
+		return reply
+	}
+	for _, r := range m.rules {
+		if r.match(prompt) {
+			return r.reply
+		}
+	}
+	if m.Default.Body == nil && m.Default.Err == nil {
+		return Reply{Err: ErrNoReply}
+	}
+	return m.Default
+}
+
+// Raw returns a reply with the given raw response body.
+func Raw(body []byte) Reply {
+	return Reply{Body: body}
+}
+
+// Error returns a reply that fails with err.
+func Error(err error) Reply {
+	return Reply{Err: err}
+}
+
+// OpenAIText returns a reply shaped like an OpenAI chat completion with text as the
+// assistant message.
+func OpenAIText(text string) Reply {
+	body, _ := json.Marshal(map[string]any{
+		"id":     "chatcmpl-mock",
+		"object": "chat.completion",
+		"model":  "mock",
+		"choices": []any{map[string]any{
+			"index":         0,
+			"finish_reason": "stop",
+			"message":       map[string]any{"role": "assistant", "content": text},
+		}},
+		"usage": map[string]any{"prompt_tokens": 0, "completion_tokens": 0, "total_tokens": 0},
+	})
+	return Reply{Body: body}
+}
+
+// ClaudeText returns a reply shaped like a Claude Messages response with text as the
+// only content block.
+func ClaudeText(text string) Reply {
+	body, _ := json.Marshal(map[string]any{
+		"id":          "msg_mock",
+		"type":        "message",
+		"role":        "assistant",
+		"model":       "mock",
+		"content":     []any{map[string]any{"type": "text", "text": text}},
+		"stop_reason": "end_turn",
+		"usage":       map[string]any{"input_tokens": 0, "output_tokens": 0},
+	})
+	return Reply{Body: body}
+}
@@ -0,0 +1,114 @@
+package mock
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kengibson1111/go-aiprovider/client"
+	"github.com/kengibson1111/go-aiprovider/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMockClient_ReplyOrder(t *testing.T) {
+	boom := errors.New("boom")
+	m := New()
+	m.Enqueue(Raw([]byte("first")), Error(boom))
+	m.WhenContains("summarize", Raw([]byte("summary")))
+	m.Default = Raw([]byte("default"))
+
+	ctx := context.Background()
+	tests := []struct {
+		prompt   string
+		expected string
+		err      error
+	}{
+		{prompt: "summarize this", expected: "first"},
+		{prompt: "anything", err: boom},
+		{prompt: "summarize that", expected: "summary"},
+		{prompt: "anything", expected: "default"},
+	}
+	for _, tt := range tests {
+		response, err := m.CallWithPrompt(ctx, tt.prompt)
+		if tt.err != nil {
+			assert.ErrorIs(t, err, tt.err)
+			continue
+		}
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, string(response))
+	}
+
+	// Reset drops rules and recorded calls but keeps Default
+	m.Reset()
+	response, err := m.CallWithPrompt(ctx, "summarize")
+	require.NoError(t, err)
+	assert.Equal(t, "default", string(response))
+	assert.Equal(t, 1, m.CallCount())
+
+	_, err = New().CallWithPrompt(ctx, "x")
+	assert.ErrorIs(t, err, ErrNoReply)
+}
+
+func TestMockClient_RecordsCalls(t *testing.T) {
+	m := New()
+	m.Default = OpenAIText("ok")
+	ctx := context.Background()
+
+	_, err := m.CallWithPromptAndVariables(ctx, "Hello {{name}}", `{"name": "Alice"}`, types.WithModel("gpt-4o"))
+	require.NoError(t, err)
+	_, err = client.CallWithValues(ctx, m, "Bye {{name}}", map[string]any{"name": "Bob"})
+	require.NoError(t, err)
+	require.NoError(t, m.ValidateCredentials(ctx))
+
+	calls := m.Calls()
+	require.Len(t, calls, 3)
+	assert.Equal(t, "CallWithPromptAndVariables", calls[0].Method)
+	assert.Equal(t, "Hello Alice", calls[0].Prompt)
+	assert.Equal(t, "Hello {{name}}", calls[0].Template)
+	assert.Equal(t, "gpt-4o", calls[0].Options.Model)
+	assert.Equal(t, "Bye Bob", calls[1].Prompt)
+	assert.Equal(t, "ValidateCredentials", calls[2].Method)
+	assert.Equal(t, 3, m.CallCount())
+}
+
+func TestMockClient_Latency(t *testing.T) {
+	m := New()
+	m.Default = Raw([]byte("slow"))
+	m.Latency = time.Second
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := m.CallWithPrompt(ctx, "x")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	m.Latency = 0
+	m.Enqueue(Reply{Body: []byte("delayed"), Delay: 5 * time.Millisecond})
+	start := time.Now()
+	response, err := m.CallWithPrompt(context.Background(), "x")
+	require.NoError(t, err)
+	assert.Equal(t, "delayed", string(response))
+	assert.GreaterOrEqual(t, time.Since(start), 5*time.Millisecond)
+}
+
+func TestMockClient_ResponseShapes(t *testing.T) {
+	m := New()
+	m.Enqueue(ClaudeText("hi"), OpenAIText("hi"))
+	gc, err := client.NewGlossaryClient(m, []client.GlossaryTerm{{Term: "Hi", IgnoreCase: true}}, client.GlossaryOptions{})
+	require.NoError(t, err)
+
+	for range 2 {
+		response, err := gc.CallWithPrompt(context.Background(), "x")
+		require.NoError(t, err)
+		assert.Contains(t, string(response), `"Hi"`)
+	}
+
+	m.CredentialsErr = errors.New("bad key")
+	assert.EqualError(t, m.ValidateCredentials(context.Background()), "bad key")
+
+	m.Models = []types.ModelInfo{{ID: "mock-model"}}
+	models, err := client.ListModels(context.Background(), m)
+	require.NoError(t, err)
+	assert.Equal(t, "mock-model", models[0].ID)
+}
@@ -0,0 +1,110 @@
+package prompts
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// templateExtensions lists the file extensions LoadDir treats as templates.
+var templateExtensions = map[string]bool{
+	".tmpl":   true,
+	".prompt": true,
+	".txt":    true,
+	".md":     true,
+}
+
+// LoadDir registers every template file found under dir (recursively).
+//
+// File names follow the pattern <name>@<version>.<ext>, for example
+// "code-review@v2.tmpl". Files without "@<version>" are registered as DefaultVersion.
+// Supported extensions are .tmpl, .prompt, .txt, and .md.
+//
+// A file may start with an optional front-matter header delimited by "---" lines:
+//
+//	---
+//	description: Review a change for correctness
+//	required: language, code
+//	mode: enhanced
+//	---
+//	You are a senior {{language}} reviewer...
+//
+// Recognized keys are description, required (comma-separated), mode, and version
+// (which overrides the version in the file name). Unknown keys are rejected so that
+// typos do not silently disable validation.
+func (r *Registry) LoadDir(dir string) error {
+	return filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !templateExtensions[strings.ToLower(filepath.Ext(path))] {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read template %s: %w", path, err)
+		}
+
+		t, err := parseTemplateFile(filepath.Base(path), string(data))
+		if err != nil {
+			return fmt.Errorf("failed to parse template %s: %w", path, err)
+		}
+
+		if err := r.Register(t); err != nil {
+			return fmt.Errorf("failed to register template %s: %w", path, err)
+		}
+		return nil
+	})
+}
+
+// parseTemplateFile builds a Template from a file name and its contents.
+func parseTemplateFile(fileName, data string) (Template, error) {
+	base := strings.TrimSuffix(fileName, filepath.Ext(fileName))
+	name, version, _ := strings.Cut(base, "@")
+
+	t := Template{Name: name, Version: version}
+
+	content := strings.ReplaceAll(data, "\r\n", "\n")
+	if rest, ok := strings.CutPrefix(content, "---\n"); ok {
+		header, body, found := strings.Cut(rest, "\n---\n")
+		if !found {
+			return Template{}, fmt.Errorf("%w: unterminated front matter", ErrInvalidTemplate)
+		}
+
+		for _, line := range strings.Split(header, "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+
+			key, value, ok := strings.Cut(line, ":")
+			if !ok {
+				return Template{}, fmt.Errorf("%w: malformed front matter line %q", ErrInvalidTemplate, line)
+			}
+			value = strings.TrimSpace(value)
+
+			switch strings.ToLower(strings.TrimSpace(key)) {
+			case "description":
+				t.Description = value
+			case "required":
+				for _, v := range strings.Split(value, ",") {
+					if v = strings.TrimSpace(v); v != "" {
+						t.Required = append(t.Required, v)
+					}
+				}
+			case "mode":
+				t.Mode = value
+			case "version":
+				t.Version = value
+			default:
+				return Template{}, fmt.Errorf("%w: unknown front matter key %q", ErrInvalidTemplate, key)
+			}
+		}
+		content = body
+	}
+
+	t.Content = content
+	return t, nil
+}
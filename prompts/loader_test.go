@@ -0,0 +1,101 @@
+package prompts
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_LoadDir(t *testing.T) {
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"code-review@v1.tmpl": "Review {{code}}",
+		"code-review@v2.tmpl": "---\ndescription: Careful review\nrequired: language, code\n---\nReview this {{language}} code:\n{{code}}",
+		"nested/summary.md":   "Summarize {{text}}",
+		"README":              "not a template",
+		"notes.json":          `{"ignored": true}`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	registry := NewRegistry()
+	require.NoError(t, registry.LoadDir(dir))
+
+	assert.Equal(t, []string{"code-review@v1", "code-review@v2", "summary@v1"}, registry.List())
+
+	tmpl, err := registry.Get("code-review@v2")
+	require.NoError(t, err)
+	assert.Equal(t, "Careful review", tmpl.Description)
+	assert.Equal(t, []string{"language", "code"}, tmpl.Required)
+	assert.Equal(t, "Review this {{language}} code:\n{{code}}", tmpl.Content)
+}
+
+func TestRegistry_LoadDirErrors(t *testing.T) {
+	t.Run("missing directory", func(t *testing.T) {
+		registry := NewRegistry()
+		assert.Error(t, registry.LoadDir(filepath.Join(t.TempDir(), "missing")))
+	})
+
+	t.Run("duplicate version via front matter", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "a@v2.tmpl"), []byte("one"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "a@v3.tmpl"), []byte("---\nversion: v2\n---\ntwo"), 0644))
+
+		registry := NewRegistry()
+		assert.ErrorIs(t, registry.LoadDir(dir), ErrDuplicateTemplate)
+	})
+}
+
+func TestParseTemplateFile(t *testing.T) {
+	tests := []struct {
+		name        string
+		fileName    string
+		data        string
+		expected    Template
+		expectError bool
+	}{
+		{
+			name:     "plain file",
+			fileName: "greet@v3.tmpl",
+			data:     "Hello {{name}}",
+			expected: Template{Name: "greet", Version: "v3", Content: "Hello {{name}}"},
+		},
+		{
+			name:     "front matter with CRLF line endings",
+			fileName: "greet.prompt",
+			data:     "---\r\nmode: enhanced\r\nrequired: name\r\n---\r\nHello {{name}}",
+			expected: Template{Name: "greet", Mode: "enhanced", Required: []string{"name"}, Content: "Hello {{name}}"},
+		},
+		{
+			name:        "unterminated front matter",
+			fileName:    "greet.tmpl",
+			data:        "---\nmode: enhanced\nHello",
+			expectError: true,
+		},
+		{
+			name:        "unknown front matter key",
+			fileName:    "greet.tmpl",
+			data:        "---\nrequird: name\n---\nHello",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := parseTemplateFile(tt.fileName, tt.data)
+			if tt.expectError {
+				assert.ErrorIs(t, err, ErrInvalidTemplate)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
@@ -0,0 +1,223 @@
+// Package prompts provides a registry of named, versioned prompt templates.
+//
+// Templates are registered in code or loaded from a directory with LoadDir, then
+// referenced as "name@version" (or just "name" for the highest registered version).
+// Rendering validates required variables before substituting them, so a missing
+// variable is reported instead of shipping a prompt with an unresolved placeholder.
+//
+// # Usage
+//
+//	registry := prompts.NewRegistry()
+//	if err := registry.LoadDir("templates"); err != nil {
+//		log.Fatal(err)
+//	}
+//
+//	prompt, err := registry.Render("code-review@v2", map[string]any{
+//		"language": "Go",
+//		"code":     source,
+//	})
+//
+// Pair a registry with an AIClient using client.NewTemplateClient to call templates
+// directly with CallWithTemplate.
+package prompts
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/kengibson1111/go-aiprovider/internal/shared/utils"
+)
+
+// Registry errors
+var (
+	// ErrTemplateNotFound is returned when no template matches a reference
+	ErrTemplateNotFound = errors.New("template not found")
+
+	// ErrDuplicateTemplate is returned when a name@version pair is registered twice
+	ErrDuplicateTemplate = errors.New("template already registered")
+
+	// ErrInvalidTemplate is returned when a template is missing its name or content
+	ErrInvalidTemplate = errors.New("invalid template")
+
+	// ErrMissingVariables is returned when required variables are not supplied to Render
+	ErrMissingVariables = errors.New("missing required template variables")
+)
+
+// DefaultVersion is assigned to templates registered without a version.
+const DefaultVersion = "v1"
+
+// Template is a named, versioned prompt template.
+type Template struct {
+	Name        string   `json:"name"`
+	Version     string   `json:"version"`
+	Description string   `json:"description,omitempty"`
+	Content     string   `json:"content"`
+	Required    []string `json:"required,omitempty"`
+
+	// Mode selects the template processing mode (types.TemplateModeSimple or
+	// types.TemplateModeEnhanced). Empty selects simple substitution.
+	Mode string `json:"mode,omitempty"`
+}
+
+// Ref returns the "name@version" reference for the template.
+func (t *Template) Ref() string {
+	return t.Name + "@" + t.Version
+}
+
+// Registry stores templates by name and version. It is safe for concurrent use.
+type Registry struct {
+	mu        sync.RWMutex
+	templates map[string]map[string]*Template
+}
+
+// NewRegistry creates an empty template registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		templates: make(map[string]map[string]*Template),
+	}
+}
+
+// Register adds a template to the registry. The version defaults to DefaultVersion
+// when empty. Registering the same name@version twice returns ErrDuplicateTemplate.
+func (r *Registry) Register(t Template) error {
+	t.Name = strings.TrimSpace(t.Name)
+	t.Version = strings.TrimSpace(t.Version)
+	if t.Name == "" || strings.Contains(t.Name, "@") {
+		return fmt.Errorf("%w: name %q must be non-empty and must not contain '@'", ErrInvalidTemplate, t.Name)
+	}
+	if t.Content == "" {
+		return fmt.Errorf("%w: %s has no content", ErrInvalidTemplate, t.Name)
+	}
+	if t.Version == "" {
+		t.Version = DefaultVersion
+	}
+	if _, err := utils.ParseTemplateMode(t.Mode); err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrInvalidTemplate, t.Ref(), err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	versions, ok := r.templates[t.Name]
+	if !ok {
+		versions = make(map[string]*Template)
+		r.templates[t.Name] = versions
+	}
+	if _, exists := versions[t.Version]; exists {
+		return fmt.Errorf("%w: %s", ErrDuplicateTemplate, t.Ref())
+	}
+
+	versions[t.Version] = &t
+	return nil
+}
+
+// Get returns the template for a reference of the form "name@version" or "name".
+// A bare name resolves to the highest registered version.
+func (r *Registry) Get(ref string) (*Template, error) {
+	name, version, hasVersion := strings.Cut(strings.TrimSpace(ref), "@")
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	versions, ok := r.templates[name]
+	if !ok || len(versions) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrTemplateNotFound, ref)
+	}
+
+	if hasVersion {
+		t, ok := versions[version]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrTemplateNotFound, ref)
+		}
+		return t, nil
+	}
+
+	var latest *Template
+	for _, t := range versions {
+		if latest == nil || compareVersions(t.Version, latest.Version) > 0 {
+			latest = t
+		}
+	}
+	return latest, nil
+}
+
+// List returns the references of all registered templates in sorted order.
+func (r *Registry) List() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var refs []string
+	for _, versions := range r.templates {
+		for _, t := range versions {
+			refs = append(refs, t.Ref())
+		}
+	}
+	sort.Strings(refs)
+	return refs
+}
+
+// Render resolves a template reference, checks that every required variable is
+// present in vars, and returns the rendered prompt.
+func (r *Registry) Render(ref string, vars map[string]any) (string, error) {
+	t, err := r.Get(ref)
+	if err != nil {
+		return "", err
+	}
+	return t.Render(vars)
+}
+
+// Render checks required variables and substitutes vars into the template content.
+func (t *Template) Render(vars map[string]any) (string, error) {
+	var missing []string
+	for _, name := range t.Required {
+		if _, ok := vars[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return "", fmt.Errorf("%w for %s: %s", ErrMissingVariables, t.Ref(), strings.Join(missing, ", "))
+	}
+
+	mode, err := utils.ParseTemplateMode(t.Mode)
+	if err != nil {
+		return "", err
+	}
+
+	return utils.SubstituteValues(t.Content, vars, utils.TemplateOptions{Mode: mode})
+}
+
+// compareVersions orders version tags such as "v1", "v2", "v1.10" numerically,
+// falling back to string comparison for non-numeric segments.
+func compareVersions(a, b string) int {
+	as := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	bs := strings.Split(strings.TrimPrefix(b, "v"), ".")
+
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var sa, sb string
+		if i < len(as) {
+			sa = as[i]
+		}
+		if i < len(bs) {
+			sb = bs[i]
+		}
+
+		na, errA := strconv.Atoi(sa)
+		nb, errB := strconv.Atoi(sb)
+		switch {
+		case errA == nil && errB == nil:
+			if na != nb {
+				if na < nb {
+					return -1
+				}
+				return 1
+			}
+		case sa != sb:
+			return strings.Compare(sa, sb)
+		}
+	}
+	return 0
+}
@@ -0,0 +1,126 @@
+package prompts
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_RegisterAndGet(t *testing.T) {
+	registry := NewRegistry()
+
+	require.NoError(t, registry.Register(Template{Name: "code-review", Version: "v1", Content: "Review {{code}}"}))
+	require.NoError(t, registry.Register(Template{Name: "code-review", Version: "v2", Content: "Carefully review {{code}}"}))
+	require.NoError(t, registry.Register(Template{Name: "code-review", Version: "v10", Content: "Thoroughly review {{code}}"}))
+	require.NoError(t, registry.Register(Template{Name: "summary", Content: "Summarize {{text}}"}))
+
+	tests := []struct {
+		name            string
+		ref             string
+		expectedVersion string
+		expectError     bool
+	}{
+		{name: "explicit version", ref: "code-review@v2", expectedVersion: "v2"},
+		{name: "latest version uses numeric ordering", ref: "code-review", expectedVersion: "v10"},
+		{name: "default version", ref: "summary@v1", expectedVersion: DefaultVersion},
+		{name: "unknown version", ref: "code-review@v3", expectError: true},
+		{name: "unknown template", ref: "missing", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := registry.Get(tt.ref)
+			if tt.expectError {
+				assert.ErrorIs(t, err, ErrTemplateNotFound)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedVersion, tmpl.Version)
+		})
+	}
+
+	assert.Equal(t, []string{"code-review@v1", "code-review@v10", "code-review@v2", "summary@v1"}, registry.List())
+}
+
+func TestRegistry_RegisterValidation(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(Template{Name: "greet", Version: "v1", Content: "Hello"}))
+
+	tests := []struct {
+		name     string
+		template Template
+		expected error
+	}{
+		{name: "duplicate", template: Template{Name: "greet", Version: "v1", Content: "Hi"}, expected: ErrDuplicateTemplate},
+		{name: "empty name", template: Template{Content: "Hi"}, expected: ErrInvalidTemplate},
+		{name: "name with version separator", template: Template{Name: "a@b", Content: "Hi"}, expected: ErrInvalidTemplate},
+		{name: "empty content", template: Template{Name: "empty"}, expected: ErrInvalidTemplate},
+		{name: "unknown mode", template: Template{Name: "mode", Content: "Hi", Mode: "jinja"}, expected: ErrInvalidTemplate},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, registry.Register(tt.template), tt.expected)
+		})
+	}
+}
+
+func TestRegistry_Render(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(Template{
+		Name:     "code-review",
+		Version:  "v2",
+		Content:  "Review this {{language}} code:\n{{code}}",
+		Required: []string{"language", "code"},
+	}))
+	require.NoError(t, registry.Register(Template{
+		Name:    "checklist",
+		Version: "v1",
+		Content: "{{#each items}}- {{this}}\n{{/each}}",
+		Mode:    "enhanced",
+	}))
+
+	t.Run("renders with all required variables", func(t *testing.T) {
+		result, err := registry.Render("code-review@v2", map[string]any{"language": "Go", "code": "func main() {}"})
+		require.NoError(t, err)
+		assert.Equal(t, "Review this Go code:\nfunc main() {}", result)
+	})
+
+	t.Run("reports missing required variables", func(t *testing.T) {
+		_, err := registry.Render("code-review@v2", map[string]any{"language": "Go"})
+		assert.ErrorIs(t, err, ErrMissingVariables)
+		assert.Contains(t, err.Error(), "code")
+	})
+
+	t.Run("renders enhanced templates", func(t *testing.T) {
+		result, err := registry.Render("checklist", map[string]any{"items": []string{"tests", "docs"}})
+		require.NoError(t, err)
+		assert.Equal(t, "- tests\n- docs\n", result)
+	})
+
+	t.Run("unknown template", func(t *testing.T) {
+		_, err := registry.Render("nope", nil)
+		assert.ErrorIs(t, err, ErrTemplateNotFound)
+	})
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{a: "v1", b: "v2", expected: -1},
+		{a: "v10", b: "v2", expected: 1},
+		{a: "v1.2", b: "v1.10", expected: -1},
+		{a: "v2", b: "v2", expected: 0},
+		{a: "1.0", b: "v1.0", expected: 0},
+		{a: "beta", b: "alpha", expected: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"_"+tt.b, func(t *testing.T) {
+			assert.Equal(t, tt.expected, compareVersions(tt.a, tt.b))
+		})
+	}
+}
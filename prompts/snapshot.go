@@ -0,0 +1,174 @@
+package prompts
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Snapshot maps template references to their rendered output. Templates that fail to
+// render are recorded as "error: <message>" so a new rendering failure shows up as a change.
+type Snapshot map[string]string
+
+// Snapshot change kinds for SnapshotChange.Kind
+const (
+	SnapshotAdded   = "added"
+	SnapshotRemoved = "removed"
+	SnapshotChanged = "changed"
+)
+
+// SnapshotChange is one template whose rendered output differs between two snapshots.
+type SnapshotChange struct {
+	Ref  string
+	Kind string
+	Old  string
+	New  string
+}
+
+// Snapshot renders every registered template with sample variables and returns the
+// outputs. samples is keyed by "name@version" or by name, for variables shared by all
+// versions; a versioned key takes precedence. Templates without samples render with no
+// variables.
+//
+// Commit a snapshot next to your templates and compare it in CI after upgrading this
+// library or editing templates, to catch changes to the prompts actually sent:
+//
+//	current := registry.Snapshot(samples)
+//	golden, err := prompts.ReadSnapshot("testdata/prompts.snapshot.json")
+//	if changes := prompts.DiffSnapshots(golden, current); len(changes) > 0 {
+//		t.Errorf("rendered prompts changed:\n%s", prompts.FormatChanges(changes))
+//	}
+func (r *Registry) Snapshot(samples map[string]map[string]any) Snapshot {
+	snapshot := Snapshot{}
+	for _, ref := range r.List() {
+		t, err := r.Get(ref)
+		if err != nil {
+			continue
+		}
+		vars, ok := samples[ref]
+		if !ok {
+			vars = samples[t.Name]
+		}
+
+		rendered, err := t.Render(vars)
+		if err != nil {
+			rendered = "error: " + err.Error()
+		}
+		snapshot[ref] = rendered
+	}
+	return snapshot
+}
+
+// WriteSnapshot writes a snapshot to path as indented JSON with sorted keys.
+func WriteSnapshot(path string, snapshot Snapshot) error {
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write snapshot %s: %w", path, err)
+	}
+	return nil
+}
+
+// ReadSnapshot reads a snapshot written by WriteSnapshot.
+func ReadSnapshot(path string) (Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot %s: %w", path, err)
+	}
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot %s: %w", path, err)
+	}
+	return snapshot, nil
+}
+
+// DiffSnapshots compares two snapshots and returns the templates that were added,
+// removed, or render differently, sorted by reference.
+func DiffSnapshots(old, current Snapshot) []SnapshotChange {
+	var changes []SnapshotChange
+	for ref, oldOutput := range old {
+		newOutput, ok := current[ref]
+		switch {
+		case !ok:
+			changes = append(changes, SnapshotChange{Ref: ref, Kind: SnapshotRemoved, Old: oldOutput})
+		case newOutput != oldOutput:
+			changes = append(changes, SnapshotChange{Ref: ref, Kind: SnapshotChanged, Old: oldOutput, New: newOutput})
+		}
+	}
+	for ref, newOutput := range current {
+		if _, ok := old[ref]; !ok {
+			changes = append(changes, SnapshotChange{Ref: ref, Kind: SnapshotAdded, New: newOutput})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Ref < changes[j].Ref })
+	return changes
+}
+
+// FormatChanges renders snapshot changes as a readable report with a line diff for each
+// changed template ("-" for removed lines, "+" for added lines).
+func FormatChanges(changes []SnapshotChange) string {
+	var b strings.Builder
+	for _, change := range changes {
+		fmt.Fprintf(&b, "=== %s (%s)\n", change.Ref, change.Kind)
+		for _, line := range diffLines(splitLines(change.Old), splitLines(change.New)) {
+			b.WriteString(line)
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// splitLines splits text into lines, returning nil for empty text.
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
+
+// diffLines returns a line diff of a and b based on their longest common subsequence.
+// Unchanged lines are prefixed with two spaces.
+func diffLines(a, b []string) []string {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var out []string
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			out = append(out, "  "+a[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			out = append(out, "- "+a[i])
+			i++
+		default:
+			out = append(out, "+ "+b[j])
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		out = append(out, "- "+a[i])
+	}
+	for ; j < len(b); j++ {
+		out = append(out, "+ "+b[j])
+	}
+	return out
+}
@@ -0,0 +1,58 @@
+package prompts
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_Snapshot(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(Template{Name: "greet", Version: "v1", Content: "Hello {{name}}"}))
+	require.NoError(t, registry.Register(Template{Name: "greet", Version: "v2", Content: "Hi {{name}}, welcome"}))
+	require.NoError(t, registry.Register(Template{Name: "review", Content: "Review {{code}}", Required: []string{"code"}}))
+
+	snapshot := registry.Snapshot(map[string]map[string]any{
+		"greet":    {"name": "Ann"},
+		"greet@v2": {"name": "Bo"},
+	})
+
+	assert.Equal(t, "Hello Ann", snapshot["greet@v1"])
+	assert.Equal(t, "Hi Bo, welcome", snapshot["greet@v2"])
+	assert.Contains(t, snapshot["review@v1"], "error: missing required template variables")
+
+	path := filepath.Join(t.TempDir(), "prompts.snapshot.json")
+	require.NoError(t, WriteSnapshot(path, snapshot))
+	read, err := ReadSnapshot(path)
+	require.NoError(t, err)
+	assert.Equal(t, snapshot, read)
+
+	_, err = ReadSnapshot(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	old := Snapshot{
+		"greet@v1":  "Hello Ann",
+		"review@v1": "Review the code.\nBe concise.\nList bugs.",
+		"gone@v1":   "bye",
+	}
+	current := Snapshot{
+		"greet@v1":  "Hello Ann",
+		"review@v1": "Review the code.\nBe thorough.\nList bugs.",
+		"new@v1":    "hi",
+	}
+
+	changes := DiffSnapshots(old, current)
+	require.Len(t, changes, 3)
+	assert.Equal(t, SnapshotChange{Ref: "gone@v1", Kind: SnapshotRemoved, Old: "bye"}, changes[0])
+	assert.Equal(t, SnapshotChange{Ref: "new@v1", Kind: SnapshotAdded, New: "hi"}, changes[1])
+	assert.Equal(t, SnapshotChanged, changes[2].Kind)
+
+	report := FormatChanges(changes[2:])
+	assert.Equal(t, "=== review@v1 (changed)\n  Review the code.\n- Be concise.\n+ Be thorough.\n  List bugs.\n", report)
+
+	assert.Empty(t, DiffSnapshots(old, old))
+}
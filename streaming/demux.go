@@ -0,0 +1,231 @@
+package streaming
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/openai/openai-go/v2"
+)
+
+// ErrUnexpectedChoice is returned when a stream delivers a choice index outside the
+// range the demultiplexer was created for
+var ErrUnexpectedChoice = errors.New("unexpected choice index in stream")
+
+// ChoiceDemux splits a streaming completion that generates several choices (n > 1)
+// into one ChoiceStream per choice index, so candidates can be rendered in parallel.
+//
+// The source is read in the background and deltas are buffered per choice, so a slow
+// reader of one choice never blocks the others. Each ChoiceStream may be consumed from
+// its own goroutine.
+//
+// Example:
+//
+//	stream, err := client.CallWithPromptStream(ctx, "Suggest a name for a Go linter", types.WithN(3))
+//	if err != nil {
+//		return err
+//	}
+//	demux, err := streaming.NewChoiceDemux(stream, 3)
+//	if err != nil {
+//		return err
+//	}
+//
+//	var wg sync.WaitGroup
+//	for i := 0; i < demux.Len(); i++ {
+//		wg.Add(1)
+//		go func(choice *streaming.ChoiceStream) {
+//			defer wg.Done()
+//			for choice.Next() {
+//				render(choice.Index(), choice.Text())
+//			}
+//		}(demux.Choice(i))
+//	}
+//	wg.Wait()
+//
+//	usage, err := demux.Wait()
+type ChoiceDemux struct {
+	source  ChunkStream[openai.ChatCompletionChunk]
+	streams []*ChoiceStream
+	done    chan struct{}
+	usage   openai.CompletionUsage
+	err     error
+}
+
+// NewChoiceDemux starts reading source and returns a demultiplexer with n choice streams.
+func NewChoiceDemux(source ChunkStream[openai.ChatCompletionChunk], n int) (*ChoiceDemux, error) {
+	if source == nil {
+		return nil, errors.New("source stream is required")
+	}
+	if n < 1 {
+		return nil, fmt.Errorf("choice count must be at least 1, got %d", n)
+	}
+
+	d := &ChoiceDemux{
+		source:  source,
+		streams: make([]*ChoiceStream, n),
+		done:    make(chan struct{}),
+	}
+	for i := range d.streams {
+		d.streams[i] = newChoiceStream(i)
+	}
+
+	go d.read()
+	return d, nil
+}
+
+// read routes each choice delta to its stream and records the final usage chunk.
+func (d *ChoiceDemux) read() {
+	var err error
+
+read:
+	for d.source.Next() {
+		chunk := d.source.Current()
+		if chunk.Usage.TotalTokens > 0 {
+			d.usage = chunk.Usage
+		}
+		for _, choice := range chunk.Choices {
+			if choice.Index < 0 || choice.Index >= int64(len(d.streams)) {
+				err = fmt.Errorf("%w: %d (expected fewer than %d)", ErrUnexpectedChoice, choice.Index, len(d.streams))
+				break read
+			}
+			d.streams[choice.Index].push(choice)
+		}
+	}
+
+	if err == nil {
+		err = d.source.Err()
+	}
+	d.err = err
+	for _, s := range d.streams {
+		s.finish(err)
+	}
+	close(d.done)
+}
+
+// Len returns the number of choice streams.
+func (d *ChoiceDemux) Len() int {
+	return len(d.streams)
+}
+
+// Choice returns the stream for the given choice index.
+func (d *ChoiceDemux) Choice(index int) *ChoiceStream {
+	return d.streams[index]
+}
+
+// Wait blocks until the source stream has ended and returns the combined usage for all
+// choices. Usage is only reported when the provider sends a usage chunk; the OpenAI
+// client requests one automatically for streaming calls with more than one choice.
+func (d *ChoiceDemux) Wait() (openai.CompletionUsage, error) {
+	<-d.done
+	return d.usage, d.err
+}
+
+// Close closes the source stream when it implements io.Closer.
+func (d *ChoiceDemux) Close() error {
+	if closer, ok := d.source.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// ChoiceStream delivers the deltas of a single choice. It implements
+// ChunkStream[openai.ChatCompletionChunkChoice], so it can be wrapped in a Pacer
+// using ChoiceText.
+type ChoiceStream struct {
+	index int
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	pending []openai.ChatCompletionChunkChoice
+	done    bool
+	err     error
+
+	current      openai.ChatCompletionChunkChoice
+	content      strings.Builder
+	finishReason string
+}
+
+func newChoiceStream(index int) *ChoiceStream {
+	s := &ChoiceStream{index: index}
+	s.cond = sync.NewCond(&s.mu)
+	return s
+}
+
+func (s *ChoiceStream) push(choice openai.ChatCompletionChunkChoice) {
+	s.mu.Lock()
+	s.pending = append(s.pending, choice)
+	s.mu.Unlock()
+	s.cond.Signal()
+}
+
+func (s *ChoiceStream) finish(err error) {
+	s.mu.Lock()
+	s.done = true
+	s.err = err
+	s.mu.Unlock()
+	s.cond.Broadcast()
+}
+
+// Next blocks until the next delta for this choice is available. It returns false
+// once the source stream has ended and all deltas for the choice have been read.
+func (s *ChoiceStream) Next() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for len(s.pending) == 0 && !s.done {
+		s.cond.Wait()
+	}
+	if len(s.pending) == 0 {
+		return false
+	}
+
+	s.current = s.pending[0]
+	s.pending = s.pending[1:]
+	s.content.WriteString(s.current.Delta.Content)
+	if s.current.FinishReason != "" {
+		s.finishReason = s.current.FinishReason
+	}
+	return true
+}
+
+// Current returns the delta read by the most recent call to Next.
+func (s *ChoiceStream) Current() openai.ChatCompletionChunkChoice {
+	return s.current
+}
+
+// Text returns the content of the delta read by the most recent call to Next.
+func (s *ChoiceStream) Text() string {
+	return s.current.Delta.Content
+}
+
+// Content returns all content read from this choice so far.
+func (s *ChoiceStream) Content() string {
+	return s.content.String()
+}
+
+// FinishReason returns the finish reason reported for this choice, or "" if the
+// choice has not finished.
+func (s *ChoiceStream) FinishReason() string {
+	return s.finishReason
+}
+
+// Index returns the choice index this stream carries.
+func (s *ChoiceStream) Index() int {
+	return s.index
+}
+
+// Err returns the error that ended the source stream, if any. It is shared by all
+// choices of the demultiplexer.
+func (s *ChoiceStream) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// ChoiceText returns the content delta of a single streamed choice. Use it with
+// NewPacer to pace a ChoiceStream.
+func ChoiceText(choice openai.ChatCompletionChunkChoice) string {
+	return choice.Delta.Content
+}
@@ -0,0 +1,116 @@
+package streaming
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/openai/openai-go/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// chunkStream is a ChunkStream over fixed OpenAI chunks.
+type chunkStream struct {
+	chunks []openai.ChatCompletionChunk
+	err    error
+	pos    int
+}
+
+func (s *chunkStream) Next() bool {
+	if s.pos >= len(s.chunks) {
+		return false
+	}
+	s.pos++
+	return true
+}
+
+func (s *chunkStream) Current() openai.ChatCompletionChunk { return s.chunks[s.pos-1] }
+func (s *chunkStream) Err() error                          { return s.err }
+
+func choiceChunk(index int64, content, finishReason string) openai.ChatCompletionChunk {
+	return openai.ChatCompletionChunk{
+		Choices: []openai.ChatCompletionChunkChoice{{
+			Index:        index,
+			Delta:        openai.ChatCompletionChunkChoiceDelta{Content: content},
+			FinishReason: finishReason,
+		}},
+	}
+}
+
+func TestChoiceDemux_SplitsChoices(t *testing.T) {
+	source := &chunkStream{chunks: []openai.ChatCompletionChunk{
+		choiceChunk(0, "Go", ""),
+		choiceChunk(1, "Lint", ""),
+		choiceChunk(0, "pher", "stop"),
+		choiceChunk(1, "ster", ""),
+		choiceChunk(1, "!", "length"),
+		{Usage: openai.CompletionUsage{PromptTokens: 10, CompletionTokens: 6, TotalTokens: 16}},
+	}}
+
+	demux, err := NewChoiceDemux(source, 2)
+	require.NoError(t, err)
+	require.Equal(t, 2, demux.Len())
+
+	results := make([]string, demux.Len())
+	var wg sync.WaitGroup
+	for i := 0; i < demux.Len(); i++ {
+		wg.Add(1)
+		go func(choice *ChoiceStream) {
+			defer wg.Done()
+			var text string
+			for choice.Next() {
+				text += choice.Text()
+			}
+			results[choice.Index()] = text
+		}(demux.Choice(i))
+	}
+	wg.Wait()
+
+	assert.Equal(t, []string{"Gopher", "Lintster!"}, results)
+	assert.Equal(t, "Gopher", demux.Choice(0).Content())
+	assert.Equal(t, "stop", demux.Choice(0).FinishReason())
+	assert.Equal(t, "length", demux.Choice(1).FinishReason())
+
+	usage, err := demux.Wait()
+	require.NoError(t, err)
+	assert.Equal(t, int64(16), usage.TotalTokens)
+}
+
+func TestChoiceDemux_SourceErrorReachesEveryChoice(t *testing.T) {
+	streamErr := errors.New("stream interrupted")
+	source := &chunkStream{chunks: []openai.ChatCompletionChunk{choiceChunk(0, "partial", "")}, err: streamErr}
+
+	demux, err := NewChoiceDemux(source, 2)
+	require.NoError(t, err)
+
+	_, err = demux.Wait()
+	assert.ErrorIs(t, err, streamErr)
+
+	first := demux.Choice(0)
+	require.True(t, first.Next())
+	assert.Equal(t, "partial", first.Text())
+	assert.False(t, first.Next())
+	assert.ErrorIs(t, first.Err(), streamErr)
+
+	assert.False(t, demux.Choice(1).Next())
+	assert.ErrorIs(t, demux.Choice(1).Err(), streamErr)
+}
+
+func TestChoiceDemux_UnexpectedChoiceIndex(t *testing.T) {
+	source := &chunkStream{chunks: []openai.ChatCompletionChunk{choiceChunk(0, "a", ""), choiceChunk(2, "b", "")}}
+
+	demux, err := NewChoiceDemux(source, 2)
+	require.NoError(t, err)
+
+	_, err = demux.Wait()
+	assert.ErrorIs(t, err, ErrUnexpectedChoice)
+}
+
+func TestNewChoiceDemux_Validation(t *testing.T) {
+	_, err := NewChoiceDemux(nil, 2)
+	assert.Error(t, err)
+
+	_, err = NewChoiceDemux(&chunkStream{}, 0)
+	assert.Error(t, err)
+}
@@ -0,0 +1,243 @@
+package streaming
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sync"
+	"time"
+)
+
+// ErrInvalidPaceOptions is returned by NewPacer when PaceOptions are out of range
+var ErrInvalidPaceOptions = errors.New("invalid pace options")
+
+// defaultPaceTick is the interval between paced emissions when PaceOptions.Tick is zero.
+const defaultPaceTick = 50 * time.Millisecond
+
+// PaceOptions configures a Pacer.
+type PaceOptions struct {
+	// CharsPerSecond is the display rate. Required; must be greater than zero.
+	CharsPerSecond float64
+
+	// Tick is the interval between emitted pieces of text (default: 50ms).
+	Tick time.Duration
+
+	// MaxLag bounds how far display may fall behind the raw stream. When the buffered
+	// text would take longer than MaxLag to display at CharsPerSecond, the excess is
+	// emitted immediately. Zero means no bound.
+	MaxLag time.Duration
+}
+
+// StreamMetrics records the timing of the raw stream, independent of pacing.
+type StreamMetrics struct {
+	Chunks           int           // Chunks received from the source stream
+	Characters       int           // Characters (runes) of text received
+	TimeToFirstChunk time.Duration // Time from pacer creation to the first chunk
+	MaxChunkGap      time.Duration // Longest interval between consecutive chunks
+	Duration         time.Duration // Time from pacer creation until the source ended (zero while running)
+}
+
+// Pacer smooths bursty stream delivery to a steady characters-per-second rate for
+// display. The source stream is read in the background as fast as it produces chunks,
+// so Metrics reflects the provider's real timing while Next releases text at the
+// configured pace.
+//
+// Example:
+//
+//	stream, err := client.CallWithPromptStream(ctx, "Tell me a story")
+//	if err != nil {
+//		return err
+//	}
+//	pacer, err := streaming.NewPacer(ctx, stream, streaming.ChatChunkText, streaming.PaceOptions{CharsPerSecond: 80})
+//	if err != nil {
+//		return err
+//	}
+//	defer pacer.Close()
+//
+//	for pacer.Next() {
+//		fmt.Print(pacer.Current())
+//	}
+//	if err := pacer.Err(); err != nil {
+//		return err
+//	}
+type Pacer[T any] struct {
+	ctx     context.Context
+	source  ChunkStream[T]
+	text    func(T) string
+	opts    PaceOptions
+	perTick int
+	maxBuf  int
+
+	mu          sync.Mutex
+	buf         []rune
+	done        bool
+	err         error
+	metrics     StreamMetrics
+	start       time.Time
+	lastArrival time.Time
+	notify      chan struct{}
+
+	current  string
+	lastEmit time.Time
+}
+
+// NewPacer starts reading source in the background and returns a Pacer that releases
+// its text at opts.CharsPerSecond. text extracts the displayable text from a chunk.
+func NewPacer[T any](ctx context.Context, source ChunkStream[T], text func(T) string, opts PaceOptions) (*Pacer[T], error) {
+	if source == nil || text == nil {
+		return nil, errors.New("source stream and text function are required")
+	}
+	if opts.CharsPerSecond <= 0 || math.IsInf(opts.CharsPerSecond, 0) || math.IsNaN(opts.CharsPerSecond) {
+		return nil, fmt.Errorf("%w: CharsPerSecond must be greater than zero", ErrInvalidPaceOptions)
+	}
+	if opts.Tick < 0 || opts.MaxLag < 0 {
+		return nil, fmt.Errorf("%w: Tick and MaxLag must not be negative", ErrInvalidPaceOptions)
+	}
+	if opts.Tick == 0 {
+		opts.Tick = defaultPaceTick
+	}
+
+	p := &Pacer[T]{
+		ctx:     ctx,
+		source:  source,
+		text:    text,
+		opts:    opts,
+		perTick: max(1, int(math.Round(opts.CharsPerSecond*opts.Tick.Seconds()))),
+		start:   time.Now(),
+		notify:  make(chan struct{}, 1),
+	}
+	if opts.MaxLag > 0 {
+		p.maxBuf = max(1, int(opts.CharsPerSecond*opts.MaxLag.Seconds()))
+	}
+
+	go p.read()
+	return p, nil
+}
+
+// read drains the source stream, recording raw timing as chunks arrive.
+func (p *Pacer[T]) read() {
+	for p.source.Next() {
+		chunk := []rune(p.text(p.source.Current()))
+		now := time.Now()
+
+		p.mu.Lock()
+		if p.metrics.Chunks == 0 {
+			p.metrics.TimeToFirstChunk = now.Sub(p.start)
+		} else if gap := now.Sub(p.lastArrival); gap > p.metrics.MaxChunkGap {
+			p.metrics.MaxChunkGap = gap
+		}
+		p.lastArrival = now
+		p.metrics.Chunks++
+		p.metrics.Characters += len(chunk)
+		p.buf = append(p.buf, chunk...)
+		p.mu.Unlock()
+
+		p.signal()
+	}
+
+	p.mu.Lock()
+	p.done = true
+	if p.err == nil {
+		p.err = p.source.Err()
+	}
+	p.metrics.Duration = time.Since(p.start)
+	p.mu.Unlock()
+
+	p.signal()
+}
+
+func (p *Pacer[T]) signal() {
+	select {
+	case p.notify <- struct{}{}:
+	default:
+	}
+}
+
+// Next waits until the next piece of text is due and reports whether one is available.
+// It returns false once the source has ended and all buffered text has been released,
+// or when the context is cancelled.
+func (p *Pacer[T]) Next() bool {
+	for {
+		p.mu.Lock()
+		buffered, done := len(p.buf), p.done
+		p.mu.Unlock()
+
+		if buffered == 0 {
+			p.current = ""
+			if done {
+				return false
+			}
+			select {
+			case <-p.notify:
+				continue
+			case <-p.ctx.Done():
+				p.fail(p.ctx.Err())
+				return false
+			}
+		}
+
+		if !p.lastEmit.IsZero() {
+			if wait := p.opts.Tick - time.Since(p.lastEmit); wait > 0 {
+				timer := time.NewTimer(wait)
+				select {
+				case <-timer.C:
+				case <-p.ctx.Done():
+					timer.Stop()
+					p.current = ""
+					p.fail(p.ctx.Err())
+					return false
+				}
+			}
+		}
+
+		p.mu.Lock()
+		take := p.perTick
+		if p.maxBuf > 0 && len(p.buf)-take > p.maxBuf {
+			take = len(p.buf) - p.maxBuf
+		}
+		take = min(take, len(p.buf))
+		p.current = string(p.buf[:take])
+		p.buf = p.buf[take:]
+		p.mu.Unlock()
+
+		p.lastEmit = time.Now()
+		return true
+	}
+}
+
+// Current returns the text released by the most recent call to Next.
+func (p *Pacer[T]) Current() string {
+	return p.current
+}
+
+// Err returns the error that ended the stream, if any.
+func (p *Pacer[T]) Err() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.err
+}
+
+// Metrics returns the raw stream timing recorded so far.
+func (p *Pacer[T]) Metrics() StreamMetrics {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.metrics
+}
+
+// Close closes the source stream when it implements io.Closer.
+func (p *Pacer[T]) Close() error {
+	if closer, ok := p.source.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+func (p *Pacer[T]) fail(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err == nil {
+		p.err = err
+	}
+}
@@ -0,0 +1,146 @@
+package streaming
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sliceStream is a ChunkStream that yields fixed chunks, optionally pausing between them.
+type sliceStream struct {
+	chunks []string
+	delay  time.Duration
+	err    error
+	pos    int
+	closed bool
+}
+
+func (s *sliceStream) Next() bool {
+	if s.pos >= len(s.chunks) {
+		return false
+	}
+	if s.delay > 0 && s.pos > 0 {
+		time.Sleep(s.delay)
+	}
+	s.pos++
+	return true
+}
+
+func (s *sliceStream) Current() string { return s.chunks[s.pos-1] }
+func (s *sliceStream) Err() error      { return s.err }
+func (s *sliceStream) Close() error {
+	s.closed = true
+	return nil
+}
+
+func identity(s string) string { return s }
+
+func drain(t *testing.T, p *Pacer[string]) []string {
+	t.Helper()
+	var pieces []string
+	for p.Next() {
+		pieces = append(pieces, p.Current())
+	}
+	return pieces
+}
+
+func TestPacer_DeliversAllTextAtPace(t *testing.T) {
+	source := &sliceStream{chunks: []string{"Hello, ", "wörld", "", "! This arrives in one burst."}}
+	pacer, err := NewPacer(context.Background(), source, identity, PaceOptions{CharsPerSecond: 1000, Tick: 2 * time.Millisecond})
+	require.NoError(t, err)
+
+	start := time.Now()
+	pieces := drain(t, pacer)
+	elapsed := time.Since(start)
+
+	require.NoError(t, pacer.Err())
+	assert.Equal(t, strings.Join(source.chunks, ""), strings.Join(pieces, ""))
+	for _, piece := range pieces {
+		assert.LessOrEqual(t, len([]rune(piece)), 2)
+	}
+	// 40 characters at 2 per tick is 20 emissions, 19 of which wait a full tick
+	assert.GreaterOrEqual(t, elapsed, 19*2*time.Millisecond)
+
+	metrics := pacer.Metrics()
+	assert.Equal(t, 4, metrics.Chunks)
+	assert.Equal(t, 40, metrics.Characters)
+	assert.Less(t, metrics.Duration, elapsed)
+}
+
+func TestPacer_MaxLagBoundsBuffering(t *testing.T) {
+	source := &sliceStream{chunks: []string{strings.Repeat("x", 100)}}
+	pacer, err := NewPacer(context.Background(), source, identity, PaceOptions{CharsPerSecond: 1000, Tick: time.Millisecond, MaxLag: 10 * time.Millisecond})
+	require.NoError(t, err)
+
+	// Wait for the background reader so the whole chunk is buffered
+	require.Eventually(t, func() bool { return pacer.Metrics().Chunks == 1 }, time.Second, time.Millisecond)
+
+	require.True(t, pacer.Next())
+	assert.Len(t, pacer.Current(), 90, "excess beyond MaxLag is released immediately")
+
+	pieces := drain(t, pacer)
+	assert.Equal(t, 10, len(strings.Join(pieces, "")))
+}
+
+func TestPacer_RecordsRawTiming(t *testing.T) {
+	source := &sliceStream{chunks: []string{"a", "b", "c"}, delay: 5 * time.Millisecond}
+	pacer, err := NewPacer(context.Background(), source, identity, PaceOptions{CharsPerSecond: 1000})
+	require.NoError(t, err)
+
+	assert.Equal(t, "abc", strings.Join(drain(t, pacer), ""))
+
+	metrics := pacer.Metrics()
+	assert.Equal(t, 3, metrics.Chunks)
+	assert.GreaterOrEqual(t, metrics.MaxChunkGap, 5*time.Millisecond)
+	assert.GreaterOrEqual(t, metrics.Duration, 10*time.Millisecond)
+}
+
+func TestPacer_SourceError(t *testing.T) {
+	streamErr := errors.New("connection reset")
+	source := &sliceStream{chunks: []string{"partial"}, err: streamErr}
+	pacer, err := NewPacer(context.Background(), source, identity, PaceOptions{CharsPerSecond: 10000, Tick: time.Millisecond})
+	require.NoError(t, err)
+
+	assert.Equal(t, "partial", strings.Join(drain(t, pacer), ""))
+	assert.ErrorIs(t, pacer.Err(), streamErr)
+}
+
+func TestPacer_ContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	source := &sliceStream{chunks: []string{strings.Repeat("x", 50)}}
+	pacer, err := NewPacer(ctx, source, identity, PaceOptions{CharsPerSecond: 10, Tick: time.Second})
+	require.NoError(t, err)
+
+	require.True(t, pacer.Next())
+	cancel()
+
+	assert.False(t, pacer.Next())
+	assert.ErrorIs(t, pacer.Err(), context.Canceled)
+
+	require.NoError(t, pacer.Close())
+	assert.True(t, source.closed)
+}
+
+func TestNewPacer_InvalidOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		opts PaceOptions
+	}{
+		{name: "zero rate", opts: PaceOptions{}},
+		{name: "negative rate", opts: PaceOptions{CharsPerSecond: -1}},
+		{name: "negative tick", opts: PaceOptions{CharsPerSecond: 10, Tick: -time.Millisecond}},
+		{name: "negative max lag", opts: PaceOptions{CharsPerSecond: 10, MaxLag: -time.Millisecond}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewPacer(context.Background(), &sliceStream{}, identity, tt.opts)
+			assert.ErrorIs(t, err, ErrInvalidPaceOptions)
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package streaming
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/kengibson1111/go-aiprovider/types"
+)
+
+// defaultSSEHeartbeat is the interval between heartbeat comments when SSEOptions.Heartbeat is zero.
+const defaultSSEHeartbeat = 15 * time.Second
+
+// SSEOptions configures ServeSSE.
+type SSEOptions struct {
+	// Heartbeat is the interval between comment lines sent to keep proxies and load
+	// balancers from closing a quiet connection (default: 15s). Negative disables heartbeats.
+	Heartbeat time.Duration
+
+	// Event, if set, is the event name for chunk events. Empty sends unnamed events, as
+	// the OpenAI API does.
+	Event string
+
+	// OmitDone suppresses the final "data: [DONE]" event.
+	OmitDone bool
+}
+
+// sseItem is one chunk read from the source stream, or its end.
+type sseItem[T any] struct {
+	chunk T
+	done  bool
+	err   error
+}
+
+// ServeSSE copies a provider stream onto w as server-sent events, flushing after each
+// event, so a service can expose its own streaming endpoint. encode converts each chunk
+// to the event data: strings are sent as is and other values as JSON. A nil encode sends
+// the chunk itself as JSON.
+//
+// The stream ends with "data: [DONE]" on success. If the source fails, an "error" event
+// with {"error": {"code": ..., "message": ...}} is sent and the error is returned. ServeSSE
+// stops when ctx is cancelled (for example when the client disconnects) and closes the
+// source if it implements io.Closer.
+//
+// Example:
+//
+//	http.HandleFunc("/chat", func(w http.ResponseWriter, r *http.Request) {
+//		stream, err := openaiClient.CallWithPromptStream(r.Context(), r.FormValue("q"))
+//		if err != nil {
+//			http.Error(w, err.Error(), http.StatusBadGateway)
+//			return
+//		}
+//		_ = streaming.ServeSSE(r.Context(), w, stream, func(c openai.ChatCompletionChunk) any {
+//			return map[string]string{"text": streaming.ChatChunkText(c)}
+//		}, streaming.SSEOptions{})
+//	})
+func ServeSSE[T any](ctx context.Context, w http.ResponseWriter, source ChunkStream[T], encode func(T) any, opts SSEOptions) error {
+	if source == nil {
+		return errors.New("source stream is required")
+	}
+	if closer, ok := source.(io.Closer); ok {
+		defer closer.Close()
+	}
+	heartbeat := opts.Heartbeat
+	if heartbeat == 0 {
+		heartbeat = defaultSSEHeartbeat
+	}
+
+	header := w.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	rc := http.NewResponseController(w)
+	send := func(event, data string) error {
+		var b strings.Builder
+		if event != "" {
+			fmt.Fprintf(&b, "event: %s\n", event)
+		}
+		for _, line := range strings.Split(data, "\n") {
+			fmt.Fprintf(&b, "data: %s\n", line)
+		}
+		b.WriteString("\n")
+		return writeSSE(w, rc, b.String())
+	}
+
+	items := make(chan sseItem[T])
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		for source.Next() {
+			select {
+			case items <- sseItem[T]{chunk: source.Current()}:
+			case <-stop:
+				return
+			}
+		}
+		select {
+		case items <- sseItem[T]{done: true, err: source.Err()}:
+		case <-stop:
+		}
+	}()
+
+	var ticks <-chan time.Time
+	if heartbeat > 0 {
+		ticker := time.NewTicker(heartbeat)
+		defer ticker.Stop()
+		ticks = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
+		case <-ticks:
+			if err := writeSSE(w, rc, ": heartbeat\n\n"); err != nil {
+				return err
+			}
+
+		case item := <-items:
+			if item.done {
+				if item.err != nil {
+					if err := send("error", sseErrorData(item.err)); err != nil {
+						return err
+					}
+					return item.err
+				}
+				if opts.OmitDone {
+					return nil
+				}
+				return send("", "[DONE]")
+			}
+
+			var value any = item.chunk
+			if encode != nil {
+				value = encode(item.chunk)
+			}
+			data, err := sseData(value, encode != nil)
+			if err != nil {
+				return err
+			}
+			if err := send(opts.Event, data); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// writeSSE writes text and flushes it to the client.
+func writeSSE(w http.ResponseWriter, rc *http.ResponseController, text string) error {
+	if _, err := io.WriteString(w, text); err != nil {
+		return err
+	}
+	if err := rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+	return nil
+}
+
+// sseData converts a chunk, or the value encode returned for it, to event data. Strings
+// from encode are sent as is.
+func sseData(value any, encoded bool) (string, error) {
+	if s, ok := value.(string); ok && encoded {
+		return s, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode stream chunk: %w", err)
+	}
+	return string(data), nil
+}
+
+// sseErrorData builds the data of an error event, using the code of a types.ErrorResponse
+// when the error carries one.
+func sseErrorData(err error) string {
+	code := "stream_error"
+	var errResp *types.ErrorResponse
+	if errors.As(err, &errResp) && errResp.Code != "" {
+		code = errResp.Code
+	}
+	data, _ := json.Marshal(map[string]any{
+		"error": map[string]string{"code": code, "message": err.Error()},
+	})
+	return string(data)
+}
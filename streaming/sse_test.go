@@ -0,0 +1,69 @@
+package streaming
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kengibson1111/go-aiprovider/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServeSSE_Chunks(t *testing.T) {
+	source := &sliceStream{chunks: []string{"Hello", "two\nlines"}}
+	w := httptest.NewRecorder()
+
+	err := ServeSSE(context.Background(), w, source, nil, SSEOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+	assert.Equal(t, "no-cache", w.Header().Get("Cache-Control"))
+	assert.True(t, w.Flushed)
+	assert.True(t, source.closed)
+	assert.Equal(t, "data: \"Hello\"\n\ndata: \"two\\nlines\"\n\ndata: [DONE]\n\n", w.Body.String())
+}
+
+func TestServeSSE_EncodeAndEventName(t *testing.T) {
+	source := &sliceStream{chunks: []string{"a", "b\nc"}}
+	w := httptest.NewRecorder()
+
+	err := ServeSSE(context.Background(), w, source, func(s string) any { return s }, SSEOptions{Event: "delta", OmitDone: true})
+	require.NoError(t, err)
+	assert.Equal(t, "event: delta\ndata: a\n\nevent: delta\ndata: b\ndata: c\n\n", w.Body.String())
+}
+
+func TestServeSSE_ErrorEvent(t *testing.T) {
+	streamErr := &types.ErrorResponse{Code: "rate_limit_exceeded", Message: "slow down"}
+	source := &sliceStream{chunks: []string{"partial"}, err: streamErr}
+	w := httptest.NewRecorder()
+
+	err := ServeSSE(context.Background(), w, source, func(s string) any { return map[string]string{"text": s} }, SSEOptions{})
+	assert.ErrorIs(t, err, streamErr)
+	assert.Equal(t, "data: {\"text\":\"partial\"}\n\n"+
+		"event: error\ndata: {\"error\":{\"code\":\"rate_limit_exceeded\",\"message\":\"rate_limit_exceeded: slow down\"}}\n\n", w.Body.String())
+
+	w = httptest.NewRecorder()
+	err = ServeSSE(context.Background(), w, &sliceStream{err: errors.New("boom")}, nil, SSEOptions{})
+	assert.EqualError(t, err, "boom")
+	assert.Contains(t, w.Body.String(), `"code":"stream_error"`)
+}
+
+func TestServeSSE_HeartbeatAndCancel(t *testing.T) {
+	source := &sliceStream{chunks: []string{"first", "late"}, delay: time.Second}
+	w := httptest.NewRecorder()
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	err := ServeSSE(ctx, w, source, nil, SSEOptions{Heartbeat: 20 * time.Millisecond})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	body := w.Body.String()
+	assert.True(t, strings.HasPrefix(body, "data: \"first\"\n\n"), body)
+	assert.Contains(t, body, ": heartbeat\n\n")
+	assert.NotContains(t, body, "late")
+	assert.NotContains(t, body, "[DONE]")
+}
@@ -0,0 +1,24 @@
+// Package streaming provides helpers for consuming streaming completions, such as
+// the stream returned by the OpenAI client's CallWithPromptStream.
+package streaming
+
+import (
+	"github.com/openai/openai-go/v2"
+)
+
+// ChunkStream is the iterator shape shared by provider streams. It is satisfied by
+// *ssestream.Stream[openai.ChatCompletionChunk] from the OpenAI SDK.
+type ChunkStream[T any] interface {
+	Next() bool
+	Current() T
+	Err() error
+}
+
+// ChatChunkText returns the content delta of the first choice in an OpenAI chunk,
+// or "" when the chunk carries no content.
+func ChatChunkText(chunk openai.ChatCompletionChunk) string {
+	if len(chunk.Choices) == 0 {
+		return ""
+	}
+	return chunk.Choices[0].Delta.Content
+}
@@ -0,0 +1,127 @@
+package types
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/kengibson1111/go-aiprovider/internal/shared/env"
+)
+
+// envVarNames lists the environment variables read for a provider by ConfigFromEnv.
+// An empty name means the provider has no such setting in the environment.
+type envVarNames struct {
+	apiKey      string
+	model       string
+	endpoint    string
+	maxTokens   string
+	temperature string
+}
+
+// providerEnvVars maps each provider to its environment variables. The names match
+// .env.sample, so a .env written for the examples and integration tests works unchanged.
+var providerEnvVars = map[string]envVarNames{
+	ProviderClaude: {
+		apiKey:      "CLAUDE_API_KEY",
+		model:       "CLAUDE_MODEL",
+		endpoint:    "CLAUDE_API_ENDPOINT",
+		maxTokens:   "CLAUDE_MAX_TOKENS",
+		temperature: "CLAUDE_TEMPERATURE",
+	},
+	ProviderClaudeBedrock: {
+		model:       "CLAUDE_BEDROCK_MODEL",
+		endpoint:    "CLAUDE_BEDROCK_ENDPOINT",
+		maxTokens:   "CLAUDE_BEDROCK_MAX_TOKENS",
+		temperature: "CLAUDE_BEDROCK_TEMPERATURE",
+	},
+	ProviderOpenAI: {
+		apiKey:      "OPENAI_API_KEY",
+		model:       "OPENAI_MODEL",
+		endpoint:    "OPENAI_API_ENDPOINT",
+		maxTokens:   "OPENAI_MAX_TOKENS",
+		temperature: "OPENAI_TEMPERATURE",
+	},
+	ProviderOpenAIAzure: {
+		model:       "OPENAI_AZURE_MODEL",
+		endpoint:    "OPENAI_AZURE_ENDPOINT",
+		maxTokens:   "OPENAI_AZURE_MAX_TOKENS",
+		temperature: "OPENAI_AZURE_TEMPERATURE",
+	},
+	ProviderOpenAIAzureUP: {
+		model:       "OPENAI_AZURE_MODEL",
+		endpoint:    "OPENAI_AZURE_ENDPOINT",
+		maxTokens:   "OPENAI_AZURE_MAX_TOKENS",
+		temperature: "OPENAI_AZURE_TEMPERATURE",
+	},
+}
+
+// ConfigFromEnv builds an AIConfig for provider from environment variables, loading a .env
+// file from the current directory first if one exists. Variables already set in the process
+// environment take precedence over the .env file.
+//
+// Variables read per provider (all optional; unset values are left at their zero value so
+// the client applies its defaults):
+//
+//   - claude: CLAUDE_API_KEY, CLAUDE_MODEL, CLAUDE_API_ENDPOINT, CLAUDE_MAX_TOKENS, CLAUDE_TEMPERATURE
+//   - claude-bedrock: CLAUDE_BEDROCK_MODEL, CLAUDE_BEDROCK_ENDPOINT, CLAUDE_BEDROCK_MAX_TOKENS,
+//     CLAUDE_BEDROCK_TEMPERATURE
+//   - openai: OPENAI_API_KEY, OPENAI_MODEL, OPENAI_API_ENDPOINT, OPENAI_MAX_TOKENS, OPENAI_TEMPERATURE
+//   - openai-azure, openai-azure-up: OPENAI_AZURE_MODEL, OPENAI_AZURE_ENDPOINT,
+//     OPENAI_AZURE_MAX_TOKENS, OPENAI_AZURE_TEMPERATURE
+//
+// Credentials for Bedrock and Azure (CLAUDE_BEDROCK_REGION, OPENAI_AZURE_TENANT_ID, etc.)
+// are still read by the client constructors. The returned config is not validated; pass it
+// to ClientFactory.CreateClient or call Validate.
+//
+// Example:
+//
+//	config, err := types.ConfigFromEnv(types.ProviderOpenAI)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	aiClient, err := client.NewClientFactory().CreateClient(config)
+func ConfigFromEnv(provider string) (*AIConfig, error) {
+	provider = strings.ToLower(strings.TrimSpace(provider))
+	names, ok := providerEnvVars[provider]
+	if !ok {
+		return nil, fmt.Errorf("unsupported provider: %q (one of %s)", provider, providerList())
+	}
+
+	if err := env.LoadEnvConfig(); err != nil {
+		return nil, fmt.Errorf("failed to load .env file: %w", err)
+	}
+
+	config := &AIConfig{
+		Provider: provider,
+		APIKey:   lookupEnv(names.apiKey),
+		Model:    lookupEnv(names.model),
+		BaseURL:  lookupEnv(names.endpoint),
+	}
+
+	if value := lookupEnv(names.maxTokens); value != "" {
+		maxTokens, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be an integer, got %q", names.maxTokens, value)
+		}
+		config.MaxTokens = maxTokens
+	}
+
+	if value := lookupEnv(names.temperature); value != "" {
+		temperature, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be a number, got %q", names.temperature, value)
+		}
+		config.Temperature = temperature
+	}
+
+	return config, nil
+}
+
+// lookupEnv returns the trimmed value of the named variable, or "" when name is empty or unset.
+func lookupEnv(name string) string {
+	if name == "" {
+		return ""
+	}
+	return strings.TrimSpace(os.Getenv(name))
+}
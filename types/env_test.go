@@ -0,0 +1,43 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Chdir(t.TempDir()) // no .env file
+	t.Setenv("OPENAI_API_KEY", " sk-test ")
+	t.Setenv("OPENAI_MODEL", "gpt-4o-mini")
+	t.Setenv("OPENAI_API_ENDPOINT", "")
+	t.Setenv("OPENAI_MAX_TOKENS", "512")
+	t.Setenv("OPENAI_TEMPERATURE", "0.2")
+
+	config, err := ConfigFromEnv("OpenAI")
+	require.NoError(t, err)
+	assert.Equal(t, &AIConfig{
+		Provider:    ProviderOpenAI,
+		APIKey:      "sk-test",
+		Model:       "gpt-4o-mini",
+		MaxTokens:   512,
+		Temperature: 0.2,
+	}, config)
+}
+
+func TestConfigFromEnv_Errors(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	_, err := ConfigFromEnv("gemini")
+	assert.ErrorContains(t, err, "unsupported provider")
+
+	t.Setenv("CLAUDE_MAX_TOKENS", "lots")
+	_, err = ConfigFromEnv(ProviderClaude)
+	assert.ErrorContains(t, err, "CLAUDE_MAX_TOKENS must be an integer")
+
+	t.Setenv("CLAUDE_MAX_TOKENS", "")
+	t.Setenv("CLAUDE_TEMPERATURE", "warm")
+	_, err = ConfigFromEnv(ProviderClaude)
+	assert.ErrorContains(t, err, "CLAUDE_TEMPERATURE must be a number")
+}
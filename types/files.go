@@ -0,0 +1,33 @@
+package types
+
+import "time"
+
+// FileInfo describes a file uploaded to a provider.
+type FileInfo struct {
+	ID        string    `json:"id"`
+	Filename  string    `json:"filename"`
+	Bytes     int64     `json:"bytes"`
+	Purpose   string    `json:"purpose"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// VectorStoreInfo describes a provider-hosted vector store that indexes uploaded files
+// for retrieval.
+type VectorStoreInfo struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Status string `json:"status"`
+
+	// FileCount is the number of files in the store, and FilesInProgress how many of
+	// them are still being indexed.
+	FileCount       int `json:"fileCount"`
+	FilesInProgress int `json:"filesInProgress"`
+}
+
+// FileSearchResult is a chunk of an uploaded file that matched a vector store search.
+type FileSearchResult struct {
+	FileID   string  `json:"fileId"`
+	Filename string  `json:"filename"`
+	Score    float64 `json:"score"`
+	Text     string  `json:"text"`
+}
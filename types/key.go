@@ -0,0 +1,54 @@
+package types
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// KeyProvider supplies the API key for each request (AIConfig.KeyProvider), so a key
+// rotated in a secret manager is picked up without recreating clients. GetKey is called
+// before every request; implementations that fetch keys over the network should cache
+// them.
+type KeyProvider interface {
+	GetKey(ctx context.Context) (string, error)
+}
+
+// KeyProviderFunc adapts a function to a KeyProvider, for example to read keys from
+// Vault or AWS Secrets Manager.
+type KeyProviderFunc func(ctx context.Context) (string, error)
+
+// GetKey calls f.
+func (f KeyProviderFunc) GetKey(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+// EnvKey returns a KeyProvider that reads the API key from the named environment
+// variable on every request.
+func EnvKey(name string) KeyProvider {
+	return KeyProviderFunc(func(ctx context.Context) (string, error) {
+		key := strings.TrimSpace(os.Getenv(name))
+		if key == "" {
+			return "", fmt.Errorf("environment variable %s is not set", name)
+		}
+		return key, nil
+	})
+}
+
+// FileKey returns a KeyProvider that reads the API key from a file on every request,
+// such as a Kubernetes secret volume that is updated in place. Surrounding whitespace is
+// trimmed.
+func FileKey(path string) KeyProvider {
+	return KeyProviderFunc(func(ctx context.Context) (string, error) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read API key file: %w", err)
+		}
+		key := strings.TrimSpace(string(data))
+		if key == "" {
+			return "", fmt.Errorf("API key file %s is empty", path)
+		}
+		return key, nil
+	})
+}
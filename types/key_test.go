@@ -0,0 +1,50 @@
+package types
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvKey(t *testing.T) {
+	t.Setenv("TEST_ROTATING_KEY", "sk-first")
+	provider := EnvKey("TEST_ROTATING_KEY")
+
+	key, err := provider.GetKey(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "sk-first", key)
+
+	t.Setenv("TEST_ROTATING_KEY", "sk-second")
+	key, err = provider.GetKey(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "sk-second", key)
+
+	_, err = EnvKey("TEST_UNSET_KEY").GetKey(context.Background())
+	assert.ErrorContains(t, err, "TEST_UNSET_KEY is not set")
+}
+
+func TestFileKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api-key")
+	require.NoError(t, os.WriteFile(path, []byte("sk-first\n"), 0o600))
+	provider := FileKey(path)
+
+	key, err := provider.GetKey(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "sk-first", key)
+
+	require.NoError(t, os.WriteFile(path, []byte("sk-second"), 0o600))
+	key, err = provider.GetKey(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "sk-second", key)
+
+	require.NoError(t, os.WriteFile(path, []byte(" \n"), 0o600))
+	_, err = provider.GetKey(context.Background())
+	assert.ErrorContains(t, err, "is empty")
+
+	_, err = FileKey(filepath.Join(t.TempDir(), "missing")).GetKey(context.Background())
+	assert.ErrorContains(t, err, "failed to read API key file")
+}
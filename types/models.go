@@ -0,0 +1,93 @@
+package types
+
+import (
+	"sort"
+	"strings"
+)
+
+// ModelInfo describes a model available from a provider.
+type ModelInfo struct {
+	// ID is the model identifier to use as AIConfig.Model.
+	ID string `json:"id"`
+
+	// DisplayName is the provider's human-readable name, when it has one.
+	DisplayName string `json:"displayName,omitempty"`
+
+	// OwnedBy is the organization that owns the model, when the provider reports it.
+	OwnedBy string `json:"ownedBy,omitempty"`
+
+	// Capabilities are looked up from the built-in capability matrix. Known is false
+	// for models the matrix doesn't cover.
+	Capabilities ModelCapabilities `json:"capabilities"`
+}
+
+// ModelCapabilities describes what a model supports.
+type ModelCapabilities struct {
+	// Known is true when the model matched an entry in the capability matrix.
+	Known bool `json:"known"`
+
+	SupportsTools    bool `json:"supportsTools"`
+	SupportsVision   bool `json:"supportsVision"`
+	MaxContextTokens int  `json:"maxContextTokens"`
+}
+
+// modelCapabilities is the capability matrix, keyed by model ID prefix. Dated snapshots
+// and point releases (e.g. gpt-4o-2024-08-06, gpt-5.4-mini) match the entry for their family.
+var modelCapabilities = map[string]ModelCapabilities{
+	// Anthropic
+	"claude-opus-4":     {SupportsTools: true, SupportsVision: true, MaxContextTokens: 200000},
+	"claude-sonnet-4":   {SupportsTools: true, SupportsVision: true, MaxContextTokens: 200000},
+	"claude-haiku-4":    {SupportsTools: true, SupportsVision: true, MaxContextTokens: 200000},
+	"claude-3-7-sonnet": {SupportsTools: true, SupportsVision: true, MaxContextTokens: 200000},
+	"claude-3-5-sonnet": {SupportsTools: true, SupportsVision: true, MaxContextTokens: 200000},
+	"claude-3-5-haiku":  {SupportsTools: true, SupportsVision: true, MaxContextTokens: 200000},
+	"claude-3-opus":     {SupportsTools: true, SupportsVision: true, MaxContextTokens: 200000},
+	"claude-3-haiku":    {SupportsTools: true, SupportsVision: true, MaxContextTokens: 200000},
+
+	// OpenAI
+	"gpt-5":         {SupportsTools: true, SupportsVision: true, MaxContextTokens: 400000},
+	"gpt-4.1":       {SupportsTools: true, SupportsVision: true, MaxContextTokens: 1047576},
+	"gpt-4o":        {SupportsTools: true, SupportsVision: true, MaxContextTokens: 128000},
+	"gpt-4-turbo":   {SupportsTools: true, SupportsVision: true, MaxContextTokens: 128000},
+	"gpt-4":         {SupportsTools: true, SupportsVision: false, MaxContextTokens: 8192},
+	"gpt-3.5-turbo": {SupportsTools: true, SupportsVision: false, MaxContextTokens: 16385},
+	"o1-mini":       {SupportsTools: false, SupportsVision: false, MaxContextTokens: 128000},
+	"o1":            {SupportsTools: true, SupportsVision: true, MaxContextTokens: 200000},
+	"o3":            {SupportsTools: true, SupportsVision: true, MaxContextTokens: 200000},
+	"o4-mini":       {SupportsTools: true, SupportsVision: true, MaxContextTokens: 200000},
+}
+
+// modelPrefixes holds the matrix keys, longest first, so the most specific entry wins.
+var modelPrefixes = func() []string {
+	prefixes := make([]string, 0, len(modelCapabilities))
+	for prefix := range modelCapabilities {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Slice(prefixes, func(i, j int) bool {
+		if len(prefixes[i]) != len(prefixes[j]) {
+			return len(prefixes[i]) > len(prefixes[j])
+		}
+		return prefixes[i] < prefixes[j]
+	})
+	return prefixes
+}()
+
+// LookupModelCapabilities returns the capabilities of a model from the built-in matrix.
+// Bedrock model and inference profile IDs (e.g. us.anthropic.claude-sonnet-4-20250514-v1:0)
+// are matched by their Claude model name. The result has Known set to false for models
+// the matrix doesn't cover, such as Azure deployment names.
+func LookupModelCapabilities(model string) ModelCapabilities {
+	id := strings.ToLower(strings.TrimSpace(model))
+	if i := strings.Index(id, "anthropic."); i >= 0 {
+		id = id[i+len("anthropic."):]
+	}
+
+	for _, prefix := range modelPrefixes {
+		if id == prefix || strings.HasPrefix(id, prefix+"-") || strings.HasPrefix(id, prefix+".") || strings.HasPrefix(id, prefix+"@") {
+			capabilities := modelCapabilities[prefix]
+			capabilities.Known = true
+			return capabilities
+		}
+	}
+	return ModelCapabilities{}
+}
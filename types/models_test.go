@@ -0,0 +1,30 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLookupModelCapabilities(t *testing.T) {
+	tests := []struct {
+		model    string
+		expected ModelCapabilities
+	}{
+		{model: "gpt-4o-mini", expected: ModelCapabilities{Known: true, SupportsTools: true, SupportsVision: true, MaxContextTokens: 128000}},
+		{model: "gpt-4-turbo-2024-04-09", expected: ModelCapabilities{Known: true, SupportsTools: true, SupportsVision: true, MaxContextTokens: 128000}},
+		{model: "gpt-4", expected: ModelCapabilities{Known: true, SupportsTools: true, MaxContextTokens: 8192}},
+		{model: "gpt-5.4-mini", expected: ModelCapabilities{Known: true, SupportsTools: true, SupportsVision: true, MaxContextTokens: 400000}},
+		{model: "o1-mini-2024-09-12", expected: ModelCapabilities{Known: true, MaxContextTokens: 128000}},
+		{model: "claude-sonnet-4-6", expected: ModelCapabilities{Known: true, SupportsTools: true, SupportsVision: true, MaxContextTokens: 200000}},
+		{model: "us.anthropic.claude-sonnet-4-20250514-v1:0", expected: ModelCapabilities{Known: true, SupportsTools: true, SupportsVision: true, MaxContextTokens: 200000}},
+		{model: "gpt-4ox", expected: ModelCapabilities{}},
+		{model: "my-azure-deployment", expected: ModelCapabilities{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			assert.Equal(t, tt.expected, LookupModelCapabilities(tt.model))
+		})
+	}
+}
@@ -0,0 +1,235 @@
+package types
+
+// DefaultReasoningBudget is the thinking-token budget used when reasoning is enabled
+// without one. It is also the smallest budget Claude accepts.
+const DefaultReasoningBudget = 1024
+
+// CallOptions holds the settings used for a single request. Clients start from the
+// values in their AIConfig and apply any CallOption passed to the call on top.
+type CallOptions struct {
+	// Model is the model (or deployment) name for the request.
+	Model string
+
+	// MaxTokens is the maximum number of tokens to generate.
+	MaxTokens int
+
+	// Temperature controls randomness.
+	Temperature float64
+
+	// SystemPrompt is sent as the system message. Empty means no system message.
+	SystemPrompt string
+
+	// N is the number of choices to generate. Zero or one requests a single choice.
+	// Providers that cannot return multiple choices ignore it.
+	N int
+
+	// Stop lists sequences that end generation. Nil sends none.
+	Stop []string
+
+	// TopP sets nucleus sampling. Nil leaves the provider default.
+	TopP *float64
+
+	// FrequencyPenalty and PresencePenalty discourage repetition. Zero leaves the
+	// provider default.
+	FrequencyPenalty float64
+	PresencePenalty  float64
+
+	// Seed requests deterministic sampling where the provider supports it. Nil sends none.
+	Seed *int64
+
+	// User identifies the end user to the provider for abuse monitoring. Empty sends none.
+	User string
+
+	// ReasoningBudget, when positive, enables extended thinking (Claude) or reasoning
+	// effort (OpenAI) with this many thinking tokens on top of MaxTokens. Zero disables it.
+	ReasoningBudget int
+
+	// Logprobs requests the log probability of each generated token, where the provider
+	// supports it (OpenAI). Claude does not return log probabilities and ignores it.
+	Logprobs bool
+
+	// TopLogprobs, when positive, also requests this many most likely alternatives for
+	// each generated token (OpenAI allows up to 20). It implies Logprobs.
+	TopLogprobs int
+
+	// Prediction is content the response is expected to largely repeat, such as a file
+	// being edited, sent as an OpenAI predicted output so matching tokens are generated
+	// much faster. Empty sends none. Claude ignores it.
+	Prediction string
+
+	// ToolChoice controls whether a call with tools must call one: ToolChoiceAuto,
+	// ToolChoiceNone, ToolChoiceRequired, or the name of the function to call. Empty
+	// leaves the provider default. Calls without tools ignore it.
+	ToolChoice string
+
+	// ParallelToolCalls allows or forbids several tool calls in one response. Nil leaves
+	// the provider default, which allows them.
+	ParallelToolCalls *bool
+
+	// PartialResults makes stream helpers return the content received so far, with the
+	// error, when a stream ends early (for example because the context was cancelled).
+	PartialResults bool
+}
+
+// CallOption overrides a setting for a single call.
+type CallOption func(*CallOptions)
+
+// WithModel sets the model (or deployment) for a single call.
+func WithModel(model string) CallOption {
+	return func(o *CallOptions) {
+		o.Model = model
+	}
+}
+
+// WithMaxTokens sets the maximum number of tokens to generate for a single call.
+func WithMaxTokens(maxTokens int) CallOption {
+	return func(o *CallOptions) {
+		o.MaxTokens = maxTokens
+	}
+}
+
+// WithTemperature sets the sampling temperature for a single call. Unlike
+// AIConfig.Temperature, zero is sent as-is rather than replaced with the default.
+func WithTemperature(temperature float64) CallOption {
+	return func(o *CallOptions) {
+		o.Temperature = temperature
+	}
+}
+
+// WithSystemPrompt sets the system message for a single call, replacing
+// AIConfig.SystemPrompt. Passing an empty string sends no system message.
+func WithSystemPrompt(prompt string) CallOption {
+	return func(o *CallOptions) {
+		o.SystemPrompt = prompt
+	}
+}
+
+// WithN requests n choices for a single call. Streaming calls with n > 1 can be
+// split per choice with streaming.NewChoiceDemux.
+func WithN(n int) CallOption {
+	return func(o *CallOptions) {
+		o.N = n
+	}
+}
+
+// WithStop sets the stop sequences for a single call.
+func WithStop(sequences ...string) CallOption {
+	return func(o *CallOptions) {
+		o.Stop = sequences
+	}
+}
+
+// WithTopP sets nucleus sampling for a single call.
+func WithTopP(topP float64) CallOption {
+	return func(o *CallOptions) {
+		o.TopP = &topP
+	}
+}
+
+// WithFrequencyPenalty sets the frequency penalty for a single call.
+func WithFrequencyPenalty(penalty float64) CallOption {
+	return func(o *CallOptions) {
+		o.FrequencyPenalty = penalty
+	}
+}
+
+// WithPresencePenalty sets the presence penalty for a single call.
+func WithPresencePenalty(penalty float64) CallOption {
+	return func(o *CallOptions) {
+		o.PresencePenalty = penalty
+	}
+}
+
+// WithSeed sets the sampling seed for a single call. Providers without seed support ignore it.
+func WithSeed(seed int64) CallOption {
+	return func(o *CallOptions) {
+		o.Seed = &seed
+	}
+}
+
+// WithUser sets the end-user identifier for a single call.
+func WithUser(user string) CallOption {
+	return func(o *CallOptions) {
+		o.User = user
+	}
+}
+
+// WithReasoning enables extended thinking or reasoning for a single call with the given
+// thinking-token budget. A budget of zero or less uses DefaultReasoningBudget.
+func WithReasoning(budget int) CallOption {
+	return func(o *CallOptions) {
+		if budget <= 0 {
+			budget = DefaultReasoningBudget
+		}
+		o.ReasoningBudget = budget
+	}
+}
+
+// WithLogprobs requests token log probabilities for a single call, so confidence scores
+// can be based on them. Providers without log probabilities ignore it.
+func WithLogprobs() CallOption {
+	return func(o *CallOptions) {
+		o.Logprobs = true
+	}
+}
+
+// WithTopLogprobs requests token log probabilities for a single call, each with the n
+// most likely alternative tokens.
+func WithTopLogprobs(n int) CallOption {
+	return func(o *CallOptions) {
+		o.Logprobs = true
+		o.TopLogprobs = n
+	}
+}
+
+// WithPrediction sends content as a predicted output for a single call. Use it when the
+// response mostly repeats known text, such as asking for a small edit to a large file
+// with the original file as the prediction:
+//
+//	response, err := aiClient.CallWithPrompt(ctx,
+//		"Rename the Username field to Email in this file and return the full file:\n\n"+source,
+//		types.WithPrediction(source))
+//
+// OpenAI supports predictions on GPT-4o and GPT-4.1 models. Providers without predicted
+// outputs ignore it.
+func WithPrediction(content string) CallOption {
+	return func(o *CallOptions) {
+		o.Prediction = content
+	}
+}
+
+// WithToolChoice sets whether a call with tools must call one: types.ToolChoiceAuto,
+// types.ToolChoiceNone, types.ToolChoiceRequired, or the name of the function to call.
+func WithToolChoice(choice string) CallOption {
+	return func(o *CallOptions) {
+		o.ToolChoice = choice
+	}
+}
+
+// WithParallelToolCalls allows or forbids several tool calls in one response for a
+// single call.
+func WithParallelToolCalls(enabled bool) CallOption {
+	return func(o *CallOptions) {
+		o.ParallelToolCalls = &enabled
+	}
+}
+
+// WithPartialResults makes stream helpers such as StreamWithCallback return the partial
+// completion along with the error when the stream is interrupted. Other calls ignore it.
+func WithPartialResults() CallOption {
+	return func(o *CallOptions) {
+		o.PartialResults = true
+	}
+}
+
+// ResolveCallOptions applies opts in order on top of defaults and returns the result.
+// Nil options are ignored.
+func ResolveCallOptions(defaults CallOptions, opts []CallOption) CallOptions {
+	resolved := defaults
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&resolved)
+		}
+	}
+	return resolved
+}
@@ -0,0 +1,23 @@
+package types
+
+// ConnPoolStats describes a client's HTTP connection use since it was created, for
+// tuning AIConfig.MaxIdleConnsPerHost and IdleConnTimeout.
+type ConnPoolStats struct {
+	Requests    int64 `json:"requests"`    // Requests sent, including retries
+	InFlight    int64 `json:"inFlight"`    // Requests awaiting a response or with an open response body
+	NewConns    int64 `json:"newConns"`    // Requests that opened a new connection
+	ReusedConns int64 `json:"reusedConns"` // Requests that reused a pooled connection
+	// IdleConns estimates the connections waiting in the pool. Connections the transport
+	// closes after IdleConnTimeout are not observed, so it can overcount after idle periods.
+	IdleConns int64 `json:"idleConns"`
+}
+
+// ReuseRate returns the fraction of connections that were reused from the pool, or 0
+// before any request. A low rate under steady load suggests raising MaxIdleConnsPerHost.
+func (s ConnPoolStats) ReuseRate() float64 {
+	total := s.NewConns + s.ReusedConns
+	if total == 0 {
+		return 0
+	}
+	return float64(s.ReusedConns) / float64(total)
+}
@@ -0,0 +1,134 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/kengibson1111/go-aiprovider/internal/shared/env"
+	"gopkg.in/yaml.v3"
+)
+
+// ErrProfileNotFound is returned when a named profile is not defined in the profile file
+var ErrProfileNotFound = errors.New("profile not found")
+
+// Profile is a named client configuration in a profile file. API keys are never stored
+// in the file; APIKeyEnv names the environment variable that holds the key.
+type Profile struct {
+	Provider     string   `json:"provider" yaml:"provider"`
+	Model        string   `json:"model,omitempty" yaml:"model,omitempty"`
+	APIKeyEnv    string   `json:"apiKeyEnv,omitempty" yaml:"apiKeyEnv,omitempty"`
+	BaseURL      string   `json:"baseUrl,omitempty" yaml:"baseUrl,omitempty"`
+	MaxTokens    int      `json:"maxTokens,omitempty" yaml:"maxTokens,omitempty"`
+	Temperature  float64  `json:"temperature,omitempty" yaml:"temperature,omitempty"`
+	TopP         float64  `json:"topP,omitempty" yaml:"topP,omitempty"`
+	Stop         []string `json:"stop,omitempty" yaml:"stop,omitempty"`
+	SystemPrompt string   `json:"systemPrompt,omitempty" yaml:"systemPrompt,omitempty"`
+	TemplateMode string   `json:"templateMode,omitempty" yaml:"templateMode,omitempty"`
+}
+
+// Profiles is the contents of a profile file: named profiles and an optional default.
+//
+// Example providers.yaml:
+//
+//	default: prod-gpt4o
+//	profiles:
+//	  prod-gpt4o:
+//	    provider: openai
+//	    model: gpt-4o
+//	    apiKeyEnv: OPENAI_API_KEY
+//	    maxTokens: 2000
+//	  review-claude:
+//	    provider: claude
+//	    model: claude-sonnet-4-6
+//	    temperature: 0.2
+type Profiles struct {
+	Default  string             `json:"default,omitempty" yaml:"default,omitempty"`
+	Profiles map[string]Profile `json:"profiles" yaml:"profiles"`
+}
+
+// LoadProfiles reads a profile file. Files ending in .yaml or .yml are parsed as YAML and
+// anything else as JSON. Unknown fields are rejected so typos don't silently fall back
+// to defaults.
+func LoadProfiles(path string) (*Profiles, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read profile file: %w", err)
+	}
+
+	var profiles Profiles
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(&profiles); err != nil {
+			return nil, fmt.Errorf("failed to parse profile file %s: %w", path, err)
+		}
+	default:
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&profiles); err != nil {
+			return nil, fmt.Errorf("failed to parse profile file %s: %w", path, err)
+		}
+	}
+
+	if profiles.Default != "" {
+		if _, ok := profiles.Profiles[profiles.Default]; !ok {
+			return nil, fmt.Errorf("default profile %q: %w", profiles.Default, ErrProfileNotFound)
+		}
+	}
+
+	return &profiles, nil
+}
+
+// Names returns the profile names in sorted order.
+func (p *Profiles) Names() []string {
+	names := make([]string, 0, len(p.Profiles))
+	for name := range p.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Config builds the AIConfig for the named profile, or the default profile when name is
+// empty. The API key is read from the profile's APIKeyEnv, or from the provider's usual
+// variable (as in ConfigFromEnv) when APIKeyEnv is not set. A .env file in the current
+// directory is loaded first if one exists.
+func (p *Profiles) Config(name string) (*AIConfig, error) {
+	if name == "" {
+		name = p.Default
+	}
+	profile, ok := p.Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q (available: %s)", ErrProfileNotFound, name, strings.Join(p.Names(), ", "))
+	}
+
+	if err := env.LoadEnvConfig(); err != nil {
+		return nil, fmt.Errorf("failed to load .env file: %w", err)
+	}
+
+	provider := strings.ToLower(strings.TrimSpace(profile.Provider))
+	keyEnv := profile.APIKeyEnv
+	if keyEnv == "" {
+		keyEnv = providerEnvVars[provider].apiKey
+	}
+
+	return &AIConfig{
+		Provider:     provider,
+		APIKey:       lookupEnv(keyEnv),
+		BaseURL:      profile.BaseURL,
+		Model:        profile.Model,
+		MaxTokens:    profile.MaxTokens,
+		Temperature:  profile.Temperature,
+		SystemPrompt: profile.SystemPrompt,
+		TopP:         profile.TopP,
+		Stop:         profile.Stop,
+		TemplateMode: profile.TemplateMode,
+	}, nil
+}
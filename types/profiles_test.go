@@ -0,0 +1,85 @@
+package types
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeProfileFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoadProfiles(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv("PROD_OPENAI_KEY", "sk-prod")
+	t.Setenv("CLAUDE_API_KEY", "sk-ant-dev")
+
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{
+			name: "YAML",
+			file: "providers.yaml",
+			content: `default: prod-gpt4o
+profiles:
+  prod-gpt4o:
+    provider: openai
+    model: gpt-4o
+    apiKeyEnv: PROD_OPENAI_KEY
+    maxTokens: 2000
+  review-claude:
+    provider: Claude
+    model: claude-sonnet-4-6
+    temperature: 0.2
+    stop: ["END"]
+`,
+		},
+		{
+			name: "JSON",
+			file: "providers.json",
+			content: `{"default": "prod-gpt4o", "profiles": {
+				"prod-gpt4o": {"provider": "openai", "model": "gpt-4o", "apiKeyEnv": "PROD_OPENAI_KEY", "maxTokens": 2000},
+				"review-claude": {"provider": "Claude", "model": "claude-sonnet-4-6", "temperature": 0.2, "stop": ["END"]}
+			}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profiles, err := LoadProfiles(writeProfileFile(t, tt.file, tt.content))
+			require.NoError(t, err)
+			assert.Equal(t, []string{"prod-gpt4o", "review-claude"}, profiles.Names())
+
+			config, err := profiles.Config("")
+			require.NoError(t, err)
+			assert.Equal(t, &AIConfig{Provider: ProviderOpenAI, APIKey: "sk-prod", Model: "gpt-4o", MaxTokens: 2000}, config)
+
+			config, err = profiles.Config("review-claude")
+			require.NoError(t, err)
+			assert.Equal(t, &AIConfig{Provider: ProviderClaude, APIKey: "sk-ant-dev", Model: "claude-sonnet-4-6", Temperature: 0.2, Stop: []string{"END"}}, config)
+
+			_, err = profiles.Config("missing")
+			assert.ErrorIs(t, err, ErrProfileNotFound)
+		})
+	}
+}
+
+func TestLoadProfiles_Errors(t *testing.T) {
+	_, err := LoadProfiles(writeProfileFile(t, "p.yaml", "profiles:\n  a:\n    provider: openai\n    modle: gpt-4o\n"))
+	assert.ErrorContains(t, err, "modle")
+
+	_, err = LoadProfiles(writeProfileFile(t, "p.json", `{"default": "b", "profiles": {"a": {"provider": "openai"}}}`))
+	assert.ErrorIs(t, err, ErrProfileNotFound)
+
+	_, err = LoadProfiles(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+}
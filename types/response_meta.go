@@ -0,0 +1,59 @@
+package types
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// ResponseMeta is HTTP metadata of a provider response, normalized across providers. Use
+// WithResponseMeta to capture it for a call.
+type ResponseMeta struct {
+	// StatusCode is the HTTP status of the last response, including error responses.
+	StatusCode int
+
+	// RequestID identifies the request to the provider's support: x-request-id for
+	// OpenAI, request-id for Anthropic, or apim-request-id for Azure OpenAI.
+	RequestID string
+
+	// RemainingRequests and RemainingTokens are the rate limit left in the current
+	// window, or -1 when the provider did not report them.
+	RemainingRequests int
+	RemainingTokens   int
+
+	// ResetRequests and ResetTokens are the time until those limits reset, or zero when
+	// not reported.
+	ResetRequests time.Duration
+	ResetTokens   time.Duration
+
+	// RetryAfter is how long the provider asked to wait before retrying, or zero.
+	RetryAfter time.Duration
+
+	// Header holds all response headers, for headers the fields above do not cover.
+	Header http.Header
+}
+
+// responseMetaKey is the context key for the ResponseMeta a call fills in
+type responseMetaKey struct{}
+
+// WithResponseMeta returns a context whose calls fill in the returned ResponseMeta with
+// the metadata of the last HTTP response, including a failed one. When a call is retried,
+// the metadata is that of the final attempt. The Claude, OpenAI, and Azure OpenAI clients
+// support it; Claude on Bedrock does not.
+//
+// Example:
+//
+//	ctx, meta := types.WithResponseMeta(ctx)
+//	response, err := aiClient.CallWithPrompt(ctx, prompt)
+//	log.Printf("request %s, %d requests left", meta.RequestID, meta.RemainingRequests)
+func WithResponseMeta(ctx context.Context) (context.Context, *ResponseMeta) {
+	meta := &ResponseMeta{RemainingRequests: -1, RemainingTokens: -1}
+	return context.WithValue(ctx, responseMetaKey{}, meta), meta
+}
+
+// ResponseMetaFromContext returns the ResponseMeta attached by WithResponseMeta, or nil.
+// Provider clients use it to record response metadata.
+func ResponseMetaFromContext(ctx context.Context) *ResponseMeta {
+	meta, _ := ctx.Value(responseMetaKey{}).(*ResponseMeta)
+	return meta
+}
@@ -0,0 +1,38 @@
+package types
+
+// Review severities for ReviewFinding.Severity
+const (
+	SeverityCritical = "critical"
+	SeverityMajor    = "major"
+	SeverityMinor    = "minor"
+	SeverityInfo     = "info"
+)
+
+// ReviewRequest is a code change to review.
+type ReviewRequest struct {
+	// Diff is the change in unified diff format.
+	Diff string `json:"diff"`
+
+	// Language is the main programming language of the change, e.g. "go". Optional.
+	Language string `json:"language,omitempty"`
+
+	// Guidelines are project rules the reviewer checks in addition to correctness.
+	Guidelines []string `json:"guidelines,omitempty"`
+}
+
+// ReviewFinding is one problem found in a code review.
+type ReviewFinding struct {
+	File string `json:"file"`
+
+	// Line is the line number in the new version of the file, or zero when the finding
+	// is not about one line.
+	Line int `json:"line"`
+
+	// Severity is one of the Severity constants.
+	Severity string `json:"severity"`
+
+	Message string `json:"message"`
+
+	// Suggestion is the proposed fix, when the reviewer has one.
+	Suggestion string `json:"suggestion,omitempty"`
+}
@@ -0,0 +1,36 @@
+package types
+
+import (
+	"context"
+	"time"
+)
+
+// Token is an OAuth bearer access token. A zero Expiry means the token does not expire.
+type Token struct {
+	AccessToken string
+	Expiry      time.Time
+}
+
+// TokenSource supplies bearer tokens for API requests (AIConfig.TokenSource). Clients
+// cache the token and call Token again shortly before it expires.
+//
+// To use a golang.org/x/oauth2 token source:
+//
+//	config.TokenSource = types.TokenSourceFunc(func(ctx context.Context) (types.Token, error) {
+//		t, err := ts.Token()
+//		if err != nil {
+//			return types.Token{}, err
+//		}
+//		return types.Token{AccessToken: t.AccessToken, Expiry: t.Expiry}, nil
+//	})
+type TokenSource interface {
+	Token(ctx context.Context) (Token, error)
+}
+
+// TokenSourceFunc adapts a function to a TokenSource.
+type TokenSourceFunc func(ctx context.Context) (Token, error)
+
+// Token calls f.
+func (f TokenSourceFunc) Token(ctx context.Context) (Token, error) {
+	return f(ctx)
+}
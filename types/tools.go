@@ -0,0 +1,21 @@
+package types
+
+// Tool choice values for WithToolChoice. Any other value names the function the model
+// must call.
+const (
+	ToolChoiceAuto     = "auto"
+	ToolChoiceNone     = "none"
+	ToolChoiceRequired = "required"
+)
+
+// ToolCall is a function call requested by the model, in a provider-neutral form.
+type ToolCall struct {
+	// ID identifies the call, for sending its result back to the model.
+	ID string `json:"id"`
+
+	// Name is the name of the function to call.
+	Name string `json:"name"`
+
+	// Arguments is the JSON object of arguments generated by the model.
+	Arguments string `json:"arguments"`
+}
@@ -1,7 +1,12 @@
 package types
 
 import (
+	"errors"
 	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"time"
 )
 
 // Provider constants for AIConfig.Provider
@@ -13,6 +18,12 @@ const (
 	ProviderOpenAIAzureUP = "openai-azure-up"
 )
 
+// Template mode constants for AIConfig.TemplateMode
+const (
+	TemplateModeSimple   = "simple"
+	TemplateModeEnhanced = "enhanced"
+)
+
 // ErrorResponse represents a structured error response.
 // It implements the error interface so it can be used with errors.As.
 type ErrorResponse struct {
@@ -20,6 +31,15 @@ type ErrorResponse struct {
 	Message string `json:"message"`
 	Details string `json:"details,omitempty"`
 	Retry   bool   `json:"retry"`
+
+	// RetryAfter is how long the provider asked to wait before retrying, from the
+	// Retry-After header or, when a rate limit is exhausted, its reset time. Zero when the
+	// provider did not say.
+	RetryAfter time.Duration `json:"retryAfter,omitempty"`
+
+	// Err is the underlying error when the failure did not come from the provider, such as
+	// a KeyProvider or TokenSource error. It is returned by Unwrap.
+	Err error `json:"-"`
 }
 
 // Error implements the error interface for ErrorResponse.
@@ -30,6 +50,50 @@ func (e *ErrorResponse) Error() string {
 	return fmt.Sprintf("%s: %s", e.Code, e.Message)
 }
 
+// Unwrap returns the underlying error, if any.
+func (e *ErrorResponse) Unwrap() error {
+	return e.Err
+}
+
+// Sentinel errors for the common failure classes of all providers. Errors returned by the
+// clients match them with errors.Is, whatever the provider's own error code:
+//
+//	if errors.Is(err, types.ErrRateLimited) {
+//		time.Sleep(time.Second)
+//	}
+var (
+	ErrRateLimited           = errors.New("rate limited")
+	ErrInvalidAPIKey         = errors.New("invalid API key")
+	ErrContextLengthExceeded = errors.New("context length exceeded")
+	ErrModelNotFound         = errors.New("model not found")
+	ErrServerError           = errors.New("provider server error")
+
+	// ErrProviderPanic is returned when a provider SDK panics during a call. The panic is
+	// recovered and logged with its stack trace instead of crashing the process.
+	ErrProviderPanic = errors.New("provider call panicked")
+)
+
+// sentinelByCode maps the ErrorResponse codes of the providers to sentinel errors.
+var sentinelByCode = map[string]error{
+	"rate_limit_exceeded":     ErrRateLimited,
+	"rate_limit_error":        ErrRateLimited,
+	"invalid_api_key":         ErrInvalidAPIKey,
+	"authentication_error":    ErrInvalidAPIKey,
+	"context_length_exceeded": ErrContextLengthExceeded,
+	"model_not_found":         ErrModelNotFound,
+	"server_error":            ErrServerError,
+	"service_unavailable":     ErrServerError,
+	"overloaded_error":        ErrServerError,
+	"provider_panic":          ErrProviderPanic,
+}
+
+// Is reports whether the error's code belongs to target, one of the sentinel errors such
+// as ErrRateLimited, so errors.Is works without matching codes or messages.
+func (e *ErrorResponse) Is(target error) bool {
+	sentinel, ok := sentinelByCode[e.Code]
+	return ok && sentinel == target
+}
+
 // AIConfig represents the AI service configuration
 type AIConfig struct {
 	Provider    string  `json:"provider"`
@@ -38,4 +102,238 @@ type AIConfig struct {
 	Model       string  `json:"model"`
 	MaxTokens   int     `json:"maxTokens"`
 	Temperature float64 `json:"temperature"`
+
+	// SystemPrompt is sent as the system message on every prompt call unless
+	// overridden with WithSystemPrompt. Empty sends no system message.
+	SystemPrompt string `json:"systemPrompt,omitempty"`
+
+	// TopP sets nucleus sampling (0.0-1.0). Zero leaves the provider default.
+	TopP float64 `json:"topP,omitempty"`
+
+	// FrequencyPenalty and PresencePenalty (-2.0-2.0) discourage repetition. Zero leaves
+	// the provider default. Claude does not support penalties and ignores them.
+	FrequencyPenalty float64 `json:"frequencyPenalty,omitempty"`
+	PresencePenalty  float64 `json:"presencePenalty,omitempty"`
+
+	// Stop lists sequences that end generation.
+	Stop []string `json:"stop,omitempty"`
+
+	// Seed requests deterministic sampling where the provider supports it (OpenAI).
+	Seed *int64 `json:"seed,omitempty"`
+
+	// N is the number of choices to generate. Zero or one requests a single choice.
+	N int `json:"n,omitempty"`
+
+	// TemplateMode selects how CallWithPromptAndVariables processes prompt templates:
+	// TemplateModeSimple (default) or TemplateModeEnhanced for conditionals and loops.
+	TemplateMode string `json:"templateMode,omitempty"`
+
+	// StrictVariables makes CallWithPromptAndVariables fail when a template placeholder
+	// has no value instead of sending the prompt with {{missing_var}} left in place.
+	StrictVariables bool `json:"strictVariables,omitempty"`
+
+	// ErrorOnUnusedVariables makes CallWithPromptAndVariables fail when supplied
+	// variables are not referenced by the template.
+	ErrorOnUnusedVariables bool `json:"errorOnUnusedVariables,omitempty"`
+
+	// Logger, if set, receives the client's log records instead of stdout. API keys and
+	// bearer tokens are redacted from every message.
+	Logger *slog.Logger `json:"-"`
+
+	// LogLevel sets the client's log level: "debug", "info", "warn", or "error". Empty
+	// uses the LOG_LEVEL environment variable.
+	LogLevel string `json:"logLevel,omitempty"`
+
+	// RedactPrompts keeps prompt text out of debug logs; only its length is logged.
+	RedactPrompts bool `json:"redactPrompts,omitempty"`
+
+	// Reasoning enables Claude extended thinking and OpenAI reasoning effort on every
+	// prompt call, with ReasoningBudget thinking tokens (default: DefaultReasoningBudget).
+	// Parse the reasoning from a response with client.ParseReasoning.
+	Reasoning       bool `json:"reasoning,omitempty"`
+	ReasoningBudget int  `json:"reasoningBudget,omitempty"`
+
+	// TokenSource, if set, supplies OAuth bearer tokens for API requests, such as Microsoft
+	// Entra ID tokens for Azure OpenAI or org-scoped OpenAI tokens. It replaces APIKey for
+	// the openai provider and the DefaultAzureCredential for the openai-azure provider.
+	// Tokens are cached and refreshed shortly before they expire.
+	TokenSource TokenSource `json:"-"`
+
+	// KeyProvider, if set, supplies the API key for every request in place of APIKey for
+	// the claude and openai providers, so keys can rotate without recreating clients.
+	// See EnvKey, FileKey, and KeyProviderFunc.
+	KeyProvider KeyProvider `json:"-"`
+
+	// ProxyURL routes API requests through an HTTP, HTTPS, or SOCKS5 proxy. When empty,
+	// the HTTP_PROXY, HTTPS_PROXY, and NO_PROXY environment variables are used.
+	ProxyURL string `json:"proxyUrl,omitempty"`
+
+	// CACertFile names a PEM bundle of CA certificates trusted in addition to the system
+	// roots, for TLS-inspecting proxies and endpoints with private certificates.
+	CACertFile string `json:"caCertFile,omitempty"`
+
+	// ClientCertFile and ClientKeyFile name a PEM certificate and private key presented
+	// for mutual TLS.
+	ClientCertFile string `json:"clientCertFile,omitempty"`
+	ClientKeyFile  string `json:"clientKeyFile,omitempty"`
+
+	// DisableKeepAlives opens a new connection for every request, for proxies and load
+	// balancers that drop idle connections.
+	DisableKeepAlives bool `json:"disableKeepAlives,omitempty"`
+
+	// RequestTimeout limits each API request attempt, including reading the response
+	// (default: 30s for Claude, 25s for OpenAI and Azure OpenAI). Raise it for long
+	// generations with a large MaxTokens.
+	RequestTimeout time.Duration `json:"requestTimeout,omitempty"`
+
+	// StreamIdleTimeout limits the wait between chunks of a streaming response, and
+	// StreamMaxDuration limits a whole stream (defaults: 60s and 10m). Streaming calls
+	// use these in place of RequestTimeout, so long generations can stream for as long
+	// as chunks keep arriving. OpenAI and Azure OpenAI only.
+	StreamIdleTimeout time.Duration `json:"streamIdleTimeout,omitempty"`
+	StreamMaxDuration time.Duration `json:"streamMaxDuration,omitempty"`
+
+	// ResponseHeaderTimeout limits the wait for response headers once a request is sent
+	// (default: 15s for OpenAI and Azure OpenAI, none for Claude).
+	ResponseHeaderTimeout time.Duration `json:"responseHeaderTimeout,omitempty"`
+
+	// IdleConnTimeout and MaxIdleConnsPerHost tune connection reuse (defaults: 90s and 10
+	// for OpenAI and Azure OpenAI, the net/http defaults for Claude).
+	IdleConnTimeout     time.Duration `json:"idleConnTimeout,omitempty"`
+	MaxIdleConnsPerHost int           `json:"maxIdleConnsPerHost,omitempty"`
+
+	// HTTPClient, if set, sends API requests for the Claude, OpenAI, and Azure OpenAI
+	// clients in place of the client they build. It is used as-is: Transport and the proxy,
+	// TLS, keep-alive, and connection settings above are ignored, and for Claude so is
+	// RequestTimeout.
+	HTTPClient *http.Client `json:"-"`
+
+	// Transport, if set, replaces the HTTP transport used for API requests by the Claude,
+	// OpenAI, and Azure OpenAI clients, for example a vcr.Recorder in tests.
+	Transport http.RoundTripper `json:"-"`
+}
+
+// Reasoning is the model's reasoning behind a response, normalized across providers
+// (Claude thinking blocks, OpenAI reasoning token counts).
+type Reasoning struct {
+	// Text is the reasoning text from Claude thinking blocks. OpenAI does not return its
+	// reasoning, so Text is empty for OpenAI responses.
+	Text string `json:"text,omitempty"`
+
+	// Redacted reports that the provider encrypted part of the reasoning (Claude
+	// redacted_thinking blocks); that part is not included in Text.
+	Redacted bool `json:"redacted,omitempty"`
+
+	// Tokens is the number of reasoning tokens the provider reported separately (OpenAI
+	// reasoning_tokens), or zero when it does not.
+	Tokens int `json:"tokens,omitempty"`
+}
+
+// Finish reasons for AIResponse.FinishReason. Provider stop reasons are mapped to these
+// values; reasons without an equivalent are passed through unchanged.
+const (
+	FinishStop          = "stop"
+	FinishLength        = "length"
+	FinishToolCalls     = "tool_calls"
+	FinishContentFilter = "content_filter"
+	FinishRefusal       = "refusal"
+)
+
+// TokenUsage is the token accounting of a response, normalized across providers.
+type TokenUsage struct {
+	PromptTokens     int `json:"promptTokens"`
+	CompletionTokens int `json:"completionTokens"`
+	TotalTokens      int `json:"totalTokens"`
+}
+
+// TokenLogprob is a generated token with its log probability.
+type TokenLogprob struct {
+	Token   string  `json:"token"`
+	Logprob float64 `json:"logprob"`
+
+	// TopLogprobs are the most likely tokens at this position, including the generated
+	// one, when they were requested with WithTopLogprobs.
+	TopLogprobs []TokenLogprob `json:"topLogprobs,omitempty"`
+}
+
+// Probability returns the token's probability, from 0 to 1.
+func (t TokenLogprob) Probability() float64 {
+	return math.Exp(t.Logprob)
+}
+
+// AIResponse is a provider-neutral view of a completion response.
+type AIResponse struct {
+	// Content is the generated text: the first choice's message for OpenAI, or the text
+	// blocks joined for Claude.
+	Content string `json:"content"`
+
+	// FinishReason is why generation stopped, one of the Finish constants.
+	FinishReason string `json:"finishReason"`
+
+	Usage TokenUsage `json:"usage"`
+
+	// Model is the model that generated the response, as reported by the provider.
+	Model string `json:"model"`
+
+	// Logprobs are the generated tokens of the first choice with their log probabilities,
+	// when they were requested with WithLogprobs or WithTopLogprobs and the provider
+	// returns them (OpenAI).
+	Logprobs []TokenLogprob `json:"logprobs,omitempty"`
+
+	// Reasoning is the model's reasoning, when the response has Claude thinking blocks
+	// or OpenAI reasoning tokens, and nil otherwise.
+	Reasoning *Reasoning `json:"reasoning,omitempty"`
+
+	// Raw is the provider's response, for fields the envelope does not cover.
+	Raw []byte `json:"-"`
+}
+
+// CodeBlock is a fenced code block from markdown response text.
+type CodeBlock struct {
+	// Language is the normalized language from the fence's info string, e.g. "go" for
+	// "golang" or "javascript" for "js", or empty when the fence has none.
+	Language string `json:"language,omitempty"`
+
+	// Code is the block's content without the fences.
+	Code string `json:"code"`
+}
+
+// Citation is a source reference attached to generated text, normalized across providers
+// (Anthropic citations on text blocks, OpenAI url_citation annotations).
+type Citation struct {
+	// Type is the provider's citation type, e.g. "url_citation", "char_location",
+	// "page_location", or "web_search_result_location".
+	Type string `json:"type"`
+
+	// URL links to the cited source when the source is a web page.
+	URL string `json:"url,omitempty"`
+
+	// Title is the web page or document title.
+	Title string `json:"title,omitempty"`
+
+	// CitedText is the quoted source text, when the provider returns it.
+	CitedText string `json:"citedText,omitempty"`
+
+	// DocumentIndex identifies the request document that was cited, or -1 when the
+	// citation does not refer to a request document.
+	DocumentIndex int `json:"documentIndex"`
+
+	// Location is the cited span within the source document, when known.
+	Location *CitationLocation `json:"location,omitempty"`
+
+	// TextStart and TextEnd are the character offsets of the supported span within the
+	// response text of the choice.
+	TextStart int `json:"textStart"`
+	TextEnd   int `json:"textEnd"`
+
+	// Choice is the index of the response choice the citation belongs to.
+	Choice int `json:"choice"`
+}
+
+// CitationLocation is a span within a cited document. Unit is "char", "page", or "block";
+// End is exclusive.
+type CitationLocation struct {
+	Unit  string `json:"unit"`
+	Start int    `json:"start"`
+	End   int    `json:"end"`
 }
@@ -0,0 +1,227 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Configuration validation errors
+var (
+	// ErrInvalidConfig is returned by AIConfig.Validate when any setting is invalid
+	ErrInvalidConfig = errors.New("invalid configuration")
+
+	// ErrInvalidGenerationParams is returned when generation parameters are out of range
+	ErrInvalidGenerationParams = errors.New("invalid generation parameters")
+)
+
+// apiKeyEnvVars names the environment variable that usually supplies each provider's API key,
+// so validation errors can point at where to look.
+var apiKeyEnvVars = map[string]string{
+	ProviderClaude: "CLAUDE_API_KEY",
+	ProviderOpenAI: "OPENAI_API_KEY",
+}
+
+// apiKeyPrefixes are the key prefixes issued by each provider's own API. Keys for custom
+// endpoints (BaseURL set) are not checked, since gateways issue their own keys.
+var apiKeyPrefixes = map[string]string{
+	ProviderClaude: "sk-ant-",
+	ProviderOpenAI: "sk-",
+}
+
+// Normalize trims surrounding whitespace from the string settings and lowercases Provider,
+// so values pasted from environment files or flags validate as intended.
+func (c *AIConfig) Normalize() {
+	c.Provider = strings.ToLower(strings.TrimSpace(c.Provider))
+	c.APIKey = strings.TrimSpace(c.APIKey)
+	c.BaseURL = strings.TrimSpace(c.BaseURL)
+	c.Model = strings.TrimSpace(c.Model)
+	c.TemplateMode = strings.ToLower(strings.TrimSpace(c.TemplateMode))
+	c.LogLevel = strings.ToLower(strings.TrimSpace(c.LogLevel))
+}
+
+// Validate checks the configuration without creating a client: the provider name, the API key
+// for providers that need one, BaseURL and ProxyURL syntax, template mode, and generation
+// parameter ranges.
+// All problems are reported together in a single error wrapping ErrInvalidConfig, each naming
+// the setting and what to change.
+//
+// Validate does not modify the config; call Normalize first to accept values with stray
+// whitespace or mixed-case provider names.
+//
+// Example:
+//
+//	config.Normalize()
+//	if err := config.Validate(); err != nil {
+//		log.Fatal(err)
+//	}
+func (c *AIConfig) Validate() error {
+	var problems []error
+
+	switch c.Provider {
+	case ProviderOpenAI:
+		if c.TokenSource == nil && c.KeyProvider == nil {
+			problems = append(problems, c.validateAPIKey()...)
+		}
+	case ProviderClaude:
+		if c.KeyProvider == nil {
+			problems = append(problems, c.validateAPIKey()...)
+		}
+	case ProviderClaudeBedrock, ProviderOpenAIAzure, ProviderOpenAIAzureUP:
+		// Credentials come from the AWS or Azure environment, not APIKey
+	case "":
+		problems = append(problems, fmt.Errorf("unsupported provider: provider is required (one of %s)", providerList()))
+	default:
+		problems = append(problems, fmt.Errorf("unsupported provider: %q (one of %s)", c.Provider, providerList()))
+	}
+
+	if c.TokenSource != nil && c.Provider != ProviderOpenAI && c.Provider != ProviderOpenAIAzure {
+		problems = append(problems, fmt.Errorf("tokenSource: token sources are supported by the %s and %s providers, not %s", ProviderOpenAI, ProviderOpenAIAzure, c.Provider))
+	}
+
+	if c.KeyProvider != nil && c.Provider != ProviderOpenAI && c.Provider != ProviderClaude {
+		problems = append(problems, fmt.Errorf("keyProvider: key providers are supported by the %s and %s providers, not %s", ProviderClaude, ProviderOpenAI, c.Provider))
+	}
+	if c.KeyProvider != nil && c.TokenSource != nil {
+		problems = append(problems, fmt.Errorf("keyProvider: set either keyProvider or tokenSource, not both"))
+	}
+
+	if c.BaseURL != "" {
+		if err := validateEndpointURL(c.BaseURL); err != nil {
+			problems = append(problems, fmt.Errorf("baseUrl: %w", err))
+		}
+	}
+
+	if c.ProxyURL != "" {
+		if err := validateProxyURL(c.ProxyURL); err != nil {
+			problems = append(problems, fmt.Errorf("proxyUrl: %w", err))
+		}
+	}
+	if (c.ClientCertFile == "") != (c.ClientKeyFile == "") {
+		problems = append(problems, fmt.Errorf("clientCertFile: clientCertFile and clientKeyFile must be set together"))
+	}
+
+	if c.RequestTimeout < 0 || c.ResponseHeaderTimeout < 0 || c.IdleConnTimeout < 0 {
+		problems = append(problems, fmt.Errorf("timeouts must not be negative (requestTimeout %s, responseHeaderTimeout %s, idleConnTimeout %s)", c.RequestTimeout, c.ResponseHeaderTimeout, c.IdleConnTimeout))
+	}
+	if c.StreamIdleTimeout < 0 || c.StreamMaxDuration < 0 {
+		problems = append(problems, fmt.Errorf("stream timeouts must not be negative (streamIdleTimeout %s, streamMaxDuration %s)", c.StreamIdleTimeout, c.StreamMaxDuration))
+	}
+	if c.MaxIdleConnsPerHost < 0 {
+		problems = append(problems, fmt.Errorf("maxIdleConnsPerHost must not be negative, got %d", c.MaxIdleConnsPerHost))
+	}
+
+	switch strings.ToLower(c.TemplateMode) {
+	case "", TemplateModeSimple, TemplateModeEnhanced:
+	default:
+		problems = append(problems, fmt.Errorf("templateMode: %q is not supported (use %q or %q)", c.TemplateMode, TemplateModeSimple, TemplateModeEnhanced))
+	}
+
+	switch strings.ToLower(strings.TrimSpace(c.LogLevel)) {
+	case "", "debug", "info", "warn", "warning", "error":
+	default:
+		problems = append(problems, fmt.Errorf("logLevel: %q is not supported (use debug, info, warn, or error)", c.LogLevel))
+	}
+
+	if err := c.ValidateGenerationParams(); err != nil {
+		problems = append(problems, err)
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %w", ErrInvalidConfig, errors.Join(problems...))
+	}
+	return nil
+}
+
+// ValidateGenerationParams checks MaxTokens, Temperature, TopP, the penalties, N, Stop,
+// and ReasoningBudget against the ranges accepted by the supported providers. The
+// returned error wraps ErrInvalidGenerationParams.
+func (c *AIConfig) ValidateGenerationParams() error {
+	var problems []error
+
+	if c.MaxTokens < 0 {
+		problems = append(problems, fmt.Errorf("maxTokens must not be negative, got %d", c.MaxTokens))
+	}
+	if c.Temperature < 0 || c.Temperature > 2 {
+		problems = append(problems, fmt.Errorf("temperature must be between 0 and 2, got %g", c.Temperature))
+	}
+	if c.TopP < 0 || c.TopP > 1 {
+		problems = append(problems, fmt.Errorf("topP must be between 0 and 1, got %g", c.TopP))
+	}
+	if c.FrequencyPenalty < -2 || c.FrequencyPenalty > 2 {
+		problems = append(problems, fmt.Errorf("frequencyPenalty must be between -2 and 2, got %g", c.FrequencyPenalty))
+	}
+	if c.PresencePenalty < -2 || c.PresencePenalty > 2 {
+		problems = append(problems, fmt.Errorf("presencePenalty must be between -2 and 2, got %g", c.PresencePenalty))
+	}
+	if c.N < 0 || c.N > 128 {
+		problems = append(problems, fmt.Errorf("n must be between 0 and 128, got %d", c.N))
+	}
+	if c.ReasoningBudget < 0 {
+		problems = append(problems, fmt.Errorf("reasoningBudget must not be negative, got %d", c.ReasoningBudget))
+	}
+	for i, stop := range c.Stop {
+		if stop == "" {
+			problems = append(problems, fmt.Errorf("stop sequence %d must not be empty", i))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %w", ErrInvalidGenerationParams, errors.Join(problems...))
+	}
+	return nil
+}
+
+// validateAPIKey checks that a key is present and, for the provider's own endpoint, that it
+// looks like a key that provider issues.
+func (c *AIConfig) validateAPIKey() []error {
+	envVar := apiKeyEnvVars[c.Provider]
+	key := c.APIKey
+
+	if strings.TrimSpace(key) == "" {
+		return []error{fmt.Errorf("apiKey: API key is required for %s (usually set via %s)", c.Provider, envVar)}
+	}
+	if strings.ContainsAny(key, " \t\r\n") {
+		return []error{fmt.Errorf("apiKey: API key contains whitespace; check %s for stray spaces or quotes", envVar)}
+	}
+	if prefix := apiKeyPrefixes[c.Provider]; c.BaseURL == "" && !strings.HasPrefix(key, prefix) {
+		return []error{fmt.Errorf("apiKey: %s API keys start with %q; check that %s holds a %s key", c.Provider, prefix, envVar, c.Provider)}
+	}
+	return nil
+}
+
+// validateEndpointURL checks that endpoint is an absolute http or https URL with a host.
+func validateEndpointURL(endpoint string) error {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return fmt.Errorf("%q is not a valid URL: %w", endpoint, err)
+	}
+	if u.Scheme != "https" && u.Scheme != "http" {
+		return fmt.Errorf("%q must start with https:// or http://", endpoint)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("%q has no host", endpoint)
+	}
+	return nil
+}
+
+// validateProxyURL checks that proxy is an absolute http, https, or socks5 URL with a host.
+func validateProxyURL(proxy string) error {
+	u, err := url.Parse(proxy)
+	if err != nil {
+		return fmt.Errorf("%q is not a valid URL: %w", proxy, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "socks5" {
+		return fmt.Errorf("%q must start with http://, https://, or socks5://", proxy)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("%q has no host", proxy)
+	}
+	return nil
+}
+
+// providerList returns the supported provider names for error messages.
+func providerList() string {
+	return strings.Join([]string{ProviderClaude, ProviderClaudeBedrock, ProviderOpenAI, ProviderOpenAIAzure, ProviderOpenAIAzureUP}, ", ")
+}
@@ -0,0 +1,120 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAIConfigValidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    AIConfig
+		errorText []string
+	}{
+		{
+			name:   "Valid Claude config",
+			config: AIConfig{Provider: ProviderClaude, APIKey: "sk-ant-api03-abc", Temperature: 0.7},
+		},
+		{
+			name:   "Gateway key accepted with custom base URL",
+			config: AIConfig{Provider: ProviderOpenAI, APIKey: "gw-123", BaseURL: "https://gateway.example.com/v1"},
+		},
+		{
+			name:   "Azure needs no API key",
+			config: AIConfig{Provider: ProviderOpenAIAzure, BaseURL: "https://res.openai.azure.com"},
+		},
+		{
+			name:   "Token source replaces OpenAI API key",
+			config: AIConfig{Provider: ProviderOpenAI, TokenSource: TokenSourceFunc(func(context.Context) (Token, error) { return Token{}, nil })},
+		},
+		{
+			name:      "Token source unsupported for Claude",
+			config:    AIConfig{Provider: ProviderClaude, APIKey: "sk-ant-api03-abc", TokenSource: TokenSourceFunc(func(context.Context) (Token, error) { return Token{}, nil })},
+			errorText: []string{"tokenSource: token sources are supported by the openai and openai-azure providers, not claude"},
+		},
+		{
+			name:   "Key provider replaces API key",
+			config: AIConfig{Provider: ProviderClaude, KeyProvider: EnvKey("CLAUDE_API_KEY")},
+		},
+		{
+			name:      "Key provider unsupported for Bedrock",
+			config:    AIConfig{Provider: ProviderClaudeBedrock, KeyProvider: EnvKey("CLAUDE_API_KEY")},
+			errorText: []string{"keyProvider: key providers are supported by the claude and openai providers, not claude-bedrock"},
+		},
+		{
+			name:      "Bad proxy and half a client certificate",
+			config:    AIConfig{Provider: ProviderOpenAI, APIKey: "sk-abc", ProxyURL: "ftp://proxy", ClientCertFile: "client.pem"},
+			errorText: []string{`proxyUrl: "ftp://proxy" must start with http://, https://, or socks5://`, "clientCertFile and clientKeyFile must be set together"},
+		},
+		{
+			name:      "Negative timeout",
+			config:    AIConfig{Provider: ProviderOpenAI, APIKey: "sk-abc", RequestTimeout: -time.Second, MaxIdleConnsPerHost: -1},
+			errorText: []string{"timeouts must not be negative", "requestTimeout -1s", "maxIdleConnsPerHost must not be negative"},
+		},
+		{
+			name:      "Unknown provider",
+			config:    AIConfig{Provider: "gemini"},
+			errorText: []string{`unsupported provider: "gemini"`},
+		},
+		{
+			name:      "Missing API key",
+			config:    AIConfig{Provider: ProviderOpenAI},
+			errorText: []string{"API key is required for openai", "OPENAI_API_KEY"},
+		},
+		{
+			name:      "Wrong key for provider",
+			config:    AIConfig{Provider: ProviderClaude, APIKey: "sk-proj-123"},
+			errorText: []string{`claude API keys start with "sk-ant-"`},
+		},
+		{
+			name:      "Key with whitespace",
+			config:    AIConfig{Provider: ProviderOpenAI, APIKey: "sk-abc def"},
+			errorText: []string{"contains whitespace"},
+		},
+		{
+			name:      "Problems are aggregated",
+			config:    AIConfig{Provider: ProviderOpenAI, APIKey: "sk-abc", BaseURL: "api.openai.com", Temperature: 3, TemplateMode: "jinja", LogLevel: "trace"},
+			errorText: []string{"baseUrl", "must start with https://", "temperature must be between 0 and 2", `templateMode: "jinja"`, `logLevel: "trace"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.errorText == nil {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, ErrInvalidConfig))
+			for _, text := range tt.errorText {
+				assert.Contains(t, err.Error(), text)
+			}
+		})
+	}
+}
+
+func TestAIConfigValidate_GenerationParams(t *testing.T) {
+	config := AIConfig{Provider: ProviderClaudeBedrock, TopP: 1.5}
+	err := config.Validate()
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+	assert.ErrorIs(t, err, ErrInvalidGenerationParams)
+}
+
+func TestAIConfigNormalize(t *testing.T) {
+	config := AIConfig{Provider: " OpenAI ", APIKey: "sk-abc\n", BaseURL: " https://api.openai.com/v1 ", Model: " gpt-4o ", TemplateMode: "Enhanced", LogLevel: " WARN"}
+	config.Normalize()
+
+	assert.Equal(t, ProviderOpenAI, config.Provider)
+	assert.Equal(t, "sk-abc", config.APIKey)
+	assert.Equal(t, "https://api.openai.com/v1", config.BaseURL)
+	assert.Equal(t, "gpt-4o", config.Model)
+	assert.Equal(t, TemplateModeEnhanced, config.TemplateMode)
+	assert.Equal(t, "warn", config.LogLevel)
+	assert.NoError(t, config.Validate())
+}
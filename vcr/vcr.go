@@ -0,0 +1,359 @@
+// Package vcr records provider HTTP traffic to fixture files ("cassettes") and replays it,
+// so tests of code built on this library are deterministic and run without API keys.
+//
+// A Recorder is an http.RoundTripper. Set it as AIConfig.Transport, run the test once
+// with real credentials to record, and commit the cassette. Later runs replay the
+// recorded responses without network access. Credentials are redacted before the
+// cassette is written.
+//
+//	rec, err := vcr.New("testdata/summarize.json", vcr.Options{})
+//	if err != nil {
+//		t.Fatal(err)
+//	}
+//	defer rec.Stop()
+//
+//	config := &types.AIConfig{Provider: types.ProviderOpenAI, APIKey: vcr.APIKey("OPENAI_API_KEY"), Transport: rec}
+//	aiClient, err := client.NewClientFactory().CreateClient(config)
+package vcr
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ErrNoInteraction is returned in replay mode when no unused recorded interaction
+// matches a request
+var ErrNoInteraction = errors.New("vcr: no recorded interaction matches request")
+
+// Redacted replaces sensitive header values in cassettes
+const Redacted = "REDACTED"
+
+// ModeEnvVar overrides Options.Mode when set to "record" or "replay"
+const ModeEnvVar = "AIPROVIDER_VCR_MODE"
+
+// Mode selects whether a Recorder records or replays.
+type Mode int
+
+const (
+	// ModeAuto replays when the cassette file exists and records otherwise.
+	ModeAuto Mode = iota
+
+	// ModeReplay serves responses from the cassette and never contacts the provider.
+	ModeReplay
+
+	// ModeRecord sends requests to the provider and overwrites the cassette on Stop.
+	ModeRecord
+)
+
+// String returns the mode name.
+func (m Mode) String() string {
+	switch m {
+	case ModeReplay:
+		return "replay"
+	case ModeRecord:
+		return "record"
+	default:
+		return "auto"
+	}
+}
+
+// sensitiveHeaders are always redacted, in requests and responses.
+var sensitiveHeaders = []string{
+	"Authorization",
+	"X-Api-Key",
+	"Api-Key",
+	"Cookie",
+	"Set-Cookie",
+	"X-Amz-Security-Token",
+	"Openai-Organization",
+	"Openai-Project",
+	"Anthropic-Organization-Id",
+}
+
+// Options configures a Recorder.
+type Options struct {
+	// Mode selects recording or replay. The AIPROVIDER_VCR_MODE environment variable
+	// takes precedence when set.
+	Mode Mode
+
+	// Transport sends requests while recording. Defaults to http.DefaultTransport.
+	Transport http.RoundTripper
+
+	// RedactHeaders lists additional header names whose values are redacted.
+	RedactHeaders []string
+
+	// Sanitize, if set, is called on each interaction before it is saved, for example
+	// to scrub personal data from request or response bodies.
+	Sanitize func(*Interaction)
+}
+
+// Cassette is the fixture file format.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is one recorded request and its response.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is the sanitized form of a request.
+type RecordedRequest struct {
+	Method  string      `json:"method"`
+	URL     string      `json:"url"`
+	Headers http.Header `json:"headers,omitempty"`
+	Body    string      `json:"body,omitempty"`
+}
+
+// RecordedResponse is the sanitized form of a response. Streaming responses are stored
+// whole and replayed in one piece.
+type RecordedResponse struct {
+	StatusCode int         `json:"statusCode"`
+	Headers    http.Header `json:"headers,omitempty"`
+	Body       string      `json:"body"`
+}
+
+// Recorder is an http.RoundTripper that records or replays provider traffic. It is safe
+// for concurrent use.
+type Recorder struct {
+	path      string
+	mode      Mode
+	transport http.RoundTripper
+	redact    []string
+	sanitize  func(*Interaction)
+
+	mu       sync.Mutex
+	cassette Cassette
+	used     []bool
+}
+
+// New creates a Recorder for the cassette at path. In replay mode the cassette is loaded
+// immediately and a missing file is an error.
+func New(path string, opts Options) (*Recorder, error) {
+	mode := opts.Mode
+	switch strings.ToLower(strings.TrimSpace(os.Getenv(ModeEnvVar))) {
+	case "record":
+		mode = ModeRecord
+	case "replay":
+		mode = ModeReplay
+	}
+
+	if mode == ModeAuto {
+		mode = ModeRecord
+		if _, err := os.Stat(path); err == nil {
+			mode = ModeReplay
+		}
+	}
+
+	transport := opts.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	r := &Recorder{
+		path:      path,
+		mode:      mode,
+		transport: transport,
+		redact:    append(append([]string{}, sensitiveHeaders...), opts.RedactHeaders...),
+		sanitize:  opts.Sanitize,
+	}
+
+	if mode == ModeReplay {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("vcr: failed to read cassette: %w", err)
+		}
+		if err := json.Unmarshal(data, &r.cassette); err != nil {
+			return nil, fmt.Errorf("vcr: failed to parse cassette %s: %w", path, err)
+		}
+		r.used = make([]bool, len(r.cassette.Interactions))
+	}
+
+	return r, nil
+}
+
+// Mode returns the mode the Recorder is running in; ModeAuto is resolved when the
+// Recorder is created.
+func (r *Recorder) Mode() Mode {
+	return r.mode
+}
+
+// HTTPClient returns an http.Client that uses the Recorder, for code that takes a client
+// rather than a transport.
+func (r *Recorder) HTTPClient() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, fmt.Errorf("vcr: failed to read request body: %w", err)
+	}
+
+	if r.mode == ModeReplay {
+		return r.replay(req, body)
+	}
+	return r.record(req, body)
+}
+
+// replay serves the first unused interaction that matches the request method, URL, and body.
+func (r *Recorder) replay(req *http.Request, body []byte) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	url := req.URL.String()
+	for i, interaction := range r.cassette.Interactions {
+		if r.used[i] || interaction.Request.Method != req.Method || interaction.Request.URL != url {
+			continue
+		}
+		if !bodiesMatch(interaction.Request.Body, body) {
+			continue
+		}
+		r.used[i] = true
+		return interaction.Response.httpResponse(req), nil
+	}
+	return nil, fmt.Errorf("%w: %s %s", ErrNoInteraction, req.Method, url)
+}
+
+// record forwards the request and saves the sanitized interaction.
+func (r *Recorder) record(req *http.Request, body []byte) (*http.Response, error) {
+	resp, err := r.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("vcr: failed to read response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	interaction := Interaction{
+		Request: RecordedRequest{
+			Method:  req.Method,
+			URL:     req.URL.String(),
+			Headers: r.redactHeaders(req.Header),
+			Body:    string(body),
+		},
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Headers:    r.redactHeaders(resp.Header),
+			Body:       string(respBody),
+		},
+	}
+	if r.sanitize != nil {
+		r.sanitize(&interaction)
+	}
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	r.mu.Unlock()
+
+	return resp, nil
+}
+
+// Stop writes the cassette when recording. It does nothing in replay mode.
+func (r *Recorder) Stop() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+
+	r.mu.Lock()
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("vcr: failed to encode cassette: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return fmt.Errorf("vcr: failed to create cassette directory: %w", err)
+	}
+	if err := os.WriteFile(r.path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("vcr: failed to write cassette: %w", err)
+	}
+	return nil
+}
+
+// APIKey returns the value of the environment variable named by envVar, or a placeholder
+// when it is unset. The placeholder has the Claude and OpenAI key prefixes, so replayed
+// tests pass configuration validation without a real key.
+func APIKey(envVar string) string {
+	if key := strings.TrimSpace(os.Getenv(envVar)); key != "" {
+		return key
+	}
+	return "sk-ant-vcr-replay"
+}
+
+// redactHeaders copies headers, replacing sensitive values with Redacted.
+func (r *Recorder) redactHeaders(headers http.Header) http.Header {
+	if len(headers) == 0 {
+		return nil
+	}
+	out := headers.Clone()
+	for _, name := range r.redact {
+		name = http.CanonicalHeaderKey(name)
+		if _, ok := out[name]; ok {
+			out[name] = []string{Redacted}
+		}
+	}
+	return out
+}
+
+// readRequestBody reads the request body and restores it for the transport.
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// bodiesMatch compares request bodies, as JSON when both parse so formatting and key
+// order don't matter.
+func bodiesMatch(recorded string, body []byte) bool {
+	if recorded == string(body) {
+		return true
+	}
+	var a, b any
+	if json.Unmarshal([]byte(recorded), &a) != nil || json.Unmarshal(body, &b) != nil {
+		return false
+	}
+	ja, _ := json.Marshal(a)
+	jb, _ := json.Marshal(b)
+	return bytes.Equal(ja, jb)
+}
+
+// httpResponse builds the response for a replayed interaction.
+func (rr RecordedResponse) httpResponse(req *http.Request) *http.Response {
+	header := rr.Headers.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", rr.StatusCode, http.StatusText(rr.StatusCode)),
+		StatusCode:    rr.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(rr.Body)),
+		ContentLength: int64(len(rr.Body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,166 @@
+package vcr
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kengibson1111/go-aiprovider/client"
+	"github.com/kengibson1111/go-aiprovider/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const claudeResponse = `{"id":"msg_1","type":"message","role":"assistant","model":"claude-sonnet-4-6","content":[{"type":"text","text":"recorded answer"}],"stop_reason":"end_turn","usage":{"input_tokens":3,"output_tokens":2}}`
+
+func newClaudeServer(t *testing.T, hits *int) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*hits++
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=secret")
+		_, _ = io.WriteString(w, claudeResponse)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRecorder_RecordThenReplay(t *testing.T) {
+	t.Setenv(ModeEnvVar, "")
+	path := filepath.Join(t.TempDir(), "fixtures", "claude.json")
+	hits := 0
+	server := newClaudeServer(t, &hits)
+
+	config := &types.AIConfig{
+		Provider: types.ProviderClaude,
+		APIKey:   "sk-ant-real-secret-key",
+		BaseURL:  server.URL,
+	}
+
+	// First run records because the cassette does not exist yet
+	rec, err := New(path, Options{})
+	require.NoError(t, err)
+	assert.Equal(t, ModeRecord, rec.Mode())
+
+	config.Transport = rec
+	aiClient, err := client.NewClientFactory().CreateClient(config)
+	require.NoError(t, err)
+	response, err := aiClient.CallWithPrompt(context.Background(), "hello")
+	require.NoError(t, err)
+	assert.Contains(t, string(response), "recorded answer")
+	require.NoError(t, rec.Stop())
+	assert.Equal(t, 1, hits)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "sk-ant-real-secret-key")
+	assert.NotContains(t, string(data), "session=secret")
+	assert.Contains(t, string(data), Redacted)
+
+	// Second run replays without contacting the server
+	server.Close()
+	rec, err = New(path, Options{})
+	require.NoError(t, err)
+	assert.Equal(t, ModeReplay, rec.Mode())
+
+	config.APIKey = "sk-ant-placeholder"
+	config.Transport = rec
+	aiClient, err = client.NewClientFactory().CreateClient(config)
+	require.NoError(t, err)
+	response, err = aiClient.CallWithPrompt(context.Background(), "hello")
+	require.NoError(t, err)
+	assert.Contains(t, string(response), "recorded answer")
+	assert.Equal(t, 1, hits)
+}
+
+func TestRecorder_ReplayMatching(t *testing.T) {
+	t.Setenv(ModeEnvVar, "")
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	cassette := `{"interactions":[
+		{"request":{"method":"POST","url":"https://api.example.com/v1/x","body":"{\"a\":1,\"b\":2}"},"response":{"statusCode":200,"body":"first"}},
+		{"request":{"method":"POST","url":"https://api.example.com/v1/x","body":"{\"a\":1,\"b\":2}"},"response":{"statusCode":429,"body":"second"}}
+	]}`
+	require.NoError(t, os.WriteFile(path, []byte(cassette), 0o644))
+
+	rec, err := New(path, Options{Mode: ModeReplay})
+	require.NoError(t, err)
+	httpClient := rec.HTTPClient()
+
+	post := func(body string) (*http.Response, error) {
+		return httpClient.Post("https://api.example.com/v1/x", "application/json", strings.NewReader(body))
+	}
+
+	// Key order and whitespace don't affect matching; identical requests replay in order
+	resp, err := post(`{"b": 2, "a": 1}`)
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, "first", string(body))
+
+	resp, err = post(`{"a":1,"b":2}`)
+	require.NoError(t, err)
+	body, _ = io.ReadAll(resp.Body)
+	assert.Equal(t, 429, resp.StatusCode)
+	assert.Equal(t, "second", string(body))
+
+	_, err = post(`{"a":1,"b":2}`)
+	assert.ErrorIs(t, err, ErrNoInteraction)
+
+	_, err = post(`{"a":2}`)
+	assert.ErrorIs(t, err, ErrNoInteraction)
+}
+
+func TestRecorder_SanitizeAndModes(t *testing.T) {
+	t.Setenv(ModeEnvVar, "")
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	_, err := New(path, Options{Mode: ModeReplay})
+	assert.Error(t, err, "replay requires an existing cassette")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"email":"jane@example.com"}`)
+	}))
+	defer server.Close()
+
+	rec, err := New(path, Options{
+		Mode:          ModeRecord,
+		RedactHeaders: []string{"X-Customer-Id"},
+		Sanitize: func(i *Interaction) {
+			i.Response.Body = strings.ReplaceAll(i.Response.Body, "jane@example.com", "user@example.com")
+		},
+	})
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set("X-Customer-Id", "cust-42")
+	req.Header.Set("Authorization", "Bearer sk-secret")
+	resp, err := rec.HTTPClient().Do(req)
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, `{"email":"jane@example.com"}`, string(body), "the caller sees the real response")
+	require.NoError(t, rec.Stop())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	for _, secret := range []string{"cust-42", "sk-secret", "jane@example.com"} {
+		assert.NotContains(t, string(data), secret)
+	}
+
+	// The environment variable overrides Options.Mode
+	t.Setenv(ModeEnvVar, "replay")
+	rec, err = New(path, Options{Mode: ModeRecord})
+	require.NoError(t, err)
+	assert.Equal(t, ModeReplay, rec.Mode())
+}
+
+func TestAPIKey(t *testing.T) {
+	t.Setenv("VCR_TEST_KEY", "")
+	assert.Equal(t, "sk-ant-vcr-replay", APIKey("VCR_TEST_KEY"))
+	t.Setenv("VCR_TEST_KEY", "sk-live")
+	assert.Equal(t, "sk-live", APIKey("VCR_TEST_KEY"))
+}